@@ -0,0 +1,108 @@
+// Package api contains the http/json control interface
+package api
+
+import (
+	"github.com/LogicalOverflow/music-sync/logging"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"net/http"
+	"time"
+)
+
+var logger = log.GetLogger("api")
+
+// EventInterval is the interval in which the event streams check the status and the playlist for changes
+var EventInterval = 500 * time.Millisecond
+
+// Song is a song with its metadata as returned by the api
+type Song struct {
+	File        string  `json:"file"`
+	Title       string  `json:"title,omitempty"`
+	Artist      string  `json:"artist,omitempty"`
+	Album       string  `json:"album,omitempty"`
+	AlbumArtist string  `json:"albumArtist,omitempty"`
+	Genre       string  `json:"genre,omitempty"`
+	Composer    string  `json:"composer,omitempty"`
+	Year        int     `json:"year,omitempty"`
+	Track       int     `json:"track,omitempty"`
+	Disc        int     `json:"disc,omitempty"`
+	Duration    float64 `json:"duration,omitempty"` // Duration is the length of the song in seconds
+}
+
+// NewSong returns the song file with the metadata md
+func NewSong(file string, md metadata.SongMetadata) Song {
+	return Song{
+		File:        file,
+		Title:       md.Title,
+		Artist:      md.Artist,
+		Album:       md.Album,
+		AlbumArtist: md.AlbumArtist,
+		Genre:       md.Genre,
+		Composer:    md.Composer,
+		Year:        md.Year,
+		Track:       md.Track,
+		Disc:        md.Disc,
+		Duration:    md.Duration.Seconds(),
+	}
+}
+
+// PlaylistEntry is a song in the playlist
+type PlaylistEntry struct {
+	Song
+	// ID identifies the entry for as long as it is in the playlist
+	ID         int  `json:"id"`
+	AutoQueued bool `json:"autoQueued"`
+}
+
+// Status is the state of playback
+type Status struct {
+	Playing bool `json:"playing"`
+	// Position is the position of the current song in the playlist
+	Position int `json:"position"`
+	// Song is the current song or nil, if there is none
+	Song *Song `json:"song"`
+	// Elapsed is the time in seconds the current song has been playing for
+	Elapsed float64 `json:"elapsed"`
+	// Length is the length of the current song in seconds at the current speed, 0 for live songs
+	Length     float64 `json:"length"`
+	Volume     float64 `json:"volume"`
+	Speed      float64 `json:"speed"`
+	AutoDJMode string  `json:"autoDJMode"`
+	Party      bool    `json:"party"`
+	// Lyrics are the lyrics of the current song. Their timestamps are in milliseconds on the timeline of Elapsed.
+	Lyrics []metadata.LyricsLine `json:"lyrics,omitempty"`
+}
+
+// Library is the content of a directory in the music directory
+type Library struct {
+	// Dirs are the sub directories of the directory
+	Dirs  []string `json:"dirs"`
+	Songs []Song   `json:"songs"`
+}
+
+// PlayerConfig is the configuration of the browser player
+type PlayerConfig struct {
+	// SampleRate is the sample rate of the chunks sent to players
+	SampleRate int `json:"sampleRate"`
+}
+
+// The handlers providing the state of the server. They are set by the server.
+var (
+	// StatusHandler returns the current status
+	StatusHandler func() Status
+	// PlaylistHandler returns the current playlist
+	PlaylistHandler func() []PlaylistEntry
+	// SearchHandler returns at most limit songs of the music directory matching query
+	SearchHandler func(query string, limit int) []Song
+	// LibraryHandler returns the content of the directory dir in the music directory
+	LibraryHandler func(dir string) Library
+	// PlayerHandler returns the configuration of the browser player
+	PlayerHandler func() PlayerConfig
+)
+
+// StartAPI starts the http api listening on address. Requests are authenticated by the api tokens of users.
+func StartAPI(address string, users map[string]ssh.UserAuth) {
+	logger.Infof("starting http api at %s", address)
+	err := http.ListenAndServe(address, NewHandler(users))
+	logger.Errorf("http api at %s stopped: %v", address, err)
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"github.com/LogicalOverflow/music-sync/ssh/sshtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testToken = "test-token"
+
+var testUsers = map[string]ssh.UserAuth{"api-user": {Token: testToken}}
+
+// commandCalls records the calls of the commands run by the endpoints, which return their output as message and
+// fail for the argument "invalid"
+var commandCalls = sshtest.RegisterCommands(func(user, name string, args []string) (string, bool) {
+	if len(args) == 1 && args[0] == "invalid" {
+		return "", false
+	}
+	return fmt.Sprintf("%s ran %s %v", user, name, args), true
+}, "queue", "remove", "move", "jump", "seek", "pause", "resume", "volume")
+
+func testRequest(t *testing.T, method, path, token string, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	NewHandler(testUsers).ServeHTTP(w, r)
+	return w
+}
+
+func decodeResponse(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	require.NoError(t, json.NewDecoder(w.Body).Decode(v), "response is not valid json")
+}
+
+func TestNewSong(t *testing.T) {
+	assert.Equal(t, Song{File: "song.mp3", Title: "title", Artist: "artist", Year: 2019, Track: 3, Duration: 90},
+		NewSong("song.mp3", metadata.SongMetadata{Title: "title", Artist: "artist", Year: 2019, Track: 3, Comment: "comment",
+			Duration: 90 * time.Second}), "NewSong returned the wrong song")
+}
+
+func TestAuthentication(t *testing.T) {
+	StatusHandler = func() Status { return Status{} }
+	defer func() { StatusHandler = nil }()
+
+	assert.Equal(t, http.StatusUnauthorized, testRequest(t, http.MethodGet, "/api/status", "", "").Code,
+		"a request without token was not rejected")
+	assert.Equal(t, http.StatusUnauthorized, testRequest(t, http.MethodGet, "/api/status", "wrong-token", "").Code,
+		"a request with a wrong token was not rejected")
+	assert.Equal(t, http.StatusOK, testRequest(t, http.MethodGet, "/api/status", testToken, "").Code,
+		"a request with a valid token was rejected")
+	assert.Equal(t, http.StatusOK, testRequest(t, http.MethodGet, "/api/status?token="+testToken, "", "").Code,
+		"a request with a valid token query parameter was rejected")
+	assert.Equal(t, http.StatusMethodNotAllowed, testRequest(t, http.MethodPost, "/api/status", testToken, "").Code,
+		"a request with the wrong method was not rejected")
+}
+
+func TestStateEndpoints(t *testing.T) {
+	song := Song{File: "a.mp3", Title: "A"}
+	StatusHandler = func() Status { return Status{Playing: true, Song: &song, Volume: 0.5} }
+	PlaylistHandler = func() []PlaylistEntry { return []PlaylistEntry{{Song: song, ID: 3, AutoQueued: true}} }
+	SearchHandler = func(query string, limit int) []Song {
+		return []Song{{File: query, Track: limit}}
+	}
+	defer func() { StatusHandler, PlaylistHandler, SearchHandler = nil, nil, nil }()
+
+	var status Status
+	decodeResponse(t, testRequest(t, http.MethodGet, "/api/status", testToken, ""), &status)
+	assert.Equal(t, StatusHandler(), status, "status endpoint returned the wrong status")
+
+	var playlist []PlaylistEntry
+	decodeResponse(t, testRequest(t, http.MethodGet, "/api/playlist", testToken, ""), &playlist)
+	assert.Equal(t, PlaylistHandler(), playlist, "playlist endpoint returned the wrong playlist")
+
+	var songs []Song
+	decodeResponse(t, testRequest(t, http.MethodGet, "/api/search?q=query&limit=3", testToken, ""), &songs)
+	assert.Equal(t, []Song{{File: "query", Track: 3}}, songs, "search endpoint did not pass the query and limit")
+	decodeResponse(t, testRequest(t, http.MethodGet, "/api/search?q=query", testToken, ""), &songs)
+	assert.Equal(t, []Song{{File: "query", Track: defaultSearchLimit}}, songs, "search endpoint did not use the default limit")
+	assert.Equal(t, http.StatusBadRequest, testRequest(t, http.MethodGet, "/api/search?limit=-1", testToken, "").Code,
+		"search endpoint accepted an invalid limit")
+}
+
+func TestLibraryEndpoint(t *testing.T) {
+	LibraryHandler = func(dir string) Library {
+		return Library{Dirs: []string{dir + "/sub"}, Songs: []Song{{File: dir + "/song.mp3"}}}
+	}
+	defer func() { LibraryHandler = nil }()
+
+	var library Library
+	decodeResponse(t, testRequest(t, http.MethodGet, "/api/library?dir=albums", testToken, ""), &library)
+	assert.Equal(t, LibraryHandler("albums"), library, "library endpoint returned the wrong library")
+
+	for _, dir := range []string{"..", "albums/../..", "/etc"} {
+		assert.Equal(t, http.StatusBadRequest, testRequest(t, http.MethodGet, "/api/library?dir="+dir, testToken, "").Code,
+			"library endpoint accepted the directory %s outside the music directory", dir)
+	}
+}
+
+func TestPlayerEndpoint(t *testing.T) {
+	assert.Equal(t, http.StatusServiceUnavailable, testRequest(t, http.MethodGet, "/api/player", "", "").Code,
+		"player endpoint did not report the server as not ready")
+
+	PlayerHandler = func() PlayerConfig { return PlayerConfig{SampleRate: 48000} }
+	defer func() { PlayerHandler = nil }()
+
+	var config PlayerConfig
+	decodeResponse(t, testRequest(t, http.MethodGet, "/api/player", "", ""), &config)
+	assert.Equal(t, PlayerConfig{SampleRate: 48000}, config, "player endpoint returned the wrong configuration without a token")
+	assert.Equal(t, http.StatusMethodNotAllowed, testRequest(t, http.MethodPost, "/api/player", "", "").Code,
+		"player endpoint accepted a post request")
+}
+
+func TestWebSocketEndpoint(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	w := httptest.NewRecorder()
+	NewHandler(testUsers).ServeHTTP(w, r)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "websocket endpoint did not report the server as not running")
+}
+
+func TestWebInterface(t *testing.T) {
+	for _, path := range []string{"/", "/app.js", "/style.css", "/player.html", "/player.js"} {
+		w := testRequest(t, http.MethodGet, path, "", "")
+		assert.Equal(t, http.StatusOK, w.Code, "the web interface did not serve %s without a token", path)
+		assert.NotEmpty(t, w.Body.String(), "the web interface served an empty %s", path)
+	}
+	assert.Contains(t, testRequest(t, http.MethodGet, "/", "", "").Body.String(), "<script src=\"app.js\">",
+		"the web interface did not serve the index page")
+}
+
+func TestStateEndpointsNotReady(t *testing.T) {
+	for _, path := range []string{"/api/status", "/api/playlist", "/api/search", "/api/library", "/api/events"} {
+		assert.Equal(t, http.StatusServiceUnavailable, testRequest(t, http.MethodGet, path, testToken, "").Code,
+			"%s did not report the server as not ready", path)
+	}
+}
+
+func TestCommandEndpoints(t *testing.T) {
+	cases := []struct {
+		path    string
+		body    string
+		call    string
+		message string
+	}{
+		{path: "/api/queue", body: `{"song": "a b.mp3"}`, call: "api-user queue a b.mp3", message: "api-user ran queue [a b.mp3]"},
+		{path: "/api/queue", body: `{"song": "a.mp3", "position": 0}`, call: "api-user queue a.mp3 0", message: "api-user ran queue [a.mp3 0]"},
+		{path: "/api/remove", body: `{"position": 2}`, call: "api-user remove 2", message: "api-user ran remove [2]"},
+		{path: "/api/move", body: `{"from": 3, "to": 0}`, call: "api-user move 3 0", message: "api-user ran move [3 0]"},
+		{path: "/api/jump", body: `{"position": 1}`, call: "api-user jump 1", message: "api-user ran jump [1]"},
+		{path: "/api/seek", body: `{"seconds": 10, "relative": true}`, call: "api-user seek +10", message: "api-user ran seek [+10]"},
+		{path: "/api/seek", body: `{"seconds": -2.5, "relative": true}`, call: "api-user seek -2.5", message: "api-user ran seek [-2.5]"},
+		{path: "/api/seek", body: `{"seconds": 70.5}`, call: "api-user seek 70.5", message: "api-user ran seek [70.5]"},
+		{path: "/api/pause", call: "api-user pause", message: "api-user ran pause []"},
+		{path: "/api/resume", body: `{"position": 1}`, call: "api-user resume", message: "api-user ran resume []"},
+		{path: "/api/volume", body: `{"volume": 0.25, "ramp": 2}`, call: "api-user volume 0.25 2", message: "api-user ran volume [0.25 2]"},
+		{path: "/api/volume", body: `{"volume": 1}`, call: "api-user volume 1", message: "api-user ran volume [1]"},
+	}
+	for _, c := range cases {
+		commandCalls.Reset()
+		w := testRequest(t, http.MethodPost, c.path, testToken, c.body)
+		if assert.Equal(t, http.StatusOK, w.Code, "%s with %s failed", c.path, c.body) {
+			var response map[string]string
+			decodeResponse(t, w, &response)
+			assert.Equal(t, c.message, response["message"], "%s with %s did not return the command output", c.path, c.body)
+			assert.Equal(t, []string{c.call}, commandCalls.Calls(), "%s with %s ran the wrong command", c.path, c.body)
+		}
+	}
+
+	invalid := []struct {
+		path string
+		body string
+		err  string
+	}{
+		{path: "/api/queue", body: `{"position": 0}`, err: "song is missing"},
+		{path: "/api/remove", body: `{}`, err: "position is missing"},
+		{path: "/api/move", body: `{"from": 1}`, err: "from or to is missing"},
+		{path: "/api/jump", body: `{}`, err: "position is missing"},
+		{path: "/api/jump", body: `{"position": "one"}`, err: "invalid request body: json: cannot unmarshal string into Go struct field commandRequest.position of type int"},
+		{path: "/api/seek", body: `{"relative": true}`, err: "seconds are missing"},
+		{path: "/api/volume", body: `{"ramp": 2}`, err: "volume is missing"},
+		{path: "/api/queue", body: `{"song": "invalid"}`, err: "No usage information for command 'queue'"},
+	}
+	for _, c := range invalid {
+		commandCalls.Reset()
+		w := testRequest(t, http.MethodPost, c.path, testToken, c.body)
+		if assert.Equal(t, http.StatusBadRequest, w.Code, "%s accepted the invalid body %s", c.path, c.body) {
+			var response map[string]string
+			decodeResponse(t, w, &response)
+			assert.Equal(t, c.err, response["error"], "%s with %s returned the wrong error", c.path, c.body)
+			assert.Empty(t, commandCalls.Calls(), "%s with %s ran a command", c.path, c.body)
+		}
+	}
+}
+
+func TestEventsEndpoint(t *testing.T) {
+	oldInterval := EventInterval
+	defer func() { EventInterval = oldInterval }()
+	EventInterval = time.Millisecond
+
+	statusCalls := 0
+	StatusHandler = func() Status {
+		statusCalls++
+		// only the elapsed time changes until the third call
+		return Status{Elapsed: float64(statusCalls), Playing: 3 <= statusCalls}
+	}
+	PlaylistHandler = func() []PlaylistEntry { return []PlaylistEntry{} }
+	defer func() { StatusHandler, PlaylistHandler = nil, nil }()
+
+	server := httptest.NewServer(NewHandler(testUsers))
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/api/events?token=" + testToken)
+	require.NoError(t, err, "failed to connect to the events endpoint")
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"), "events endpoint returned the wrong content type")
+
+	reader := bufio.NewReader(resp.Body)
+	var events bytes.Buffer
+	for i := 0; i < 9; i++ {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err, "failed to read an event")
+		events.WriteString(line)
+	}
+	assert.Equal(t, "event: status\ndata: "+`{"playing":false,"position":0,"song":null,"elapsed":1,"length":0,"volume":0,"speed":0,"autoDJMode":"","party":false}`+"\n\n"+
+		"event: playlist\ndata: []\n\n"+
+		"event: status\ndata: "+`{"playing":true,"position":0,"song":null,"elapsed":3,"length":0,"volume":0,"speed":0,"autoDJMode":"","party":false}`+"\n\n",
+		events.String(), "events endpoint did not stream the changes")
+}
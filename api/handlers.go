@@ -0,0 +1,314 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSearchLimit is the number of songs returned by searches without a limit
+const defaultSearchLimit = 50
+
+// userHandler handles a request of an authenticated user
+type userHandler func(w http.ResponseWriter, r *http.Request, user string)
+
+// commandRequest is the body of requests running a command. Fields not used by the command are ignored.
+type commandRequest struct {
+	Song     string   `json:"song"`
+	Position *int     `json:"position"`
+	Seconds  *float64 `json:"seconds"`
+	Relative bool     `json:"relative"`
+	Volume   *float64 `json:"volume"`
+	Ramp     *float64 `json:"ramp"`
+	From     *int     `json:"from"`
+	To       *int     `json:"to"`
+}
+
+// commandArgs returns the ssh command and its arguments executing a request
+type commandArgs func(req commandRequest) (string, []string, error)
+
+// commandEndpoints are the endpoints running ssh commands
+var commandEndpoints = map[string]commandArgs{
+	"/api/queue": func(req commandRequest) (string, []string, error) {
+		if req.Song == "" {
+			return "", nil, fmt.Errorf("song is missing")
+		}
+		args := []string{req.Song}
+		if req.Position != nil {
+			args = append(args, strconv.Itoa(*req.Position))
+		}
+		return "queue", args, nil
+	},
+	"/api/remove": func(req commandRequest) (string, []string, error) {
+		if req.Position == nil {
+			return "", nil, fmt.Errorf("position is missing")
+		}
+		return "remove", []string{strconv.Itoa(*req.Position)}, nil
+	},
+	"/api/move": func(req commandRequest) (string, []string, error) {
+		if req.From == nil || req.To == nil {
+			return "", nil, fmt.Errorf("from or to is missing")
+		}
+		return "move", []string{strconv.Itoa(*req.From), strconv.Itoa(*req.To)}, nil
+	},
+	"/api/jump": func(req commandRequest) (string, []string, error) {
+		if req.Position == nil {
+			return "", nil, fmt.Errorf("position is missing")
+		}
+		return "jump", []string{strconv.Itoa(*req.Position)}, nil
+	},
+	"/api/seek": func(req commandRequest) (string, []string, error) {
+		if req.Seconds == nil {
+			return "", nil, fmt.Errorf("seconds are missing")
+		}
+		if req.Relative {
+			return "seek", []string{fmt.Sprintf("%+g", *req.Seconds)}, nil
+		}
+		return "seek", []string{fmt.Sprintf("%g", *req.Seconds)}, nil
+	},
+	"/api/pause": func(commandRequest) (string, []string, error) {
+		return "pause", nil, nil
+	},
+	"/api/resume": func(commandRequest) (string, []string, error) {
+		return "resume", nil, nil
+	},
+	"/api/volume": func(req commandRequest) (string, []string, error) {
+		if req.Volume == nil {
+			return "", nil, fmt.Errorf("volume is missing")
+		}
+		args := []string{strconv.FormatFloat(*req.Volume, 'f', -1, 64)}
+		if req.Ramp != nil {
+			args = append(args, strconv.FormatFloat(*req.Ramp, 'f', -1, 64))
+		}
+		return "volume", args, nil
+	},
+}
+
+// NewHandler returns the handler of the api endpoints and the web interface. Api requests are authenticated by
+// the api tokens of users, passed as bearer token or as token query parameter. Like the tcp connections of players,
+// the browser player and its websocket are not authenticated.
+func NewHandler(users map[string]ssh.UserAuth) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", webHandler())
+	mux.HandleFunc("/api/player", handlePlayer)
+	mux.HandleFunc("/ws", comm.HandleWebSocket)
+	route := func(path, method string, h userHandler) {
+		mux.HandleFunc(path, authenticated(users, method, h))
+	}
+	route("/api/status", http.MethodGet, handleStatus)
+	route("/api/playlist", http.MethodGet, handlePlaylist)
+	route("/api/search", http.MethodGet, handleSearch)
+	route("/api/library", http.MethodGet, handleLibrary)
+	route("/api/events", http.MethodGet, handleEvents)
+	for path, args := range commandEndpoints {
+		route(path, http.MethodPost, commandHandler(args))
+	}
+	return mux
+}
+
+// requestToken returns the api token of r
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return r.URL.Query().Get("token")
+}
+
+// authenticated returns a handler, which passes requests using method with a valid token to h
+func authenticated(users map[string]ssh.UserAuth, method string, h userHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		user, ok := ssh.UserByToken(users, requestToken(r))
+		if !ok {
+			logger.Warnf("rejected api request from %s to %s: invalid token", r.RemoteAddr, r.URL.Path)
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("invalid token"))
+			return
+		}
+		h(w, r, user)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Warnf("failed to write api response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// errNotReady is returned while the server has not set the handlers yet
+var errNotReady = fmt.Errorf("the server is not ready")
+
+func handleStatus(w http.ResponseWriter, _ *http.Request, _ string) {
+	if StatusHandler == nil {
+		writeError(w, http.StatusServiceUnavailable, errNotReady)
+		return
+	}
+	writeJSON(w, http.StatusOK, StatusHandler())
+}
+
+func handlePlaylist(w http.ResponseWriter, _ *http.Request, _ string) {
+	if PlaylistHandler == nil {
+		writeError(w, http.StatusServiceUnavailable, errNotReady)
+		return
+	}
+	writeJSON(w, http.StatusOK, PlaylistHandler())
+}
+
+func handleSearch(w http.ResponseWriter, r *http.Request, _ string) {
+	if SearchHandler == nil {
+		writeError(w, http.StatusServiceUnavailable, errNotReady)
+		return
+	}
+	limit := defaultSearchLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		l, err := strconv.Atoi(value)
+		if err != nil || l <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit '%s'", value))
+			return
+		}
+		limit = l
+	}
+	writeJSON(w, http.StatusOK, SearchHandler(r.URL.Query().Get("q"), limit))
+}
+
+// ValidLibraryDir returns true, if dir is a relative path within the music directory
+func ValidLibraryDir(dir string) bool {
+	if path.IsAbs(dir) || filepath.IsAbs(dir) {
+		return false
+	}
+	for _, element := range strings.FieldsFunc(dir, func(r rune) bool { return r == '/' || r == filepath.Separator }) {
+		if element == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func handleLibrary(w http.ResponseWriter, r *http.Request, _ string) {
+	if LibraryHandler == nil {
+		writeError(w, http.StatusServiceUnavailable, errNotReady)
+		return
+	}
+	dir := r.URL.Query().Get("dir")
+	if !ValidLibraryDir(dir) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid directory '%s'", dir))
+		return
+	}
+	writeJSON(w, http.StatusOK, LibraryHandler(dir))
+}
+
+func handlePlayer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	if PlayerHandler == nil {
+		writeError(w, http.StatusServiceUnavailable, errNotReady)
+		return
+	}
+	writeJSON(w, http.StatusOK, PlayerHandler())
+}
+
+// commandHandler returns a handler, which runs the ssh command returned by args as the requesting user
+func commandHandler(args commandArgs) userHandler {
+	return func(w http.ResponseWriter, r *http.Request, user string) {
+		var req commandRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+				return
+			}
+		}
+		name, cmdArgs, err := args(req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		logger.Infof("%s runs %s %v through the api", user, name, cmdArgs)
+		msg, err := ssh.RunCommand(user, name, cmdArgs)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"message": msg})
+	}
+}
+
+// statusChanged returns true, if the status changed beyond the time elapsed in the song
+func statusChanged(old *Status, new Status) bool {
+	if old == nil {
+		return true
+	}
+	oldStatus := *old
+	oldStatus.Elapsed, new.Elapsed = 0, 0
+	o, _ := json.Marshal(oldStatus)
+	n, _ := json.Marshal(new)
+	return string(o) != string(n)
+}
+
+// writeEvent writes a server-sent event with data
+func writeEvent(w http.ResponseWriter, event string, data []byte) error {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
+
+// handleEvents streams the status and the playlist as server-sent events, whenever they change.
+// Changes of the elapsed time alone are not sent.
+func handleEvents(w http.ResponseWriter, r *http.Request, _ string) {
+	if StatusHandler == nil || PlaylistHandler == nil {
+		writeError(w, http.StatusServiceUnavailable, errNotReady)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	var lastStatus *Status
+	var lastPlaylist string
+	ticker := time.NewTicker(EventInterval)
+	defer ticker.Stop()
+	for {
+		if status := StatusHandler(); statusChanged(lastStatus, status) {
+			data, _ := json.Marshal(status)
+			if err := writeEvent(w, "status", data); err != nil {
+				return
+			}
+			lastStatus = &status
+		}
+		if playlist, _ := json.Marshal(PlaylistHandler()); string(playlist) != lastPlaylist {
+			if err := writeEvent(w, "playlist", playlist); err != nil {
+				return
+			}
+			lastPlaylist = string(playlist)
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package api
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// webFiles are the files of the web interface
+//
+//go:embed web
+var webFiles embed.FS
+
+// webHandler returns the handler serving the web interface. The web interface itself is not authenticated, it asks
+// for an api token and uses it for all api requests.
+func webHandler() http.Handler {
+	files, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		panic(err)
+	}
+	return http.FileServer(http.FS(files))
+}
@@ -0,0 +1,332 @@
+'use strict';
+
+// the state sent by the server, updated by the event stream
+const state = {
+    status: null,
+    // statusTime is the time the status was received, used to advance the elapsed time while playing
+    statusTime: 0,
+    playlist: [],
+    libraryDir: '',
+};
+
+let token = new URLSearchParams(location.search).get('token') || localStorage.getItem('music-sync-token') || '';
+let events = null;
+
+const $ = id => document.getElementById(id);
+
+function formatTime(seconds) {
+    seconds = Math.floor(seconds);
+    const pad = n => String(n).padStart(2, '0');
+    if (seconds < 3600) {
+        return Math.floor(seconds / 60) + ':' + pad(seconds % 60);
+    }
+    return Math.floor(seconds / 3600) + ':' + pad(Math.floor(seconds / 60) % 60) + ':' + pad(seconds % 60);
+}
+
+function songName(song) {
+    if (!song) {
+        return 'Nothing playing';
+    }
+    if (!song.title) {
+        return song.file;
+    }
+    return song.track ? song.track + '. ' + song.title : song.title;
+}
+
+function songDetails(song) {
+    if (!song) {
+        return '';
+    }
+    const album = song.album && song.year ? song.album + ' (' + song.year + ')' : song.album;
+    return [song.artist || song.albumArtist, album, song.genre].filter(Boolean).join(' - ');
+}
+
+// api sends a request to the api and returns the decoded response. Errors are shown as message.
+async function api(method, path, body) {
+    const options = {method: method, headers: {'Authorization': 'Bearer ' + token}};
+    if (body !== undefined) {
+        options.headers['Content-Type'] = 'application/json';
+        options.body = JSON.stringify(body);
+    }
+    const response = await fetch(path, options);
+    if (response.status === 401) {
+        showLogin('Invalid token');
+        throw new Error('invalid token');
+    }
+    const result = await response.json();
+    if (!response.ok) {
+        $('message').textContent = result.error;
+        throw new Error(result.error);
+    }
+    $('message').textContent = '';
+    return result;
+}
+
+function command(path, body) {
+    return api('POST', path, body).catch(() => {
+    });
+}
+
+function showLogin(error) {
+    if (events) {
+        events.close();
+        events = null;
+    }
+    $('app').hidden = true;
+    $('login').hidden = false;
+    $('login-error').textContent = error || '';
+}
+
+async function connect() {
+    try {
+        await api('GET', '/api/status');
+    } catch (e) {
+        showLogin(e.message);
+        return;
+    }
+    localStorage.setItem('music-sync-token', token);
+    $('login').hidden = true;
+    $('app').hidden = false;
+
+    events = new EventSource('/api/events?token=' + encodeURIComponent(token));
+    events.addEventListener('status', e => {
+        state.status = JSON.parse(e.data);
+        state.statusTime = performance.now();
+        renderStatus();
+        renderPlaylist();
+    });
+    events.addEventListener('playlist', e => {
+        state.playlist = JSON.parse(e.data);
+        renderPlaylist();
+    });
+    showLibrary('');
+}
+
+// elapsed returns the seconds the current song has been playing for, advanced since the status was received
+function elapsed() {
+    const status = state.status;
+    if (!status) {
+        return 0;
+    }
+    let seconds = status.elapsed;
+    if (status.playing) {
+        seconds += (performance.now() - state.statusTime) / 1000;
+    }
+    return status.length ? Math.min(seconds, status.length) : seconds;
+}
+
+function renderStatus() {
+    const status = state.status;
+    $('song-title').textContent = songName(status.song);
+    $('song-details').textContent = songDetails(status.song);
+    $('length').textContent = status.length ? formatTime(status.length) : 'LIVE';
+    $('play-pause').innerHTML = status.playing ? '&#9208;' : '&#9654;';
+    $('play-pause').title = status.playing ? 'Pause' : 'Resume';
+    if (document.activeElement !== $('volume')) {
+        $('volume').value = status.volume;
+    }
+    renderProgress();
+}
+
+function renderProgress() {
+    if (!state.status) {
+        return;
+    }
+    const seconds = elapsed();
+    $('elapsed').textContent = formatTime(seconds);
+    const progress = state.status.length ? seconds / state.status.length : 0;
+    $('progress-bar').style.width = (progress * 100) + '%';
+    renderLyrics(seconds * 1000);
+}
+
+// renderLyrics shows the lines around the line sung at time (in milliseconds), marking the atoms already sung
+function renderLyrics(time) {
+    const lyrics = (state.status && state.status.lyrics) || [];
+    let current = -1;
+    lyrics.forEach((line, i) => {
+        if (line.length && line[0].timestamp <= time) {
+            current = i;
+        }
+    });
+
+    const container = $('lyrics');
+    container.textContent = '';
+    for (let i = Math.max(current - 2, 0); i < lyrics.length && i <= current + 2; i++) {
+        const line = document.createElement('div');
+        if (i === current) {
+            line.className = 'current';
+        }
+        lyrics[i].forEach(atom => {
+            const span = document.createElement('span');
+            span.textContent = atom.caption;
+            if (i === current && atom.timestamp <= time) {
+                span.className = 'sung';
+            }
+            line.appendChild(span);
+        });
+        container.appendChild(line);
+    }
+}
+
+function renderPlaylist() {
+    const list = $('playlist');
+    list.textContent = '';
+    const position = state.status ? state.status.position : -1;
+    state.playlist.forEach((entry, i) => {
+        const item = document.createElement('li');
+        item.draggable = true;
+        item.dataset.index = i;
+        item.classList.toggle('current', i === position);
+        item.classList.toggle('auto-queued', entry.autoQueued);
+
+        const name = document.createElement('span');
+        name.className = 'name';
+        name.textContent = songName(entry) + (entry.artist ? ' - ' + entry.artist : '');
+        name.title = entry.file;
+        name.addEventListener('dblclick', () => command('/api/jump', {position: i}));
+
+        const duration = document.createElement('span');
+        duration.textContent = entry.duration ? formatTime(entry.duration) : '';
+
+        const remove = document.createElement('button');
+        remove.innerHTML = '&times;';
+        remove.title = 'Remove';
+        remove.addEventListener('click', () => command('/api/remove', {position: i}));
+
+        item.append(name, duration, remove);
+        list.appendChild(item);
+    });
+}
+
+// drag to reorder the playlist
+let dragFrom = -1;
+$('playlist').addEventListener('dragstart', e => {
+    dragFrom = Number(e.target.dataset.index);
+    e.dataTransfer.effectAllowed = 'move';
+});
+$('playlist').addEventListener('dragover', e => {
+    const item = e.target.closest('li');
+    if (item && 0 <= dragFrom) {
+        e.preventDefault();
+        document.querySelectorAll('#playlist .drop-target').forEach(i => i.classList.remove('drop-target'));
+        item.classList.add('drop-target');
+    }
+});
+$('playlist').addEventListener('drop', e => {
+    const item = e.target.closest('li');
+    e.preventDefault();
+    if (item && 0 <= dragFrom && Number(item.dataset.index) !== dragFrom) {
+        command('/api/move', {from: dragFrom, to: Number(item.dataset.index)});
+    }
+});
+$('playlist').addEventListener('dragend', () => {
+    dragFrom = -1;
+    document.querySelectorAll('#playlist .drop-target').forEach(i => i.classList.remove('drop-target'));
+});
+
+function songItem(song) {
+    const item = document.createElement('li');
+    const name = document.createElement('span');
+    name.className = 'name';
+    name.textContent = songName(song) + (song.artist ? ' - ' + song.artist : '');
+    name.title = song.file;
+    const queue = document.createElement('button');
+    queue.textContent = '+';
+    queue.title = 'Queue';
+    queue.addEventListener('click', () => command('/api/queue', {song: song.file}));
+    item.append(name, queue);
+    return item;
+}
+
+function renderBreadcrumbs(dir) {
+    const nav = $('breadcrumbs');
+    nav.textContent = '';
+    const parts = dir ? dir.split('/') : [];
+    const root = document.createElement('a');
+    root.textContent = 'music';
+    root.addEventListener('click', () => showLibrary(''));
+    nav.appendChild(root);
+    parts.forEach((part, i) => {
+        const link = document.createElement('a');
+        link.textContent = part;
+        link.addEventListener('click', () => showLibrary(parts.slice(0, i + 1).join('/')));
+        nav.append(' / ', link);
+    });
+}
+
+async function showLibrary(dir) {
+    let library;
+    try {
+        library = await api('GET', '/api/library?dir=' + encodeURIComponent(dir));
+    } catch (e) {
+        return;
+    }
+    state.libraryDir = dir;
+    $('search').value = '';
+    renderBreadcrumbs(dir);
+    const list = $('library');
+    list.textContent = '';
+    (library.dirs || []).forEach(sub => {
+        const item = document.createElement('li');
+        const name = document.createElement('span');
+        name.className = 'name dir';
+        name.textContent = sub.split('/').pop() + '/';
+        name.addEventListener('click', () => showLibrary(sub));
+        item.appendChild(name);
+        list.appendChild(item);
+    });
+    (library.songs || []).forEach(song => list.appendChild(songItem(song)));
+}
+
+$('search-form').addEventListener('submit', async e => {
+    e.preventDefault();
+    const query = $('search').value.trim();
+    if (!query) {
+        showLibrary(state.libraryDir);
+        return;
+    }
+    let songs;
+    try {
+        songs = await api('GET', '/api/search?q=' + encodeURIComponent(query));
+    } catch (err) {
+        return;
+    }
+    $('breadcrumbs').textContent = songs.length + ' song(s) found';
+    const list = $('library');
+    list.textContent = '';
+    songs.forEach(song => list.appendChild(songItem(song)));
+});
+
+$('play-pause').addEventListener('click', () => {
+    command(state.status && state.status.playing ? '/api/pause' : '/api/resume');
+});
+$('previous').addEventListener('click', () => {
+    command('/api/jump', {position: Math.max((state.status ? state.status.position : 0) - 1, 0)});
+});
+$('next').addEventListener('click', () => {
+    command('/api/jump', {position: (state.status ? state.status.position : 0) + 1});
+});
+$('volume').addEventListener('change', e => command('/api/volume', {volume: Number(e.target.value)}));
+$('progress').addEventListener('click', e => {
+    const status = state.status;
+    if (!status || !status.length) {
+        return;
+    }
+    const fraction = (e.clientX - e.currentTarget.getBoundingClientRect().left) / e.currentTarget.clientWidth;
+    // the length is at the current speed, seeking is in song time
+    command('/api/seek', {seconds: fraction * status.length * (status.speed || 1)});
+});
+
+$('login').addEventListener('submit', e => {
+    e.preventDefault();
+    token = $('token').value;
+    connect();
+});
+
+setInterval(renderProgress, 250);
+
+if (token) {
+    connect();
+} else {
+    showLogin();
+}
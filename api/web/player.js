@@ -0,0 +1,396 @@
+'use strict';
+
+// The player speaks the protocol of the tcp players over the websocket at /ws: each binary message is a message as
+// sent over tcp, its length (8 bytes, little endian) followed by an Envelope (see comm/packages.proto), which holds
+// the type and the zlib compressed inner message.
+
+// protobuf wire types
+const VARINT = 0, FIXED64 = 1, BYTES = 2, FIXED32 = 5;
+
+// the fields of the messages the player reads, by field number
+const messageFields = {
+    'comm.Envelope': {1: ['type', 'string'], 2: ['data', 'bytes']},
+    'comm.TimeSyncResponse': {1: ['clientSendTime', 'int'], 2: ['serverRecvTime', 'int'], 3: ['serverSendTime', 'int']},
+    'comm.QueueChunkRequest': {
+        1: ['startTime', 'int'], 2: ['chunkId', 'int'], 3: ['sampleLow', 'doubles'], 4: ['sampleHigh', 'doubles'],
+        5: ['firstSampleIndex', 'int'],
+    },
+    'comm.SetVolumeRequest': {1: ['volume', 'double'], 2: ['sampleIndex', 'int'], 3: ['rampLength', 'int']},
+    'comm.PingMessage': {},
+};
+
+// decodeMessage decodes the protobuf message type from bytes. Integers are decoded as numbers, which is exact for
+// the nanosecond timestamps of the server for more than 100 days of uptime.
+function decodeMessage(type, bytes) {
+    const fields = messageFields[type];
+    const view = new DataView(bytes.buffer, bytes.byteOffset, bytes.byteLength);
+    const message = {};
+    let pos = 0;
+
+    const varint = () => {
+        let result = 0n, shift = 0n, b;
+        do {
+            b = bytes[pos++];
+            result |= BigInt(b & 0x7f) << shift;
+            shift += 7n;
+        } while (b & 0x80);
+        return result;
+    };
+    const doubles = data => new Float64Array(data.slice().buffer);
+
+    while (pos < bytes.length) {
+        const key = Number(varint());
+        const [name, kind] = fields[key >> 3] || [];
+        switch (key & 7) {
+            case VARINT: {
+                const value = BigInt.asIntN(64, varint());
+                if (name) {
+                    message[name] = Number(value);
+                }
+                break;
+            }
+            case FIXED64: {
+                const value = view.getFloat64(pos, true);
+                pos += 8;
+                if (name === undefined) {
+                    break;
+                }
+                if (kind === 'doubles') {
+                    message[name] = (message[name] || []).concat([value]);
+                } else {
+                    message[name] = value;
+                }
+                break;
+            }
+            case BYTES: {
+                const length = Number(varint());
+                const data = bytes.subarray(pos, pos + length);
+                pos += length;
+                if (kind === 'string') {
+                    message[name] = new TextDecoder().decode(data);
+                } else if (kind === 'doubles') {
+                    message[name] = doubles(data);
+                } else if (kind === 'bytes') {
+                    message[name] = data;
+                }
+                break;
+            }
+            case FIXED32:
+                pos += 4;
+                break;
+            default:
+                throw new Error('unknown wire type ' + (key & 7));
+        }
+    }
+    return message;
+}
+
+// ProtoWriter encodes protobuf messages
+class ProtoWriter {
+    constructor() {
+        this.bytes = [];
+    }
+
+    varint(value) {
+        let v = BigInt.asUintN(64, BigInt(value));
+        while (0x80n <= v) {
+            this.bytes.push(Number(v & 0x7fn) | 0x80);
+            v >>= 7n;
+        }
+        this.bytes.push(Number(v));
+        return this;
+    }
+
+    int(field, value) {
+        return value ? this.varint(field << 3 | VARINT).varint(value) : this;
+    }
+
+    data(field, data) {
+        return data.length ? this.varint(field << 3 | BYTES).varint(data.length).raw(data) : this;
+    }
+
+    string(field, value) {
+        return this.data(field, new TextEncoder().encode(value));
+    }
+
+    raw(data) {
+        data.forEach(b => this.bytes.push(b));
+        return this;
+    }
+
+    finish() {
+        return new Uint8Array(this.bytes);
+    }
+}
+
+// zlibStored wraps data in a zlib stream of uncompressed blocks. The messages sent are tiny and this needs no
+// asynchronous CompressionStream, which would delay time sync requests.
+function zlibStored(data) {
+    const out = [0x78, 0x01];
+    let offset = 0;
+    do {
+        const length = Math.min(data.length - offset, 0xffff);
+        const final = offset + length === data.length ? 1 : 0;
+        out.push(final, length & 0xff, length >> 8, ~length & 0xff, (~length >> 8) & 0xff);
+        for (let i = 0; i < length; i++) {
+            out.push(data[offset + i]);
+        }
+        offset += length;
+    } while (offset < data.length);
+
+    let a = 1, b = 0;
+    data.forEach(d => {
+        a = (a + d) % 65521;
+        b = (b + a) % 65521;
+    });
+    out.push(b >> 8, b & 0xff, a >> 8, a & 0xff);
+    return new Uint8Array(out);
+}
+
+async function inflate(data) {
+    const stream = new Blob([data]).stream().pipeThrough(new DecompressionStream('deflate'));
+    return new Uint8Array(await new Response(stream).arrayBuffer());
+}
+
+// wire returns the message type with the encoded inner message as sent to the server
+function wire(type, inner) {
+    const envelope = new ProtoWriter().string(1, type).data(2, zlibStored(inner)).finish();
+    const message = new Uint8Array(8 + envelope.length);
+    new DataView(message.buffer).setBigUint64(0, BigInt(envelope.length), true);
+    message.set(envelope, 8);
+    return message;
+}
+
+// the number of time sync requests sent when connecting and the delay between them
+const syncCycles = 20;
+const syncCycleDelay = 100;
+// the interval in which the time is synced again
+const syncInterval = 30000;
+// the number of recent time sync responses the offset is chosen from
+const syncSamples = 32;
+
+let socket = null;
+let reconnectTimeout = null;
+let syncTimer = null;
+let sampleRate = 44100;
+let audio = null;
+let serverGain = null;
+let localGain = null;
+let chunks = 0;
+
+const clock = {
+    // offset is added to the local time (in nanoseconds) to get the time of the server
+    offset: null,
+    // samples are the offsets and round trip times of recent time syncs
+    samples: [],
+};
+
+// anchor maps a sample index of the stream to the server time it is played at
+let anchor = null;
+
+const $ = id => document.getElementById(id);
+
+function localTime() {
+    return Math.round(performance.now() * 1e6);
+}
+
+function send(type, inner) {
+    if (socket && socket.readyState === WebSocket.OPEN) {
+        socket.send(wire(type, inner));
+    }
+}
+
+function syncTime(cycles) {
+    for (let i = 0; i < cycles; i++) {
+        setTimeout(() => send('comm.TimeSyncRequest', new ProtoWriter().int(1, localTime()).finish()), i * syncCycleDelay);
+    }
+}
+
+// handleTimeSyncResponse updates the offset to the one measured with the shortest round trip, which is the least
+// affected by network jitter
+function handleTimeSyncResponse(tsr, clientRecv) {
+    const offset = ((tsr.serverRecvTime - tsr.clientSendTime) + (tsr.serverSendTime - clientRecv)) / 2;
+    const roundTrip = (clientRecv - tsr.clientSendTime) - (tsr.serverSendTime - tsr.serverRecvTime);
+    clock.samples.push({offset: offset, roundTrip: roundTrip});
+    clock.samples = clock.samples.slice(-syncSamples);
+    const best = clock.samples.reduce((a, b) => b.roundTrip < a.roundTrip ? b : a);
+    clock.offset = best.offset;
+    $('player-sync').textContent = 'Synced to the server (round trip ' + (best.roundTrip / 1e6).toFixed(1) + ' ms)';
+}
+
+// contextTime returns the time of the audio context the server time (in nanoseconds) is played at
+function contextTime(serverTime) {
+    let ts = audio.getOutputTimestamp ? audio.getOutputTimestamp() : null;
+    if (!ts || !ts.performanceTime) {
+        ts = {contextTime: audio.currentTime - (audio.outputLatency || 0), performanceTime: performance.now()};
+    }
+    const latency = Number($('player-latency').value) || 0;
+    const local = (serverTime - clock.offset) / 1e6;
+    return ts.contextTime + (local + latency - ts.performanceTime) / 1000;
+}
+
+function handleQueueChunkRequest(qcr) {
+    const low = qcr.sampleLow || [], high = qcr.sampleHigh || [];
+    const length = Math.min(low.length, high.length);
+    if (clock.offset === null || length === 0) {
+        return;
+    }
+    anchor = {index: qcr.firstSampleIndex || 0, time: qcr.startTime};
+
+    const buffer = audio.createBuffer(2, length, sampleRate);
+    const left = buffer.getChannelData(0), right = buffer.getChannelData(1);
+    for (let i = 0; i < length; i++) {
+        // NaN samples are breaks in the stream
+        left[i] = low[i] === low[i] ? low[i] : 0;
+        right[i] = high[i] === high[i] ? high[i] : 0;
+    }
+
+    const source = audio.createBufferSource();
+    source.buffer = buffer;
+    source.connect(serverGain);
+    const start = contextTime(qcr.startTime);
+    const now = audio.currentTime;
+    if (start + buffer.duration <= now) {
+        return;
+    }
+    if (start < now) {
+        source.start(now, now - start);
+    } else {
+        source.start(start);
+    }
+    chunks++;
+    $('player-status').textContent = 'Playing (' + chunks + ' chunk(s) received)';
+}
+
+function handleSetVolumeRequest(svr) {
+    const gain = serverGain.gain;
+    const volume = svr.volume || 0;
+    const now = audio.currentTime;
+    if (!anchor) {
+        gain.cancelScheduledValues(now);
+        gain.setValueAtTime(volume, now);
+        return;
+    }
+    const startTime = anchor.time + ((svr.sampleIndex || 0) - anchor.index) * 1e9 / sampleRate;
+    const start = Math.max(contextTime(startTime), now);
+    const end = start + (svr.rampLength || 0) / sampleRate;
+    gain.cancelScheduledValues(start);
+    gain.setValueAtTime(gain.value, start);
+    gain.linearRampToValueAtTime(volume, end);
+}
+
+async function handleMessage(data, clientRecv) {
+    if (!audio) {
+        return;
+    }
+    const bytes = new Uint8Array(data, 8);
+    const envelope = decodeMessage('comm.Envelope', bytes);
+    if (!(envelope.type in messageFields)) {
+        return;
+    }
+    const message = decodeMessage(envelope.type, await inflate(envelope.data || new Uint8Array(0)));
+    if (!audio) {
+        return;
+    }
+    switch (envelope.type) {
+        case 'comm.TimeSyncResponse':
+            handleTimeSyncResponse(message, clientRecv);
+            break;
+        case 'comm.QueueChunkRequest':
+            handleQueueChunkRequest(message);
+            break;
+        case 'comm.SetVolumeRequest':
+            handleSetVolumeRequest(message);
+            break;
+        case 'comm.PingMessage':
+            send('comm.PongMessage', new Uint8Array(0));
+            break;
+    }
+}
+
+function connect() {
+    const url = (location.protocol === 'https:' ? 'wss://' : 'ws://') + location.host + '/ws';
+    socket = new WebSocket(url);
+    socket.binaryType = 'arraybuffer';
+    $('player-status').textContent = 'Connecting...';
+
+    // messages are handled in order, although decompressing them is asynchronous
+    let handled = Promise.resolve();
+    socket.onmessage = e => {
+        const clientRecv = localTime();
+        handled = handled.then(() => handleMessage(e.data, clientRecv)).catch(err => console.error(err));
+    };
+    socket.onopen = () => {
+        $('player-status').textContent = 'Waiting for audio...';
+        clock.samples = [];
+        syncTime(syncCycles);
+        syncTimer = setInterval(() => syncTime(syncCycles), syncInterval);
+        const name = $('name').value.trim() || 'browser';
+        // the audio channel is 0, the default value, which is not encoded
+        send('comm.SubscribeChannelRequest', new ProtoWriter().string(2, name).finish());
+    };
+    socket.onclose = () => {
+        clearInterval(syncTimer);
+        if (socket) {
+            $('player-status').textContent = 'Disconnected, reconnecting...';
+            reconnectTimeout = setTimeout(connect, 2000);
+        }
+    };
+}
+
+async function join() {
+    let config;
+    try {
+        const response = await fetch('api/player');
+        config = await response.json();
+        if (!response.ok) {
+            throw new Error(config.error);
+        }
+    } catch (e) {
+        $('join-error').textContent = 'Failed to load the player configuration: ' + e.message;
+        return;
+    }
+    sampleRate = config.sampleRate;
+    localStorage.setItem('music-sync-player-name', $('name').value);
+
+    // the audio context has to be created by a user gesture to be allowed to play
+    audio = new AudioContext({latencyHint: 'playback'});
+    serverGain = audio.createGain();
+    localGain = audio.createGain();
+    localGain.gain.value = Number($('player-volume').value);
+    serverGain.connect(localGain).connect(audio.destination);
+
+    chunks = 0;
+    anchor = null;
+    $('join').hidden = true;
+    $('player').hidden = false;
+    connect();
+}
+
+function leave() {
+    clearTimeout(reconnectTimeout);
+    clearInterval(syncTimer);
+    const s = socket;
+    socket = null;
+    if (s) {
+        s.close();
+    }
+    if (audio) {
+        audio.close();
+        audio = null;
+    }
+    $('player').hidden = true;
+    $('join').hidden = false;
+}
+
+$('name').value = localStorage.getItem('music-sync-player-name') || '';
+$('join').addEventListener('submit', e => {
+    e.preventDefault();
+    join();
+});
+$('leave').addEventListener('click', leave);
+$('player-volume').addEventListener('input', e => {
+    if (localGain) {
+        localGain.gain.value = Number(e.target.value);
+    }
+});
@@ -20,6 +20,12 @@ const (
 	DefaultSSHUsersFile = "users.json"
 	DefaultSSHKeyFile   = "id_rsa"
 
+	DefaultAPIAddress = DefaultMusicAddress
+	DefaultAPIPort    = 0
+
+	DefaultMPDAddress = DefaultMusicAddress
+	DefaultMPDPort    = 0
+
 	DefaultAudioDir = "audio"
 
 	DefaultSampleRate = 44100
@@ -33,6 +39,21 @@ const (
 	DefaultStreamDelay        = 15 * time.Second
 
 	DefaultLyricsHistorySize = uint(5)
+
+	DefaultLoudnessCacheFile = "loudness-cache.json"
+
+	DefaultFadeDuration       = 50 * time.Millisecond
+	DefaultSilenceThreshold   = -60.0
+	DefaultVolumeRampDuration = 500 * time.Millisecond
+
+	DefaultPlaylistDir       = "playlists"
+	DefaultScheduleFile      = "schedules.json"
+	DefaultSleepFadeDuration = 30 * time.Second
+	DefaultCoverArtSize      = uint(64)
+	DefaultSpectrumBands     = uint(32)
+
+	DefaultCoverGraphics = "auto"
+	DefaultOutput        = ""
 )
 
 // TODO: refine logging
@@ -44,7 +65,9 @@ type LoggingFlag struct {
 }
 
 var loggingFlags = []LoggingFlag{
+	newLoggingFlag("api"),
 	newLoggingFlag("comm"),
+	newLoggingFlag("mpd"),
 	newLoggingFlag("play"),
 	newLoggingFlag("shed"),
 	newLoggingFlag("ssh"),
@@ -166,6 +189,32 @@ var (
 		Value: DefaultSSHKeyFile,
 	}
 
+	// APIAddressFlag is a flag for the address of the master's http api
+	APIAddressFlag = cli.StringFlag{
+		Name:  "api-address",
+		Usage: "the address to listen for http api requests on",
+		Value: DefaultAPIAddress,
+	}
+	// APIPortFlag is a flag for the port of the master's http api
+	APIPortFlag = cli.IntFlag{
+		Name:  "api-port",
+		Usage: "the port to listen for http api requests on (0 disables the http api)",
+		Value: DefaultAPIPort,
+	}
+
+	// MPDAddressFlag is a flag for the address of the master's mpd listener
+	MPDAddressFlag = cli.StringFlag{
+		Name:  "mpd-address",
+		Usage: "the address to listen for mpd clients on",
+		Value: DefaultMPDAddress,
+	}
+	// MPDPortFlag is a flag for the port of the master's mpd listener
+	MPDPortFlag = cli.IntFlag{
+		Name:  "mpd-port",
+		Usage: "the port to listen for mpd clients on (0 disables the mpd listener, mpd clients use 6600)",
+		Value: DefaultMPDPort,
+	}
+
 	// TimeSyncIntervalFlag is a flag for the time sync interval
 	TimeSyncIntervalFlag = cli.DurationFlag{
 		Name:  "time-sync-interval",
@@ -218,14 +267,128 @@ var (
 		Value: DefaultSampleRate,
 	}
 
+	// LoudnessCacheFileFlag is a flag for the file caching the measured loudness of songs
+	LoudnessCacheFileFlag = cli.StringFlag{
+		Name:  "loudness-cache",
+		Usage: "the json file caching the measured loudness of songs (empty to disable caching)",
+		Value: DefaultLoudnessCacheFile,
+	}
+
+	// FadeDurationFlag is a flag for the duration of fades when pausing, resuming or skipping songs
+	FadeDurationFlag = cli.DurationFlag{
+		Name:  "fade-duration",
+		Usage: "duration of the fade when pausing, resuming or skipping a song",
+		Value: DefaultFadeDuration,
+	}
+	// SilenceThresholdFlag is a flag for the level below which leading and trailing silence is trimmed
+	SilenceThresholdFlag = cli.Float64Flag{
+		Name:  "silence-threshold",
+		Usage: "the level in dBFS below which leading and trailing silence of songs is trimmed (0 to disable)",
+		Value: DefaultSilenceThreshold,
+	}
+	// VolumeRampDurationFlag is a flag for the default duration of volume changes
+	VolumeRampDurationFlag = cli.DurationFlag{
+		Name:  "volume-ramp-duration",
+		Usage: "default duration of a volume change",
+		Value: DefaultVolumeRampDuration,
+	}
+
+	// PlaylistDirFlag is a flag for the directory saved playlists are stored in
+	PlaylistDirFlag = cli.StringFlag{
+		Name:  "playlist-dir",
+		Usage: "the directory saved playlists are stored in",
+		Value: DefaultPlaylistDir,
+	}
+	// ScheduleFileFlag is a flag for the file scheduled actions are persisted in
+	ScheduleFileFlag = cli.StringFlag{
+		Name:  "schedule-file",
+		Usage: "the json file scheduled actions are persisted in (empty to disable persistence)",
+		Value: DefaultScheduleFile,
+	}
+	// SleepFadeDurationFlag is a flag for the duration of the fade out of the sleep timer
+	SleepFadeDurationFlag = cli.DurationFlag{
+		Name:  "sleep-fade-duration",
+		Usage: "duration of the fade out before the sleep timer pauses playback",
+		Value: DefaultSleepFadeDuration,
+	}
+	// CoverArtSizeFlag is a flag for the size covers are scaled to before they are sent to the infoers
+	CoverArtSizeFlag = cli.UintFlag{
+		Name:  "cover-art-size",
+		Usage: "size in pixels covers are scaled to before they are sent to the infoers",
+		Value: DefaultCoverArtSize,
+	}
+	// SpectrumBandsFlag is a flag for the number of bands of the spectra sent to the infoers
+	SpectrumBandsFlag = cli.UintFlag{
+		Name:  "spectrum-bands",
+		Usage: "number of bands of the spectra sent to the infoers for their visualizer (0 to disable)",
+		Value: DefaultSpectrumBands,
+	}
+
+	// PlayerNameFlag is a flag for the name of a player
+	PlayerNameFlag = cli.StringFlag{
+		Name:  "name, n",
+		Usage: "the name of the player, used to configure this player from the server",
+		Value: defaultPlayerName(),
+	}
+
 	// LyricsHistorySizeFlag is a flag for the number of lyrics lines to display
 	LyricsHistorySizeFlag = cli.UintFlag{
 		Name:  "lyrics-history-size",
 		Usage: "number of lyrics lines to display",
 		Value: DefaultLyricsHistorySize,
 	}
+
+	// EditLyricsFlag is a flag for a plain text lyrics file to time in the lyrics editor
+	EditLyricsFlag = cli.StringFlag{
+		Name:  "edit-lyrics",
+		Usage: "time the plain text lyrics in this file (one line of lyrics per line) for the current song and upload them to the server",
+	}
+
+	// KaraokeFlag is a flag to always display the lyrics in the karaoke layout
+	KaraokeFlag = cli.BoolFlag{
+		Name:  "karaoke",
+		Usage: "always display the lyrics in the karaoke layout, even if karaoke mode is off on the server",
+	}
+
+	// ControlUserFlag is a flag for the user controlling playback from the infoer
+	ControlUserFlag = cli.StringFlag{
+		Name:  "control-user",
+		Usage: "the ssh user to control playback as (using the key bindings, press ? for help)",
+	}
+	// ControlPasswordFlag is a flag for the password of the user controlling playback from the infoer
+	ControlPasswordFlag = cli.StringFlag{
+		Name:  "control-password",
+		Usage: "the password of the control user",
+	}
+
+	// CoverGraphicsFlag is a flag for how the infoer renders covers
+	CoverGraphicsFlag = cli.StringFlag{
+		Name:  "cover-graphics",
+		Usage: "how to render covers: auto, blocks, kitty, sixel or off",
+		Value: DefaultCoverGraphics,
+	}
+
+	// OutputFlag is a flag for the headless output format of the infoer
+	OutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "instead of the full-screen interface, print a line to stdout whenever the playback changes: json, text or template",
+		Value: DefaultOutput,
+	}
+	// OutputTemplateFlag is a flag for the go template formatting the lines of the template output
+	OutputTemplateFlag = cli.StringFlag{
+		Name:  "output-template",
+		Usage: "go template formatting the lines of the template output, e.g. '{{.Artist}} - {{.Title}}'",
+	}
 )
 
+func defaultPlayerName() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
 // FlagKey returns the key to retrieve the flags value from the cli context
 func FlagKey(flag cli.Flag) string {
 	return strings.Split(flag.GetName(), ",")[0]
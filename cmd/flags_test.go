@@ -8,7 +8,7 @@ import (
 )
 
 func TestAddLoggingFlags(t *testing.T) {
-	names := []string{"comm-logging", "play-logging", "shed-logging", "ssh-logging", "time-logging", "logging"}
+	names := []string{"api-logging", "comm-logging", "mpd-logging", "play-logging", "shed-logging", "ssh-logging", "time-logging", "logging"}
 	f := AddLoggingFlags([]cli.Flag{})
 	require.Equal(t, len(names), len(f), "AddLoggingFlags did not add the right number of flags")
 	for i := range names {
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/gdamore/tcell"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// controlVolumeStep is the volume change of one press of + or -
+	controlVolumeStep = 0.05
+	// controlSeekStep is the number of seconds the arrow keys seek
+	controlSeekStep = 10
+	// controlStatusDuration is how long the response to a control request is shown
+	controlStatusDuration = 5 * time.Second
+)
+
+// controlBindings are the key bindings shown in the help overlay
+var controlBindings = [][2]string{
+	{"space, p", "pause/resume"},
+	{"n", "next song"},
+	{"b", "previous song"},
+	{"+, -", "volume up/down"},
+	{"right, left", fmt.Sprintf("seek %d seconds forward/back", controlSeekStep)},
+	{"l", "show/hide the playlist"},
+	{"v", "show/hide the visualizer"},
+	{"?", "show/hide this help"},
+	{"ctrl-c", "quit"},
+}
+
+// controls sends control requests to the server, it is used while the infoer is not editing lyrics
+var controls = &playbackControls{}
+
+// playbackControls sends control requests to the server on key presses and shows their results
+type playbackControls struct {
+	mutex sync.Mutex
+
+	user, password string
+	help           bool
+	status         string
+	statusUntil    time.Time
+}
+
+// setStatus shows status for controlStatusDuration
+func (pc *playbackControls) setStatus(status string) {
+	pc.status = status
+	pc.statusUntil = time.Now().Add(controlStatusDuration)
+}
+
+// currentStatus returns the status, if it was set less than controlStatusDuration ago
+func (pc *playbackControls) currentStatus() string {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+	if time.Now().After(pc.statusUntil) {
+		return ""
+	}
+	return pc.status
+}
+
+// helpShown returns true, if the help overlay is shown
+func (pc *playbackControls) helpShown() bool {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+	return pc.help
+}
+
+// send sends a control request for action with value to the server
+func (pc *playbackControls) send(sender comm.MessageSender, action comm.ControlRequest_Action, value float64) {
+	if pc.user == "" {
+		pc.setStatus("start the infoer with --control-user to control playback")
+		return
+	}
+	request := &comm.ControlRequest{User: pc.user, Password: pc.password, Action: action, Value: value}
+	if err := sender.SendMessage(request); err != nil {
+		pc.setStatus(fmt.Sprintf("failed to send the control request: %v", err))
+	}
+}
+
+// controlActionName returns the readable name of action, e.g. volume up
+func controlActionName(action comm.ControlRequest_Action) string {
+	return strings.ToLower(strings.Replace(action.String(), "_", " ", -1))
+}
+
+// responded shows the response to a control request
+func (pc *playbackControls) responded(response *comm.ControlResponse) {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+	if response.Error != "" {
+		pc.setStatus(fmt.Sprintf("%s failed: %s", controlActionName(response.Action), response.Error))
+	} else {
+		pc.setStatus(response.Result)
+	}
+}
+
+// handleKey sends the control request bound to the key. info is used to toggle between pause and resume.
+func (pc *playbackControls) handleKey(ev *tcell.EventKey, info *playbackInformation, sender comm.MessageSender) {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	switch ev.Key() {
+	case tcell.KeyRight:
+		pc.send(sender, comm.ControlRequest_SEEK, controlSeekStep)
+	case tcell.KeyLeft:
+		pc.send(sender, comm.ControlRequest_SEEK, -controlSeekStep)
+	case tcell.KeyEscape:
+		pc.help = false
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case ' ', 'p':
+			if info.Playing {
+				pc.send(sender, comm.ControlRequest_PAUSE, 0)
+			} else {
+				pc.send(sender, comm.ControlRequest_RESUME, 0)
+			}
+		case 'n':
+			pc.send(sender, comm.ControlRequest_NEXT, 0)
+		case 'b':
+			pc.send(sender, comm.ControlRequest_PREVIOUS, 0)
+		case '+', '=':
+			pc.send(sender, comm.ControlRequest_VOLUME_UP, controlVolumeStep)
+		case '-':
+			pc.send(sender, comm.ControlRequest_VOLUME_DOWN, controlVolumeStep)
+		case '?', 'h':
+			pc.help = !pc.help
+		}
+	}
+}
+
+// drawControls draws the status of the last control request below the playback info and the help overlay
+func drawControls(d *drawer) {
+	if status := controls.currentStatus(); status != "" {
+		d.drawString(2, d.h-1, tcell.StyleDefault, " "+status+" ")
+	}
+	if !controls.helpShown() {
+		return
+	}
+
+	width := 0
+	for _, b := range controlBindings {
+		if w := len(b[0]) + len(b[1]) + 6; width < w {
+			width = w
+		}
+	}
+	height := len(controlBindings) + 2
+	x, y := (d.w-width)/2, (d.h-height)/2
+	if x < 0 || y < 0 {
+		return
+	}
+	for i := x; i < x+width; i++ {
+		for j := y; j < y+height; j++ {
+			d.SetContent(i, j, ' ', nil, tcell.StyleDefault)
+		}
+	}
+	for i, b := range controlBindings {
+		d.drawString(x+2, y+1+i, tcell.StyleDefault.Bold(true), b[0])
+		d.drawString(x+width-2-len(b[1]), y+1+i, tcell.StyleDefault, b[1])
+	}
+	d.drawBox(x, y, width, height, tcell.StyleDefault)
+	d.drawString(x+2, y, tcell.StyleDefault, "Key Bindings")
+}
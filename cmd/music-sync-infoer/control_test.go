@@ -0,0 +1,64 @@
+package main
+
+import (
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/gdamore/tcell"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var controlKeyCases = []struct {
+	key     tcell.Key
+	r       rune
+	playing bool
+	request *comm.ControlRequest
+}{
+	{key: tcell.KeyRune, r: ' ', playing: true, request: &comm.ControlRequest{Action: comm.ControlRequest_PAUSE}},
+	{key: tcell.KeyRune, r: 'p', playing: false, request: &comm.ControlRequest{Action: comm.ControlRequest_RESUME}},
+	{key: tcell.KeyRune, r: 'n', request: &comm.ControlRequest{Action: comm.ControlRequest_NEXT}},
+	{key: tcell.KeyRune, r: 'b', request: &comm.ControlRequest{Action: comm.ControlRequest_PREVIOUS}},
+	{key: tcell.KeyRune, r: '+', request: &comm.ControlRequest{Action: comm.ControlRequest_VOLUME_UP, Value: controlVolumeStep}},
+	{key: tcell.KeyRune, r: '-', request: &comm.ControlRequest{Action: comm.ControlRequest_VOLUME_DOWN, Value: controlVolumeStep}},
+	{key: tcell.KeyRight, request: &comm.ControlRequest{Action: comm.ControlRequest_SEEK, Value: controlSeekStep}},
+	{key: tcell.KeyLeft, request: &comm.ControlRequest{Action: comm.ControlRequest_SEEK, Value: -controlSeekStep}},
+	{key: tcell.KeyRune, r: 'x', request: nil},
+}
+
+func TestPlaybackControls_handleKey(t *testing.T) {
+	pc := &playbackControls{user: "user", password: "password"}
+	for _, c := range controlKeyCases {
+		fms := &fakeMessageSender{}
+		pc.handleKey(tcell.NewEventKey(c.key, c.r, tcell.ModNone), &playbackInformation{Playing: c.playing}, fms)
+		if c.request == nil {
+			assert.Empty(t, fms.messages, "handleKey sent a request for the unbound key %q", c.r)
+			continue
+		}
+		c.request.User, c.request.Password = "user", "password"
+		assert.Equal(t, []proto.Message{c.request}, fms.messages, "handleKey sent the wrong request for key %v %q", c.key, c.r)
+	}
+
+	pc.handleKey(tcell.NewEventKey(tcell.KeyRune, '?', tcell.ModNone), &playbackInformation{}, nil)
+	assert.True(t, pc.helpShown(), "handleKey did not show the help")
+	pc.handleKey(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone), &playbackInformation{}, nil)
+	assert.False(t, pc.helpShown(), "handleKey did not hide the help")
+}
+
+func TestPlaybackControls_withoutUser(t *testing.T) {
+	pc := &playbackControls{}
+	fms := &fakeMessageSender{}
+	pc.handleKey(tcell.NewEventKey(tcell.KeyRune, 'n', tcell.ModNone), &playbackInformation{}, fms)
+	assert.Empty(t, fms.messages, "handleKey sent a request without a user")
+	assert.Equal(t, "start the infoer with --control-user to control playback", pc.currentStatus(),
+		"handleKey did not explain how to control playback")
+}
+
+func TestPlaybackControls_responded(t *testing.T) {
+	pc := &playbackControls{}
+	assert.Equal(t, "", pc.currentStatus(), "playbackControls has a status before any response")
+
+	pc.responded(&comm.ControlResponse{Action: comm.ControlRequest_NEXT, Result: "jumped to 3"})
+	assert.Equal(t, "jumped to 3", pc.currentStatus(), "responded did not show the result")
+	pc.responded(&comm.ControlResponse{Action: comm.ControlRequest_VOLUME_UP, Error: "invalid credentials"})
+	assert.Equal(t, "volume up failed: invalid credentials", pc.currentStatus(), "responded did not show the error")
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/gdamore/tcell"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// the ways covers are rendered
+const (
+	coverGraphicsAuto   = "auto"
+	coverGraphicsBlocks = "blocks"
+	coverGraphicsKitty  = "kitty"
+	coverGraphicsSixel  = "sixel"
+	coverGraphicsOff    = "off"
+)
+
+// coverCacheSize is the number of covers kept, so covers of songs announced again do not have to be resent
+const coverCacheSize = 8
+
+// kittyChunkSize is the maximal size of the base64 payload of a single kitty graphics escape sequence
+const kittyChunkSize = 4096
+
+// the assumed size of a terminal cell in pixels, used to size sixel images
+const (
+	sixelCellWidth  = 10
+	sixelCellHeight = 20
+)
+
+// coverGraphics is the way covers are rendered
+var coverGraphics = coverGraphicsBlocks
+
+// coverOutput is where the escape sequences of kitty and sixel images are written to
+var coverOutput io.Writer = os.Stdout
+
+// coverImage is a cover sent by the server
+type coverImage struct {
+	id  string
+	png []byte
+	img image.Image
+}
+
+// coverPlacement is the position of a cover rendered with kitty or sixel graphics
+type coverPlacement struct {
+	id               string
+	x, y, cols, rows int
+}
+
+var (
+	wantedCover coverPlacement
+	placedCover coverPlacement
+)
+
+// detectCoverGraphics returns the best way to render covers in the terminal described by the environment
+func detectCoverGraphics(getenv func(string) string) string {
+	term := getenv("TERM")
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || term == "xterm-ghostty" || getenv("TERM_PROGRAM") == "WezTerm":
+		return coverGraphicsKitty
+	case strings.Contains(term, "sixel") || term == "foot" || term == "mlterm" || strings.HasPrefix(term, "yaft"):
+		return coverGraphicsSixel
+	default:
+		return coverGraphicsBlocks
+	}
+}
+
+// parseCoverGraphics returns the way covers are rendered for the value of the cover-graphics flag
+func parseCoverGraphics(value string) (string, error) {
+	switch value {
+	case coverGraphicsAuto:
+		return detectCoverGraphics(os.Getenv), nil
+	case coverGraphicsBlocks, coverGraphicsKitty, coverGraphicsSixel, coverGraphicsOff:
+		return value, nil
+	default:
+		return "", fmt.Errorf("unknown cover graphics '%s'", value)
+	}
+}
+
+// addCover decodes the png of a cover and adds it to the cache, dropping the oldest cover if it is full
+func (s *state) addCover(id string, data []byte) error {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	s.CoversMutex.Lock()
+	defer s.CoversMutex.Unlock()
+	for _, c := range s.Covers {
+		if c.id == id {
+			return nil
+		}
+	}
+	if coverCacheSize <= len(s.Covers) {
+		s.Covers = s.Covers[1:]
+	}
+	s.Covers = append(s.Covers, coverImage{id: id, png: data, img: img})
+	return nil
+}
+
+// cover returns the cached cover with id or nil, if it is not cached
+func (s *state) cover(id string) *coverImage {
+	s.CoversMutex.RLock()
+	defer s.CoversMutex.RUnlock()
+	for i := range s.Covers {
+		if s.Covers[i].id == id {
+			c := s.Covers[i]
+			return &c
+		}
+	}
+	return nil
+}
+
+// halfBlockColors returns the colors of the cell in column x and row of a half block rendering of img.
+// The upper pixel is the foreground of '▀', the lower pixel its background.
+func halfBlockColors(img image.Image, x, row int) (upper, lower tcell.Color) {
+	b := img.Bounds()
+	toColor := func(y int) tcell.Color {
+		if b.Max.Y <= y {
+			return tcell.ColorDefault
+		}
+		c := color.RGBAModel.Convert(img.At(b.Min.X+x, y)).(color.RGBA)
+		return tcell.NewRGBColor(int32(c.R), int32(c.G), int32(c.B))
+	}
+	return toColor(b.Min.Y + 2*row), toColor(b.Min.Y + 2*row + 1)
+}
+
+// drawHalfBlocks draws img at x, y using one cell for two pixels above each other
+func (d *drawer) drawHalfBlocks(x, y int, img image.Image) {
+	b := img.Bounds()
+	for row := 0; row < (b.Dy()+1)/2; row++ {
+		for col := 0; col < b.Dx(); col++ {
+			upper, lower := halfBlockColors(img, col, row)
+			d.SetContent(x+col, y+row, '▀', nil, tcell.StyleDefault.Foreground(upper).Background(lower))
+		}
+	}
+}
+
+// drawCover draws the cover of the current song above the row bottom and returns its width or 0, if no cover
+// is drawn. With kitty or sixel graphics, the cover is only placed after the screen is shown.
+func drawCover(d *drawer, info *playbackInformation, bottom int) int {
+	wantedCover = coverPlacement{}
+	if coverGraphics == coverGraphicsOff || info.CurrentSong.coverID == "" {
+		return 0
+	}
+	c := currentState.cover(info.CurrentSong.coverID)
+	if c == nil {
+		return 0
+	}
+	size := d.w - 2
+	if 2*bottom < size {
+		size = 2 * bottom
+	}
+	if size < 4 {
+		return 0
+	}
+	if coverGraphics == coverGraphicsBlocks {
+		img := metadata.ScaleCover(c.img, size)
+		d.drawHalfBlocks(1, 0, img)
+		return img.Bounds().Dx()
+	}
+	wantedCover = coverPlacement{id: c.id, x: 1, y: 0, cols: size, rows: size / 2}
+	return size
+}
+
+// showCoverGraphics places the cover drawn last with kitty or sixel graphics, if it changed since it was placed
+func showCoverGraphics(d *drawer) {
+	if wantedCover == placedCover {
+		return
+	}
+	if coverGraphics == coverGraphicsKitty {
+		io.WriteString(coverOutput, kittyDelete())
+	} else if placedCover.id != "" {
+		// a full redraw removes the old sixel image
+		d.Sync()
+	}
+	placedCover = wantedCover
+	c := currentState.cover(wantedCover.id)
+	if c == nil {
+		return
+	}
+
+	var graphic string
+	if coverGraphics == coverGraphicsKitty {
+		graphic = kittyImage(c.png, wantedCover.cols, wantedCover.rows)
+	} else {
+		graphic = sixelImage(scaleNearest(c.img, wantedCover.cols*sixelCellWidth, wantedCover.rows*sixelCellHeight))
+	}
+	io.WriteString(coverOutput, fmt.Sprintf("\x1b7\x1b[%d;%dH%s\x1b8", wantedCover.y+1, wantedCover.x+1, graphic))
+}
+
+// kittyImage returns the escape sequences displaying the png data in a box of cols x rows cells using the kitty
+// graphics protocol
+func kittyImage(data []byte, cols, rows int) string {
+	payload := base64.StdEncoding.EncodeToString(data)
+	var buf bytes.Buffer
+	for first := true; first || payload != ""; first = false {
+		chunk := payload
+		if kittyChunkSize < len(chunk) {
+			chunk = chunk[:kittyChunkSize]
+		}
+		payload = payload[len(chunk):]
+		more := 0
+		if payload != "" {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&buf, "\x1b_Ga=T,f=100,q=2,C=1,c=%d,r=%d,m=%d;%s\x1b\\", cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&buf, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return buf.String()
+}
+
+// kittyDelete returns the escape sequence deleting all images displayed using the kitty graphics protocol
+func kittyDelete() string {
+	return "\x1b_Ga=d,q=2\x1b\\"
+}
+
+// sixelColorIndex returns the index of c in the 6x6x6 color cube used for sixel images or -1, if c is transparent
+func sixelColorIndex(c color.Color) int {
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+	if rgba.A < 128 {
+		return -1
+	}
+	level := func(v uint8) int { return (int(v)*5 + 127) / 255 }
+	return 36*level(rgba.R) + 6*level(rgba.G) + level(rgba.B)
+}
+
+// writeSixelRun writes count sixels of value, using the repeat introducer for longer runs
+func writeSixelRun(buf *bytes.Buffer, value byte, count int) {
+	if 3 < count {
+		fmt.Fprintf(buf, "!%d%c", count, value)
+		return
+	}
+	for i := 0; i < count; i++ {
+		buf.WriteByte(value)
+	}
+}
+
+// sixelImage returns the escape sequence displaying img as sixel image, using a 6x6x6 color cube as palette
+func sixelImage(img image.Image) string {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	indices := make([]int, w*h)
+	used := make(map[int]bool)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := sixelColorIndex(img.At(b.Min.X+x, b.Min.Y+y))
+			indices[y*w+x] = i
+			if 0 <= i {
+				used[i] = true
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "\x1bPq\"1;1;%d;%d", w, h)
+	palette := make([]int, 0, len(used))
+	for i := range used {
+		palette = append(palette, i)
+	}
+	sort.Ints(palette)
+	for _, i := range palette {
+		fmt.Fprintf(&buf, "#%d;2;%d;%d;%d", i, i/36*20, i/6%6*20, i%6*20)
+	}
+
+	for top := 0; top < h; top += 6 {
+		bandColors := make([]int, 0)
+		for _, i := range palette {
+			for j := top * w; j < (top+6)*w && j < len(indices); j++ {
+				if indices[j] == i {
+					bandColors = append(bandColors, i)
+					break
+				}
+			}
+		}
+		for n, i := range bandColors {
+			if 0 < n {
+				buf.WriteByte('$')
+			}
+			fmt.Fprintf(&buf, "#%d", i)
+			var last byte
+			count := 0
+			for x := 0; x < w; x++ {
+				bits := 0
+				for dy := 0; dy < 6 && top+dy < h; dy++ {
+					if indices[(top+dy)*w+x] == i {
+						bits |= 1 << uint(dy)
+					}
+				}
+				value := byte(63 + bits)
+				if 0 < count && value != last {
+					writeSixelRun(&buf, last, count)
+					count = 0
+				}
+				last = value
+				count++
+			}
+			writeSixelRun(&buf, last, count)
+		}
+		buf.WriteByte('-')
+	}
+	buf.WriteString("\x1b\\")
+	return buf.String()
+}
+
+// scaleNearest scales img to w x h pixels, using the nearest pixel of img for each pixel
+func scaleNearest(img image.Image, w, h int) image.Image {
+	b := img.Bounds()
+	scaled := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			scaled.Set(x, y, img.At(b.Min.X+x*b.Dx()/w, b.Min.Y+y*b.Dy()/h))
+		}
+	}
+	return scaled
+}
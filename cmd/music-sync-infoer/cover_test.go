@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func testCoverPNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode cover: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func testTwoPixelCover() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 1, 2))
+	img.SetRGBA(0, 0, color.RGBA{R: 255, A: 255})
+	img.SetRGBA(0, 1, color.RGBA{B: 255, A: 255})
+	return img
+}
+
+func TestDetectCoverGraphics(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+	assert.Equal(t, coverGraphicsKitty, detectCoverGraphics(env(map[string]string{"KITTY_WINDOW_ID": "1", "TERM": "screen"})),
+		"detectCoverGraphics did not detect kitty by its window id")
+	assert.Equal(t, coverGraphicsKitty, detectCoverGraphics(env(map[string]string{"TERM": "xterm-kitty"})),
+		"detectCoverGraphics did not detect kitty by its TERM")
+	assert.Equal(t, coverGraphicsSixel, detectCoverGraphics(env(map[string]string{"TERM": "foot"})),
+		"detectCoverGraphics did not detect a sixel terminal")
+	assert.Equal(t, coverGraphicsBlocks, detectCoverGraphics(env(map[string]string{"TERM": "xterm-256color"})),
+		"detectCoverGraphics did not fall back to blocks")
+}
+
+func TestParseCoverGraphics(t *testing.T) {
+	for _, mode := range []string{coverGraphicsBlocks, coverGraphicsKitty, coverGraphicsSixel, coverGraphicsOff} {
+		parsed, err := parseCoverGraphics(mode)
+		if assert.NoError(t, err, "parseCoverGraphics returned an error for %s", mode) {
+			assert.Equal(t, mode, parsed, "parseCoverGraphics returned the wrong mode for %s", mode)
+		}
+	}
+	_, err := parseCoverGraphics("ascii")
+	assert.Error(t, err, "parseCoverGraphics did not return an error for an unknown mode")
+}
+
+func TestState_addCover(t *testing.T) {
+	s := &state{}
+	data := testCoverPNG(t, testTwoPixelCover())
+	assert.Error(t, s.addCover("broken", []byte("not a png")), "addCover did not return an error for a broken png")
+	for i := 0; i < coverCacheSize+1; i++ {
+		assert.NoError(t, s.addCover(string(rune('a'+i)), data), "addCover returned an error")
+	}
+	assert.NoError(t, s.addCover("b", data), "addCover returned an error for a cached cover")
+
+	assert.Len(t, s.Covers, coverCacheSize, "addCover did not limit the cache size")
+	assert.Nil(t, s.cover("a"), "addCover did not drop the oldest cover")
+	assert.Nil(t, s.cover("broken"), "addCover cached a broken cover")
+	if c := s.cover("b"); assert.NotNil(t, c, "cover did not return a cached cover") {
+		assert.Equal(t, data, c.png, "cover returned the wrong png")
+		assert.Equal(t, color.RGBA{R: 255, A: 255}, color.RGBAModel.Convert(c.img.At(0, 0)), "addCover decoded the png wrongly")
+	}
+}
+
+func TestHalfBlockColors(t *testing.T) {
+	upper, lower := halfBlockColors(testTwoPixelCover(), 0, 0)
+	assert.Equal(t, tcell.NewRGBColor(255, 0, 0), upper, "halfBlockColors returned the wrong upper color")
+	assert.Equal(t, tcell.NewRGBColor(0, 0, 255), lower, "halfBlockColors returned the wrong lower color")
+
+	upper, lower = halfBlockColors(image.NewRGBA(image.Rect(0, 0, 1, 1)), 0, 0)
+	assert.Equal(t, tcell.NewRGBColor(0, 0, 0), upper, "halfBlockColors returned the wrong upper color of an odd image")
+	assert.Equal(t, tcell.ColorDefault, lower, "halfBlockColors did not use the default color below an odd image")
+}
+
+func TestKittyImage(t *testing.T) {
+	assert.Equal(t, "\x1b_Ga=T,f=100,q=2,C=1,c=4,r=2,m=0;AQID\x1b\\", kittyImage([]byte{1, 2, 3}, 4, 2),
+		"kittyImage returned the wrong escape sequence")
+
+	data := bytes.Repeat([]byte{0xff}, kittyChunkSize)
+	sequences := strings.Split(strings.TrimSuffix(kittyImage(data, 10, 5), "\x1b\\"), "\x1b\\")
+	if assert.Len(t, sequences, 2, "kittyImage did not split the payload into chunks") {
+		assert.True(t, strings.HasPrefix(sequences[0], "\x1b_Ga=T,f=100,q=2,C=1,c=10,r=5,m=1;"), "kittyImage did not announce more chunks")
+		assert.True(t, strings.HasPrefix(sequences[1], "\x1b_Gm=0;"), "kittyImage did not end the chunks")
+		payload := sequences[0][strings.Index(sequences[0], ";")+1:] + sequences[1][len("\x1b_Gm=0;"):]
+		decoded, err := base64.StdEncoding.DecodeString(payload)
+		if assert.NoError(t, err, "kittyImage returned an invalid payload") {
+			assert.Equal(t, data, decoded, "kittyImage returned the wrong payload")
+		}
+	}
+}
+
+func TestSixelImage(t *testing.T) {
+	assert.Equal(t, "\x1bPq\"1;1;1;2#5;2;0;0;100#180;2;100;0;0#5A$#180@-\x1b\\", sixelImage(testTwoPixelCover()),
+		"sixelImage returned the wrong escape sequence")
+
+	wide := image.NewRGBA(image.Rect(0, 0, 5, 1))
+	for x := 0; x < 5; x++ {
+		wide.SetRGBA(x, 0, color.RGBA{G: 255, A: 255})
+	}
+	assert.Equal(t, "\x1bPq\"1;1;5;1#30;2;0;100;0#30!5@-\x1b\\", sixelImage(wide), "sixelImage did not compress runs")
+	assert.Equal(t, -1, sixelColorIndex(color.RGBA{}), "sixelColorIndex did not treat transparent pixels as transparent")
+}
+
+func TestScaleNearest(t *testing.T) {
+	scaled := scaleNearest(testTwoPixelCover(), 2, 4)
+	assert.Equal(t, image.Rect(0, 0, 2, 4), scaled.Bounds(), "scaleNearest returned an image of the wrong size")
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, color.RGBAModel.Convert(scaled.At(1, 1)), "scaleNearest returned the wrong upper pixel")
+	assert.Equal(t, color.RGBA{B: 255, A: 255}, color.RGBAModel.Convert(scaled.At(0, 2)), "scaleNearest returned the wrong lower pixel")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"github.com/LogicalOverflow/music-sync/timing"
 	"github.com/gdamore/tcell"
 	"math"
 )
@@ -19,6 +20,15 @@ func (d *drawer) eventLoop() {
 			if ev.Key() == tcell.KeyCtrlC {
 				return
 			}
+			if ev.Key() == tcell.KeyRune && ev.Rune() == 'l' {
+				togglePlaylistPanel()
+			} else if ev.Key() == tcell.KeyRune && ev.Rune() == 'v' {
+				toggleVisualizer()
+			} else if editor != nil {
+				editor.handleKey(ev, currentState.Info(timing.GetSyncedTime()))
+			} else {
+				controls.handleKey(ev, currentState.Info(timing.GetSyncedTime()), serverSender)
+			}
 		case *tcell.EventResize:
 			d.Sync()
 			d.w, d.h = d.Size()
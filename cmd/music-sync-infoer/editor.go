@@ -0,0 +1,313 @@
+package main
+
+import (
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/LogicalOverflow/music-sync/schedule"
+	"github.com/gdamore/tcell"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+)
+
+const editorHelp = "space: word  enter: line  backspace: undo  r: restart  s: save"
+
+var (
+	editorTappedStyle = tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	editorNextStyle   = tcell.StyleDefault.Foreground(tcell.ColorYellow).Bold(true)
+)
+
+// editor is the lyrics editor, it is nil if the infoer is not editing lyrics
+var editor *lyricsEditor
+
+// serverSender is used to send messages to the server
+var serverSender comm.MessageSender
+
+// editorWord is a word of the plain text lyrics, including the space following it
+type editorWord struct {
+	text string
+	line int
+}
+
+// editorTap is the timestamp of the words from start to end (exclusive)
+type editorTap struct {
+	start, end int
+	timestamp  int64
+}
+
+// lyricsEditor times plain text lyrics by tapping a key at each word or line while the song plays
+type lyricsEditor struct {
+	mutex sync.Mutex
+
+	words  []editorWord
+	song   string
+	taps   []editorTap
+	next   int
+	status string
+}
+
+// newLyricsEditor returns an editor for the plain text lyrics in text, empty lines are ignored
+func newLyricsEditor(text string) *lyricsEditor {
+	le := &lyricsEditor{}
+	line := 0
+	for _, l := range strings.Split(strings.Replace(text, "\r", "", -1), "\n") {
+		fields := strings.Fields(l)
+		if len(fields) == 0 {
+			continue
+		}
+		for i, f := range fields {
+			if i+1 < len(fields) {
+				f += " "
+			}
+			le.words = append(le.words, editorWord{text: f, line: line})
+		}
+		line++
+	}
+	return le
+}
+
+// loadLyricsEditor returns an editor for the plain text lyrics in the file at path
+func loadLyricsEditor(path string) (*lyricsEditor, error) {
+	text, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	le := newLyricsEditor(string(text))
+	if len(le.words) == 0 {
+		return nil, fmt.Errorf("%s contains no lyrics", path)
+	}
+	return le, nil
+}
+
+// lineEnd returns the index of the first word after the line of the word at index i
+func (le *lyricsEditor) lineEnd(i int) int {
+	end := i
+	for end < len(le.words) && le.words[end].line == le.words[i].line {
+		end++
+	}
+	return end
+}
+
+// tap sets the timestamp of the words from the next word to end. The first tap selects the song edited.
+func (le *lyricsEditor) tap(song string, timestamp int64, end func(next int) int) {
+	if len(le.words) <= le.next {
+		le.status = "all lyrics are timed, press s to save"
+		return
+	}
+	if song == "" {
+		le.status = "no song is playing"
+		return
+	}
+	if le.song == "" {
+		le.song = song
+	}
+	if le.song != song {
+		le.status = "the song changed, press r to restart"
+		return
+	}
+	tap := editorTap{start: le.next, end: end(le.next), timestamp: timestamp}
+	le.taps = append(le.taps, tap)
+	le.next = tap.end
+	le.status = ""
+}
+
+// tapWord sets the timestamp of the next word
+func (le *lyricsEditor) tapWord(song string, timestamp int64) {
+	le.tap(song, timestamp, func(next int) int { return next + 1 })
+}
+
+// tapLine sets the timestamp of the rest of the current line
+func (le *lyricsEditor) tapLine(song string, timestamp int64) {
+	le.tap(song, timestamp, le.lineEnd)
+}
+
+// undo removes the last tap
+func (le *lyricsEditor) undo() {
+	if len(le.taps) == 0 {
+		return
+	}
+	le.next = le.taps[len(le.taps)-1].start
+	le.taps = le.taps[:len(le.taps)-1]
+	if len(le.taps) == 0 {
+		le.song = ""
+	}
+	le.status = ""
+}
+
+// restart removes all taps
+func (le *lyricsEditor) restart() {
+	le.taps = nil
+	le.next = 0
+	le.song = ""
+	le.status = ""
+}
+
+// lyrics returns the lyrics timed so far
+func (le *lyricsEditor) lyrics() []metadata.LyricsLine {
+	lyrics := make([]metadata.LyricsLine, 0)
+	line := -1
+	for _, tap := range le.taps {
+		caption := ""
+		for _, w := range le.words[tap.start:tap.end] {
+			caption += w.text
+		}
+		if le.words[tap.start].line != line {
+			line = le.words[tap.start].line
+			lyrics = append(lyrics, metadata.LyricsLine{})
+		}
+		lyrics[len(lyrics)-1] = append(lyrics[len(lyrics)-1], metadata.LyricsAtom{Timestamp: tap.timestamp, Caption: caption})
+	}
+	return lyrics
+}
+
+// uploadRequest returns the request uploading the lyrics timed so far with the credentials of the controls
+func (le *lyricsEditor) uploadRequest() (*comm.UploadLyricsRequest, error) {
+	if controls.user == "" {
+		return nil, fmt.Errorf("start the infoer with --control-user to upload lyrics")
+	}
+	if len(le.taps) == 0 {
+		return nil, fmt.Errorf("no lyrics are timed yet")
+	}
+	lyrics := le.lyrics()
+	wireLyrics := make([]*comm.NewSongInfo_SongLyricsLine, len(lyrics))
+	for i, l := range lyrics {
+		atoms := make([]*comm.NewSongInfo_SongLyricsAtom, len(l))
+		for j, a := range l {
+			atoms[j] = &comm.NewSongInfo_SongLyricsAtom{Timestamp: a.Timestamp, Caption: a.Caption}
+		}
+		wireLyrics[i] = &comm.NewSongInfo_SongLyricsLine{Atoms: atoms}
+	}
+	return &comm.UploadLyricsRequest{
+		SongFileName: le.song,
+		Lyrics:       wireLyrics,
+		User:         controls.user,
+		Password:     controls.password,
+	}, nil
+}
+
+// save uploads the lyrics timed so far to the server
+func (le *lyricsEditor) save(sender comm.MessageSender) {
+	request, err := le.uploadRequest()
+	if err != nil {
+		le.status = err.Error()
+		return
+	}
+	if err := sender.SendMessage(request); err != nil {
+		le.status = fmt.Sprintf("failed to upload the lyrics: %v", err)
+		return
+	}
+	le.status = fmt.Sprintf("uploading the lyrics of %s", le.song)
+}
+
+// uploaded shows the result of an upload
+func (le *lyricsEditor) uploaded(response *comm.UploadLyricsResponse) {
+	le.mutex.Lock()
+	defer le.mutex.Unlock()
+	if response.Error != "" {
+		le.status = fmt.Sprintf("failed to save the lyrics of %s: %s", response.SongFileName, response.Error)
+	} else {
+		le.status = fmt.Sprintf("saved the lyrics of %s", response.SongFileName)
+	}
+}
+
+// songFilePosition returns the position in the song file in milliseconds, which lyrics timestamps refer to.
+// Unlike the time in the song, it includes the start of trimmed songs and is not stretched by the speed.
+func songFilePosition(info *playbackInformation) int64 {
+	song := info.CurrentSong
+	speed := song.speed
+	if speed == 0 {
+		speed = 1
+	}
+	elapsed := info.TimeInSong - time.Duration(song.offset)*time.Second/time.Duration(schedule.SampleRate)
+	start := time.Duration(song.filePosition) * time.Second / time.Duration(schedule.SampleRate)
+	return int64(start/time.Millisecond) + int64(float64(elapsed/time.Millisecond)*speed)
+}
+
+// handleKey handles the keys of the editor at the playback position info
+func (le *lyricsEditor) handleKey(ev *tcell.EventKey, info *playbackInformation) {
+	le.mutex.Lock()
+	defer le.mutex.Unlock()
+
+	song := ""
+	if info.Playing && !info.CurrentSong.live {
+		song = info.CurrentSong.filename
+	}
+	switch {
+	case ev.Key() == tcell.KeyRune && ev.Rune() == ' ':
+		le.tapWord(song, songFilePosition(info))
+	case ev.Key() == tcell.KeyEnter:
+		le.tapLine(song, songFilePosition(info))
+	case ev.Key() == tcell.KeyBackspace || ev.Key() == tcell.KeyBackspace2:
+		le.undo()
+	case ev.Key() == tcell.KeyRune && ev.Rune() == 'r':
+		le.restart()
+	case ev.Key() == tcell.KeyRune && ev.Rune() == 's':
+		le.save(serverSender)
+	}
+}
+
+func (d *drawer) drawRunes(x, y int, style tcell.Style, str string) int {
+	for _, r := range str {
+		d.SetContent(x, y, r, nil, style)
+		x++
+	}
+	return x
+}
+
+// drawEditor draws the lyrics around the next word to tap in place of the lyrics
+func drawEditor(d *drawer, info *playbackInformation) {
+	height := lyricsHistorySize + 1
+	if d.h < height+7 {
+		height = d.h - 7
+	}
+	if height < 2 {
+		return
+	}
+
+	le := editor
+	le.mutex.Lock()
+	defer le.mutex.Unlock()
+
+	top := d.h - 6 - height
+	line := le.words[len(le.words)-1].line + 1
+	if le.next < len(le.words) {
+		line = le.words[le.next].line
+	}
+	first := line - 1
+	if first < 0 {
+		first = 0
+	}
+
+	x := 1
+	for i, w := range le.words {
+		y := top + w.line - first
+		if w.line < first || top+height-1 <= y {
+			continue
+		}
+		if i == 0 || le.words[i-1].line != w.line {
+			x = 1
+		}
+		style := tcell.StyleDefault
+		if i < le.next {
+			style = editorTappedStyle
+		} else if i == le.next {
+			style = editorNextStyle
+		}
+		x = d.drawRunes(x, y, style, w.text)
+	}
+
+	status := le.status
+	if status == "" {
+		status = editorHelp
+	}
+	d.drawRunes(1, top+height-1, tcell.StyleDefault, status)
+
+	d.drawBox(0, top-1, d.w, height+2, tcell.StyleDefault)
+	title := "Lyrics Editor"
+	if le.song != "" {
+		title += " - " + le.song
+	}
+	d.drawRunes(2, top-1, tcell.StyleDefault, title)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/LogicalOverflow/music-sync/schedule"
+	"github.com/gdamore/tcell"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testEditorText = "first line\r\n\r\n  second  line here\nthird\n"
+
+type fakeMessageSender struct {
+	messages []proto.Message
+}
+
+func (fms *fakeMessageSender) SendMessage(m proto.Message) error {
+	fms.messages = append(fms.messages, m)
+	return nil
+}
+
+func TestNewLyricsEditor(t *testing.T) {
+	le := newLyricsEditor(testEditorText)
+	assert.Equal(t, []editorWord{
+		{text: "first ", line: 0}, {text: "line", line: 0},
+		{text: "second ", line: 1}, {text: "line ", line: 1}, {text: "here", line: 1},
+		{text: "third", line: 2},
+	}, le.words, "newLyricsEditor did not split the text into words")
+}
+
+func TestLoadLyricsEditor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "music-sync-editor")
+	if !assert.Nil(t, err, "failed to create temp dir") {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	ioutil.WriteFile(filepath.Join(dir, "lyrics.txt"), []byte(testEditorText), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "empty.txt"), []byte("\n \n"), 0644)
+
+	le, err := loadLyricsEditor(filepath.Join(dir, "lyrics.txt"))
+	if assert.NoError(t, err, "loadLyricsEditor returned an error") {
+		assert.Equal(t, 6, len(le.words), "loadLyricsEditor did not load the words")
+	}
+	_, err = loadLyricsEditor(filepath.Join(dir, "empty.txt"))
+	assert.Error(t, err, "loadLyricsEditor did not return an error for a file without lyrics")
+	_, err = loadLyricsEditor(filepath.Join(dir, "missing.txt"))
+	assert.Error(t, err, "loadLyricsEditor did not return an error for a missing file")
+}
+
+func TestLyricsEditor_tap(t *testing.T) {
+	le := newLyricsEditor(testEditorText)
+
+	le.tapWord("", 500)
+	assert.Empty(t, le.taps, "tapWord tapped without a song")
+
+	le.tapWord("song", 1000)
+	le.tapWord("song", 1500)
+	le.tapWord("song", 3000)
+	le.tapLine("song", 3500)
+	le.tapWord("other", 4000)
+	assert.Equal(t, "the song changed, press r to restart", le.status, "tapWord tapped for another song")
+	le.tapLine("song", 5000)
+	le.tapWord("song", 6000)
+	assert.Equal(t, "all lyrics are timed, press s to save", le.status, "tapWord tapped after the last word")
+
+	assert.Equal(t, "song", le.song, "the first tap did not select the song")
+	assert.Equal(t, []metadata.LyricsLine{
+		{{Timestamp: 1000, Caption: "first "}, {Timestamp: 1500, Caption: "line"}},
+		{{Timestamp: 3000, Caption: "second "}, {Timestamp: 3500, Caption: "line here"}},
+		{{Timestamp: 5000, Caption: "third"}},
+	}, le.lyrics(), "the taps did not time the lyrics correctly")
+
+	le.undo()
+	le.undo()
+	assert.Equal(t, 3, le.next, "undo did not move back to the untapped words")
+	assert.Equal(t, []metadata.LyricsLine{
+		{{Timestamp: 1000, Caption: "first "}, {Timestamp: 1500, Caption: "line"}},
+		{{Timestamp: 3000, Caption: "second "}},
+	}, le.lyrics(), "undo did not remove the last taps")
+
+	le.restart()
+	assert.Empty(t, le.lyrics(), "restart did not remove the taps")
+	assert.Equal(t, "", le.song, "restart did not reset the song")
+	le.undo()
+	assert.Equal(t, 0, le.next, "undo without taps moved the next word")
+}
+
+func TestLyricsEditor_save(t *testing.T) {
+	oldUser, oldPassword := controls.user, controls.password
+	defer func() { controls.user, controls.password = oldUser, oldPassword }()
+	controls.user, controls.password = "", ""
+
+	le := newLyricsEditor(testEditorText)
+	fms := &fakeMessageSender{}
+
+	le.tapLine("song", 1000)
+	le.save(fms)
+	assert.Empty(t, fms.messages, "save uploaded without credentials")
+	assert.Equal(t, "start the infoer with --control-user to upload lyrics", le.status,
+		"save did not report missing credentials")
+
+	controls.user, controls.password = "user", "password"
+	le.restart()
+	le.save(fms)
+	assert.Empty(t, fms.messages, "save uploaded without taps")
+	assert.Equal(t, "no lyrics are timed yet", le.status, "save did not report missing taps")
+
+	le.tapLine("song", 1000)
+	le.save(fms)
+	assert.Equal(t, []proto.Message{&comm.UploadLyricsRequest{
+		SongFileName: "song",
+		Lyrics: []*comm.NewSongInfo_SongLyricsLine{
+			{Atoms: []*comm.NewSongInfo_SongLyricsAtom{{Timestamp: 1000, Caption: "first line"}}},
+		},
+		User:     "user",
+		Password: "password",
+	}}, fms.messages, "save did not upload the lyrics")
+
+	le.uploaded(&comm.UploadLyricsResponse{SongFileName: "song"})
+	assert.Equal(t, "saved the lyrics of song", le.status, "uploaded did not show the success")
+	le.uploaded(&comm.UploadLyricsResponse{SongFileName: "song", Error: "unknown song"})
+	assert.Equal(t, "failed to save the lyrics of song: unknown song", le.status, "uploaded did not show the error")
+}
+
+func TestSongFilePosition(t *testing.T) {
+	oldSampleRate := schedule.SampleRate
+	defer func() { schedule.SampleRate = oldSampleRate }()
+	schedule.SampleRate = 1000
+
+	info := &playbackInformation{TimeInSong: 5 * time.Second, CurrentSong: upcomingSong{}}
+	assert.Equal(t, int64(5000), songFilePosition(info), "songFilePosition returned the wrong position of an untrimmed song")
+
+	info.CurrentSong.filePosition = 2000
+	assert.Equal(t, int64(7000), songFilePosition(info), "songFilePosition did not add the trimmed start")
+
+	info.CurrentSong.offset = 3000
+	info.CurrentSong.speed = 0.5
+	assert.Equal(t, int64(3000), songFilePosition(info), "songFilePosition did not apply the speed")
+}
+
+func TestLyricsEditor_handleKey(t *testing.T) {
+	oldSampleRate := schedule.SampleRate
+	defer func() { schedule.SampleRate = oldSampleRate }()
+	schedule.SampleRate = 1000
+
+	le := newLyricsEditor(testEditorText)
+	info := &playbackInformation{TimeInSong: 2 * time.Second, Playing: true, CurrentSong: upcomingSong{filename: "song"}}
+
+	le.handleKey(tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone), info)
+	le.handleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), info)
+	assert.Equal(t, []metadata.LyricsLine{{{Timestamp: 2000, Caption: "first "}, {Timestamp: 2000, Caption: "line"}}},
+		le.lyrics(), "handleKey did not tap the word and line")
+
+	le.handleKey(tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone), info)
+	assert.Equal(t, 1, le.next, "handleKey did not undo the tap")
+
+	info.Playing = false
+	le.handleKey(tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone), info)
+	assert.Equal(t, 1, le.next, "handleKey tapped while paused")
+
+	le.handleKey(tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone), info)
+	assert.Equal(t, 0, le.next, "handleKey did not restart")
+}
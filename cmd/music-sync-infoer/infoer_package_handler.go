@@ -6,6 +6,7 @@ import (
 	"github.com/LogicalOverflow/music-sync/timing"
 	"net"
 	"sort"
+	"time"
 )
 
 type infoerPackageHandler struct {
@@ -17,6 +18,27 @@ func (i *infoerPackageHandler) HandleTimeSyncResponse(tsr *comm.TimeSyncResponse
 	timing.UpdateOffset(tsr.ClientSendTime, tsr.ServerRecvTime, tsr.ServerSendTime, clientRecv)
 }
 
+func fromWireMetadata(md *comm.NewSongInfo_SongMetadata) metadata.SongMetadata {
+	if md == nil {
+		return metadata.SongMetadata{}
+	}
+	return metadata.SongMetadata{
+		Title:       md.Title,
+		Artist:      md.Artist,
+		Album:       md.Album,
+		Genre:       md.Genre,
+		AlbumArtist: md.AlbumArtist,
+		Composer:    md.Composer,
+		Comment:     md.Comment,
+		Year:        int(md.Year),
+		Track:       int(md.Track),
+		TrackTotal:  int(md.TrackTotal),
+		Disc:        int(md.Disc),
+		DiscTotal:   int(md.DiscTotal),
+		Duration:    time.Duration(md.Duration) * time.Millisecond,
+	}
+}
+
 func (i *infoerPackageHandler) HandleNewSongInfo(newSongInfo *comm.NewSongInfo, _ net.Conn) {
 	currentState.SongsMutex.Lock()
 	defer currentState.SongsMutex.Unlock()
@@ -29,19 +51,18 @@ func (i *infoerPackageHandler) HandleNewSongInfo(newSongInfo *comm.NewSongInfo,
 		lyrics[i] = atoms
 	}
 
-	md := metadata.SongMetadata{}
-	if newSongInfo.Metadata != nil {
-		md.Title = newSongInfo.Metadata.Title
-		md.Artist = newSongInfo.Metadata.Artist
-		md.Album = newSongInfo.Metadata.Album
-	}
-
 	currentState.Songs = append(currentState.Songs, upcomingSong{
 		filename:   newSongInfo.SongFileName,
 		startIndex: newSongInfo.FirstSampleOfSongIndex,
 		length:     newSongInfo.SongLength,
 		lyrics:     lyrics,
-		metadata:   md,
+		metadata:   fromWireMetadata(newSongInfo.Metadata),
+		live:       newSongInfo.Live,
+		offset:     newSongInfo.SongOffset,
+		speed:      newSongInfo.Speed,
+
+		filePosition: newSongInfo.FilePosition,
+		coverID:      newSongInfo.CoverId,
 	})
 	sort.Sort(songsByStartIndex(currentState.Songs))
 }
@@ -71,6 +92,32 @@ func (i *infoerPackageHandler) HandleSetVolumeRequest(svr *comm.SetVolumeRequest
 	currentState.Volume = svr.Volume
 }
 
+func (i *infoerPackageHandler) HandleKaraokeInfo(karaokeInfo *comm.KaraokeInfo, _ net.Conn) {
+	currentState.Karaoke = karaokeInfo.Enabled
+}
+
+func (i *infoerPackageHandler) HandleUploadLyricsResponse(response *comm.UploadLyricsResponse, _ net.Conn) {
+	if editor != nil {
+		editor.uploaded(response)
+	}
+}
+
+func (i *infoerPackageHandler) HandleControlResponse(response *comm.ControlResponse, _ net.Conn) {
+	controls.responded(response)
+}
+
+func (i *infoerPackageHandler) HandlePlaylistInfo(info *comm.PlaylistInfo, _ net.Conn) {
+	currentState.applyPlaylistInfo(info)
+}
+
+func (i *infoerPackageHandler) HandleCoverArt(cover *comm.CoverArt, _ net.Conn) {
+	currentState.addCover(cover.Id, cover.Png)
+}
+
+func (i *infoerPackageHandler) HandleSpectrumInfo(info *comm.SpectrumInfo, _ net.Conn) {
+	currentState.addSpectrum(info)
+}
+
 func (i *infoerPackageHandler) HandlePingMessage(_ *comm.PingMessage, conn net.Conn) {
 	comm.PingHandler(conn)
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/LogicalOverflow/music-sync/metadata"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 var testPackageLyrics = []*comm.NewSongInfo_SongLyricsLine{
@@ -13,9 +14,14 @@ var testPackageLyrics = []*comm.NewSongInfo_SongLyricsLine{
 	{Atoms: []*comm.NewSongInfo_SongLyricsAtom{{Timestamp: 3, Caption: "caption-1-0"}, {Timestamp: 4, Caption: "caption-1-1"}}},
 }
 var testPackageMetadata = &comm.NewSongInfo_SongMetadata{
-	Title:  "song-title",
-	Artist: "song-artist",
-	Album:  "song-album",
+	Title:      "song-title",
+	Artist:     "song-artist",
+	Album:      "song-album",
+	Genre:      "song-genre",
+	Year:       2019,
+	Track:      3,
+	TrackTotal: 12,
+	Duration:   185000,
 }
 
 var testMetadataLyrics = []metadata.LyricsLine{
@@ -23,9 +29,14 @@ var testMetadataLyrics = []metadata.LyricsLine{
 	{{Timestamp: 3, Caption: "caption-1-0"}, {Timestamp: 4, Caption: "caption-1-1"}},
 }
 var testMetadata = metadata.SongMetadata{
-	Title:  "song-title",
-	Artist: "song-artist",
-	Album:  "song-album",
+	Title:      "song-title",
+	Artist:     "song-artist",
+	Album:      "song-album",
+	Genre:      "song-genre",
+	Year:       2019,
+	Track:      3,
+	TrackTotal: 12,
+	Duration:   185 * time.Second,
 }
 
 func TestInfoerPackageHandler_HandleChunkInfo(t *testing.T) {
@@ -56,10 +67,15 @@ func TestInfoerPackageHandler_HandleNewSongInfo(t *testing.T) {
 			SongLength:             256,
 			Lyrics:                 testPackageLyrics,
 			Metadata:               testPackageMetadata,
+			Live:                   i%4 == 0,
+			SongOffset:             int64(i),
+			Speed:                  0.8,
+			FilePosition:           int64(i) * 100,
+			CoverId:                "cover",
 		}
 		ph.HandleNewSongInfo(song, nil)
 
-		songs = append([]upcomingSong{{filename: song.SongFileName, startIndex: song.FirstSampleOfSongIndex, length: song.SongLength, lyrics: testMetadataLyrics, metadata: testMetadata}}, songs...)
+		songs = append([]upcomingSong{{filename: song.SongFileName, startIndex: song.FirstSampleOfSongIndex, length: song.SongLength, lyrics: testMetadataLyrics, metadata: testMetadata, live: song.Live, offset: song.SongOffset, speed: song.Speed, filePosition: song.FilePosition, coverID: song.CoverId}}, songs...)
 		assert.Equal(t, songs, currentState.Songs, "HandleNewSongInfo did not add to currentState Songs correctly")
 	}
 }
@@ -88,3 +104,12 @@ func TestInfoerPackageHandler_HandleSetVolumeRequest(t *testing.T) {
 		assert.Equal(t, i, currentState.Volume, "HandleSetVolumeRequest did not update currentState volume correctly")
 	}
 }
+
+func TestInfoerPackageHandler_HandleKaraokeInfo(t *testing.T) {
+	ph := newInfoerPackageHandler()
+	for _, enabled := range []bool{true, false, true} {
+		ph.HandleKaraokeInfo(&comm.KaraokeInfo{Enabled: enabled}, nil)
+		assert.Equal(t, enabled, currentState.Karaoke, "HandleKaraokeInfo did not update currentState karaoke correctly")
+	}
+	currentState.Karaoke = false
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/gdamore/tcell"
+	"time"
+	"unicode"
+)
+
+const (
+	// karaokeGlyphWidth is the number of columns a glyph of the large font takes, including the spacing
+	karaokeGlyphWidth = 4
+	// karaokeGlyphHeight is the number of rows a glyph of the large font takes, including the spacing
+	karaokeGlyphHeight = 6
+)
+
+var (
+	karaokeSungStyle     = tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	karaokeCurrentStyle  = tcell.StyleDefault.Foreground(tcell.ColorYellow).Bold(true)
+	karaokeUpcomingStyle = tcell.StyleDefault.Foreground(tcell.ColorWhite)
+	karaokeNextStyle     = tcell.StyleDefault.Foreground(tcell.ColorGray)
+)
+
+// karaokeGlyphs is a 3x5 font used to draw the current lyrics line, '#' marks a filled cell
+var karaokeGlyphs = map[rune][5]string{
+	'A':  {".#.", "#.#", "###", "#.#", "#.#"},
+	'B':  {"##.", "#.#", "##.", "#.#", "##."},
+	'C':  {".##", "#..", "#..", "#..", ".##"},
+	'D':  {"##.", "#.#", "#.#", "#.#", "##."},
+	'E':  {"###", "#..", "##.", "#..", "###"},
+	'F':  {"###", "#..", "##.", "#..", "#.."},
+	'G':  {".##", "#..", "#.#", "#.#", ".##"},
+	'H':  {"#.#", "#.#", "###", "#.#", "#.#"},
+	'I':  {"###", ".#.", ".#.", ".#.", "###"},
+	'J':  {"..#", "..#", "..#", "#.#", ".#."},
+	'K':  {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'L':  {"#..", "#..", "#..", "#..", "###"},
+	'M':  {"#.#", "###", "#.#", "#.#", "#.#"},
+	'N':  {"##.", "#.#", "#.#", "#.#", "#.#"},
+	'O':  {".#.", "#.#", "#.#", "#.#", ".#."},
+	'P':  {"##.", "#.#", "##.", "#..", "#.."},
+	'Q':  {".#.", "#.#", "#.#", "##.", ".##"},
+	'R':  {"##.", "#.#", "##.", "#.#", "#.#"},
+	'S':  {".##", "#..", ".#.", "..#", "##."},
+	'T':  {"###", ".#.", ".#.", ".#.", ".#."},
+	'U':  {"#.#", "#.#", "#.#", "#.#", "###"},
+	'V':  {"#.#", "#.#", "#.#", "#.#", ".#."},
+	'W':  {"#.#", "#.#", "#.#", "###", "#.#"},
+	'X':  {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'Y':  {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'Z':  {"###", "..#", ".#.", "#..", "###"},
+	'0':  {"###", "#.#", "#.#", "#.#", "###"},
+	'1':  {".#.", "##.", ".#.", ".#.", "###"},
+	'2':  {"##.", "..#", ".#.", "#..", "###"},
+	'3':  {"##.", "..#", ".#.", "..#", "##."},
+	'4':  {"#.#", "#.#", "###", "..#", "..#"},
+	'5':  {"###", "#..", "##.", "..#", "##."},
+	'6':  {".##", "#..", "###", "#.#", "###"},
+	'7':  {"###", "..#", ".#.", ".#.", ".#."},
+	'8':  {"###", "#.#", "###", "#.#", "###"},
+	'9':  {"###", "#.#", "###", "..#", "##."},
+	' ':  {"...", "...", "...", "...", "..."},
+	'.':  {"...", "...", "...", "...", ".#."},
+	',':  {"...", "...", "...", ".#.", "#.."},
+	'!':  {".#.", ".#.", ".#.", "...", ".#."},
+	'?':  {"##.", "..#", ".#.", "...", ".#."},
+	'\'': {".#.", ".#.", "...", "...", "..."},
+	'"':  {"#.#", "#.#", "...", "...", "..."},
+	'-':  {"...", "...", "###", "...", "..."},
+	':':  {"...", ".#.", "...", ".#.", "..."},
+	'(':  {"..#", ".#.", ".#.", ".#.", "..#"},
+	')':  {"#..", ".#.", ".#.", ".#.", "#.."},
+	'&':  {".#.", "#.#", ".#.", "#.#", ".##"},
+	'/':  {"..#", "..#", ".#.", "#..", "#.."},
+}
+
+// karaokeGlyph returns the glyph of r in the large font. Runes without a glyph are drawn in the middle of their cell.
+func karaokeGlyph(r rune) [5]string {
+	if g, ok := karaokeGlyphs[unicode.ToUpper(r)]; ok {
+		return g
+	}
+	return [5]string{"   ", "   ", " " + string(r) + " ", "   ", "   "}
+}
+
+// karaokeRune is a rune of a lyrics line and the index of the atom it belongs to
+type karaokeRune struct {
+	r    rune
+	atom int
+}
+
+// karaokePosition returns the index of the line to display in the karaoke layout and the index of the atom in it,
+// which is sung at timeInSong. The atom is -1 before the line starts.
+func karaokePosition(song upcomingSong, timeInSong time.Duration) (line, atom int) {
+	line = lyricsNextLine(song, timeInSong) - 1
+	if line < 0 {
+		return 0, -1
+	}
+
+	atom = -1
+	for i, a := range song.lyrics[line] {
+		if a.Timestamp <= int64(timeInSong/time.Millisecond) {
+			atom = i
+		}
+	}
+	return line, atom
+}
+
+// karaokeWrap splits line into rows of at most width runes. Lines are broken at spaces where possible.
+func karaokeWrap(line metadata.LyricsLine, width int) [][]karaokeRune {
+	if width <= 0 {
+		return nil
+	}
+
+	var words [][]karaokeRune
+	var word []karaokeRune
+	for i, a := range line {
+		for _, r := range a.Caption {
+			if unicode.IsSpace(r) {
+				if word != nil {
+					words = append(words, word)
+					word = nil
+				}
+				continue
+			}
+			word = append(word, karaokeRune{r: r, atom: i})
+		}
+	}
+	if word != nil {
+		words = append(words, word)
+	}
+
+	var rows [][]karaokeRune
+	var row []karaokeRune
+	for _, w := range words {
+		if row != nil && width < len(row)+1+len(w) {
+			rows = append(rows, row)
+			row = nil
+		}
+		if row != nil {
+			row = append(row, karaokeRune{r: ' ', atom: w[0].atom})
+		}
+		for width < len(row)+len(w) {
+			split := width - len(row)
+			rows = append(rows, append(row, w[:split]...))
+			row, w = nil, w[split:]
+		}
+		row = append(row, w...)
+	}
+	if row != nil {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func karaokeStyle(atom, current int) tcell.Style {
+	switch {
+	case atom < current:
+		return karaokeSungStyle
+	case atom == current:
+		return karaokeCurrentStyle
+	default:
+		return karaokeUpcomingStyle
+	}
+}
+
+func (d *drawer) drawKaraokeGlyph(x, y int, style tcell.Style, r rune) {
+	for j, glyphRow := range karaokeGlyph(r) {
+		for i, c := range glyphRow {
+			switch c {
+			case '#':
+				d.SetContent(x+i, y+j, '█', nil, style)
+			case '.', ' ':
+			default:
+				d.SetContent(x+i, y+j, c, nil, style)
+			}
+		}
+	}
+}
+
+// drawKaraoke draws the current lyrics line in a large font, highlighting the atom currently sung,
+// and the next line below it
+func drawKaraoke(d *drawer, info *playbackInformation) {
+	height := d.h - 5
+	if height < 3 {
+		return
+	}
+	d.drawBox(0, 0, d.w, height, tcell.StyleDefault)
+	d.drawString(2, 0, tcell.StyleDefault, "Karaoke")
+
+	song := info.CurrentSong
+	if len(song.lyrics) == 0 {
+		return
+	}
+	line, atom := karaokePosition(song, info.TimeInSong)
+
+	rows := karaokeWrap(song.lyrics[line], (d.w-2)/karaokeGlyphWidth)
+	if maxRows := (height - 4) / karaokeGlyphHeight; maxRows < len(rows) {
+		rows = rows[:maxRows]
+	}
+	next := ""
+	if line+1 < len(song.lyrics) {
+		for _, a := range song.lyrics[line+1] {
+			next += a.Caption
+		}
+	}
+
+	y := (height - len(rows)*karaokeGlyphHeight - 1) / 2
+	if y < 1 {
+		y = 1
+	}
+	for _, row := range rows {
+		x := (d.w - len(row)*karaokeGlyphWidth + 1) / 2
+		for i, kr := range row {
+			d.drawKaraokeGlyph(x+i*karaokeGlyphWidth, y, karaokeStyle(kr.atom, atom), kr.r)
+		}
+		y += karaokeGlyphHeight
+	}
+
+	if y < height-1 {
+		x := (d.w - len([]rune(next))) / 2
+		if x < 1 {
+			x = 1
+		}
+		d.drawString(x, y, karaokeNextStyle, next)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var karaokePositionCases = []struct {
+	timeInSong time.Duration
+	line       int
+	atom       int
+}{
+	{timeInSong: 0, line: 0, atom: -1},
+	{timeInSong: 10 * time.Second, line: 0, atom: 0},
+	{timeInSong: 11500 * time.Millisecond, line: 0, atom: 1},
+	{timeInSong: 19 * time.Second, line: 0, atom: 2},
+	{timeInSong: 20 * time.Second, line: 1, atom: 0},
+	{timeInSong: 100 * time.Second, line: 2, atom: 2},
+}
+
+func TestKaraokePosition(t *testing.T) {
+	song := upcomingSong{lyrics: testLyrics}
+	for _, c := range karaokePositionCases {
+		line, atom := karaokePosition(song, c.timeInSong)
+		assert.Equal(t, c.line, line, "karaokePosition returned the wrong line at %v", c.timeInSong)
+		assert.Equal(t, c.atom, atom, "karaokePosition returned the wrong atom at %v", c.timeInSong)
+	}
+}
+
+func karaokeRows(rows [][]karaokeRune) ([]string, [][]int) {
+	texts := make([]string, len(rows))
+	atoms := make([][]int, len(rows))
+	for i, row := range rows {
+		for _, kr := range row {
+			texts[i] += string(kr.r)
+			atoms[i] = append(atoms[i], kr.atom)
+		}
+	}
+	return texts, atoms
+}
+
+func TestKaraokeWrap(t *testing.T) {
+	line := metadata.LyricsLine{{Caption: "hello "}, {Caption: "wor"}, {Caption: "ld, "}, {Caption: "karaoke"}}
+
+	texts, atoms := karaokeRows(karaokeWrap(line, 20))
+	assert.Equal(t, []string{"hello world, karaoke"}, texts, "karaokeWrap split a line, which fits")
+	assert.Equal(t, [][]int{{0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 3, 3, 3, 3, 3, 3, 3, 3}}, atoms, "karaokeWrap assigned the wrong atoms")
+
+	texts, _ = karaokeRows(karaokeWrap(line, 12))
+	assert.Equal(t, []string{"hello world,", "karaoke"}, texts, "karaokeWrap did not break the line at a space")
+
+	texts, _ = karaokeRows(karaokeWrap(line, 4))
+	assert.Equal(t, []string{"hell", "o", "worl", "d,", "kara", "oke"}, texts, "karaokeWrap did not split long words")
+
+	assert.Nil(t, karaokeWrap(line, 0), "karaokeWrap returned rows for a width of 0")
+	assert.Nil(t, karaokeWrap(nil, 10), "karaokeWrap returned rows for an empty line")
+}
+
+func TestKaraokeGlyph(t *testing.T) {
+	assert.Equal(t, karaokeGlyphs['A'], karaokeGlyph('a'), "karaokeGlyph did not use the upper case glyph")
+	assert.Equal(t, karaokeGlyphs['7'], karaokeGlyph('7'), "karaokeGlyph returned the wrong glyph")
+	assert.Equal(t, [5]string{"   ", "   ", " ä ", "   ", "   "}, karaokeGlyph('ä'), "karaokeGlyph did not fall back to the rune")
+}
+
+func TestKaraokeStyle(t *testing.T) {
+	assert.Equal(t, karaokeSungStyle, karaokeStyle(0, 1), "karaokeStyle returned the wrong style for a sung atom")
+	assert.Equal(t, karaokeCurrentStyle, karaokeStyle(1, 1), "karaokeStyle returned the wrong style for the current atom")
+	assert.Equal(t, karaokeUpcomingStyle, karaokeStyle(2, 1), "karaokeStyle returned the wrong style for an upcoming atom")
+}
@@ -6,11 +6,13 @@ import (
 	"github.com/LogicalOverflow/music-sync/cmd"
 	"github.com/LogicalOverflow/music-sync/comm"
 	"github.com/LogicalOverflow/music-sync/logging"
+	"github.com/LogicalOverflow/music-sync/metadata"
 	"github.com/LogicalOverflow/music-sync/schedule"
 	"github.com/LogicalOverflow/music-sync/timing"
 	"github.com/gdamore/tcell"
 	"github.com/urfave/cli"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -25,6 +27,13 @@ func main() {
 
 		cmd.SampleRateFlag,
 		cmd.LyricsHistorySizeFlag,
+		cmd.KaraokeFlag,
+		cmd.EditLyricsFlag,
+		cmd.ControlUserFlag,
+		cmd.ControlPasswordFlag,
+		cmd.CoverGraphicsFlag,
+		cmd.OutputFlag,
+		cmd.OutputTemplateFlag,
 	}
 
 	if err := app.Run(os.Args); err != nil {
@@ -33,7 +42,10 @@ func main() {
 	}
 }
 
-var lyricsHistorySize int
+var (
+	lyricsHistorySize int
+	forceKaraoke      bool
+)
 
 func run(ctx *cli.Context) error {
 	// disable logging
@@ -45,10 +57,41 @@ func run(ctx *cli.Context) error {
 		sampleRate = ctx.Int(cmd.FlagKey(cmd.SampleRateFlag))
 	)
 	lyricsHistorySize = int(ctx.Uint(cmd.FlagKey(cmd.LyricsHistorySizeFlag)))
+	forceKaraoke = ctx.Bool(cmd.FlagKey(cmd.KaraokeFlag))
+	controls.user = ctx.String(cmd.FlagKey(cmd.ControlUserFlag))
+	controls.password = ctx.String(cmd.FlagKey(cmd.ControlPasswordFlag))
 
 	schedule.SampleRate = sampleRate
 
-	s := createTcellScreen()
+	graphics, err := parseCoverGraphics(ctx.String(cmd.FlagKey(cmd.CoverGraphicsFlag)))
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	coverGraphics = graphics
+
+	var format outputFormatter
+	if output := ctx.String(cmd.FlagKey(cmd.OutputFlag)); output != "" {
+		format, err = newOutputFormatter(output, ctx.String(cmd.FlagKey(cmd.OutputTemplateFlag)))
+		if err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+	}
+
+	if path := ctx.String(cmd.FlagKey(cmd.EditLyricsFlag)); path != "" {
+		if format != nil {
+			return cli.NewExitError("the lyrics editor cannot be used with an output", 1)
+		}
+		le, err := loadLyricsEditor(path)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("failed to load the lyrics to edit: %v", err), 1)
+		}
+		editor = le
+	}
+
+	var s tcell.Screen
+	if format == nil {
+		s = createTcellScreen()
+	}
 
 	server := fmt.Sprintf("%s:%d", serverAddress, serverPort)
 	sender, err := comm.ConnectToServer(server, newInfoerPackageHandler())
@@ -56,8 +99,16 @@ func run(ctx *cli.Context) error {
 		cli.NewExitError(err, 1)
 	}
 
+	serverSender = sender
 	go schedule.Infoer(sender)
 
+	if format != nil {
+		if err := outputLoop(os.Stdout, format, timing.GetSyncedTime); err != nil {
+			return cli.NewExitError(fmt.Sprintf("failed to write the output: %v", err), 1)
+		}
+		return nil
+	}
+
 	tcellLoop(s)
 
 	return nil
@@ -113,28 +164,82 @@ func redraw(d *drawer) {
 
 	info := currentState.Info(timing.GetSyncedTime())
 	drawPlaybackInfo(d, info)
-	drawLyrics(d, info)
+
+	// the lyrics are drawn next to the playlist panel
+	panelWidth := playlistPanelSize(d)
+	d.w -= panelWidth
+	if editor != nil {
+		drawEditor(d, info)
+	} else if forceKaraoke || currentState.Karaoke {
+		drawKaraoke(d, info)
+	} else {
+		top := drawLyrics(d, info)
+		visualizerX := 1
+		if coverWidth := drawCover(d, info, top); 0 < coverWidth {
+			visualizerX += coverWidth + 1
+		}
+		drawVisualizer(d, visualizerX, top, info.CurrentSample)
+	}
+	d.w += panelWidth
+	if 0 < panelWidth {
+		drawPlaylistPanel(d, panelWidth, info)
+	}
+	drawControls(d)
 
 	d.Show()
+	showCoverGraphics(d)
 }
 
-func drawPlaybackInfo(d *drawer, info *playbackInformation) {
-	songLineName := ""
-	if info.CurrentSong.metadata.Title != "" {
-		songLineName = info.CurrentSong.metadata.Title
-	} else {
-		songLineName = info.CurrentSong.filename
+// songLineName returns the title of song (or its filename) with its disc and track number, e.g. "1-03. Title"
+func songLineName(song upcomingSong) string {
+	md := song.metadata
+	if md.Title == "" {
+		return song.filename
+	}
+	switch {
+	case md.Track != 0 && 1 < md.DiscTotal:
+		return fmt.Sprintf("%d-%02d. %s", md.Disc, md.Track, md.Title)
+	case md.Track != 0:
+		return fmt.Sprintf("%d. %s", md.Track, md.Title)
+	default:
+		return md.Title
 	}
+}
 
-	songLineArtistAlbum := ""
-	if info.CurrentSong.metadata.Artist != "" {
-		songLineArtistAlbum = info.CurrentSong.metadata.Artist
-		if info.CurrentSong.metadata.Album != "" {
-			songLineArtistAlbum += " - " + info.CurrentSong.metadata.Album
-		}
+// songLineDetails returns the artist, album, year, genre and composer of a song, e.g.
+// "Artist - Album (2019) - Genre - composed by Composer"
+func songLineDetails(md metadata.SongMetadata) string {
+	parts := make([]string, 0, 4)
+	if md.Artist != "" {
+		parts = append(parts, md.Artist)
+	} else if md.AlbumArtist != "" {
+		parts = append(parts, md.AlbumArtist)
+	}
+	if md.Album != "" && md.Year != 0 {
+		parts = append(parts, fmt.Sprintf("%s (%d)", md.Album, md.Year))
+	} else if md.Album != "" {
+		parts = append(parts, md.Album)
+	}
+	if md.Genre != "" {
+		parts = append(parts, md.Genre)
 	}
+	if md.Composer != "" {
+		parts = append(parts, "composed by "+md.Composer)
+	}
+	return strings.Join(parts, " - ")
+}
+
+func drawPlaybackInfo(d *drawer, info *playbackInformation) {
+	songLineName := songLineName(info.CurrentSong)
+	songLineArtistAlbum := songLineDetails(info.CurrentSong.metadata)
 
 	timeLine := fmt.Sprintf("%s/%s", fmtDuration(info.TimeInSong), fmtDuration(info.SongLength))
+	if speed := info.CurrentSong.speed; speed != 0 && speed != 1 {
+		timeLine = fmt.Sprintf("%.0f%% %s", speed*100, timeLine)
+	}
+	if info.CurrentSong.live {
+		timeLine = fmt.Sprintf("LIVE %s", fmtDuration(info.TimeInSong))
+	}
 	volumeLine := fmt.Sprintf("Volume: %06.2f%%", info.Volume*100)
 
 	d.drawString(d.w-len(volumeLine)-1, d.h-4, tcell.StyleDefault, volumeLine)
@@ -148,19 +253,22 @@ func drawPlaybackInfo(d *drawer, info *playbackInformation) {
 	d.drawString(2, d.h-5, tcell.StyleDefault, info.playingString())
 }
 
-func drawLyrics(d *drawer, info *playbackInformation) {
+// drawLyrics draws the lyrics box above the playback info and returns the row of its top
+func drawLyrics(d *drawer, info *playbackInformation) int {
 	lyricsHeight := lyricsHistorySize
 	if d.h < lyricsHeight+7 {
 		lyricsHeight = d.h - 7
 	}
-	if 0 < lyricsHeight {
-		lines := lyricsHistory(lyricsHeight, info.CurrentSong, info.TimeInSong)
-		for i, l := range lines {
-			d.drawString(1, d.h-7-i, tcell.StyleDefault, l)
-		}
-		d.drawBox(0, d.h-7-lyricsHeight, d.w, lyricsHeight+2, tcell.StyleDefault)
-		d.drawString(2, d.h-7-lyricsHeight, tcell.StyleDefault, "Lyrics")
+	if lyricsHeight <= 0 {
+		return d.h - 5
+	}
+	lines := lyricsHistory(lyricsHeight, info.CurrentSong, info.TimeInSong)
+	for i, l := range lines {
+		d.drawString(1, d.h-7-i, tcell.StyleDefault, l)
 	}
+	d.drawBox(0, d.h-7-lyricsHeight, d.w, lyricsHeight+2, tcell.StyleDefault)
+	d.drawString(2, d.h-7-lyricsHeight, tcell.StyleDefault, "Lyrics")
+	return d.h - 7 - lyricsHeight
 }
 
 func lyricsHistory(height int, song upcomingSong, timeInSong time.Duration) []string {
@@ -144,3 +144,23 @@ func TestLyricsHistory(t *testing.T) {
 		assert.Equal(t, c.result, actual, "lyricsHistory is wrong for case %v", c)
 	}
 }
+
+func TestSongLineName(t *testing.T) {
+	assert.Equal(t, "song.mp3", songLineName(upcomingSong{filename: "song.mp3", metadata: metadata.SongMetadata{Track: 3}}),
+		"songLineName did not fall back to the filename")
+	assert.Equal(t, "Title", songLineName(upcomingSong{metadata: metadata.SongMetadata{Title: "Title"}}),
+		"songLineName did not return the title")
+	assert.Equal(t, "3. Title", songLineName(upcomingSong{metadata: metadata.SongMetadata{Title: "Title", Track: 3, Disc: 1, DiscTotal: 1}}),
+		"songLineName did not include the track number")
+	assert.Equal(t, "2-03. Title", songLineName(upcomingSong{metadata: metadata.SongMetadata{Title: "Title", Track: 3, Disc: 2, DiscTotal: 2}}),
+		"songLineName did not include the disc number")
+}
+
+func TestSongLineDetails(t *testing.T) {
+	assert.Equal(t, "", songLineDetails(metadata.SongMetadata{Title: "Title"}), "songLineDetails returned details for a song without any")
+	assert.Equal(t, "Artist - Album", songLineDetails(metadata.SongMetadata{Artist: "Artist", Album: "Album"}),
+		"songLineDetails did not return artist and album")
+	assert.Equal(t, "Various - Album (2019) - Rock - composed by Composer", songLineDetails(metadata.SongMetadata{
+		AlbumArtist: "Various", Album: "Album", Year: 2019, Genre: "Rock", Composer: "Composer", Comment: "Comment"}),
+		"songLineDetails did not return all details")
+}
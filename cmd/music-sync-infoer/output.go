@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// the headless output formats
+const (
+	outputJSON     = "json"
+	outputText     = "text"
+	outputTemplate = "template"
+)
+
+// outputTextTemplate is the template used by the text output
+const outputTextTemplate = `{{.State}}: {{.Song}}{{with .Artist}} - {{.}}{{end}} [{{.Position}}{{with .Length}}/{{.}}{{end}}]` +
+	` {{printf "%.0f" .Volume}}%{{with .Lyric}} | {{.}}{{end}}`
+
+// outputInterval is the interval in which the headless output checks for state changes
+const outputInterval = 200 * time.Millisecond
+
+// nowPlaying is what the headless output prints
+type nowPlaying struct {
+	Song     string  `json:"song"`
+	Title    string  `json:"title"`
+	Artist   string  `json:"artist"`
+	Album    string  `json:"album"`
+	File     string  `json:"file"`
+	Position string  `json:"position"`
+	Length   string  `json:"length"`
+	Progress float64 `json:"progress"`
+	Playing  bool    `json:"playing"`
+	State    string  `json:"state"`
+	Volume   float64 `json:"volume"`
+	Lyric    string  `json:"lyric"`
+}
+
+// nowPlayingOf returns what is playing according to info. The volume is in percent.
+func nowPlayingOf(info *playbackInformation) nowPlaying {
+	song := info.CurrentSong
+	artist := song.metadata.Artist
+	if artist == "" {
+		artist = song.metadata.AlbumArtist
+	}
+	np := nowPlaying{
+		Song:     songLineName(song),
+		Title:    song.metadata.Title,
+		Artist:   artist,
+		Album:    song.metadata.Album,
+		File:     song.filename,
+		Position: fmtDuration(info.TimeInSong),
+		Length:   fmtDuration(info.SongLength),
+		Progress: info.ProgressInSong,
+		Playing:  info.Playing,
+		State:    info.playingString(),
+		Volume:   info.Volume * 100,
+		Lyric:    currentLyricsLine(song, info.TimeInSong),
+	}
+	if song.live {
+		np.Length, np.Progress = "", 0
+	}
+	return np
+}
+
+// currentLyricsLine returns the whole lyrics line sung at timeInSong
+func currentLyricsLine(song upcomingSong, timeInSong time.Duration) string {
+	index := lyricsNextLine(song, timeInSong) - 1
+	if index < 0 || len(song.lyrics) <= index {
+		return ""
+	}
+	var buf bytes.Buffer
+	for _, atom := range song.lyrics[index] {
+		buf.WriteString(atom.Caption)
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// outputFormatter formats what is playing as a single line
+type outputFormatter func(np nowPlaying) (string, error)
+
+// newOutputFormatter returns the formatter of the output format. The template is only used by the template output.
+func newOutputFormatter(format, tmpl string) (outputFormatter, error) {
+	switch format {
+	case outputJSON:
+		return func(np nowPlaying) (string, error) {
+			line, err := json.Marshal(np)
+			return string(line), err
+		}, nil
+	case outputText:
+		tmpl = outputTextTemplate
+	case outputTemplate:
+		if tmpl == "" {
+			return nil, fmt.Errorf("the template output needs a template")
+		}
+	default:
+		return nil, fmt.Errorf("unknown output '%s'", format)
+	}
+
+	t, err := template.New("output").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %v", err)
+	}
+	return func(np nowPlaying) (string, error) {
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, np); err != nil {
+			return "", err
+		}
+		// one line per state
+		return strings.Replace(buf.String(), "\n", " ", -1), nil
+	}, nil
+}
+
+// outputWriter writes a line to its writer, whenever the formatted state changed
+type outputWriter struct {
+	w      io.Writer
+	format outputFormatter
+	last   string
+}
+
+func (ow *outputWriter) update(info *playbackInformation) error {
+	line, err := ow.format(nowPlayingOf(info))
+	if err != nil {
+		return err
+	}
+	if line == ow.last {
+		return nil
+	}
+	ow.last = line
+	_, err = fmt.Fprintln(ow.w, line)
+	return err
+}
+
+// outputLoop writes the current state to w whenever it changes, until writing fails
+func outputLoop(w io.Writer, format outputFormatter, now func() int64) error {
+	ow := &outputWriter{w: w, format: format}
+	for range time.Tick(outputInterval) {
+		if err := ow.update(currentState.Info(now())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func testOutputInfo() *playbackInformation {
+	return &playbackInformation{
+		CurrentSong: upcomingSong{
+			filename: "artist/song.mp3",
+			lyrics:   testLyrics,
+			metadata: metadata.SongMetadata{Title: "Title", AlbumArtist: "Album Artist", Album: "Album", Track: 3},
+		},
+		Playing:        true,
+		Volume:         0.5,
+		SongLength:     3 * time.Minute,
+		TimeInSong:     21 * time.Second,
+		ProgressInSong: 21.0 / 180,
+	}
+}
+
+func TestNowPlayingOf(t *testing.T) {
+	np := nowPlayingOf(testOutputInfo())
+	assert.Equal(t, nowPlaying{
+		Song:     "3. Title",
+		Title:    "Title",
+		Artist:   "Album Artist",
+		Album:    "Album",
+		File:     "artist/song.mp3",
+		Position: "0:21",
+		Length:   "3:00",
+		Progress: 21.0 / 180,
+		Playing:  true,
+		State:    "Playing",
+		Volume:   50,
+		Lyric:    "caption-line-2-caption-0 caption-line-2-caption-1 caption-line-2-caption-2",
+	}, np, "nowPlayingOf returned the wrong state")
+
+	live := testOutputInfo()
+	live.CurrentSong.live = true
+	live.TimeInSong = 5 * time.Second
+	np = nowPlayingOf(live)
+	assert.Equal(t, "", np.Length, "nowPlayingOf returned a length for a live song")
+	assert.Equal(t, "Live", np.State, "nowPlayingOf returned the wrong state for a live song")
+	assert.Equal(t, "", np.Lyric, "nowPlayingOf returned a lyric before the first line")
+}
+
+func TestNewOutputFormatter(t *testing.T) {
+	np := nowPlayingOf(testOutputInfo())
+
+	format, err := newOutputFormatter(outputJSON, "")
+	if assert.NoError(t, err, "newOutputFormatter failed for json") {
+		line, err := format(np)
+		assert.NoError(t, err, "json output failed")
+		var decoded nowPlaying
+		assert.NoError(t, json.Unmarshal([]byte(line), &decoded), "json output is not valid json")
+		assert.Equal(t, np, decoded, "json output did not encode the state")
+	}
+
+	format, err = newOutputFormatter(outputText, "ignored")
+	if assert.NoError(t, err, "newOutputFormatter failed for text") {
+		line, err := format(np)
+		assert.NoError(t, err, "text output failed")
+		assert.Equal(t, "Playing: 3. Title - Album Artist [0:21/3:00] 50% | "+np.Lyric, line, "text output returned the wrong line")
+	}
+
+	format, err = newOutputFormatter(outputTemplate, "{{.Artist}}\n{{.Title}}")
+	if assert.NoError(t, err, "newOutputFormatter failed for a template") {
+		line, err := format(np)
+		assert.NoError(t, err, "template output failed")
+		assert.Equal(t, "Album Artist Title", line, "template output did not keep the line on a single line")
+	}
+
+	_, err = newOutputFormatter(outputTemplate, "")
+	assert.Error(t, err, "newOutputFormatter accepted the template output without a template")
+	_, err = newOutputFormatter(outputTemplate, "{{.Artist")
+	assert.Error(t, err, "newOutputFormatter accepted an invalid template")
+	_, err = newOutputFormatter("xml", "")
+	assert.Error(t, err, "newOutputFormatter accepted an unknown output")
+}
+
+func TestOutputWriterUpdate(t *testing.T) {
+	var buf bytes.Buffer
+	ow := &outputWriter{w: &buf, format: func(np nowPlaying) (string, error) { return np.State, nil }}
+
+	info := testOutputInfo()
+	assert.NoError(t, ow.update(info), "update failed")
+	assert.NoError(t, ow.update(info), "update failed")
+	info.Playing = false
+	assert.NoError(t, ow.update(info), "update failed")
+	assert.Equal(t, "Playing\nPaused\n", buf.String(), "update did not write a line per state change")
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/gdamore/tcell"
+	"sync"
+)
+
+// playlistPanelWidth is the maximal width of the playlist side panel
+const playlistPanelWidth = 40
+
+var (
+	playlistHistoryStyle = tcell.StyleDefault.Foreground(tcell.ColorGray)
+	playlistCurrentStyle = tcell.StyleDefault.Foreground(tcell.ColorYellow).Bold(true)
+)
+
+// playlistPanel is true, if the playlist side panel is shown
+var playlistPanel = false
+var playlistPanelMutex sync.Mutex
+
+// playlistEntry is a song in the playlist of the server
+type playlistEntry struct {
+	filename   string
+	metadata   metadata.SongMetadata
+	autoQueued bool
+}
+
+// playlistView is the playlist of the server, built from the snapshots and diffs it sends
+type playlistView struct {
+	version    uint64
+	entries    []playlistEntry
+	position   int
+	playing    bool
+	autoDJMode string
+	party      bool
+}
+
+func fromWirePlaylistEntries(wireEntries []*comm.PlaylistInfo_Entry) []playlistEntry {
+	entries := make([]playlistEntry, len(wireEntries))
+	for i, e := range wireEntries {
+		entries[i] = playlistEntry{filename: e.SongFileName, metadata: fromWireMetadata(e.Metadata), autoQueued: e.AutoQueued}
+	}
+	return entries
+}
+
+// apply updates the view with a snapshot or diff. Diffs not based on the current version are ignored and
+// false is returned.
+func (pv *playlistView) apply(info *comm.PlaylistInfo) bool {
+	entries := fromWirePlaylistEntries(info.Entries)
+	if !info.Snapshot {
+		index, removed := int(info.ChangeIndex), int(info.ChangeRemoved)
+		if pv.version == 0 || info.BaseVersion != pv.version || index < 0 || removed < 0 || len(pv.entries) < index+removed {
+			return false
+		}
+		updated := make([]playlistEntry, 0, len(pv.entries)-removed+len(entries))
+		updated = append(updated, pv.entries[:index]...)
+		updated = append(updated, entries...)
+		entries = append(updated, pv.entries[index+removed:]...)
+	}
+	*pv = playlistView{
+		version:    info.Version,
+		entries:    entries,
+		position:   int(info.Position),
+		playing:    info.Playing,
+		autoDJMode: info.AutoDJMode,
+		party:      info.Party,
+	}
+	return true
+}
+
+// currentIndex returns the index of the song playing. The playlist position on the server is ahead of the
+// playback, so the song playing is searched before the position first.
+func (pv *playlistView) currentIndex(song string) int {
+	if song != "" {
+		for i := pv.position; 0 <= i; i-- {
+			if i < len(pv.entries) && pv.entries[i].filename == song {
+				return i
+			}
+		}
+	}
+	return pv.position
+}
+
+// title returns the title of the panel, including the play mode
+func (pv *playlistView) title() string {
+	title := "Playlist"
+	if pv.party {
+		title += " - party"
+	}
+	if pv.autoDJMode != "" {
+		title += " - auto-dj: " + pv.autoDJMode
+	}
+	return title
+}
+
+func (e playlistEntry) name() string {
+	name := e.filename
+	if e.metadata.Title != "" {
+		name = e.metadata.Title
+		if e.metadata.Artist != "" {
+			name = e.metadata.Artist + " - " + name
+		}
+	}
+	if e.autoQueued {
+		name += " (auto-dj)"
+	}
+	if 0 < e.metadata.Duration {
+		name += " " + fmtDuration(e.metadata.Duration)
+	}
+	return name
+}
+
+// playlistPanelLine is a line of the playlist panel
+type playlistPanelLine struct {
+	text  string
+	style tcell.Style
+}
+
+// playlistPanelLines returns height lines of the playlist around current: up to a third of the lines show the
+// history, the rest the upcoming songs
+func playlistPanelLines(pv *playlistView, current int, height int) []playlistPanelLine {
+	lines := make([]playlistPanelLine, 0, height)
+	if len(pv.entries) == 0 || height <= 0 {
+		return lines
+	}
+	first := current - height/3
+	if first < 0 {
+		first = 0
+	}
+	for i := first; i < len(pv.entries) && len(lines) < height; i++ {
+		style := tcell.StyleDefault
+		if i < current {
+			style = playlistHistoryStyle
+		} else if i == current {
+			style = playlistCurrentStyle
+		}
+		lines = append(lines, playlistPanelLine{text: pv.entries[i].name(), style: style})
+	}
+	return lines
+}
+
+func (s *state) applyPlaylistInfo(info *comm.PlaylistInfo) bool {
+	s.PlaylistMutex.Lock()
+	defer s.PlaylistMutex.Unlock()
+	return s.Playlist.apply(info)
+}
+
+func (s *state) playlist() playlistView {
+	s.PlaylistMutex.RLock()
+	defer s.PlaylistMutex.RUnlock()
+	return s.Playlist
+}
+
+// togglePlaylistPanel shows or hides the playlist panel
+func togglePlaylistPanel() {
+	playlistPanelMutex.Lock()
+	defer playlistPanelMutex.Unlock()
+	playlistPanel = !playlistPanel
+}
+
+// playlistPanelSize returns the width of the playlist panel or 0, if it is hidden
+func playlistPanelSize(d *drawer) int {
+	playlistPanelMutex.Lock()
+	defer playlistPanelMutex.Unlock()
+	if !playlistPanel {
+		return 0
+	}
+	width := d.w / 3
+	if playlistPanelWidth < width {
+		width = playlistPanelWidth
+	}
+	if width < 10 {
+		return 0
+	}
+	return width
+}
+
+// drawPlaylistPanel draws the playlist in a box of width at the right side above the playback info
+func drawPlaylistPanel(d *drawer, width int, info *playbackInformation) {
+	height := d.h - 5
+	if height < 3 {
+		return
+	}
+	pv := currentState.playlist()
+	x := d.w - width
+	for i, l := range playlistPanelLines(&pv, pv.currentIndex(info.CurrentSong.filename), height-2) {
+		text := []rune(l.text)
+		if width-2 < len(text) {
+			text = text[:width-2]
+		}
+		d.drawRunes(x+1, 1+i, l.style, string(text))
+	}
+	d.drawBox(x, 0, width, height, tcell.StyleDefault)
+	title := []rune(pv.title())
+	if width-4 < len(title) {
+		title = title[:width-4]
+	}
+	d.drawRunes(x+2, 0, tcell.StyleDefault, string(title))
+}
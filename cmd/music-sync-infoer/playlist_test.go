@@ -0,0 +1,85 @@
+package main
+
+import (
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func testPlaylistView(songs ...string) *playlistView {
+	pv := &playlistView{version: 1}
+	for _, s := range songs {
+		pv.entries = append(pv.entries, playlistEntry{filename: s})
+	}
+	return pv
+}
+
+func TestPlaylistView_apply(t *testing.T) {
+	pv := &playlistView{}
+	assert.False(t, pv.apply(&comm.PlaylistInfo{Version: 2, BaseVersion: 1}), "apply applied a diff without a snapshot")
+
+	assert.True(t, pv.apply(&comm.PlaylistInfo{Version: 1, Snapshot: true, Position: 1, AutoDJMode: "lru", Entries: []*comm.PlaylistInfo_Entry{
+		{SongFileName: "a.mp3", Metadata: &comm.NewSongInfo_SongMetadata{Title: "A"}},
+		{SongFileName: "b.mp3"}, {SongFileName: "c.mp3"},
+	}}), "apply did not apply the snapshot")
+	assert.Equal(t, &playlistView{version: 1, position: 1, autoDJMode: "lru", entries: []playlistEntry{
+		{filename: "a.mp3", metadata: metadata.SongMetadata{Title: "A"}}, {filename: "b.mp3"}, {filename: "c.mp3"},
+	}}, pv, "apply did not apply the snapshot correctly")
+
+	assert.True(t, pv.apply(&comm.PlaylistInfo{Version: 2, BaseVersion: 1, ChangeIndex: 1, ChangeRemoved: 1, Position: 2, Playing: true,
+		Entries: []*comm.PlaylistInfo_Entry{{SongFileName: "x.mp3", AutoQueued: true}, {SongFileName: "y.mp3"}}}), "apply did not apply the diff")
+	assert.Equal(t, &playlistView{version: 2, position: 2, playing: true, entries: []playlistEntry{
+		{filename: "a.mp3", metadata: metadata.SongMetadata{Title: "A"}}, {filename: "x.mp3", autoQueued: true}, {filename: "y.mp3"}, {filename: "c.mp3"},
+	}}, pv, "apply did not apply the diff correctly")
+
+	assert.False(t, pv.apply(&comm.PlaylistInfo{Version: 4, BaseVersion: 3}), "apply applied a diff based on another version")
+	assert.False(t, pv.apply(&comm.PlaylistInfo{Version: 3, BaseVersion: 2, ChangeIndex: 3, ChangeRemoved: 2}), "apply applied a diff out of range")
+	assert.Equal(t, uint64(2), pv.version, "apply changed the version for an ignored diff")
+}
+
+func TestPlaylistView_currentIndex(t *testing.T) {
+	pv := testPlaylistView("a.mp3", "b.mp3", "a.mp3", "c.mp3")
+	pv.position = 3
+	assert.Equal(t, 2, pv.currentIndex("a.mp3"), "currentIndex did not find the song before the position")
+	assert.Equal(t, 3, pv.currentIndex("c.mp3"), "currentIndex did not find the song at the position")
+	assert.Equal(t, 3, pv.currentIndex("other.mp3"), "currentIndex did not return the position for an unknown song")
+	assert.Equal(t, 3, pv.currentIndex(""), "currentIndex did not return the position without a song")
+}
+
+func TestPlaylistView_title(t *testing.T) {
+	pv := testPlaylistView()
+	assert.Equal(t, "Playlist", pv.title(), "title returned the wrong title without a play mode")
+	pv.party, pv.autoDJMode = true, "random"
+	assert.Equal(t, "Playlist - party - auto-dj: random", pv.title(), "title did not include the play mode")
+}
+
+func TestPlaylistEntry_name(t *testing.T) {
+	assert.Equal(t, "a.mp3", playlistEntry{filename: "a.mp3"}.name(), "name did not fall back to the filename")
+	assert.Equal(t, "Title", playlistEntry{filename: "a.mp3", metadata: metadata.SongMetadata{Title: "Title"}}.name(),
+		"name did not use the title")
+	assert.Equal(t, "Artist - Title (auto-dj)", playlistEntry{filename: "a.mp3", autoQueued: true,
+		metadata: metadata.SongMetadata{Title: "Title", Artist: "Artist"}}.name(), "name did not include the artist and auto-dj")
+	assert.Equal(t, "Title 3:05", playlistEntry{filename: "a.mp3", metadata: metadata.SongMetadata{Title: "Title", Duration: 185 * time.Second}}.name(),
+		"name did not include the duration")
+}
+
+func TestPlaylistPanelLines(t *testing.T) {
+	pv := testPlaylistView("0", "1", "2", "3", "4", "5", "6", "7")
+	texts := func(lines []playlistPanelLine) []string {
+		r := make([]string, len(lines))
+		for i, l := range lines {
+			r[i] = l.text
+		}
+		return r
+	}
+
+	lines := playlistPanelLines(pv, 5, 6)
+	assert.Equal(t, []string{"3", "4", "5", "6", "7"}, texts(lines), "playlistPanelLines returned the wrong songs")
+	assert.Equal(t, playlistHistoryStyle, lines[0].style, "playlistPanelLines did not style the history")
+	assert.Equal(t, playlistCurrentStyle, lines[2].style, "playlistPanelLines did not highlight the current song")
+
+	assert.Equal(t, []string{"0", "1", "2"}, texts(playlistPanelLines(pv, 0, 3)), "playlistPanelLines returned the wrong songs at the start")
+	assert.Empty(t, playlistPanelLines(testPlaylistView(), 0, 5), "playlistPanelLines returned lines for an empty playlist")
+}
@@ -17,7 +17,17 @@ type state struct {
 	Pauses      []pauseToggle
 	PausesMutex sync.RWMutex
 
-	Volume float64
+	Playlist      playlistView
+	PlaylistMutex sync.RWMutex
+
+	Covers      []coverImage
+	CoversMutex sync.RWMutex
+
+	Spectra      []spectrumChunk
+	SpectraMutex sync.Mutex
+
+	Volume  float64
+	Karaoke bool
 }
 
 type pauseByToggleIndex []pauseToggle
@@ -42,13 +52,14 @@ func (s *state) Info(now int64) *playbackInformation {
 	sample := s.currentSample(now)
 	currentSong := s.currentSong(sample)
 	s.removeOldPauses(currentSong)
+	s.removeOldSpectra(sample)
 	pausesInCurrentSong, playing := s.pausesInCurrentSong(sample, currentSong)
 
 	var songLength, timeInSong time.Duration
 	var progressInSong float64
 
 	if currentSong.startIndex != 0 && int64(currentSong.startIndex) < sample {
-		sampleInSong := sample - int64(currentSong.startIndex) - pausesInCurrentSong
+		sampleInSong := sample - int64(currentSong.startIndex) - pausesInCurrentSong + currentSong.offset
 		timeInSong = time.Duration(sampleInSong) * time.Second / time.Duration(schedule.SampleRate) / time.Nanosecond
 		if 0 < currentSong.length {
 			progressInSong = float64(sampleInSong) / float64(currentSong.length)
@@ -198,6 +209,9 @@ type playbackInformation struct {
 }
 
 func (pbi playbackInformation) playingString() string {
+	if pbi.Playing && pbi.CurrentSong.live {
+		return "Live"
+	}
 	if pbi.Playing {
 		return "Playing"
 	}
@@ -212,6 +226,12 @@ type upcomingSong struct {
 	length     int64
 	lyrics     []metadata.LyricsLine
 	metadata   metadata.SongMetadata
+	live       bool
+	offset     int64
+	speed      float64
+	// filePosition is the position in the song file at startIndex in samples
+	filePosition int64
+	coverID      string
 }
 
 type upcomingChunk struct {
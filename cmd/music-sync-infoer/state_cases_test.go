@@ -227,6 +227,26 @@ var infoTestCases = []struct {
 			ProgressInSong:      0.5,
 		},
 	},
+	{
+		now: 45e9,
+		state: state{
+			Songs:  []upcomingSong{{filename: "the-song", startIndex: 15, length: 50}, {filename: "the-song", startIndex: 35, length: 80, offset: 20, speed: 0.5}},
+			Chunks: []upcomingChunk{{startTime: 0e9, startIndex: 0, size: 256}},
+			Pauses: []pauseToggle{},
+			Volume: 0.1,
+		},
+		info: &playbackInformation{
+			CurrentSong:         upcomingSong{filename: "the-song", startIndex: 35, length: 80, offset: 20, speed: 0.5},
+			CurrentSample:       45,
+			PausesInCurrentSong: 0,
+			Now:                 45e9,
+			Playing:             true,
+			Volume:              0.1,
+			SongLength:          80e9,
+			TimeInSong:          30e9,
+			ProgressInSong:      0.375,
+		},
+	},
 }
 
 var upcomingChunkCases = []struct {
@@ -63,6 +63,8 @@ func TestState_Info(t *testing.T) {
 func TestPlaybackInformation_playingString(t *testing.T) {
 	assert.Equal(t, "Playing", playbackInformation{Playing: true}.playingString(), "playbackString is wrong for Playing: true")
 	assert.Equal(t, "Paused", playbackInformation{Playing: false}.playingString(), "playbackString is wrong for Playing: false")
+	assert.Equal(t, "Live", playbackInformation{Playing: true, CurrentSong: upcomingSong{live: true}}.playingString(), "playbackString is wrong for a live song")
+	assert.Equal(t, "Paused", playbackInformation{Playing: false, CurrentSong: upcomingSong{live: true}}.playingString(), "playbackString is wrong for a paused live song")
 }
 
 func TestUpcomingChunk_lengthAndEndTime(t *testing.T) {
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/gdamore/tcell"
+	"sort"
+	"sync"
+)
+
+// visualizerMaxHeight is the maximal height of the visualizer in rows
+const visualizerMaxHeight = 8
+
+var visualizerStyle = tcell.StyleDefault.Foreground(tcell.ColorGreen)
+
+// visualizerRunes are the runes of a bar cell filled by 0 to 8 eighths
+var visualizerRunes = []rune{' ', '▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+// visualizer is true, if the spectrum visualizer is shown
+var visualizer = true
+var visualizerMutex sync.Mutex
+
+// spectrumChunk holds the spectra of the slices of a chunk sent by the server
+type spectrumChunk struct {
+	startIndex uint64
+	sliceSize  uint64
+	bands      int
+	levels     []byte
+}
+
+func (sc spectrumChunk) endIndex() uint64 {
+	return sc.startIndex + uint64(len(sc.levels)/sc.bands)*sc.sliceSize
+}
+
+type spectraByStartIndex []spectrumChunk
+
+func (s spectraByStartIndex) Len() int           { return len(s) }
+func (s spectraByStartIndex) Less(i, j int) bool { return s[i].startIndex < s[j].startIndex }
+func (s spectraByStartIndex) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func (s *state) addSpectrum(info *comm.SpectrumInfo) {
+	if info.Bands == 0 || info.SliceSize == 0 {
+		return
+	}
+	s.SpectraMutex.Lock()
+	defer s.SpectraMutex.Unlock()
+	s.Spectra = append(s.Spectra, spectrumChunk{
+		startIndex: info.FirstSampleIndex,
+		sliceSize:  info.SliceSize,
+		bands:      int(info.Bands),
+		levels:     info.Levels,
+	})
+	sort.Sort(spectraByStartIndex(s.Spectra))
+}
+
+// removeOldSpectra removes the spectra played before sample
+func (s *state) removeOldSpectra(sample int64) {
+	s.SpectraMutex.Lock()
+	defer s.SpectraMutex.Unlock()
+
+	passed := 0
+	for passed < len(s.Spectra) && int64(s.Spectra[passed].endIndex()) <= sample {
+		passed++
+	}
+	s.Spectra = s.Spectra[passed:]
+}
+
+// spectrum returns the levels of the bands playing at sample or nil, if there is no spectrum for it.
+// Spectra before sample are removed.
+func (s *state) spectrum(sample int64) []byte {
+	s.removeOldSpectra(sample)
+
+	s.SpectraMutex.Lock()
+	defer s.SpectraMutex.Unlock()
+	if len(s.Spectra) == 0 || sample < int64(s.Spectra[0].startIndex) {
+		return nil
+	}
+	sc := s.Spectra[0]
+	slice := int((uint64(sample) - sc.startIndex) / sc.sliceSize)
+	return sc.levels[slice*sc.bands : (slice+1)*sc.bands]
+}
+
+// visualizerBars returns the heights in eighths of a cell of the width bars of a visualizer height rows high
+func visualizerBars(levels []byte, width, height int) []int {
+	bars := make([]int, width)
+	if len(levels) == 0 {
+		return bars
+	}
+	for i := range bars {
+		level := int(levels[i*len(levels)/width])
+		bars[i] = (level*height*8 + 127) / 255
+	}
+	return bars
+}
+
+// toggleVisualizer shows or hides the visualizer
+func toggleVisualizer() {
+	visualizerMutex.Lock()
+	defer visualizerMutex.Unlock()
+	visualizer = !visualizer
+}
+
+func visualizerShown() bool {
+	visualizerMutex.Lock()
+	defer visualizerMutex.Unlock()
+	return visualizer
+}
+
+// drawVisualizer draws the spectrum playing at sample as bars between x and the right side, above the row bottom
+func drawVisualizer(d *drawer, x int, bottom int, sample int64) {
+	width, height := d.w-1-x, bottom
+	if visualizerMaxHeight < height {
+		height = visualizerMaxHeight
+	}
+	if !visualizerShown() || width <= 0 || height <= 0 {
+		return
+	}
+	levels := currentState.spectrum(sample)
+	if levels == nil {
+		return
+	}
+	for i, bar := range visualizerBars(levels, width, height) {
+		for row := 0; row < height && 0 < bar; row++ {
+			filled := bar
+			if 8 < filled {
+				filled = 8
+			}
+			d.SetContent(x+i, bottom-1-row, visualizerRunes[filled], nil, visualizerStyle)
+			bar -= filled
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStateSpectrum(t *testing.T) {
+	s := &state{}
+	s.addSpectrum(&comm.SpectrumInfo{FirstSampleIndex: 200, SliceSize: 50, Bands: 2, Levels: []byte{5, 6, 7, 8}})
+	s.addSpectrum(&comm.SpectrumInfo{FirstSampleIndex: 100, SliceSize: 50, Bands: 2, Levels: []byte{1, 2, 3, 4}})
+	s.addSpectrum(&comm.SpectrumInfo{FirstSampleIndex: 300, SliceSize: 50, Bands: 0})
+
+	assert.Len(t, s.Spectra, 2, "addSpectrum added a spectrum without bands")
+	assert.Nil(t, s.spectrum(50), "spectrum returned levels before the first chunk")
+	assert.Equal(t, []byte{1, 2}, s.spectrum(100), "spectrum returned the wrong levels for the first slice")
+	assert.Equal(t, []byte{3, 4}, s.spectrum(199), "spectrum returned the wrong levels for the second slice")
+	assert.Equal(t, []byte{7, 8}, s.spectrum(260), "spectrum returned the wrong levels for a later chunk")
+	assert.Len(t, s.Spectra, 1, "spectrum did not remove the passed chunk")
+	assert.Nil(t, s.spectrum(300), "spectrum returned levels after the last chunk")
+	assert.Empty(t, s.Spectra, "spectrum did not remove the last chunk")
+}
+
+func TestVisualizerBars(t *testing.T) {
+	assert.Equal(t, []int{0, 0, 0}, visualizerBars(nil, 3, 2), "visualizerBars returned bars without levels")
+	assert.Equal(t, []int{0, 16, 8, 1}, visualizerBars([]byte{0, 255, 128, 8}, 4, 2),
+		"visualizerBars returned the wrong bars for one level per bar")
+	assert.Equal(t, []int{8, 8, 0, 0}, visualizerBars([]byte{255, 0}, 4, 1),
+		"visualizerBars did not stretch the levels over the width")
+	assert.Equal(t, []int{0, 8}, visualizerBars([]byte{0, 64, 255, 32}, 2, 1),
+		"visualizerBars did not pick a level for each bar")
+}
@@ -0,0 +1,56 @@
+// This package is the main package of the music-sync lyrics converter
+package main
+
+import (
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/cmd"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/urfave/cli"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const usage = "convert lyrics from a .lrc, .srt or .json file or the ID3 tag of a mp3 file to the music-sync lyrics format"
+
+func main() {
+	app := cmd.NewApp(usage)
+	app.ArgsUsage = "input [output]"
+	app.Action = run
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || 2 < ctx.NArg() {
+		return cli.NewExitError(fmt.Sprintf("usage: %s %s", ctx.App.Name, ctx.App.ArgsUsage), 1)
+	}
+	input := ctx.Args().Get(0)
+
+	playback.AudioDir = filepath.Dir(input)
+	lyrics, err := metadata.ReadLyricsFile(filepath.Base(input))
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("failed to read lyrics from %s: %v", input, err), 1)
+	}
+	if len(lyrics) == 0 {
+		return cli.NewExitError(fmt.Sprintf("%s contains no lyrics", input), 1)
+	}
+
+	var w io.Writer = os.Stdout
+	if output := ctx.Args().Get(1); output != "" && output != "-" {
+		f, err := os.Create(output)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("failed to create %s: %v", output, err), 1)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := metadata.WriteLyrics(w, lyrics); err != nil {
+		return cli.NewExitError(fmt.Sprintf("failed to write lyrics: %v", err), 1)
+	}
+	return nil
+}
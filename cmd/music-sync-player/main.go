@@ -23,6 +23,7 @@ func main() {
 		cmd.ServerPortFlag,
 
 		cmd.SampleRateFlag,
+		cmd.PlayerNameFlag,
 	})
 
 	if err := app.Run(os.Args); err != nil {
@@ -38,9 +39,11 @@ func run(ctx *cli.Context) error {
 		serverPort    = ctx.Int(cmd.FlagKey(cmd.ServerPortFlag))
 
 		sampleRate = ctx.Int(cmd.FlagKey(cmd.SampleRateFlag))
+		playerName = ctx.String(cmd.FlagKey(cmd.PlayerNameFlag))
 	)
 
 	schedule.SampleRate = sampleRate
+	schedule.PlayerName = playerName
 
 	server := fmt.Sprintf("%s:%d", serverAddress, serverPort)
 	sender, err := comm.ConnectToServer(server, newPlayerPackageHandler())
@@ -64,10 +67,13 @@ func (c playerPackageHandler) HandleTimeSyncResponse(tsr *comm.TimeSyncResponse,
 }
 
 func (c playerPackageHandler) HandleQueueChunkRequest(qsr *comm.QueueChunkRequest, _ net.Conn) {
-	playback.QueueChunk(qsr.StartTime, qsr.ChunkId, playback.CombineSamples(qsr.SampleLow, qsr.SampleHigh))
+	playback.QueueChunk(qsr.StartTime, qsr.ChunkId, qsr.FirstSampleIndex, playback.CombineSamples(qsr.SampleLow, qsr.SampleHigh))
 }
 func (c playerPackageHandler) HandleSetVolumeRequest(svr *comm.SetVolumeRequest, _ net.Conn) {
-	playback.SetVolume(svr.Volume)
+	playback.RampVolume(svr.Volume, svr.SampleIndex, svr.RampLength)
+}
+func (c playerPackageHandler) HandleSetDSPRequest(sdr *comm.SetDSPRequest, _ net.Conn) {
+	playback.SetDSPConfig(schedule.DSPConfigFromWire(sdr))
 }
 func (c playerPackageHandler) HandlePingMessage(_ *comm.PingMessage, conn net.Conn) {
 	comm.PingHandler(conn)
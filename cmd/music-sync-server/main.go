@@ -3,8 +3,10 @@ package main
 
 import (
 	"fmt"
+	"github.com/LogicalOverflow/music-sync/api"
 	"github.com/LogicalOverflow/music-sync/cmd"
 	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/mpd"
 	"github.com/LogicalOverflow/music-sync/playback"
 	"github.com/LogicalOverflow/music-sync/schedule"
 	"github.com/LogicalOverflow/music-sync/ssh"
@@ -26,6 +28,10 @@ func main() {
 		cmd.SSHPortFlag,
 		cmd.SSHUsersFlag,
 		cmd.SSHKeyFileFlag,
+		cmd.APIAddressFlag,
+		cmd.APIPortFlag,
+		cmd.MPDAddressFlag,
+		cmd.MPDPortFlag,
 
 		cmd.TimeSyncIntervalFlag,
 		cmd.TimeSyncCyclesFlag,
@@ -35,6 +41,15 @@ func main() {
 		cmd.StreamDelayFlag,
 		cmd.NanBreakSizeFlag,
 		cmd.SampleRateFlag,
+		cmd.LoudnessCacheFileFlag,
+		cmd.FadeDurationFlag,
+		cmd.SilenceThresholdFlag,
+		cmd.VolumeRampDurationFlag,
+		cmd.PlaylistDirFlag,
+		cmd.ScheduleFileFlag,
+		cmd.SleepFadeDurationFlag,
+		cmd.CoverArtSizeFlag,
+		cmd.SpectrumBandsFlag,
 	})
 	app.Action = run
 
@@ -54,6 +69,15 @@ func setScheduleVars(ctx *cli.Context) {
 		streamDelay        = ctx.Duration(cmd.FlagKey(cmd.StreamDelayFlag))
 		nanBreakSize       = ctx.Int(cmd.FlagKey(cmd.NanBreakSizeFlag))
 		sampleRate         = ctx.Int(cmd.FlagKey(cmd.SampleRateFlag))
+		loudnessCacheFile  = ctx.String(cmd.FlagKey(cmd.LoudnessCacheFileFlag))
+		fadeDuration       = ctx.Duration(cmd.FlagKey(cmd.FadeDurationFlag))
+		silenceThreshold   = ctx.Float64(cmd.FlagKey(cmd.SilenceThresholdFlag))
+		volumeRampDuration = ctx.Duration(cmd.FlagKey(cmd.VolumeRampDurationFlag))
+		playlistDir        = ctx.String(cmd.FlagKey(cmd.PlaylistDirFlag))
+		scheduleFile       = ctx.String(cmd.FlagKey(cmd.ScheduleFileFlag))
+		sleepFadeDuration  = ctx.Duration(cmd.FlagKey(cmd.SleepFadeDurationFlag))
+		coverArtSize       = ctx.Uint(cmd.FlagKey(cmd.CoverArtSizeFlag))
+		spectrumBands      = ctx.Uint(cmd.FlagKey(cmd.SpectrumBandsFlag))
 	)
 
 	schedule.TimeSyncInterval = timeSyncInterval
@@ -65,7 +89,15 @@ func setScheduleVars(ctx *cli.Context) {
 	schedule.StreamStartDelay = streamStartDelay
 	schedule.StreamDelay = streamDelay
 	schedule.SampleRate = sampleRate
-
+	schedule.LoudnessCacheFile = loudnessCacheFile
+	schedule.FadeDuration = fadeDuration
+	schedule.SilenceThreshold = silenceThreshold
+	schedule.VolumeRampDuration = volumeRampDuration
+	schedule.PlaylistDir = playlistDir
+	schedule.ScheduleFile = scheduleFile
+	schedule.SleepFadeDuration = sleepFadeDuration
+	schedule.CoverArtSize = int(coverArtSize)
+	schedule.SpectrumBands = int(spectrumBands)
 }
 
 func run(ctx *cli.Context) error {
@@ -78,6 +110,10 @@ func run(ctx *cli.Context) error {
 		sshPort       = ctx.Int(cmd.FlagKey(cmd.SSHPortFlag))
 		sshUsers      = ctx.String(cmd.FlagKey(cmd.SSHUsersFlag))
 		sshKeyFile    = ctx.String(cmd.FlagKey(cmd.SSHKeyFileFlag))
+		apiAddress    = ctx.String(cmd.FlagKey(cmd.APIAddressFlag))
+		apiPort       = ctx.Int(cmd.FlagKey(cmd.APIPortFlag))
+		mpdAddress    = ctx.String(cmd.FlagKey(cmd.MPDAddressFlag))
+		mpdPort       = ctx.Int(cmd.FlagKey(cmd.MPDPortFlag))
 	)
 
 	listen := fmt.Sprintf("%s:%d", listenAddress, listenPort)
@@ -101,7 +137,14 @@ func run(ctx *cli.Context) error {
 	}
 
 	ssh.HostKeyFile = sshKeyFile
+	schedule.ControlUsers = users
 	go ssh.StartSSH(sshListen, users)
+	if apiPort != 0 {
+		go api.StartAPI(fmt.Sprintf("%s:%d", apiAddress, apiPort), users)
+	}
+	if mpdPort != 0 {
+		go mpd.StartMPD(fmt.Sprintf("%s:%d", mpdAddress, mpdPort), users)
+	}
 	go schedule.Server(sender)
 
 	cmd.WaitForInterrupt()
@@ -33,6 +33,56 @@ var testPackages = []proto.Message{
 	&SetVolumeRequest{
 		Volume: 1.2,
 	},
+	&SetDSPRequest{
+		Bands:     []*SetDSPRequest_EQBand{{Frequency: 60, Gain: 3, Q: 1}},
+		BassBoost: 2,
+		Width:     1.2,
+		Limiter:   true,
+	},
+	&KaraokeInfo{
+		Enabled: true,
+	},
+	&UploadLyricsRequest{
+		SongFileName: "song.mp3",
+		Lyrics: []*NewSongInfo_SongLyricsLine{
+			{Atoms: []*NewSongInfo_SongLyricsAtom{{Timestamp: 1000, Caption: "caption"}}},
+		},
+	},
+	&UploadLyricsResponse{
+		SongFileName: "song.mp3",
+		Error:        "error",
+	},
+	&ControlRequest{
+		User:     "user",
+		Password: "password",
+		Action:   ControlRequest_VOLUME_UP,
+		Value:    0.05,
+	},
+	&ControlResponse{
+		Action: ControlRequest_VOLUME_UP,
+		Result: "setting volume to 0.150",
+	},
+	&PlaylistInfo{
+		Version:     3,
+		BaseVersion: 2,
+		ChangeIndex: 1,
+		Entries: []*PlaylistInfo_Entry{
+			{SongFileName: "song.mp3", Metadata: &NewSongInfo_SongMetadata{Title: "title", Track: 3, Duration: 180000}, AutoQueued: true},
+		},
+		Position:   1,
+		Playing:    true,
+		AutoDJMode: "random",
+	},
+	&CoverArt{
+		Id:  "0123456789ab",
+		Png: []byte{0x89, 'P', 'N', 'G'},
+	},
+	&SpectrumInfo{
+		FirstSampleIndex: 4410,
+		SliceSize:        2205,
+		Bands:            4,
+		Levels:           []byte{0, 64, 128, 255, 255, 128, 64, 0},
+	},
 }
 
 type testPackageHandler struct {
@@ -83,7 +133,7 @@ func (tph *testPackageHandler) WaitForPackage() proto.Message {
 	return tph.Latest()
 }
 
-var testPackageChannels = [][]Channel{{Channel_AUDIO}, {Channel_META}, {}, {Channel_AUDIO, Channel_META}}
+var testPackageChannels = [][]Channel{{Channel_AUDIO}, {Channel_META}, {}, {Channel_AUDIO, Channel_META}, {Channel_AUDIO}, {Channel_META}, {}, {}, {}, {}, {Channel_META}, {Channel_META}, {Channel_META}}
 
 type bufferConn struct {
 	*bytes.Buffer
@@ -28,6 +28,16 @@ func handleConnection(conn net.Conn, h packageHandler) {
 // and when a client subscribes to a channel.
 var NewClientHandler func(channel Channel, conn MessageSender)
 
+// NamedClientHandler is called when a client subscribes to a channel and sends its name with the subscription.
+var NamedClientHandler func(name string, conn MessageSender)
+
+// UploadLyricsHandler is called when a client uploads lyrics. The error returned is sent back to the client.
+var UploadLyricsHandler func(request *UploadLyricsRequest) error
+
+// ControlHandler is called when a client sends a control request. The result or error returned is sent back to
+// the client.
+var ControlHandler func(request *ControlRequest) (string, error)
+
 // StartServer starts a music-sync server listening at address and returns a MessageSender to broadcast
 // to clients
 func StartServer(address string) (MessageSender, error) {
@@ -41,6 +51,8 @@ func StartServer(address string) (MessageSender, error) {
 	logger.Infof("server running at %s", address)
 
 	mms := &multiMessageSender{connections: make([]net.Conn, 0), channels: make(map[net.Conn][]Channel)}
+	h := newServerPackageHandler(mms)
+	webSocketServer = func(conn net.Conn) { serveClient(mms, h, conn) }
 	go serverConnectionAcceptor(mms, l)
 
 	return mms, nil
@@ -54,15 +66,18 @@ func serverConnectionAcceptor(mms *multiMessageSender, l net.Listener) {
 			logger.Fatalf("failed to accept connection: %v", err)
 			break
 		}
-		go func(conn net.Conn) {
-			mms.AddConn(conn)
-			handleConnection(conn, h)
-			mms.DelConn(conn)
-		}(conn)
-		if NewClientHandler != nil {
-			go NewClientHandler(-1, &singleMessageSender{conn})
-		}
+		go serveClient(mms, h, conn)
+	}
+}
+
+// serveClient serves the client connected through conn until the connection is closed
+func serveClient(mms *multiMessageSender, h packageHandler, conn net.Conn) {
+	mms.AddConn(conn)
+	if NewClientHandler != nil {
+		go NewClientHandler(-1, &singleMessageSender{conn})
 	}
+	handleConnection(conn, h)
+	mms.DelConn(conn)
 }
 
 // ConnectToServer connects to the server at server and returns a MessageSender to communicate with the master
@@ -72,6 +72,7 @@ func (mms *multiMessageSender) DelConn(c net.Conn) {
 		}
 	}
 
+	delete(mms.channels, c)
 	if 0 <= index {
 		mms.connections[index] = mms.connections[len(mms.connections)-1]
 		mms.connections = mms.connections[:len(mms.connections)-1]
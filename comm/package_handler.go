@@ -44,6 +44,33 @@ func (BaseTypedPackageHandler) HandleChunkInfo(*ChunkInfo, net.Conn) {}
 // HandlePauseInfo is called to handle PauseInfo
 func (BaseTypedPackageHandler) HandlePauseInfo(*PauseInfo, net.Conn) {}
 
+// HandleSetDSPRequest is called to handle a SetDSPRequest
+func (BaseTypedPackageHandler) HandleSetDSPRequest(*SetDSPRequest, net.Conn) {}
+
+// HandleKaraokeInfo is called to handle KaraokeInfo
+func (BaseTypedPackageHandler) HandleKaraokeInfo(*KaraokeInfo, net.Conn) {}
+
+// HandleUploadLyricsRequest is called to handle an UploadLyricsRequest
+func (BaseTypedPackageHandler) HandleUploadLyricsRequest(*UploadLyricsRequest, net.Conn) {}
+
+// HandleUploadLyricsResponse is called to handle an UploadLyricsResponse
+func (BaseTypedPackageHandler) HandleUploadLyricsResponse(*UploadLyricsResponse, net.Conn) {}
+
+// HandleControlRequest is called to handle a ControlRequest
+func (BaseTypedPackageHandler) HandleControlRequest(*ControlRequest, net.Conn) {}
+
+// HandleControlResponse is called to handle a ControlResponse
+func (BaseTypedPackageHandler) HandleControlResponse(*ControlResponse, net.Conn) {}
+
+// HandlePlaylistInfo is called to handle PlaylistInfo
+func (BaseTypedPackageHandler) HandlePlaylistInfo(*PlaylistInfo, net.Conn) {}
+
+// HandleCoverArt is called to handle CoverArt
+func (BaseTypedPackageHandler) HandleCoverArt(*CoverArt, net.Conn) {}
+
+// HandleSpectrumInfo is called to handle SpectrumInfo
+func (BaseTypedPackageHandler) HandleSpectrumInfo(*SpectrumInfo, net.Conn) {}
+
 // TypedPackageHandlerInterface has methods to handle all packages received
 type TypedPackageHandlerInterface interface {
 	HandleTimeSyncRequest(*TimeSyncRequest, net.Conn)
@@ -56,6 +83,15 @@ type TypedPackageHandlerInterface interface {
 	HandleNewSongInfo(*NewSongInfo, net.Conn)
 	HandleChunkInfo(*ChunkInfo, net.Conn)
 	HandlePauseInfo(*PauseInfo, net.Conn)
+	HandleSetDSPRequest(*SetDSPRequest, net.Conn)
+	HandleKaraokeInfo(*KaraokeInfo, net.Conn)
+	HandleUploadLyricsRequest(*UploadLyricsRequest, net.Conn)
+	HandleUploadLyricsResponse(*UploadLyricsResponse, net.Conn)
+	HandleControlRequest(*ControlRequest, net.Conn)
+	HandleControlResponse(*ControlResponse, net.Conn)
+	HandlePlaylistInfo(*PlaylistInfo, net.Conn)
+	HandleCoverArt(*CoverArt, net.Conn)
+	HandleSpectrumInfo(*SpectrumInfo, net.Conn)
 }
 
 // Handle forwards the message and sender to the matching Handle function of TypedPackageHandlerInterface
@@ -81,6 +117,24 @@ func (t TypedPackageHandler) Handle(message proto.Message, sender net.Conn) {
 		go t.HandleChunkInfo(message.(*ChunkInfo), sender)
 	case *PauseInfo:
 		go t.HandlePauseInfo(message.(*PauseInfo), sender)
+	case *SetDSPRequest:
+		go t.HandleSetDSPRequest(message.(*SetDSPRequest), sender)
+	case *KaraokeInfo:
+		go t.HandleKaraokeInfo(message.(*KaraokeInfo), sender)
+	case *UploadLyricsRequest:
+		go t.HandleUploadLyricsRequest(message.(*UploadLyricsRequest), sender)
+	case *UploadLyricsResponse:
+		go t.HandleUploadLyricsResponse(message.(*UploadLyricsResponse), sender)
+	case *ControlRequest:
+		go t.HandleControlRequest(message.(*ControlRequest), sender)
+	case *ControlResponse:
+		go t.HandleControlResponse(message.(*ControlResponse), sender)
+	case *PlaylistInfo:
+		go t.HandlePlaylistInfo(message.(*PlaylistInfo), sender)
+	case *CoverArt:
+		go t.HandleCoverArt(message.(*CoverArt), sender)
+	case *SpectrumInfo:
+		go t.HandleSpectrumInfo(message.(*SpectrumInfo), sender)
 	}
 }
 
@@ -102,6 +156,35 @@ func (s serverPackageHandler) HandleTimeSyncRequest(tsr *TimeSyncRequest, c net.
 func (s serverPackageHandler) HandleSubscribeChannelRequest(scr *SubscribeChannelRequest, c net.Conn) {
 	s.sender.Subscribe(c, scr.Channel)
 	NewClientHandler(scr.Channel, &singleMessageSender{c})
+	if scr.Name != "" && NamedClientHandler != nil {
+		NamedClientHandler(scr.Name, &singleMessageSender{c})
+	}
+}
+
+func (s serverPackageHandler) HandleUploadLyricsRequest(ulr *UploadLyricsRequest, c net.Conn) {
+	response := &UploadLyricsResponse{SongFileName: ulr.SongFileName}
+	if UploadLyricsHandler == nil {
+		response.Error = "the server does not accept lyrics"
+	} else if err := UploadLyricsHandler(ulr); err != nil {
+		response.Error = err.Error()
+	}
+	if err := sendWire(response, c); err != nil {
+		logger.Warnf("failed to send upload lyrics response: %v", err)
+	}
+}
+
+func (s serverPackageHandler) HandleControlRequest(cr *ControlRequest, c net.Conn) {
+	response := &ControlResponse{Action: cr.Action}
+	if ControlHandler == nil {
+		response.Error = "the server does not accept control requests"
+	} else if result, err := ControlHandler(cr); err != nil {
+		response.Error = err.Error()
+	} else {
+		response.Result = result
+	}
+	if err := sendWire(response, c); err != nil {
+		logger.Warnf("failed to send control response: %v", err)
+	}
 }
 
 func (s serverPackageHandler) HandlePingMessage(_ *PingMessage, c net.Conn) { PingHandler(c) }
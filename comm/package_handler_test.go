@@ -1,6 +1,7 @@
 package comm
 
 import (
+	"fmt"
 	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
 	"net"
@@ -64,6 +65,59 @@ func (t *testTypedPackageHandler) HandlePauseInfo(p *PauseInfo, _ net.Conn) {
 	t.lastType = "PauseInfo"
 	t.cond.Broadcast()
 }
+func (t *testTypedPackageHandler) HandleSetDSPRequest(p *SetDSPRequest, _ net.Conn) {
+	t.lastPackage = p
+	t.lastType = "SetDSPRequest"
+	t.cond.Broadcast()
+}
+
+func (t *testTypedPackageHandler) HandleKaraokeInfo(p *KaraokeInfo, _ net.Conn) {
+	t.lastPackage = p
+	t.lastType = "KaraokeInfo"
+	t.cond.Broadcast()
+}
+
+func (t *testTypedPackageHandler) HandleUploadLyricsRequest(p *UploadLyricsRequest, _ net.Conn) {
+	t.lastPackage = p
+	t.lastType = "UploadLyricsRequest"
+	t.cond.Broadcast()
+}
+
+func (t *testTypedPackageHandler) HandleUploadLyricsResponse(p *UploadLyricsResponse, _ net.Conn) {
+	t.lastPackage = p
+	t.lastType = "UploadLyricsResponse"
+	t.cond.Broadcast()
+}
+
+func (t *testTypedPackageHandler) HandleControlRequest(p *ControlRequest, _ net.Conn) {
+	t.lastPackage = p
+	t.lastType = "ControlRequest"
+	t.cond.Broadcast()
+}
+
+func (t *testTypedPackageHandler) HandleControlResponse(p *ControlResponse, _ net.Conn) {
+	t.lastPackage = p
+	t.lastType = "ControlResponse"
+	t.cond.Broadcast()
+}
+
+func (t *testTypedPackageHandler) HandlePlaylistInfo(p *PlaylistInfo, _ net.Conn) {
+	t.lastPackage = p
+	t.lastType = "PlaylistInfo"
+	t.cond.Broadcast()
+}
+
+func (t *testTypedPackageHandler) HandleCoverArt(p *CoverArt, _ net.Conn) {
+	t.lastPackage = p
+	t.lastType = "CoverArt"
+	t.cond.Broadcast()
+}
+
+func (t *testTypedPackageHandler) HandleSpectrumInfo(p *SpectrumInfo, _ net.Conn) {
+	t.lastPackage = p
+	t.lastType = "SpectrumInfo"
+	t.cond.Broadcast()
+}
 
 var typedPackageHandlerHandleCases = []struct {
 	pType string
@@ -79,6 +133,15 @@ var typedPackageHandlerHandleCases = []struct {
 	{pType: "NewSongInfo", p: &NewSongInfo{FirstSampleOfSongIndex: 1, SongFileName: "abc", SongLength: 2}},
 	{pType: "ChunkInfo", p: &ChunkInfo{StartTime: 1, FirstSampleIndex: 2, ChunkSize: 3}},
 	{pType: "PauseInfo", p: &PauseInfo{Playing: true, ToggleSampleIndex: 2}},
+	{pType: "SetDSPRequest", p: &SetDSPRequest{BassBoost: 3, Width: 1.5, Limiter: true}},
+	{pType: "KaraokeInfo", p: &KaraokeInfo{Enabled: true}},
+	{pType: "UploadLyricsRequest", p: &UploadLyricsRequest{SongFileName: "song.mp3"}},
+	{pType: "UploadLyricsResponse", p: &UploadLyricsResponse{SongFileName: "song.mp3", Error: "error"}},
+	{pType: "ControlRequest", p: &ControlRequest{User: "user", Action: ControlRequest_SEEK, Value: 10}},
+	{pType: "ControlResponse", p: &ControlResponse{Action: ControlRequest_NEXT, Result: "jumped to 1"}},
+	{pType: "PlaylistInfo", p: &PlaylistInfo{Version: 2, Snapshot: true, Entries: []*PlaylistInfo_Entry{{SongFileName: "song.mp3"}}}},
+	{pType: "CoverArt", p: &CoverArt{Id: "abc", Png: []byte{1, 2, 3}}},
+	{pType: "SpectrumInfo", p: &SpectrumInfo{FirstSampleIndex: 1, SliceSize: 2, Bands: 2, Levels: []byte{1, 2}}},
 }
 
 func TestTypedPackageHandler_Handle(t *testing.T) {
@@ -101,3 +164,80 @@ func TestTypedPackageHandler_Handle(t *testing.T) {
 		assert.Equal(t, c.p, ttph.lastPackage, "handling package of type %s called handler with wrong package through TypedPackageHandler", c.pType)
 	}
 }
+
+func TestServerPackageHandler_HandleSubscribeChannelRequest(t *testing.T) {
+	mms := &multiMessageSender{connections: make([]net.Conn, 0), channels: make(map[net.Conn][]Channel)}
+	h := serverPackageHandler{sender: mms}
+
+	var lastChan Channel = invalidLastChannel
+	lastName := ""
+	NewClientHandler = func(channel Channel, conn MessageSender) { lastChan = channel }
+	NamedClientHandler = func(name string, conn MessageSender) { lastName = name }
+	defer func() { NamedClientHandler = nil }()
+
+	conn := newBufferConn()
+	h.HandleSubscribeChannelRequest(&SubscribeChannelRequest{Channel: Channel_AUDIO}, conn)
+	assert.Equal(t, Channel_AUDIO, lastChan, "HandleSubscribeChannelRequest did not call NewClientHandler with the correct channel")
+	assert.Equal(t, "", lastName, "HandleSubscribeChannelRequest called NamedClientHandler for an unnamed client")
+	assert.True(t, mms.isSubscribed(conn, []Channel{Channel_AUDIO}), "HandleSubscribeChannelRequest did not subscribe the client")
+
+	h.HandleSubscribeChannelRequest(&SubscribeChannelRequest{Channel: Channel_AUDIO, Name: "kitchen"}, conn)
+	assert.Equal(t, "kitchen", lastName, "HandleSubscribeChannelRequest did not call NamedClientHandler with the correct name")
+}
+
+func TestServerPackageHandler_HandleUploadLyricsRequest(t *testing.T) {
+	h := serverPackageHandler{}
+	request := &UploadLyricsRequest{SongFileName: "song.mp3"}
+	defer func() { UploadLyricsHandler = nil }()
+
+	cases := []struct {
+		handler func(*UploadLyricsRequest) error
+		err     string
+	}{
+		{handler: nil, err: "the server does not accept lyrics"},
+		{handler: func(*UploadLyricsRequest) error { return fmt.Errorf("failed") }, err: "failed"},
+		{handler: func(r *UploadLyricsRequest) error {
+			assert.Equal(t, request, r, "HandleUploadLyricsRequest called UploadLyricsHandler with the wrong request")
+			return nil
+		}, err: ""},
+	}
+	for _, c := range cases {
+		UploadLyricsHandler = c.handler
+		conn := newBufferConn()
+		h.HandleUploadLyricsRequest(request, conn)
+
+		m, err := readWire(conn)
+		if assert.NoError(t, err, "HandleUploadLyricsRequest did not send a valid response") {
+			assert.Equal(t, &UploadLyricsResponse{SongFileName: "song.mp3", Error: c.err}, m,
+				"HandleUploadLyricsRequest did not send the correct response")
+		}
+	}
+}
+
+func TestServerPackageHandler_HandleControlRequest(t *testing.T) {
+	h := serverPackageHandler{}
+	request := &ControlRequest{User: "user", Password: "password", Action: ControlRequest_PAUSE}
+	defer func() { ControlHandler = nil }()
+
+	cases := []struct {
+		handler  func(*ControlRequest) (string, error)
+		response *ControlResponse
+	}{
+		{handler: nil, response: &ControlResponse{Error: "the server does not accept control requests"}},
+		{handler: func(*ControlRequest) (string, error) { return "", fmt.Errorf("failed") }, response: &ControlResponse{Error: "failed"}},
+		{handler: func(r *ControlRequest) (string, error) {
+			assert.Equal(t, request, r, "HandleControlRequest called ControlHandler with the wrong request")
+			return "playback paused", nil
+		}, response: &ControlResponse{Result: "playback paused"}},
+	}
+	for _, c := range cases {
+		ControlHandler = c.handler
+		conn := newBufferConn()
+		h.HandleControlRequest(request, conn)
+
+		m, err := readWire(conn)
+		if assert.NoError(t, err, "HandleControlRequest did not send a valid response") {
+			assert.Equal(t, c.response, m, "HandleControlRequest did not send the correct response")
+		}
+	}
+}
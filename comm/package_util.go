@@ -16,11 +16,11 @@ const zlibLevel = 5
 
 func channelOf(m proto.Message) ([]Channel, bool) {
 	switch m.(type) {
-	case *QueueChunkRequest:
+	case *QueueChunkRequest, *SetDSPRequest:
 		return []Channel{Channel_AUDIO}, true
 	case *SetVolumeRequest:
 		return []Channel{Channel_AUDIO, Channel_META}, true
-	case *ChunkInfo, *NewSongInfo, *PauseInfo:
+	case *ChunkInfo, *NewSongInfo, *PauseInfo, *KaraokeInfo, *PlaylistInfo, *CoverArt, *SpectrumInfo:
 		return []Channel{Channel_META}, true
 	default:
 		return []Channel{}, false
@@ -19,6 +19,15 @@ It has these top-level messages:
 	NewSongInfo
 	ChunkInfo
 	PauseInfo
+	SetDSPRequest
+	KaraokeInfo
+	UploadLyricsRequest
+	UploadLyricsResponse
+	ControlRequest
+	ControlResponse
+	PlaylistInfo
+	CoverArt
+	SpectrumInfo
 */
 package comm
 
@@ -195,7 +204,9 @@ func (*PongMessage) ProtoMessage()               {}
 func (*PongMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
 
 type SetVolumeRequest struct {
-	Volume float64 `protobuf:"fixed64,1,opt,name=volume" json:"volume,omitempty"`
+	Volume      float64 `protobuf:"fixed64,1,opt,name=volume" json:"volume,omitempty"`
+	SampleIndex uint64  `protobuf:"varint,2,opt,name=sampleIndex" json:"sampleIndex,omitempty"`
+	RampLength  uint64  `protobuf:"varint,3,opt,name=rampLength" json:"rampLength,omitempty"`
 }
 
 func (m *SetVolumeRequest) Reset()                    { *m = SetVolumeRequest{} }
@@ -210,8 +221,23 @@ func (m *SetVolumeRequest) GetVolume() float64 {
 	return 0
 }
 
+func (m *SetVolumeRequest) GetSampleIndex() uint64 {
+	if m != nil {
+		return m.SampleIndex
+	}
+	return 0
+}
+
+func (m *SetVolumeRequest) GetRampLength() uint64 {
+	if m != nil {
+		return m.RampLength
+	}
+	return 0
+}
+
 type SubscribeChannelRequest struct {
 	Channel Channel `protobuf:"varint,1,opt,name=channel,enum=comm.Channel" json:"channel,omitempty"`
+	Name    string  `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
 }
 
 func (m *SubscribeChannelRequest) Reset()                    { *m = SubscribeChannelRequest{} }
@@ -226,12 +252,24 @@ func (m *SubscribeChannelRequest) GetChannel() Channel {
 	return Channel_AUDIO
 }
 
+func (m *SubscribeChannelRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
 type NewSongInfo struct {
 	FirstSampleOfSongIndex uint64                        `protobuf:"varint,1,opt,name=firstSampleOfSongIndex" json:"firstSampleOfSongIndex,omitempty"`
 	SongFileName           string                        `protobuf:"bytes,2,opt,name=songFileName" json:"songFileName,omitempty"`
 	SongLength             int64                         `protobuf:"varint,3,opt,name=songLength" json:"songLength,omitempty"`
 	Lyrics                 []*NewSongInfo_SongLyricsLine `protobuf:"bytes,4,rep,name=lyrics" json:"lyrics,omitempty"`
 	Metadata               *NewSongInfo_SongMetadata     `protobuf:"bytes,5,opt,name=metadata" json:"metadata,omitempty"`
+	Live                   bool                          `protobuf:"varint,6,opt,name=live" json:"live,omitempty"`
+	SongOffset             int64                         `protobuf:"varint,7,opt,name=songOffset" json:"songOffset,omitempty"`
+	Speed                  float64                       `protobuf:"fixed64,8,opt,name=speed" json:"speed,omitempty"`
+	FilePosition           int64                         `protobuf:"varint,9,opt,name=filePosition" json:"filePosition,omitempty"`
+	CoverId                string                        `protobuf:"bytes,10,opt,name=coverId" json:"coverId,omitempty"`
 }
 
 func (m *NewSongInfo) Reset()                    { *m = NewSongInfo{} }
@@ -274,6 +312,41 @@ func (m *NewSongInfo) GetMetadata() *NewSongInfo_SongMetadata {
 	return nil
 }
 
+func (m *NewSongInfo) GetLive() bool {
+	if m != nil {
+		return m.Live
+	}
+	return false
+}
+
+func (m *NewSongInfo) GetSongOffset() int64 {
+	if m != nil {
+		return m.SongOffset
+	}
+	return 0
+}
+
+func (m *NewSongInfo) GetSpeed() float64 {
+	if m != nil {
+		return m.Speed
+	}
+	return 0
+}
+
+func (m *NewSongInfo) GetFilePosition() int64 {
+	if m != nil {
+		return m.FilePosition
+	}
+	return 0
+}
+
+func (m *NewSongInfo) GetCoverId() string {
+	if m != nil {
+		return m.CoverId
+	}
+	return ""
+}
+
 type NewSongInfo_SongLyricsAtom struct {
 	Timestamp int64  `protobuf:"varint,1,opt,name=timestamp" json:"timestamp,omitempty"`
 	Caption   string `protobuf:"bytes,2,opt,name=caption" json:"caption,omitempty"`
@@ -315,9 +388,19 @@ func (m *NewSongInfo_SongLyricsLine) GetAtoms() []*NewSongInfo_SongLyricsAtom {
 }
 
 type NewSongInfo_SongMetadata struct {
-	Title  string `protobuf:"bytes,1,opt,name=Title" json:"Title,omitempty"`
-	Artist string `protobuf:"bytes,2,opt,name=Artist" json:"Artist,omitempty"`
-	Album  string `protobuf:"bytes,3,opt,name=Album" json:"Album,omitempty"`
+	Title       string `protobuf:"bytes,1,opt,name=Title" json:"Title,omitempty"`
+	Artist      string `protobuf:"bytes,2,opt,name=Artist" json:"Artist,omitempty"`
+	Album       string `protobuf:"bytes,3,opt,name=Album" json:"Album,omitempty"`
+	Genre       string `protobuf:"bytes,4,opt,name=Genre" json:"Genre,omitempty"`
+	AlbumArtist string `protobuf:"bytes,5,opt,name=AlbumArtist" json:"AlbumArtist,omitempty"`
+	Composer    string `protobuf:"bytes,6,opt,name=Composer" json:"Composer,omitempty"`
+	Comment     string `protobuf:"bytes,7,opt,name=Comment" json:"Comment,omitempty"`
+	Year        int32  `protobuf:"varint,8,opt,name=Year" json:"Year,omitempty"`
+	Track       int32  `protobuf:"varint,9,opt,name=Track" json:"Track,omitempty"`
+	TrackTotal  int32  `protobuf:"varint,10,opt,name=TrackTotal" json:"TrackTotal,omitempty"`
+	Disc        int32  `protobuf:"varint,11,opt,name=Disc" json:"Disc,omitempty"`
+	DiscTotal   int32  `protobuf:"varint,12,opt,name=DiscTotal" json:"DiscTotal,omitempty"`
+	Duration    int64  `protobuf:"varint,13,opt,name=Duration" json:"Duration,omitempty"`
 }
 
 func (m *NewSongInfo_SongMetadata) Reset()                    { *m = NewSongInfo_SongMetadata{} }
@@ -346,6 +429,76 @@ func (m *NewSongInfo_SongMetadata) GetAlbum() string {
 	return ""
 }
 
+func (m *NewSongInfo_SongMetadata) GetGenre() string {
+	if m != nil {
+		return m.Genre
+	}
+	return ""
+}
+
+func (m *NewSongInfo_SongMetadata) GetAlbumArtist() string {
+	if m != nil {
+		return m.AlbumArtist
+	}
+	return ""
+}
+
+func (m *NewSongInfo_SongMetadata) GetComposer() string {
+	if m != nil {
+		return m.Composer
+	}
+	return ""
+}
+
+func (m *NewSongInfo_SongMetadata) GetComment() string {
+	if m != nil {
+		return m.Comment
+	}
+	return ""
+}
+
+func (m *NewSongInfo_SongMetadata) GetYear() int32 {
+	if m != nil {
+		return m.Year
+	}
+	return 0
+}
+
+func (m *NewSongInfo_SongMetadata) GetTrack() int32 {
+	if m != nil {
+		return m.Track
+	}
+	return 0
+}
+
+func (m *NewSongInfo_SongMetadata) GetTrackTotal() int32 {
+	if m != nil {
+		return m.TrackTotal
+	}
+	return 0
+}
+
+func (m *NewSongInfo_SongMetadata) GetDisc() int32 {
+	if m != nil {
+		return m.Disc
+	}
+	return 0
+}
+
+func (m *NewSongInfo_SongMetadata) GetDiscTotal() int32 {
+	if m != nil {
+		return m.DiscTotal
+	}
+	return 0
+}
+
+func (m *NewSongInfo_SongMetadata) GetDuration() int64 {
+	if m != nil {
+		return m.Duration
+	}
+	return 0
+}
+
 type ChunkInfo struct {
 	StartTime        int64  `protobuf:"varint,1,opt,name=startTime" json:"startTime,omitempty"`
 	FirstSampleIndex uint64 `protobuf:"varint,2,opt,name=firstSampleIndex" json:"firstSampleIndex,omitempty"`
@@ -402,6 +555,450 @@ func (m *PauseInfo) GetToggleSampleIndex() uint64 {
 	return 0
 }
 
+type SetDSPRequest struct {
+	Bands     []*SetDSPRequest_EQBand `protobuf:"bytes,1,rep,name=bands" json:"bands,omitempty"`
+	BassBoost float64                 `protobuf:"fixed64,2,opt,name=bassBoost" json:"bassBoost,omitempty"`
+	Width     float64                 `protobuf:"fixed64,3,opt,name=width" json:"width,omitempty"`
+	Limiter   bool                    `protobuf:"varint,4,opt,name=limiter" json:"limiter,omitempty"`
+}
+
+func (m *SetDSPRequest) Reset()                    { *m = SetDSPRequest{} }
+func (m *SetDSPRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetDSPRequest) ProtoMessage()               {}
+func (*SetDSPRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *SetDSPRequest) GetBands() []*SetDSPRequest_EQBand {
+	if m != nil {
+		return m.Bands
+	}
+	return nil
+}
+
+func (m *SetDSPRequest) GetBassBoost() float64 {
+	if m != nil {
+		return m.BassBoost
+	}
+	return 0
+}
+
+func (m *SetDSPRequest) GetWidth() float64 {
+	if m != nil {
+		return m.Width
+	}
+	return 0
+}
+
+func (m *SetDSPRequest) GetLimiter() bool {
+	if m != nil {
+		return m.Limiter
+	}
+	return false
+}
+
+type SetDSPRequest_EQBand struct {
+	Frequency float64 `protobuf:"fixed64,1,opt,name=frequency" json:"frequency,omitempty"`
+	Gain      float64 `protobuf:"fixed64,2,opt,name=gain" json:"gain,omitempty"`
+	Q         float64 `protobuf:"fixed64,3,opt,name=q" json:"q,omitempty"`
+}
+
+func (m *SetDSPRequest_EQBand) Reset()                    { *m = SetDSPRequest_EQBand{} }
+func (m *SetDSPRequest_EQBand) String() string            { return proto.CompactTextString(m) }
+func (*SetDSPRequest_EQBand) ProtoMessage()               {}
+func (*SetDSPRequest_EQBand) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11, 0} }
+
+func (m *SetDSPRequest_EQBand) GetFrequency() float64 {
+	if m != nil {
+		return m.Frequency
+	}
+	return 0
+}
+
+func (m *SetDSPRequest_EQBand) GetGain() float64 {
+	if m != nil {
+		return m.Gain
+	}
+	return 0
+}
+
+func (m *SetDSPRequest_EQBand) GetQ() float64 {
+	if m != nil {
+		return m.Q
+	}
+	return 0
+}
+
+type KaraokeInfo struct {
+	Enabled bool `protobuf:"varint,1,opt,name=enabled" json:"enabled,omitempty"`
+}
+
+func (m *KaraokeInfo) Reset()                    { *m = KaraokeInfo{} }
+func (m *KaraokeInfo) String() string            { return proto.CompactTextString(m) }
+func (*KaraokeInfo) ProtoMessage()               {}
+func (*KaraokeInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *KaraokeInfo) GetEnabled() bool {
+	if m != nil {
+		return m.Enabled
+	}
+	return false
+}
+
+type UploadLyricsRequest struct {
+	SongFileName string                        `protobuf:"bytes,1,opt,name=songFileName" json:"songFileName,omitempty"`
+	Lyrics       []*NewSongInfo_SongLyricsLine `protobuf:"bytes,2,rep,name=lyrics" json:"lyrics,omitempty"`
+	User         string                        `protobuf:"bytes,3,opt,name=user" json:"user,omitempty"`
+	Password     string                        `protobuf:"bytes,4,opt,name=password" json:"password,omitempty"`
+}
+
+func (m *UploadLyricsRequest) Reset()                    { *m = UploadLyricsRequest{} }
+func (m *UploadLyricsRequest) String() string            { return proto.CompactTextString(m) }
+func (*UploadLyricsRequest) ProtoMessage()               {}
+func (*UploadLyricsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *UploadLyricsRequest) GetSongFileName() string {
+	if m != nil {
+		return m.SongFileName
+	}
+	return ""
+}
+
+func (m *UploadLyricsRequest) GetLyrics() []*NewSongInfo_SongLyricsLine {
+	if m != nil {
+		return m.Lyrics
+	}
+	return nil
+}
+
+func (m *UploadLyricsRequest) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *UploadLyricsRequest) GetPassword() string {
+	if m != nil {
+		return m.Password
+	}
+	return ""
+}
+
+type UploadLyricsResponse struct {
+	SongFileName string `protobuf:"bytes,1,opt,name=songFileName" json:"songFileName,omitempty"`
+	Error        string `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *UploadLyricsResponse) Reset()                    { *m = UploadLyricsResponse{} }
+func (m *UploadLyricsResponse) String() string            { return proto.CompactTextString(m) }
+func (*UploadLyricsResponse) ProtoMessage()               {}
+func (*UploadLyricsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *UploadLyricsResponse) GetSongFileName() string {
+	if m != nil {
+		return m.SongFileName
+	}
+	return ""
+}
+
+func (m *UploadLyricsResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type ControlRequest struct {
+	User     string                `protobuf:"bytes,1,opt,name=user" json:"user,omitempty"`
+	Password string                `protobuf:"bytes,2,opt,name=password" json:"password,omitempty"`
+	Action   ControlRequest_Action `protobuf:"varint,3,opt,name=action,enum=comm.ControlRequest.Action" json:"action,omitempty"`
+	Value    float64               `protobuf:"fixed64,4,opt,name=value" json:"value,omitempty"`
+}
+
+func (m *ControlRequest) Reset()                    { *m = ControlRequest{} }
+func (m *ControlRequest) String() string            { return proto.CompactTextString(m) }
+func (*ControlRequest) ProtoMessage()               {}
+func (*ControlRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *ControlRequest) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *ControlRequest) GetPassword() string {
+	if m != nil {
+		return m.Password
+	}
+	return ""
+}
+
+func (m *ControlRequest) GetAction() ControlRequest_Action {
+	if m != nil {
+		return m.Action
+	}
+	return ControlRequest_PAUSE
+}
+
+func (m *ControlRequest) GetValue() float64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+type ControlRequest_Action int32
+
+const (
+	ControlRequest_PAUSE       ControlRequest_Action = 0
+	ControlRequest_RESUME      ControlRequest_Action = 1
+	ControlRequest_NEXT        ControlRequest_Action = 2
+	ControlRequest_PREVIOUS    ControlRequest_Action = 3
+	ControlRequest_VOLUME_UP   ControlRequest_Action = 4
+	ControlRequest_VOLUME_DOWN ControlRequest_Action = 5
+	ControlRequest_SEEK        ControlRequest_Action = 6
+)
+
+var ControlRequest_Action_name = map[int32]string{
+	0: "PAUSE",
+	1: "RESUME",
+	2: "NEXT",
+	3: "PREVIOUS",
+	4: "VOLUME_UP",
+	5: "VOLUME_DOWN",
+	6: "SEEK",
+}
+var ControlRequest_Action_value = map[string]int32{
+	"PAUSE":       0,
+	"RESUME":      1,
+	"NEXT":        2,
+	"PREVIOUS":    3,
+	"VOLUME_UP":   4,
+	"VOLUME_DOWN": 5,
+	"SEEK":        6,
+}
+
+func (x ControlRequest_Action) String() string {
+	return proto.EnumName(ControlRequest_Action_name, int32(x))
+}
+func (ControlRequest_Action) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{15, 0} }
+
+type ControlResponse struct {
+	Action ControlRequest_Action `protobuf:"varint,1,opt,name=action,enum=comm.ControlRequest.Action" json:"action,omitempty"`
+	Result string                `protobuf:"bytes,2,opt,name=result" json:"result,omitempty"`
+	Error  string                `protobuf:"bytes,3,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *ControlResponse) Reset()                    { *m = ControlResponse{} }
+func (m *ControlResponse) String() string            { return proto.CompactTextString(m) }
+func (*ControlResponse) ProtoMessage()               {}
+func (*ControlResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *ControlResponse) GetAction() ControlRequest_Action {
+	if m != nil {
+		return m.Action
+	}
+	return ControlRequest_PAUSE
+}
+
+func (m *ControlResponse) GetResult() string {
+	if m != nil {
+		return m.Result
+	}
+	return ""
+}
+
+func (m *ControlResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type PlaylistInfo struct {
+	Version       uint64                `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	Snapshot      bool                  `protobuf:"varint,2,opt,name=snapshot" json:"snapshot,omitempty"`
+	BaseVersion   uint64                `protobuf:"varint,3,opt,name=baseVersion" json:"baseVersion,omitempty"`
+	ChangeIndex   int32                 `protobuf:"varint,4,opt,name=changeIndex" json:"changeIndex,omitempty"`
+	ChangeRemoved int32                 `protobuf:"varint,5,opt,name=changeRemoved" json:"changeRemoved,omitempty"`
+	Entries       []*PlaylistInfo_Entry `protobuf:"bytes,6,rep,name=entries" json:"entries,omitempty"`
+	Position      int32                 `protobuf:"varint,7,opt,name=position" json:"position,omitempty"`
+	Playing       bool                  `protobuf:"varint,8,opt,name=playing" json:"playing,omitempty"`
+	AutoDJMode    string                `protobuf:"bytes,9,opt,name=autoDJMode" json:"autoDJMode,omitempty"`
+	Party         bool                  `protobuf:"varint,10,opt,name=party" json:"party,omitempty"`
+}
+
+func (m *PlaylistInfo) Reset()                    { *m = PlaylistInfo{} }
+func (m *PlaylistInfo) String() string            { return proto.CompactTextString(m) }
+func (*PlaylistInfo) ProtoMessage()               {}
+func (*PlaylistInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *PlaylistInfo) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *PlaylistInfo) GetSnapshot() bool {
+	if m != nil {
+		return m.Snapshot
+	}
+	return false
+}
+
+func (m *PlaylistInfo) GetBaseVersion() uint64 {
+	if m != nil {
+		return m.BaseVersion
+	}
+	return 0
+}
+
+func (m *PlaylistInfo) GetChangeIndex() int32 {
+	if m != nil {
+		return m.ChangeIndex
+	}
+	return 0
+}
+
+func (m *PlaylistInfo) GetChangeRemoved() int32 {
+	if m != nil {
+		return m.ChangeRemoved
+	}
+	return 0
+}
+
+func (m *PlaylistInfo) GetEntries() []*PlaylistInfo_Entry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+func (m *PlaylistInfo) GetPosition() int32 {
+	if m != nil {
+		return m.Position
+	}
+	return 0
+}
+
+func (m *PlaylistInfo) GetPlaying() bool {
+	if m != nil {
+		return m.Playing
+	}
+	return false
+}
+
+func (m *PlaylistInfo) GetAutoDJMode() string {
+	if m != nil {
+		return m.AutoDJMode
+	}
+	return ""
+}
+
+func (m *PlaylistInfo) GetParty() bool {
+	if m != nil {
+		return m.Party
+	}
+	return false
+}
+
+type PlaylistInfo_Entry struct {
+	SongFileName string                    `protobuf:"bytes,1,opt,name=songFileName" json:"songFileName,omitempty"`
+	Metadata     *NewSongInfo_SongMetadata `protobuf:"bytes,2,opt,name=metadata" json:"metadata,omitempty"`
+	AutoQueued   bool                      `protobuf:"varint,3,opt,name=autoQueued" json:"autoQueued,omitempty"`
+}
+
+func (m *PlaylistInfo_Entry) Reset()                    { *m = PlaylistInfo_Entry{} }
+func (m *PlaylistInfo_Entry) String() string            { return proto.CompactTextString(m) }
+func (*PlaylistInfo_Entry) ProtoMessage()               {}
+func (*PlaylistInfo_Entry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17, 0} }
+
+func (m *PlaylistInfo_Entry) GetSongFileName() string {
+	if m != nil {
+		return m.SongFileName
+	}
+	return ""
+}
+
+func (m *PlaylistInfo_Entry) GetMetadata() *NewSongInfo_SongMetadata {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *PlaylistInfo_Entry) GetAutoQueued() bool {
+	if m != nil {
+		return m.AutoQueued
+	}
+	return false
+}
+
+type CoverArt struct {
+	Id  string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Png []byte `protobuf:"bytes,2,opt,name=png,proto3" json:"png,omitempty"`
+}
+
+func (m *CoverArt) Reset()                    { *m = CoverArt{} }
+func (m *CoverArt) String() string            { return proto.CompactTextString(m) }
+func (*CoverArt) ProtoMessage()               {}
+func (*CoverArt) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *CoverArt) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *CoverArt) GetPng() []byte {
+	if m != nil {
+		return m.Png
+	}
+	return nil
+}
+
+type SpectrumInfo struct {
+	FirstSampleIndex uint64 `protobuf:"varint,1,opt,name=firstSampleIndex" json:"firstSampleIndex,omitempty"`
+	SliceSize        uint64 `protobuf:"varint,2,opt,name=sliceSize" json:"sliceSize,omitempty"`
+	Bands            uint32 `protobuf:"varint,3,opt,name=bands" json:"bands,omitempty"`
+	Levels           []byte `protobuf:"bytes,4,opt,name=levels,proto3" json:"levels,omitempty"`
+}
+
+func (m *SpectrumInfo) Reset()                    { *m = SpectrumInfo{} }
+func (m *SpectrumInfo) String() string            { return proto.CompactTextString(m) }
+func (*SpectrumInfo) ProtoMessage()               {}
+func (*SpectrumInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *SpectrumInfo) GetFirstSampleIndex() uint64 {
+	if m != nil {
+		return m.FirstSampleIndex
+	}
+	return 0
+}
+
+func (m *SpectrumInfo) GetSliceSize() uint64 {
+	if m != nil {
+		return m.SliceSize
+	}
+	return 0
+}
+
+func (m *SpectrumInfo) GetBands() uint32 {
+	if m != nil {
+		return m.Bands
+	}
+	return 0
+}
+
+func (m *SpectrumInfo) GetLevels() []byte {
+	if m != nil {
+		return m.Levels
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Envelope)(nil), "comm.Envelope")
 	proto.RegisterType((*TimeSyncRequest)(nil), "comm.TimeSyncRequest")
@@ -417,52 +1014,113 @@ func init() {
 	proto.RegisterType((*NewSongInfo_SongMetadata)(nil), "comm.NewSongInfo.SongMetadata")
 	proto.RegisterType((*ChunkInfo)(nil), "comm.ChunkInfo")
 	proto.RegisterType((*PauseInfo)(nil), "comm.PauseInfo")
+	proto.RegisterType((*SetDSPRequest)(nil), "comm.SetDSPRequest")
+	proto.RegisterType((*SetDSPRequest_EQBand)(nil), "comm.SetDSPRequest.EQBand")
+	proto.RegisterType((*KaraokeInfo)(nil), "comm.KaraokeInfo")
+	proto.RegisterType((*UploadLyricsRequest)(nil), "comm.UploadLyricsRequest")
+	proto.RegisterType((*UploadLyricsResponse)(nil), "comm.UploadLyricsResponse")
+	proto.RegisterType((*ControlRequest)(nil), "comm.ControlRequest")
+	proto.RegisterType((*ControlResponse)(nil), "comm.ControlResponse")
+	proto.RegisterType((*PlaylistInfo)(nil), "comm.PlaylistInfo")
+	proto.RegisterType((*PlaylistInfo_Entry)(nil), "comm.PlaylistInfo.Entry")
+	proto.RegisterType((*CoverArt)(nil), "comm.CoverArt")
+	proto.RegisterType((*SpectrumInfo)(nil), "comm.SpectrumInfo")
 	proto.RegisterEnum("comm.Channel", Channel_name, Channel_value)
+	proto.RegisterEnum("comm.ControlRequest.Action", ControlRequest_Action_name, ControlRequest_Action_value)
 }
 
 func init() { proto.RegisterFile("comm/packages.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 641 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x54, 0xdb, 0x6e, 0xd3, 0x4a,
-	0x14, 0x3d, 0xce, 0xa5, 0x8d, 0x77, 0xda, 0x9e, 0x74, 0xce, 0x51, 0xb1, 0x2a, 0x54, 0x45, 0x7e,
-	0x80, 0xa8, 0x42, 0x41, 0x04, 0xa9, 0x42, 0xbc, 0xa5, 0xa5, 0xa8, 0x91, 0xd2, 0x0b, 0xe3, 0xc2,
-	0xbb, 0xe3, 0xec, 0x3a, 0xa3, 0xda, 0x33, 0xc6, 0x33, 0x4e, 0x09, 0x9f, 0xc0, 0x3f, 0xf1, 0x3d,
-	0xfc, 0x06, 0x9a, 0xb1, 0x1d, 0xbb, 0xb4, 0xc0, 0x9b, 0xd7, 0x9a, 0xb5, 0x6f, 0x33, 0x6b, 0x1b,
-	0xfe, 0x0b, 0x44, 0x1c, 0xbf, 0x4c, 0xfc, 0xe0, 0xd6, 0x0f, 0x51, 0x0e, 0x93, 0x54, 0x28, 0x41,
-	0x5a, 0x9a, 0x74, 0x47, 0xd0, 0x39, 0xe5, 0x4b, 0x8c, 0x44, 0x82, 0x84, 0x40, 0x4b, 0xad, 0x12,
-	0x74, 0xac, 0xbe, 0x35, 0xb0, 0xa9, 0xf9, 0xd6, 0xdc, 0xdc, 0x57, 0xbe, 0xd3, 0xe8, 0x5b, 0x83,
-	0x2d, 0x6a, 0xbe, 0xdd, 0x57, 0xf0, 0xef, 0x35, 0x8b, 0xd1, 0x5b, 0xf1, 0x80, 0xe2, 0xe7, 0x0c,
-	0xa5, 0x22, 0x07, 0x00, 0x41, 0xc4, 0x90, 0x2b, 0x0f, 0xf9, 0xdc, 0x24, 0x68, 0xd2, 0x1a, 0xe3,
-	0x7e, 0xb3, 0xa0, 0x57, 0xc5, 0xc8, 0x44, 0x70, 0x89, 0xe4, 0x19, 0xec, 0x54, 0x12, 0x7d, 0x5a,
-	0x04, 0xfe, 0xc2, 0x6a, 0x9d, 0xc4, 0x74, 0x89, 0x29, 0xc5, 0x60, 0x69, 0x74, 0x8d, 0x5c, 0x77,
-	0x9f, 0xad, 0x74, 0xeb, 0x7c, 0xcd, 0xba, 0xae, 0x64, 0xdd, 0xef, 0x16, 0xec, 0x7e, 0xc8, 0x30,
-	0xc3, 0x93, 0x45, 0xc6, 0x6f, 0xcb, 0x11, 0x9e, 0x82, 0x2d, 0x95, 0x9f, 0xaa, 0x5a, 0x23, 0x15,
-	0x41, 0x1c, 0xd8, 0x0c, 0xb4, 0x7a, 0x32, 0x2f, 0x8a, 0x97, 0x90, 0xf4, 0xc1, 0x96, 0x7e, 0x9c,
-	0x44, 0x38, 0x15, 0x77, 0x4e, 0xb3, 0xdf, 0x1c, 0x58, 0xc7, 0x8d, 0x9e, 0x45, 0x2b, 0x92, 0xb8,
-	0x00, 0x39, 0x38, 0x63, 0xe1, 0xc2, 0x69, 0xad, 0x25, 0x35, 0x96, 0x1c, 0x42, 0xef, 0x86, 0xa5,
-	0x52, 0x79, 0x86, 0x9a, 0xf0, 0x39, 0x7e, 0x71, 0xda, 0x7d, 0x6b, 0xd0, 0xa2, 0x0f, 0x78, 0x77,
-	0x1b, 0xba, 0x57, 0x8c, 0x87, 0xe7, 0x28, 0xa5, 0x1f, 0xa2, 0x81, 0xa2, 0x82, 0x87, 0xd0, 0xf3,
-	0x50, 0x7d, 0x12, 0x51, 0x16, 0x63, 0x39, 0xdb, 0x1e, 0x6c, 0x2c, 0x0d, 0x61, 0x06, 0xb3, 0x68,
-	0x81, 0xdc, 0x63, 0x78, 0xe2, 0x65, 0x33, 0x19, 0xa4, 0x6c, 0x86, 0x27, 0x0b, 0x9f, 0x73, 0x8c,
-	0xca, 0x90, 0xe7, 0x7a, 0x60, 0xc3, 0x98, 0x98, 0x9d, 0xd1, 0xf6, 0x50, 0x1b, 0x66, 0x58, 0xca,
-	0xca, 0x53, 0xf7, 0x47, 0x13, 0xba, 0x17, 0x78, 0xe7, 0x09, 0x1e, 0x4e, 0xf8, 0x8d, 0x20, 0x47,
-	0xb0, 0x57, 0xeb, 0xf8, 0xf2, 0x26, 0x3f, 0xd0, 0xf3, 0x58, 0x66, 0x9e, 0xdf, 0x9c, 0x12, 0x17,
-	0xb6, 0xa4, 0xe0, 0xe1, 0x7b, 0x16, 0xe1, 0x85, 0x5f, 0xbc, 0xb1, 0x4d, 0xef, 0x71, 0xda, 0x66,
-	0x1a, 0x4f, 0x91, 0x87, 0x6a, 0x51, 0xbc, 0x6e, 0x8d, 0x21, 0x6f, 0x60, 0x23, 0x5a, 0xa5, 0x2c,
-	0x90, 0xe6, 0x96, 0xbb, 0xa3, 0x7e, 0xde, 0x73, 0xad, 0xbd, 0xa1, 0xfe, 0x98, 0x1a, 0xcd, 0x94,
-	0x71, 0xa4, 0x85, 0x9e, 0xbc, 0x85, 0x4e, 0x8c, 0xca, 0x37, 0x5e, 0xd7, 0xf7, 0xde, 0x1d, 0x1d,
-	0x3c, 0x1e, 0x7b, 0x5e, 0xa8, 0xe8, 0x5a, 0xbf, 0x7f, 0x06, 0x3b, 0x55, 0xd6, 0xb1, 0x12, 0xb1,
-	0xf6, 0x92, 0x62, 0x31, 0x4a, 0xe5, 0xc7, 0x49, 0xe9, 0xa5, 0x35, 0x61, 0xbc, 0xe4, 0x27, 0x8a,
-	0x09, 0x5e, 0x0c, 0x59, 0xc2, 0xfb, 0x99, 0x74, 0x7f, 0xe4, 0x08, 0xda, 0xbe, 0x12, 0xb1, 0x74,
-	0xac, 0xbf, 0x0f, 0xa4, 0x4b, 0xd3, 0x5c, 0xbe, 0x4f, 0x61, 0xab, 0xde, 0x2d, 0xf9, 0x1f, 0xda,
-	0xd7, 0x4c, 0x45, 0xe5, 0x72, 0xe7, 0x40, 0xfb, 0x62, 0x9c, 0x2a, 0x26, 0x55, 0xd1, 0x48, 0x81,
-	0xb4, 0x7a, 0x1c, 0xcd, 0xb2, 0xd8, 0x5c, 0xb1, 0x4d, 0x73, 0xe0, 0x4a, 0xb0, 0xcd, 0xc6, 0x98,
-	0x67, 0xfe, 0xf3, 0xba, 0x3c, 0x66, 0xe7, 0xc6, 0xe3, 0x76, 0xd6, 0x99, 0xcc, 0x2e, 0x79, 0xec,
-	0x6b, 0xbe, 0xb1, 0x2d, 0x5a, 0x11, 0xae, 0x07, 0xf6, 0x95, 0x9f, 0x49, 0x34, 0x45, 0x1d, 0xd8,
-	0x4c, 0x22, 0x7f, 0xc5, 0x78, 0x68, 0x4a, 0x76, 0x68, 0x09, 0xc9, 0x0b, 0xd8, 0x55, 0x22, 0x0c,
-	0x23, 0x7c, 0x58, 0xf1, 0xe1, 0xc1, 0xe1, 0x01, 0x6c, 0x16, 0x3e, 0x26, 0x36, 0xb4, 0xc7, 0x1f,
-	0xdf, 0x4d, 0x2e, 0x7b, 0xff, 0x90, 0x0e, 0xb4, 0xce, 0x4f, 0xaf, 0xc7, 0x3d, 0x6b, 0xb6, 0x61,
-	0x7e, 0x91, 0xaf, 0x7f, 0x06, 0x00, 0x00, 0xff, 0xff, 0x74, 0x68, 0x48, 0x2d, 0x39, 0x05, 0x00,
-	0x00,
+	// 1426 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x57, 0xdd, 0x6e, 0x1b, 0x45,
+	0x14, 0xee, 0xfa, 0x2f, 0xf6, 0xb1, 0x93, 0xba, 0xd3, 0x2a, 0xac, 0x02, 0x8a, 0xac, 0x15, 0xa2,
+	0x51, 0x55, 0x05, 0x48, 0xa5, 0x0a, 0x71, 0xe7, 0x26, 0x86, 0x86, 0xe6, 0xc7, 0x1d, 0x27, 0x01,
+	0xae, 0xd0, 0xd8, 0x3b, 0x71, 0x56, 0xd9, 0xdd, 0xd9, 0xce, 0x8c, 0x1d, 0xcc, 0x03, 0x80, 0xc4,
+	0x6b, 0xf0, 0x1c, 0xbc, 0x47, 0x79, 0x09, 0x2e, 0xb9, 0x46, 0x73, 0x66, 0xd6, 0xbb, 0x6e, 0x53,
+	0x68, 0xaf, 0x3c, 0xdf, 0x37, 0x67, 0xce, 0x39, 0x73, 0xfe, 0x66, 0x0d, 0xf7, 0x27, 0x22, 0x49,
+	0x3e, 0xcf, 0xd8, 0xe4, 0x9a, 0x4d, 0xb9, 0xda, 0xcd, 0xa4, 0xd0, 0x82, 0xd4, 0x0c, 0x19, 0xec,
+	0x41, 0x73, 0x90, 0xce, 0x79, 0x2c, 0x32, 0x4e, 0x08, 0xd4, 0xf4, 0x22, 0xe3, 0xbe, 0xd7, 0xf3,
+	0x76, 0x5a, 0x14, 0xd7, 0x86, 0x0b, 0x99, 0x66, 0x7e, 0xa5, 0xe7, 0xed, 0x74, 0x28, 0xae, 0x83,
+	0x2f, 0xe1, 0xee, 0x59, 0x94, 0xf0, 0xd1, 0x22, 0x9d, 0x50, 0xfe, 0x6a, 0xc6, 0x95, 0x26, 0xdb,
+	0x00, 0x93, 0x38, 0xe2, 0xa9, 0x1e, 0xf1, 0x34, 0x44, 0x05, 0x55, 0x5a, 0x62, 0x82, 0xdf, 0x3d,
+	0xe8, 0x16, 0x67, 0x54, 0x26, 0x52, 0xc5, 0xc9, 0x67, 0xb0, 0x51, 0x88, 0x98, 0x5d, 0x77, 0xf0,
+	0x0d, 0xd6, 0xc8, 0x29, 0x2e, 0xe7, 0x5c, 0x52, 0x3e, 0x99, 0xa3, 0x5c, 0xc5, 0xca, 0xad, 0xb2,
+	0x85, 0xdc, 0x52, 0x5f, 0xb5, 0x2c, 0x97, 0xb3, 0xc1, 0x9f, 0x1e, 0xdc, 0x7b, 0x39, 0xe3, 0x33,
+	0xbe, 0x7f, 0x35, 0x4b, 0xaf, 0xf3, 0x2b, 0x7c, 0x02, 0x2d, 0xa5, 0x99, 0xd4, 0x25, 0x47, 0x0a,
+	0x82, 0xf8, 0xb0, 0x36, 0x31, 0xd2, 0x87, 0xa1, 0x33, 0x9e, 0x43, 0xd2, 0x83, 0x96, 0x62, 0x49,
+	0x16, 0xf3, 0x23, 0x71, 0xe3, 0x57, 0x7b, 0xd5, 0x1d, 0xef, 0x59, 0xa5, 0xeb, 0xd1, 0x82, 0x24,
+	0x01, 0x80, 0x05, 0xcf, 0xa3, 0xe9, 0x95, 0x5f, 0x5b, 0x8a, 0x94, 0x58, 0xf2, 0x08, 0xba, 0x97,
+	0x91, 0x54, 0x7a, 0x84, 0xd4, 0x61, 0x1a, 0xf2, 0x9f, 0xfd, 0x7a, 0xcf, 0xdb, 0xa9, 0xd1, 0xb7,
+	0xf8, 0x60, 0x1d, 0xda, 0xc3, 0x28, 0x9d, 0x1e, 0x73, 0xa5, 0xd8, 0x94, 0x23, 0x14, 0x05, 0x8c,
+	0xa1, 0x3b, 0xe2, 0xfa, 0x42, 0xc4, 0xb3, 0x84, 0xe7, 0x77, 0xdb, 0x84, 0xc6, 0x1c, 0x09, 0xbc,
+	0x98, 0x47, 0x1d, 0x22, 0x3d, 0x68, 0xab, 0x92, 0xc1, 0x0a, 0x1a, 0x2c, 0x53, 0x26, 0xb1, 0x92,
+	0x25, 0xd9, 0x11, 0x4f, 0xa7, 0xfa, 0x0a, 0xe3, 0x59, 0xa3, 0x25, 0x26, 0xb8, 0x80, 0x8f, 0x46,
+	0xb3, 0xb1, 0x9a, 0xc8, 0x68, 0xcc, 0xf7, 0xaf, 0x58, 0x9a, 0xf2, 0x38, 0x37, 0xfa, 0xd0, 0x84,
+	0x0c, 0x19, 0xb4, 0xba, 0xb1, 0xb7, 0xbe, 0x6b, 0x4a, 0x6e, 0x37, 0x17, 0xcb, 0x77, 0x4d, 0x8d,
+	0xa5, 0xcc, 0x65, 0xb5, 0x45, 0x71, 0x1d, 0xfc, 0xd3, 0x80, 0xf6, 0x09, 0xbf, 0x19, 0x89, 0x74,
+	0x7a, 0x98, 0x5e, 0x0a, 0xf2, 0x14, 0x36, 0x4b, 0x71, 0x38, 0xbd, 0xb4, 0x1b, 0xc6, 0x69, 0x0f,
+	0x7d, 0x7a, 0xc7, 0x2e, 0x09, 0xa0, 0xa3, 0x44, 0x3a, 0xfd, 0x26, 0x8a, 0xf9, 0x49, 0x61, 0x63,
+	0x85, 0x33, 0x77, 0x34, 0xb8, 0x74, 0xc7, 0x2a, 0x2d, 0x31, 0xe4, 0x2b, 0x68, 0xc4, 0x0b, 0x19,
+	0x4d, 0x14, 0xe6, 0xae, 0xbd, 0xd7, 0xb3, 0xf7, 0x28, 0xb9, 0xb7, 0x6b, 0x16, 0x47, 0x28, 0x73,
+	0x14, 0xa5, 0x9c, 0x3a, 0x79, 0xf2, 0x35, 0x34, 0x13, 0xae, 0x19, 0x76, 0x90, 0xc9, 0x66, 0x7b,
+	0x6f, 0xfb, 0xf6, 0xb3, 0xc7, 0x4e, 0x8a, 0x2e, 0xe5, 0x4d, 0x54, 0xe2, 0x68, 0xce, 0xfd, 0x46,
+	0xcf, 0xdb, 0x69, 0x52, 0x5c, 0xe7, 0x9e, 0x9e, 0x5e, 0x5e, 0x2a, 0xae, 0xfd, 0xb5, 0xc2, 0x53,
+	0xcb, 0x90, 0x07, 0x50, 0x57, 0x19, 0xe7, 0xa1, 0xdf, 0xc4, 0x34, 0x5b, 0x60, 0x62, 0x70, 0x19,
+	0xc5, 0x7c, 0x28, 0x54, 0xa4, 0x23, 0x91, 0xfa, 0x2d, 0x3c, 0xb7, 0xc2, 0x61, 0x7d, 0x8b, 0x39,
+	0x97, 0x87, 0xa1, 0x0f, 0x18, 0xa2, 0x1c, 0x6e, 0x3d, 0x87, 0x8d, 0xe2, 0x76, 0x7d, 0x2d, 0x12,
+	0xd3, 0x29, 0x3a, 0x4a, 0xb8, 0xd2, 0x2c, 0xc9, 0xf2, 0x4e, 0x59, 0x12, 0xa8, 0x89, 0x65, 0x68,
+	0xa8, 0xe2, 0x34, 0x59, 0xb8, 0xaa, 0xc9, 0xc4, 0x89, 0x3c, 0x85, 0x3a, 0xd3, 0x22, 0x51, 0xbe,
+	0xf7, 0xff, 0x81, 0x35, 0xa6, 0xa9, 0x15, 0xdf, 0x7a, 0x5d, 0x81, 0x4e, 0x39, 0x6c, 0xe6, 0xe2,
+	0x67, 0x91, 0x8e, 0xf3, 0xd9, 0x65, 0x81, 0x29, 0xfb, 0xbe, 0xd4, 0x91, 0xd2, 0xce, 0x13, 0x87,
+	0x8c, 0x74, 0x3f, 0x1e, 0xcf, 0x12, 0xcc, 0x75, 0x8b, 0x5a, 0x60, 0xd8, 0x6f, 0x79, 0x2a, 0xb9,
+	0x5f, 0xb3, 0x2c, 0x02, 0xd3, 0x22, 0xb8, 0xed, 0x14, 0xd5, 0x71, 0xaf, 0x4c, 0x91, 0x2d, 0x68,
+	0xee, 0x8b, 0x24, 0x13, 0x8a, 0x4b, 0x4c, 0x56, 0x8b, 0x2e, 0xb1, 0x09, 0xc6, 0xbe, 0x48, 0x12,
+	0x9e, 0xda, 0x6c, 0xb5, 0x68, 0x0e, 0x4d, 0x7a, 0x7f, 0xe4, 0x4c, 0x62, 0xa6, 0xea, 0x14, 0xd7,
+	0x78, 0x0b, 0xc9, 0x26, 0xd7, 0x98, 0xa1, 0x3a, 0xb5, 0xc0, 0x24, 0x1d, 0x17, 0x67, 0x42, 0xb3,
+	0x18, 0xb3, 0x53, 0xa7, 0x25, 0xc6, 0x68, 0x3a, 0x88, 0xd4, 0xc4, 0x6f, 0x5b, 0x4d, 0x66, 0x6d,
+	0x52, 0x64, 0x7e, 0xed, 0x91, 0x0e, 0x6e, 0x14, 0x84, 0xf1, 0xf8, 0x60, 0x26, 0x19, 0xe6, 0x68,
+	0x1d, 0xf3, 0xb7, 0xc4, 0x81, 0x82, 0x16, 0x8e, 0x45, 0xec, 0xba, 0xff, 0x9e, 0x89, 0xb7, 0xcd,
+	0xac, 0xca, 0xed, 0x33, 0xcb, 0x68, 0xc2, 0x81, 0x39, 0x8a, 0x7e, 0xe1, 0x6e, 0x8c, 0x14, 0x44,
+	0x30, 0x82, 0xd6, 0x90, 0xcd, 0x14, 0x47, 0xa3, 0x3e, 0xac, 0x65, 0x31, 0x5b, 0x44, 0xe9, 0x14,
+	0x4d, 0x36, 0x69, 0x0e, 0xc9, 0x63, 0xb8, 0xa7, 0xc5, 0x74, 0x1a, 0xf3, 0xb7, 0x2d, 0xbe, 0xbd,
+	0x11, 0xfc, 0xe5, 0xc1, 0xfa, 0x88, 0xeb, 0x83, 0xd1, 0x30, 0x9f, 0x48, 0x5f, 0x40, 0x7d, 0xcc,
+	0xd2, 0x30, 0x2f, 0xb7, 0x2d, 0x5b, 0x6e, 0x2b, 0x32, 0xbb, 0x83, 0x97, 0xcf, 0x58, 0x1a, 0x52,
+	0x2b, 0x68, 0xdc, 0x1e, 0x33, 0xa5, 0x9e, 0x09, 0xe1, 0x8a, 0xc8, 0xa3, 0x05, 0x61, 0xf2, 0x75,
+	0x13, 0x85, 0x6e, 0x66, 0x78, 0xd4, 0x02, 0xe3, 0x7f, 0x1c, 0x25, 0x91, 0xe6, 0x12, 0x2b, 0xa9,
+	0x49, 0x73, 0xb8, 0xf5, 0x1c, 0x1a, 0x56, 0xbd, 0xd1, 0x7b, 0x29, 0x8d, 0xc5, 0x74, 0xb2, 0x70,
+	0x33, 0xb9, 0x20, 0x4c, 0x46, 0xa7, 0x2c, 0x4a, 0x9d, 0x41, 0x5c, 0x93, 0x0e, 0x78, 0xaf, 0x9c,
+	0x1d, 0xef, 0x55, 0xf0, 0x10, 0xda, 0x2f, 0x98, 0x64, 0xe2, 0x7a, 0x19, 0x32, 0x9e, 0xb2, 0x71,
+	0xcc, 0xc3, 0x3c, 0x64, 0x0e, 0x06, 0x7f, 0x78, 0x70, 0xff, 0x3c, 0x8b, 0x05, 0x0b, 0x6d, 0x17,
+	0xe5, 0xa1, 0x78, 0x73, 0x2e, 0x7a, 0xb7, 0xcc, 0xc5, 0x62, 0xee, 0x55, 0x3e, 0x70, 0xee, 0x11,
+	0xa8, 0xcd, 0x4c, 0x3b, 0xd8, 0xfe, 0xc2, 0xb5, 0x29, 0xba, 0x8c, 0x29, 0x75, 0x23, 0x64, 0xe8,
+	0x3a, 0x6c, 0x89, 0x83, 0x21, 0x3c, 0x58, 0x75, 0xd2, 0x7d, 0x21, 0xbc, 0x8f, 0x97, 0x0f, 0xa0,
+	0xce, 0xa5, 0x14, 0xd2, 0xf5, 0xb8, 0x05, 0xc1, 0xdf, 0x1e, 0x6c, 0xec, 0x8b, 0x54, 0x4b, 0xb1,
+	0x7c, 0x8f, 0x72, 0xa7, 0xbc, 0x77, 0x38, 0x55, 0x59, 0x75, 0x8a, 0x3c, 0x81, 0x06, 0x9b, 0x60,
+	0x8f, 0x54, 0xf1, 0xf9, 0xfa, 0xd8, 0x3d, 0x5f, 0x2b, 0x5a, 0x77, 0xfb, 0x28, 0x42, 0x9d, 0xa8,
+	0xf1, 0x66, 0xce, 0xe2, 0x99, 0x1d, 0x22, 0x1e, 0xb5, 0x20, 0x60, 0xd0, 0xb0, 0x72, 0xa4, 0x05,
+	0xf5, 0x61, 0xff, 0x7c, 0x34, 0xe8, 0xde, 0x21, 0x00, 0x0d, 0x3a, 0x18, 0x9d, 0x1f, 0x0f, 0xba,
+	0x1e, 0x69, 0x42, 0xed, 0x64, 0xf0, 0xc3, 0x59, 0xb7, 0x42, 0x3a, 0xd0, 0x1c, 0xd2, 0xc1, 0xc5,
+	0xe1, 0xe9, 0xf9, 0xa8, 0x5b, 0x25, 0xeb, 0xd0, 0xba, 0x38, 0x3d, 0x3a, 0x3f, 0x1e, 0xfc, 0x74,
+	0x3e, 0xec, 0xd6, 0xc8, 0x5d, 0x68, 0x3b, 0x78, 0x70, 0xfa, 0xfd, 0x49, 0xb7, 0x6e, 0xce, 0x8d,
+	0x06, 0x83, 0x17, 0xdd, 0x46, 0xa0, 0xe1, 0xee, 0xd2, 0x33, 0x17, 0xbd, 0xe2, 0x02, 0xde, 0xfb,
+	0x5f, 0x60, 0x13, 0x1a, 0x92, 0xab, 0x59, 0xbc, 0x9c, 0x99, 0x16, 0x15, 0x61, 0xae, 0x96, 0xc3,
+	0xfc, 0xba, 0x0a, 0x9d, 0x61, 0xcc, 0x16, 0x71, 0xa4, 0x74, 0x5e, 0x89, 0x73, 0x2e, 0x55, 0x6e,
+	0xb4, 0x46, 0x73, 0x68, 0x42, 0xad, 0x52, 0x96, 0xa9, 0x2b, 0x61, 0x55, 0x37, 0xe9, 0x12, 0x9b,
+	0x21, 0x3b, 0x66, 0x8a, 0x5f, 0xb8, 0x93, 0x76, 0x3e, 0x94, 0x29, 0x23, 0x61, 0x3e, 0x17, 0xa6,
+	0xae, 0xe9, 0x6b, 0x38, 0xd2, 0xca, 0x14, 0xf9, 0x14, 0xd6, 0x2d, 0xa4, 0x3c, 0x11, 0x73, 0x1e,
+	0xe2, 0xa8, 0xae, 0xd3, 0x55, 0x92, 0xec, 0x99, 0x4e, 0xd1, 0x32, 0xe2, 0xca, 0x6f, 0x60, 0x51,
+	0xfb, 0x36, 0x28, 0xe5, 0x4b, 0xec, 0x0e, 0x52, 0x2d, 0x17, 0x34, 0x17, 0xc4, 0x22, 0xc9, 0xdf,
+	0xce, 0x35, 0x54, 0xba, 0xc4, 0xe5, 0x61, 0xd5, 0x5c, 0x1d, 0x56, 0xdb, 0x00, 0x6c, 0xa6, 0xc5,
+	0xc1, 0x77, 0xc7, 0x22, 0xe4, 0x38, 0xd1, 0x5b, 0xb4, 0xc4, 0x98, 0x80, 0x66, 0x4c, 0xea, 0x05,
+	0x4e, 0xf4, 0x26, 0xb5, 0x60, 0xeb, 0x37, 0x0f, 0xea, 0x68, 0xfe, 0xbd, 0x6a, 0xbf, 0xfc, 0x7d,
+	0x51, 0xf9, 0xc0, 0xef, 0x0b, 0xe7, 0x1f, 0x7e, 0x08, 0x87, 0x18, 0xf2, 0x26, 0x2d, 0x31, 0xc1,
+	0x63, 0xf3, 0xac, 0xcd, 0xb9, 0xec, 0x4b, 0x4d, 0x36, 0xa0, 0x12, 0x85, 0xce, 0x83, 0x4a, 0x14,
+	0x92, 0x2e, 0x54, 0xb3, 0x74, 0xea, 0xfe, 0x14, 0x98, 0x65, 0xf0, 0xab, 0x07, 0x9d, 0x51, 0xc6,
+	0x27, 0x5a, 0xce, 0x12, 0x2c, 0x84, 0xdb, 0x1e, 0x07, 0xef, 0xdd, 0x8f, 0x83, 0x8a, 0xa3, 0x09,
+	0xc7, 0xc7, 0xc1, 0xce, 0xf3, 0x82, 0x30, 0x81, 0xb2, 0x53, 0xdb, 0xf8, 0xb8, 0x9e, 0x4f, 0xe6,
+	0x4d, 0x68, 0xc4, 0x7c, 0xce, 0x63, 0x85, 0xb5, 0xd0, 0xa1, 0x0e, 0x3d, 0xda, 0x86, 0x35, 0xf7,
+	0x81, 0x69, 0x7a, 0xad, 0x7f, 0x7e, 0x70, 0x78, 0xda, 0xbd, 0x63, 0xfa, 0xe4, 0x78, 0x70, 0xd6,
+	0xef, 0x7a, 0xe3, 0x06, 0xfe, 0xfb, 0x79, 0xf2, 0xef, 0x00, 0x23, 0x40, 0x71, 0x7c, 0x14, 0x0d,
+	0x00, 0x00,
 }
@@ -0,0 +1,292 @@
+package comm
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// webSocketGUID is appended to the key of WebSocket handshakes to compute the accept header (RFC 6455, 1.3)
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxWebSocketFrameSize is the size of the largest frame accepted. Clients only send small messages like time sync
+// requests, so this is well above the largest envelope received.
+const maxWebSocketFrameSize = 1 << 20
+
+// WebSocket opcodes (RFC 6455, 5.2)
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xa
+)
+
+// webSocketAccept returns the Sec-WebSocket-Accept header answering the Sec-WebSocket-Key key
+func webSocketAccept(key string) string {
+	h := sha1.New()
+	io.WriteString(h, key+webSocketGUID)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// headerContains returns true, if one of the comma separated values of the header name of h is token
+func headerContains(h http.Header, name, token string) bool {
+	for _, value := range h[http.CanonicalHeaderKey(name)] {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// upgradeWebSocket completes the WebSocket handshake of r and returns the connection. If the handshake fails, an
+// error response is written to w.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (net.Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	switch {
+	case r.Method != http.MethodGet:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, fmt.Errorf("method %s not allowed", r.Method)
+	case !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket"):
+		http.Error(w, "not a websocket handshake", http.StatusBadRequest)
+		return nil, fmt.Errorf("not a websocket handshake")
+	case r.Header.Get("Sec-WebSocket-Version") != "13":
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusUpgradeRequired)
+		return nil, fmt.Errorf("unsupported websocket version '%s'", r.Header.Get("Sec-WebSocket-Version"))
+	case key == "":
+		http.Error(w, "missing websocket key", http.StatusBadRequest)
+		return nil, fmt.Errorf("missing websocket key")
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websockets are not supported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("failed to hijack connection: %v", err)
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + webSocketAccept(key) + "\r\n\r\n"
+	if _, err := rw.WriteString(response); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to write handshake response: %v", err)
+	}
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to write handshake response: %v", err)
+	}
+	return newWebSocketConn(conn, rw.Reader, false), nil
+}
+
+// webSocketConn is a net.Conn carrying its data in binary WebSocket messages. Each Write is sent as one message,
+// the payloads of received messages are read as one continuous stream.
+type webSocketConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	// client connections mask the frames they send, server connections expect masked frames
+	client bool
+
+	// payload is the unread rest of the payload of the current frame
+	payload    []byte
+	readMutex  sync.Mutex
+	writeMutex sync.Mutex
+	closeOnce  sync.Once
+}
+
+func newWebSocketConn(conn net.Conn, r *bufio.Reader, client bool) *webSocketConn {
+	return &webSocketConn{conn: conn, r: r, client: client}
+}
+
+// readFrame reads the next frame and returns its opcode and unmasked payload. Fragmented messages need no special
+// handling, as the payloads are read as one stream anyway.
+func (c *webSocketConn) readFrame() (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := head[0] & 0x0f
+	masked := head[1]&0x80 != 0
+	if masked == c.client {
+		return 0, nil, fmt.Errorf("received frame with invalid masking")
+	}
+
+	size := uint64(head[1] & 0x7f)
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		size = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(ext[:])
+	}
+	if maxWebSocketFrameSize < size {
+		return 0, nil, fmt.Errorf("frame of %d bytes exceeds the maximum of %d bytes", size, maxWebSocketFrameSize)
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.r, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+	// the buffer grows with the data received, not with the size announced in the header
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, c.r, int64(size)); err != nil {
+		if err == io.EOF && 0 < buf.Len() {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, nil, err
+	}
+	payload := buf.Bytes()
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return opcode, payload, nil
+}
+
+// writeFrame writes a final frame with opcode and payload, masking it on client connections
+func (c *webSocketConn) writeFrame(opcode byte, payload []byte) error {
+	frame := make([]byte, 0, len(payload)+14)
+	frame = append(frame, 0x80|opcode)
+
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch {
+	case len(payload) < 126:
+		frame = append(frame, maskBit|byte(len(payload)))
+	case len(payload) <= 0xffff:
+		frame = append(frame, maskBit|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(len(payload)))
+	default:
+		frame = append(frame, maskBit|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(len(payload)))
+	}
+
+	if c.client {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return fmt.Errorf("failed to generate frame mask: %v", err)
+		}
+		frame = append(frame, mask[:]...)
+		start := len(frame)
+		frame = append(frame, payload...)
+		for i := range frame[start:] {
+			frame[start+i] ^= mask[i%4]
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// Read reads the payload of the binary messages received, answering pings and closes on the way
+func (c *webSocketConn) Read(b []byte) (int, error) {
+	c.readMutex.Lock()
+	defer c.readMutex.Unlock()
+
+	for len(c.payload) == 0 {
+		opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, err
+		}
+		switch opcode {
+		case wsOpBinary, wsOpContinuation:
+			c.payload = payload
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return 0, err
+			}
+		case wsOpPong:
+		case wsOpClose:
+			c.Close()
+			return 0, io.EOF
+		case wsOpText:
+			c.Close()
+			return 0, fmt.Errorf("text messages are not supported")
+		default:
+			c.Close()
+			return 0, fmt.Errorf("unknown opcode %d", opcode)
+		}
+	}
+
+	n := copy(b, c.payload)
+	c.payload = c.payload[n:]
+	return n, nil
+}
+
+// Write sends b as one binary message
+func (c *webSocketConn) Write(b []byte) (int, error) {
+	if err := c.writeFrame(wsOpBinary, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Close sends a close frame and closes the underlying connection
+func (c *webSocketConn) Close() error {
+	err := error(nil)
+	c.closeOnce.Do(func() {
+		c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+		c.writeFrame(wsOpClose, nil)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+func (c *webSocketConn) LocalAddr() net.Addr                { return c.conn.LocalAddr() }
+func (c *webSocketConn) RemoteAddr() net.Addr               { return c.conn.RemoteAddr() }
+func (c *webSocketConn) SetDeadline(t time.Time) error      { return c.conn.SetDeadline(t) }
+func (c *webSocketConn) SetReadDeadline(t time.Time) error  { return c.conn.SetReadDeadline(t) }
+func (c *webSocketConn) SetWriteDeadline(t time.Time) error { return c.conn.SetWriteDeadline(t) }
+
+// webSocketServer serves the clients connecting through WebSockets. It is set by StartServer.
+var webSocketServer func(conn net.Conn)
+
+// HandleWebSocket upgrades r to a WebSocket connection and serves it like a tcp connection of the server started
+// by StartServer. Each binary message carries one message as sent over tcp.
+func HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	if webSocketServer == nil {
+		http.Error(w, "the server is not running", http.StatusServiceUnavailable)
+		return
+	}
+	conn, err := upgradeWebSocket(w, r)
+	if err != nil {
+		logger.Infof("websocket handshake with %s failed: %v", r.RemoteAddr, err)
+		return
+	}
+	logger.Infof("websocket client connected from %s", r.RemoteAddr)
+	webSocketServer(conn)
+}
@@ -0,0 +1,236 @@
+package comm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dialWebSocket connects to the WebSocket at the http url of server, like a browser would
+func dialWebSocket(server string) (net.Conn, error) {
+	u := strings.TrimPrefix(server, "http://")
+	conn, err := net.Dial("tcp", u)
+	if err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString([]byte("music-sync-tests"))
+	fmt.Fprintf(conn, "GET /ws HTTP/1.1\r\nHost: %s\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: %s\r\nSec-WebSocket-Version: 13\r\n\r\n", u, key)
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != webSocketAccept(key) {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected accept header '%s'", accept)
+	}
+	return newWebSocketConn(conn, r, true), nil
+}
+
+func TestWebSocketAccept(t *testing.T) {
+	// the example of RFC 6455
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", webSocketAccept("dGhlIHNhbXBsZSBub25jZQ=="),
+		"webSocketAccept returned the wrong accept header")
+}
+
+func TestWebSocketConn(t *testing.T) {
+	serverPipe, clientPipe := net.Pipe()
+	server := newWebSocketConn(serverPipe, bufio.NewReader(serverPipe), false)
+	client := newWebSocketConn(clientPipe, bufio.NewReader(clientPipe), true)
+	// closing the pipes directly, as closing the connections would wait for the close frame to be read
+	defer serverPipe.Close()
+	defer clientPipe.Close()
+
+	for _, size := range []int{1, 125, 126, 200, 0xffff, 0x10000, 100000} {
+		data := bytes.Repeat([]byte{byte(size)}, size)
+		for _, dir := range []struct {
+			name     string
+			from, to net.Conn
+		}{{"client to server", client, server}, {"server to client", server, client}} {
+			errs := make(chan error, 1)
+			go func() {
+				_, err := dir.from.Write(data)
+				errs <- err
+			}()
+			received := make([]byte, size)
+			_, err := io.ReadFull(dir.to, received)
+			assert.NoError(t, err, "reading %d bytes sent %s returned an error", size, dir.name)
+			assert.NoError(t, <-errs, "writing %d bytes %s returned an error", size, dir.name)
+			assert.Equal(t, data, received, "%d bytes sent %s were not received", size, dir.name)
+		}
+	}
+}
+
+func TestWebSocketConn_Ping(t *testing.T) {
+	serverPipe, clientPipe := net.Pipe()
+	server := newWebSocketConn(serverPipe, bufio.NewReader(serverPipe), false)
+	client := newWebSocketConn(clientPipe, bufio.NewReader(clientPipe), true)
+	// closing the pipes directly, as closing the connections would wait for the close frame to be read
+	defer serverPipe.Close()
+	defer clientPipe.Close()
+
+	go func() {
+		client.writeFrame(wsOpPing, []byte("ping"))
+		client.Write([]byte("data"))
+	}()
+	go io.ReadFull(server, make([]byte, 4))
+
+	opcode, payload, err := client.readFrame()
+	if assert.NoError(t, err, "reading the answer to a ping returned an error") {
+		assert.Equal(t, byte(wsOpPong), opcode, "the server did not answer the ping with a pong")
+		assert.Equal(t, []byte("ping"), payload, "the pong did not carry the payload of the ping")
+	}
+}
+
+func TestWebSocketConn_FrameSize(t *testing.T) {
+	serverPipe, clientPipe := net.Pipe()
+	server := newWebSocketConn(serverPipe, bufio.NewReader(serverPipe), false)
+	defer serverPipe.Close()
+	defer clientPipe.Close()
+
+	// a masked binary frame announcing 64 MiB, without sending them
+	header := []byte{0x80 | wsOpBinary, 0x80 | 127, 0, 0, 0, 0, 0x04, 0, 0, 0, 1, 2, 3, 4}
+	go clientPipe.Write(header)
+	_, err := server.Read(make([]byte, 1))
+	assert.Error(t, err, "reading a frame larger than the maximum frame size did not return an error")
+
+	// a frame of the maximum size, which is cut off
+	serverPipe2, clientPipe2 := net.Pipe()
+	server = newWebSocketConn(serverPipe2, bufio.NewReader(serverPipe2), false)
+	defer serverPipe2.Close()
+	header = []byte{0x80 | wsOpBinary, 0x80 | 127, 0, 0, 0, 0, 0, 0x10, 0, 0, 1, 2, 3, 4}
+	go func() {
+		clientPipe2.Write(append(header, make([]byte, 1000)...))
+		clientPipe2.Close()
+	}()
+	_, err = server.Read(make([]byte, 1))
+	assert.Equal(t, io.ErrUnexpectedEOF, err, "reading a cut off frame did not return io.ErrUnexpectedEOF")
+}
+
+func TestWebSocketConn_Close(t *testing.T) {
+	serverPipe, clientPipe := net.Pipe()
+	server := newWebSocketConn(serverPipe, bufio.NewReader(serverPipe), false)
+	client := newWebSocketConn(clientPipe, bufio.NewReader(clientPipe), true)
+	defer serverPipe.Close()
+
+	go server.Close()
+	_, err := client.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err, "reading from a connection closed by the other end did not return io.EOF")
+}
+
+func TestUpgradeWebSocket(t *testing.T) {
+	handshake := map[string]string{
+		"Upgrade":               "websocket",
+		"Connection":            "keep-alive, Upgrade",
+		"Sec-WebSocket-Key":     "dGhlIHNhbXBsZSBub25jZQ==",
+		"Sec-WebSocket-Version": "13",
+	}
+	for _, c := range []struct {
+		method  string
+		without string
+		version string
+		status  int
+	}{
+		{method: http.MethodPost, status: http.StatusMethodNotAllowed},
+		{method: http.MethodGet, without: "Upgrade", status: http.StatusBadRequest},
+		{method: http.MethodGet, without: "Connection", status: http.StatusBadRequest},
+		{method: http.MethodGet, without: "Sec-WebSocket-Key", status: http.StatusBadRequest},
+		{method: http.MethodGet, version: "8", status: http.StatusUpgradeRequired},
+	} {
+		r := httptest.NewRequest(c.method, "/ws", nil)
+		for name, value := range handshake {
+			if name != c.without {
+				r.Header.Set(name, value)
+			}
+		}
+		if c.version != "" {
+			r.Header.Set("Sec-WebSocket-Version", c.version)
+		}
+		w := httptest.NewRecorder()
+		conn, err := upgradeWebSocket(w, r)
+		assert.Error(t, err, "upgrading a %s request without %s (version %s) did not return an error", c.method, c.without, c.version)
+		assert.Nil(t, conn, "upgrading a %s request without %s (version %s) returned a connection", c.method, c.without, c.version)
+		assert.Equal(t, c.status, w.Code, "upgrading a %s request without %s (version %s) responded with the wrong status", c.method, c.without, c.version)
+	}
+}
+
+func TestHandleWebSocket(t *testing.T) {
+	log.DefaultCutoffLevel = log.LevelOff
+	defer func() {
+		webSocketServer = nil
+		NewClientHandler = nil
+		NamedClientHandler = nil
+	}()
+
+	mms := &multiMessageSender{connections: make([]net.Conn, 0), channels: make(map[net.Conn][]Channel)}
+	h := newServerPackageHandler(mms)
+	webSocketServer = func(conn net.Conn) { serveClient(mms, h, conn) }
+	subscribed := make(chan Channel, 2)
+	named := make(chan string, 1)
+	NewClientHandler = func(channel Channel, _ MessageSender) { subscribed <- channel }
+	NamedClientHandler = func(name string, _ MessageSender) { named <- name }
+
+	server := httptest.NewServer(http.HandlerFunc(HandleWebSocket))
+	defer server.Close()
+
+	conn, err := dialWebSocket(server.URL)
+	require.NoError(t, err, "connecting to the websocket failed")
+	defer conn.Close()
+	assert.Equal(t, Channel(-1), <-subscribed, "connecting did not call NewClientHandler")
+
+	require.NoError(t, sendWire(&TimeSyncRequest{ClientSend: 42}, conn), "sending a time sync request failed")
+	m, err := readWire(conn)
+	if assert.NoError(t, err, "reading the time sync response failed") {
+		tsr, ok := m.(*TimeSyncResponse)
+		if assert.True(t, ok, "the server did not answer the time sync request with a time sync response, but %T", m) {
+			assert.Equal(t, int64(42), tsr.ClientSendTime, "the time sync response has the wrong client send time")
+		}
+	}
+
+	require.NoError(t, sendWire(&SubscribeChannelRequest{Channel: Channel_AUDIO, Name: "browser"}, conn), "subscribing failed")
+	assert.Equal(t, Channel_AUDIO, <-subscribed, "subscribing did not call NewClientHandler with the channel")
+	assert.Equal(t, "browser", <-named, "subscribing did not call NamedClientHandler with the name")
+
+	chunk := &QueueChunkRequest{StartTime: 1, ChunkId: 2, SampleLow: []float64{.1, .2}, SampleHigh: []float64{.3, .4}, FirstSampleIndex: 3}
+	require.NoError(t, mms.SendMessage(&PauseInfo{Playing: true}), "broadcasting a meta message failed")
+	require.NoError(t, mms.SendMessage(chunk), "broadcasting a chunk failed")
+	m, err = readWire(conn)
+	if assert.NoError(t, err, "reading the chunk failed") {
+		assert.Equal(t, chunk, m, "the client did not receive the chunk (and only the chunk)")
+	}
+
+	conn.Close()
+	assert.Eventually(t, func() bool {
+		mms.mutex.RLock()
+		defer mms.mutex.RUnlock()
+		return len(mms.connections) == 0 && len(mms.channels) == 0
+	}, time.Second, 10*time.Millisecond, "closing the websocket did not remove the connection")
+}
+
+func TestHandleWebSocket_NotRunning(t *testing.T) {
+	log.DefaultCutoffLevel = log.LevelOff
+	webSocketServer = nil
+	server := httptest.NewServer(http.HandlerFunc(HandleWebSocket))
+	defer server.Close()
+
+	_, err := dialWebSocket(server.URL)
+	assert.Error(t, err, "connecting to the websocket of a server, which is not running, did not fail")
+}
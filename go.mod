@@ -1,6 +1,6 @@
 module github.com/LogicalOverflow/music-sync
 
-go 1.14
+go 1.16
 
 require (
 	github.com/anmitsu/go-shlex v0.0.0-20200514113438-38f4b401e2be // indirect
@@ -0,0 +1,11 @@
+REM GENRE Live
+PERFORMER "test-performer"
+TITLE "test-album"
+FILE "test-song.mp3" MP3
+  TRACK 01 AUDIO
+    TITLE "first track"
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    TITLE "second track"
+    PERFORMER "guest-performer"
+    INDEX 01 00:01:00
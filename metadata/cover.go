@@ -0,0 +1,147 @@
+package metadata
+
+import (
+	"bytes"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/util"
+	"github.com/dhowden/tag"
+	"image"
+	"image/color"
+	"image/draw"
+	// register the formats of cover images
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+)
+
+// coverFileNames are the names of cover images next to the songs, in the order they are looked for
+var coverFileNames = []string{"cover.jpg", "cover.jpeg", "cover.png", "folder.jpg", "folder.jpeg", "folder.png",
+	"Cover.jpg", "Cover.png", "Folder.jpg", "Folder.png"}
+
+// CoverProvider is used to get the cover art of songs
+type CoverProvider interface {
+	// CollectCover returns the cover of song or nil, if it has none
+	CollectCover(song string) image.Image
+}
+
+// GetCoverProvider returns a CoverProvider, which uses the picture embedded in the song and falls back to a cover
+// image in the directory of the song
+func GetCoverProvider() CoverProvider {
+	return basicCoverProvider{}
+}
+
+type basicCoverProvider struct{}
+
+// coverSongFile returns the audio file of song relative to the AudioDir. For cue tracks, it is the file of the track.
+func coverSongFile(song string) (string, bool) {
+	if playback.IsURL(song) || playback.IsLiveSource(song) {
+		return "", false
+	}
+	if cue, _, ok := playback.ParseCueTrackSong(song); ok {
+		sheet, i, err := playback.LookupCueTrack(song)
+		if err != nil {
+			return "", false
+		}
+		return filepath.Join(filepath.Dir(cue), sheet.Tracks[i].File), true
+	}
+	return song, true
+}
+
+// embeddedCover returns the picture embedded in the tags of the file at path
+func embeddedCover(path string) image.Image {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	md, err := tag.ReadFrom(f)
+	if err != nil || md.Picture() == nil {
+		return nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(md.Picture().Data))
+	if err != nil {
+		return nil
+	}
+	return img
+}
+
+// directoryCover returns the first cover image found in dir
+func directoryCover(dir string) image.Image {
+	for _, name := range coverFileNames {
+		path := filepath.Join(dir, name)
+		if !util.IsFile(path) {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		img, _, err := image.Decode(f)
+		f.Close()
+		if err == nil {
+			return img
+		}
+	}
+	return nil
+}
+
+func (basicCoverProvider) CollectCover(song string) image.Image {
+	file, ok := coverSongFile(song)
+	if !ok {
+		return nil
+	}
+	path := filepath.Join(playback.AudioDir, file)
+	if !util.IsFile(path) {
+		return nil
+	}
+	if img := embeddedCover(path); img != nil {
+		return img
+	}
+	return directoryCover(filepath.Dir(path))
+}
+
+// ScaleCover scales img to fit into a square of size pixels, keeping its aspect ratio.
+// Each pixel of the result is the average of the pixels of img it covers. Smaller images are not enlarged.
+func ScaleCover(img image.Image, size int) *image.RGBA {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if size < w || size < h {
+		if h < w {
+			w, h = size, h*size/w
+		} else {
+			w, h = w*size/h, size
+		}
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := y*b.Dy()/h, (y+1)*b.Dy()/h
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < w; x++ {
+			x0, x1 := x*b.Dx()/w, (x+1)*b.Dx()/w
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var r, g, bl, a, n int
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := src.RGBAAt(sx, sy)
+					r, g, bl, a, n = r+int(c.R), g+int(c.G), bl+int(c.B), a+int(c.A), n+1
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(bl / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
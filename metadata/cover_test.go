@@ -0,0 +1,76 @@
+package metadata
+
+import (
+	"bytes"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testCoverPNG(c color.RGBA) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+func apicFrame(data []byte) []byte {
+	frame := append([]byte{id3EncodingISO8859}, "image/png"...)
+	frame = append(frame, 0, 3, 0)
+	return append(frame, data...)
+}
+
+func TestBasicCoverProvider_CollectCover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "music-sync-cover")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	playback.AudioDir = dir
+
+	red, blue := color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}
+	os.Mkdir(filepath.Join(dir, "album"), 0755)
+	os.Mkdir(filepath.Join(dir, "other"), 0755)
+	writeID3TestSong(t, filepath.Join(dir, "album"), "embedded.mp3", id3Tag(map[string][]byte{"APIC": apicFrame(testCoverPNG(red))}))
+	writeID3TestSong(t, filepath.Join(dir, "album"), "plain.mp3", id3Tag(map[string][]byte{"TIT2": append([]byte{id3EncodingUTF8}, "title"...)}))
+	ioutil.WriteFile(filepath.Join(dir, "album", "cover.png"), testCoverPNG(blue), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "other", "song.mp3"), []byte{}, 0644)
+
+	cp := GetCoverProvider()
+	if img := cp.CollectCover("album/embedded.mp3"); assert.NotNil(t, img, "CollectCover did not return the embedded cover") {
+		assert.Equal(t, red, color.RGBAModel.Convert(img.At(0, 0)), "CollectCover did not prefer the embedded cover")
+	}
+	if img := cp.CollectCover("album/plain.mp3"); assert.NotNil(t, img, "CollectCover did not return the directory cover") {
+		assert.Equal(t, blue, color.RGBAModel.Convert(img.At(0, 0)), "CollectCover returned the wrong directory cover")
+	}
+	assert.Nil(t, cp.CollectCover("other/song.mp3"), "CollectCover returned a cover for a song without a cover")
+	assert.Nil(t, cp.CollectCover("missing.mp3"), "CollectCover returned a cover for a missing song")
+	assert.Nil(t, cp.CollectCover("http://example.com/stream.mp3"), "CollectCover returned a cover for a stream")
+}
+
+func TestScaleCover(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		img.SetRGBA(x, 0, color.RGBA{R: uint8(100 * (x % 2)), A: 255})
+		img.SetRGBA(x, 1, color.RGBA{G: 200, A: 255})
+	}
+
+	scaled := ScaleCover(img, 2)
+	assert.Equal(t, image.Rect(0, 0, 2, 1), scaled.Bounds(), "ScaleCover did not keep the aspect ratio")
+	assert.Equal(t, color.RGBA{R: 25, G: 100, A: 255}, scaled.RGBAAt(0, 0), "ScaleCover did not average the pixels")
+
+	assert.Equal(t, image.Rect(0, 0, 4, 2), ScaleCover(img, 10).Bounds(), "ScaleCover enlarged a small image")
+	assert.Equal(t, image.Rect(0, 0, 1, 10), ScaleCover(image.NewRGBA(image.Rect(0, 0, 1, 100)), 10).Bounds(),
+		"ScaleCover scaled a narrow image to zero width")
+}
@@ -0,0 +1,146 @@
+package metadata
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	lrcTagPattern      = regexp.MustCompile(`^\[([^\]]*)\]`)
+	lrcTimePattern     = regexp.MustCompile(`^(\d+):(\d{1,2})(?:[.:](\d{1,3}))?$`)
+	lrcWordPattern     = regexp.MustCompile(`<(\d+:\d{1,2}(?:[.:]\d{1,3})?)>`)
+	srtTimingPattern   = regexp.MustCompile(`^(\d+):(\d{2}):(\d{2})[,.](\d{1,3})\s*-->`)
+	subtitleTagPattern = regexp.MustCompile(`</?[a-zA-Z][^>]*>|\{\\[^}]*\}`)
+)
+
+// parseFraction converts the fraction of a second (e.g. "5", "50" or "500") to milliseconds
+func parseFraction(fraction string) int64 {
+	if fraction == "" {
+		return 0
+	}
+	ms, _ := strconv.ParseInt((fraction + "00")[:3], 10, 64)
+	return ms
+}
+
+// parseLRCTime parses a LRC timestamp (mm:ss, mm:ss.xx or mm:ss.xxx) to milliseconds
+func parseLRCTime(timestamp string) (int64, bool) {
+	m := lrcTimePattern.FindStringSubmatch(strings.TrimSpace(timestamp))
+	if m == nil {
+		return 0, false
+	}
+	minutes, _ := strconv.ParseInt(m[1], 10, 64)
+	seconds, _ := strconv.ParseInt(m[2], 10, 64)
+	return (minutes*60+seconds)*1000 + parseFraction(m[3]), true
+}
+
+// parseLRCText splits the text of a LRC line into atoms. Enhanced LRC marks the start of words with <mm:ss.xx>,
+// text before the first word timestamp starts at the timestamp of the line. A positive offset shows the lyrics
+// earlier.
+func parseLRCText(text string, timestamp int64, offset int64) LyricsLine {
+	timestamp -= offset
+	line := make(LyricsLine, 0)
+	for {
+		loc := lrcWordPattern.FindStringSubmatchIndex(text)
+		if loc == nil {
+			break
+		}
+		if caption := text[:loc[0]]; caption != "" {
+			line = append(line, LyricsAtom{Timestamp: timestamp, Caption: caption})
+		}
+		timestamp, _ = parseLRCTime(text[loc[2]:loc[3]])
+		timestamp -= offset
+		text = text[loc[1]:]
+	}
+	if text != "" {
+		line = append(line, LyricsAtom{Timestamp: timestamp, Caption: text})
+	}
+	return line
+}
+
+// ParseLRC parses lyrics in the (enhanced) LRC format. Lines with multiple timestamps are repeated at each
+// timestamp, the offset tag is applied and lines without text are skipped.
+func ParseLRC(r io.Reader) ([]LyricsLine, error) {
+	type timedText struct {
+		timestamp int64
+		text      string
+	}
+	var (
+		texts  []timedText
+		offset int64
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		text := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+
+		var timestamps []int64
+		for {
+			m := lrcTagPattern.FindStringSubmatch(text)
+			if m == nil {
+				break
+			}
+			text = strings.TrimSpace(text[len(m[0]):])
+			if timestamp, ok := parseLRCTime(m[1]); ok {
+				timestamps = append(timestamps, timestamp)
+			} else if kv := strings.SplitN(m[1], ":", 2); len(kv) == 2 && strings.TrimSpace(kv[0]) == "offset" {
+				offset, _ = strconv.ParseInt(strings.TrimSpace(kv[1]), 10, 64)
+			}
+		}
+		if text == "" {
+			continue
+		}
+		for _, timestamp := range timestamps {
+			texts = append(texts, timedText{timestamp: timestamp, text: text})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(texts, func(i, j int) bool { return texts[i].timestamp < texts[j].timestamp })
+	lyrics := make([]LyricsLine, 0, len(texts))
+	for _, t := range texts {
+		if line := parseLRCText(t.text, t.timestamp, offset); 0 < len(line) {
+			lyrics = append(lyrics, line)
+		}
+	}
+	return lyrics, nil
+}
+
+// ParseSRT parses lyrics in the SRT subtitle format. Each text line of a subtitle becomes a line of lyrics
+// starting with the subtitle, formatting tags are removed.
+func ParseSRT(r io.Reader) ([]LyricsLine, error) {
+	lyrics := make([]LyricsLine, 0)
+
+	timestamp := int64(-1)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		text := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if text == "" {
+			timestamp = -1
+			continue
+		}
+		if m := srtTimingPattern.FindStringSubmatch(text); m != nil {
+			hours, _ := strconv.ParseInt(m[1], 10, 64)
+			minutes, _ := strconv.ParseInt(m[2], 10, 64)
+			seconds, _ := strconv.ParseInt(m[3], 10, 64)
+			timestamp = ((hours*60+minutes)*60+seconds)*1000 + parseFraction(m[4])
+			continue
+		}
+		if timestamp < 0 {
+			// subtitle index
+			continue
+		}
+		if text = strings.TrimSpace(subtitleTagPattern.ReplaceAllString(text, "")); text != "" {
+			lyrics = append(lyrics, LyricsLine{{Timestamp: timestamp, Caption: text}})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lyrics, nil
+}
@@ -0,0 +1,75 @@
+package metadata
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+var parseLRCTimeCases = []struct {
+	timestamp string
+	ms        int64
+	ok        bool
+}{
+	{timestamp: "00:10", ms: 10000, ok: true},
+	{timestamp: "01:02.5", ms: 62500, ok: true},
+	{timestamp: "01:02.50", ms: 62500, ok: true},
+	{timestamp: "01:02.505", ms: 62505, ok: true},
+	{timestamp: "01:02:05", ms: 62050, ok: true},
+	{timestamp: "123:00.00", ms: 7380000, ok: true},
+	{timestamp: "ar:artist", ok: false},
+	{timestamp: "", ok: false},
+}
+
+func TestParseLRCTime(t *testing.T) {
+	for _, c := range parseLRCTimeCases {
+		ms, ok := parseLRCTime(c.timestamp)
+		assert.Equal(t, c.ok, ok, "parseLRCTime returned the wrong ok for %q", c.timestamp)
+		if c.ok {
+			assert.Equal(t, c.ms, ms, "parseLRCTime returned the wrong time for %q", c.timestamp)
+		}
+	}
+}
+
+func TestParseLRC(t *testing.T) {
+	lrc := "\ufeff[ti:title]\n[00:01.00]first line\n[00:02.00]<00:02.00>word <00:02.50>by <00:03.00>word\n" +
+		"[00:05.00][00:04.00]twice\n[00:06.00]\n[00:07.00]lead <00:07.50>in\n"
+	lyrics, err := ParseLRC(strings.NewReader(lrc))
+	if assert.NoError(t, err, "ParseLRC returned an error") {
+		assert.Equal(t, []LyricsLine{
+			{{Timestamp: 1000, Caption: "first line"}},
+			{{Timestamp: 2000, Caption: "word "}, {Timestamp: 2500, Caption: "by "}, {Timestamp: 3000, Caption: "word"}},
+			{{Timestamp: 4000, Caption: "twice"}},
+			{{Timestamp: 5000, Caption: "twice"}},
+			{{Timestamp: 7000, Caption: "lead "}, {Timestamp: 7500, Caption: "in"}},
+		}, lyrics, "ParseLRC returned the wrong lyrics")
+	}
+
+	lyrics, err = ParseLRC(strings.NewReader("[offset:-250]\n[00:01.00]late\n"))
+	if assert.NoError(t, err, "ParseLRC returned an error") {
+		assert.Equal(t, []LyricsLine{{{Timestamp: 1250, Caption: "late"}}}, lyrics, "ParseLRC did not apply the offset")
+	}
+
+	lyrics, err = ParseLRC(strings.NewReader("no lyrics"))
+	if assert.NoError(t, err, "ParseLRC returned an error") {
+		assert.Empty(t, lyrics, "ParseLRC returned lyrics for a text without timestamps")
+	}
+}
+
+func TestParseSRT(t *testing.T) {
+	srt := "1\r\n00:00:01,500 --> 00:00:03,000\r\n<b>first</b> subtitle\r\n{\\an8}second line\r\n\r\n" +
+		"2\n01:00:02.25 --> 01:00:04.000\nlast subtitle\n"
+	lyrics, err := ParseSRT(strings.NewReader(srt))
+	if assert.NoError(t, err, "ParseSRT returned an error") {
+		assert.Equal(t, []LyricsLine{
+			{{Timestamp: 1500, Caption: "first subtitle"}},
+			{{Timestamp: 1500, Caption: "second line"}},
+			{{Timestamp: 3602250, Caption: "last subtitle"}},
+		}, lyrics, "ParseSRT returned the wrong lyrics")
+	}
+
+	lyrics, err = ParseSRT(strings.NewReader("1\nlost text\n"))
+	if assert.NoError(t, err, "ParseSRT returned an error") {
+		assert.Empty(t, lyrics, "ParseSRT returned text without timing")
+	}
+}
@@ -0,0 +1,210 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/util"
+	"github.com/dhowden/tag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	id3EncodingISO8859      = 0
+	id3EncodingUTF16WithBOM = 1
+	id3EncodingUTF16        = 2
+	id3EncodingUTF8         = 3
+
+	// syltMilliseconds is the timestamp format of SYLT frames with timestamps in milliseconds
+	syltMilliseconds = 2
+)
+
+var lrcLinePattern = regexp.MustCompile(`(?m)^\s*\[\d+:\d{1,2}([.:]\d{1,3})?\]`)
+
+// decodeID3Text decodes text of an ID3 frame in the encoding enc
+func decodeID3Text(enc byte, b []byte) string {
+	switch enc {
+	case id3EncodingUTF16WithBOM, id3EncodingUTF16:
+		var order binary.ByteOrder = binary.BigEndian
+		if 2 <= len(b) && b[0] == 0xff && b[1] == 0xfe {
+			order, b = binary.LittleEndian, b[2:]
+		} else if 2 <= len(b) && b[0] == 0xfe && b[1] == 0xff {
+			b = b[2:]
+		}
+		u := make([]uint16, len(b)/2)
+		for i := range u {
+			u[i] = order.Uint16(b[2*i:])
+		}
+		return string(utf16.Decode(u))
+	case id3EncodingUTF8:
+		return string(b)
+	default:
+		r := make([]rune, len(b))
+		for i, c := range b {
+			r[i] = rune(c)
+		}
+		return string(r)
+	}
+}
+
+// splitID3Text decodes the terminated text at the start of b and returns it and the bytes after its terminator
+func splitID3Text(enc byte, b []byte) (string, []byte, bool) {
+	if enc == id3EncodingUTF16WithBOM || enc == id3EncodingUTF16 {
+		for i := 0; i+1 < len(b); i += 2 {
+			if b[i] == 0 && b[i+1] == 0 {
+				return decodeID3Text(enc, b[:i]), b[i+2:], true
+			}
+		}
+		return "", nil, false
+	}
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return "", nil, false
+	}
+	return decodeID3Text(enc, b[:i]), b[i+1:], true
+}
+
+// parseSYLT parses the content of an ID3 SYLT (synchronised lyrics) frame. Entries starting with a line break
+// start a new line. If no entry starts with a line break, every entry is a line.
+func parseSYLT(b []byte) ([]LyricsLine, error) {
+	if len(b) < 6 {
+		return nil, fmt.Errorf("SYLT frame is too short")
+	}
+	enc := b[0]
+	if b[4] != syltMilliseconds {
+		return nil, fmt.Errorf("unsupported SYLT timestamp format %d", b[4])
+	}
+	_, rest, ok := splitID3Text(enc, b[6:])
+	if !ok {
+		return nil, fmt.Errorf("SYLT frame has no content descriptor")
+	}
+
+	var atoms []LyricsAtom
+	for {
+		var text string
+		if text, rest, ok = splitID3Text(enc, rest); !ok || len(rest) < 4 {
+			break
+		}
+		atoms = append(atoms, LyricsAtom{Timestamp: int64(binary.BigEndian.Uint32(rest)), Caption: text})
+		rest = rest[4:]
+	}
+
+	breaks := false
+	for i, a := range atoms {
+		if 0 < i && strings.TrimLeft(a.Caption, "\r\n") != a.Caption {
+			breaks = true
+		}
+	}
+
+	lyrics := make([]LyricsLine, 0)
+	for _, a := range atoms {
+		caption := strings.TrimLeft(a.Caption, "\r\n")
+		if !breaks || len(lyrics) == 0 || caption != a.Caption {
+			lyrics = append(lyrics, make(LyricsLine, 0))
+		}
+		if caption != "" {
+			lyrics[len(lyrics)-1] = append(lyrics[len(lyrics)-1], LyricsAtom{Timestamp: a.Timestamp, Caption: caption})
+		}
+	}
+	return removeEmptyLines(lyrics), nil
+}
+
+func removeEmptyLines(lyrics []LyricsLine) []LyricsLine {
+	result := make([]LyricsLine, 0, len(lyrics))
+	for _, l := range lyrics {
+		if 0 < len(l) {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+// parseUSLT parses the text of an ID3 USLT (unsynchronised lyrics) frame. Texts in the LRC format are parsed as
+// LRC, otherwise the lines are spread evenly over the duration of the song.
+func parseUSLT(text string, duration time.Duration) ([]LyricsLine, error) {
+	if lrcLinePattern.MatchString(text) {
+		return ParseLRC(strings.NewReader(text))
+	}
+
+	var texts []string
+	for _, l := range strings.Split(strings.Replace(text, "\r", "\n", -1), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			texts = append(texts, l)
+		}
+	}
+	lyrics := make([]LyricsLine, len(texts))
+	for i, l := range texts {
+		timestamp := int64(duration/time.Millisecond) * int64(i) / int64(len(texts))
+		lyrics[i] = LyricsLine{{Timestamp: timestamp, Caption: l}}
+	}
+	return lyrics, nil
+}
+
+// readID3Lyrics reads the lyrics from the SYLT or USLT frames of the ID3 tag of the file at path.
+// SYLT frames are preferred, the lines of USLT frames without timestamps are spread over duration.
+func readID3Lyrics(path string, duration func() time.Duration) ([]LyricsLine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	md, err := tag.ReadFrom(f)
+	if err != nil {
+		return nil, err
+	}
+	raw := md.Raw()
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if b, ok := raw[name].([]byte); ok && (strings.HasPrefix(name, "SYLT") || strings.HasPrefix(name, "SLT")) {
+			if lyrics, err := parseSYLT(b); err == nil && 0 < len(lyrics) {
+				return lyrics, nil
+			}
+		}
+	}
+	for _, name := range names {
+		if c, ok := raw[name].(*tag.Comm); ok && (strings.HasPrefix(name, "USLT") || strings.HasPrefix(name, "ULT")) {
+			if lyrics, err := parseUSLT(c.Text, duration()); err == nil && 0 < len(lyrics) {
+				return lyrics, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%s has no lyrics frames", path)
+}
+
+// songDuration returns a function returning the duration of song, or 0 if the song can not be decoded
+func songDuration(song string) func() time.Duration {
+	return func() time.Duration {
+		duration, _ := playback.SongDuration(song)
+		return duration
+	}
+}
+
+type id3LyricsProvider struct{}
+
+func (id3LyricsProvider) CollectLyrics(song string) []LyricsLine {
+	if _, _, ok := playback.ParseCueTrackSong(song); ok {
+		return []LyricsLine{}
+	}
+	path := filepath.Join(playback.AudioDir, song)
+	if !util.IsFile(path) {
+		return []LyricsLine{}
+	}
+	lyrics, err := readID3Lyrics(path, songDuration(song))
+	if err != nil {
+		return []LyricsLine{}
+	}
+	return lyrics
+}
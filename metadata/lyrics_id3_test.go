@@ -0,0 +1,157 @@
+package metadata
+
+import (
+	"encoding/binary"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func syltEntry(text string, timestamp uint32) []byte {
+	b := append([]byte(text), 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(b[len(text)+1:], timestamp)
+	return b
+}
+
+func syltFrame(format byte, entries ...[]byte) []byte {
+	b := []byte{id3EncodingUTF8, 'e', 'n', 'g', format, 1, 'd', 0}
+	for _, e := range entries {
+		b = append(b, e...)
+	}
+	return b
+}
+
+func usltFrame(text string) []byte {
+	return append([]byte{id3EncodingUTF8, 'e', 'n', 'g', 0}, text...)
+}
+
+// id3Tag returns an ID3v2.3 tag containing the frames
+func id3Tag(frames map[string][]byte) []byte {
+	ids := make([]string, 0, len(frames))
+	for id := range frames {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var body []byte
+	for _, id := range ids {
+		data := frames[id]
+		header := make([]byte, 10)
+		copy(header, id)
+		binary.BigEndian.PutUint32(header[4:], uint32(len(data)))
+		body = append(append(body, header...), data...)
+	}
+	size := len(body)
+	return append([]byte{'I', 'D', '3', 3, 0, 0,
+		byte(size >> 21 & 0x7f), byte(size >> 14 & 0x7f), byte(size >> 7 & 0x7f), byte(size & 0x7f)}, body...)
+}
+
+// writeID3TestSong writes the tag followed by the audio of test-song.mp3 to dir
+func writeID3TestSong(t *testing.T, dir, name string, tag []byte) {
+	song, err := ioutil.ReadFile(filepath.Join("_test_files", "test-song.mp3"))
+	if err != nil {
+		t.Fatalf("failed to read test-song.mp3: %v", err)
+	}
+	// skip the ID3 tag of test-song.mp3
+	tagSize := int(song[6])<<21 | int(song[7])<<14 | int(song[8])<<7 | int(song[9])
+	if err := ioutil.WriteFile(filepath.Join(dir, name), append(tag, song[10+tagSize:]...), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+func TestDecodeID3Text(t *testing.T) {
+	assert.Equal(t, "bär", decodeID3Text(id3EncodingISO8859, []byte{'b', 0xe4, 'r'}), "decodeID3Text did not decode ISO-8859-1")
+	assert.Equal(t, "bär", decodeID3Text(id3EncodingUTF8, []byte("bär")), "decodeID3Text did not decode UTF-8")
+	assert.Equal(t, "bär", decodeID3Text(id3EncodingUTF16WithBOM, []byte{0xff, 0xfe, 'b', 0, 0xe4, 0, 'r', 0}), "decodeID3Text did not decode UTF-16 LE")
+	assert.Equal(t, "bär", decodeID3Text(id3EncodingUTF16WithBOM, []byte{0xfe, 0xff, 0, 'b', 0, 0xe4, 0, 'r'}), "decodeID3Text did not decode UTF-16 BE")
+	assert.Equal(t, "bär", decodeID3Text(id3EncodingUTF16, []byte{0, 'b', 0, 0xe4, 0, 'r'}), "decodeID3Text did not decode UTF-16 without BOM")
+}
+
+func TestSplitID3Text(t *testing.T) {
+	text, rest, ok := splitID3Text(id3EncodingUTF8, []byte{'a', 'b', 0, 1, 2})
+	assert.True(t, ok, "splitID3Text did not find the terminator")
+	assert.Equal(t, "ab", text, "splitID3Text returned the wrong text")
+	assert.Equal(t, []byte{1, 2}, rest, "splitID3Text returned the wrong rest")
+
+	text, rest, ok = splitID3Text(id3EncodingUTF16, []byte{0, 'a', 0, 0, 1})
+	assert.True(t, ok, "splitID3Text did not find the UTF-16 terminator")
+	assert.Equal(t, "a", text, "splitID3Text returned the wrong UTF-16 text")
+	assert.Equal(t, []byte{1}, rest, "splitID3Text returned the wrong rest after UTF-16 text")
+
+	_, _, ok = splitID3Text(id3EncodingUTF8, []byte{'a'})
+	assert.False(t, ok, "splitID3Text found a terminator in unterminated text")
+}
+
+func TestParseSYLT(t *testing.T) {
+	lyrics, err := parseSYLT(syltFrame(syltMilliseconds, syltEntry("first ", 1000), syltEntry("line", 1500),
+		syltEntry("\nsecond ", 3000), syltEntry("line", 3500)))
+	if assert.NoError(t, err, "parseSYLT returned an error") {
+		assert.Equal(t, []LyricsLine{
+			{{Timestamp: 1000, Caption: "first "}, {Timestamp: 1500, Caption: "line"}},
+			{{Timestamp: 3000, Caption: "second "}, {Timestamp: 3500, Caption: "line"}},
+		}, lyrics, "parseSYLT did not break the lines at line breaks")
+	}
+
+	lyrics, err = parseSYLT(syltFrame(syltMilliseconds, syltEntry("first line", 1000), syltEntry("second line", 3000)))
+	if assert.NoError(t, err, "parseSYLT returned an error") {
+		assert.Equal(t, []LyricsLine{{{Timestamp: 1000, Caption: "first line"}}, {{Timestamp: 3000, Caption: "second line"}}},
+			lyrics, "parseSYLT did not put every entry in a line")
+	}
+
+	_, err = parseSYLT(syltFrame(1, syltEntry("frames", 1000)))
+	assert.Error(t, err, "parseSYLT did not return an error for MPEG frame timestamps")
+	_, err = parseSYLT([]byte{id3EncodingUTF8})
+	assert.Error(t, err, "parseSYLT did not return an error for a short frame")
+}
+
+func TestParseUSLT(t *testing.T) {
+	lyrics, err := parseUSLT("[00:01.00]first\n[00:02.00]second", time.Minute)
+	if assert.NoError(t, err, "parseUSLT returned an error") {
+		assert.Equal(t, []LyricsLine{{{Timestamp: 1000, Caption: "first"}}, {{Timestamp: 2000, Caption: "second"}}},
+			lyrics, "parseUSLT did not parse LRC text")
+	}
+
+	lyrics, err = parseUSLT("first\r\n\r\nsecond\nthird\n", 9*time.Second)
+	if assert.NoError(t, err, "parseUSLT returned an error") {
+		assert.Equal(t, []LyricsLine{
+			{{Timestamp: 0, Caption: "first"}},
+			{{Timestamp: 3000, Caption: "second"}},
+			{{Timestamp: 6000, Caption: "third"}},
+		}, lyrics, "parseUSLT did not spread the lines over the duration")
+	}
+}
+
+func TestID3LyricsProvider_CollectLyrics(t *testing.T) {
+	dir, err := ioutil.TempDir("", "music-sync-lyrics")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	playback.AudioDir = dir
+
+	writeID3TestSong(t, dir, "sylt.mp3", id3Tag(map[string][]byte{
+		"SYLT": syltFrame(syltMilliseconds, syltEntry("synced", 1000)),
+		"USLT": usltFrame("unsynced"),
+	}))
+	writeID3TestSong(t, dir, "uslt.mp3", id3Tag(map[string][]byte{"USLT": usltFrame("first\nsecond")}))
+	writeID3TestSong(t, dir, "none.mp3", id3Tag(map[string][]byte{"TIT2": append([]byte{id3EncodingUTF8}, "title"...)}))
+
+	lp := id3LyricsProvider{}
+	assert.Equal(t, []LyricsLine{{{Timestamp: 1000, Caption: "synced"}}}, lp.CollectLyrics("sylt.mp3"),
+		"CollectLyrics did not prefer the SYLT frame")
+
+	duration, err := playback.SongDuration("uslt.mp3")
+	if assert.NoError(t, err, "SongDuration returned an error") {
+		assert.NotZero(t, duration, "SongDuration returned 0")
+		assert.Equal(t, []LyricsLine{{{Timestamp: 0, Caption: "first"}}, {{Timestamp: int64(duration/time.Millisecond) / 2, Caption: "second"}}},
+			lp.CollectLyrics("uslt.mp3"), "CollectLyrics did not spread the USLT lines over the song")
+	}
+
+	assert.Empty(t, lp.CollectLyrics("none.mp3"), "CollectLyrics returned lyrics for a song without lyrics frames")
+	assert.Empty(t, lp.CollectLyrics("non-song.mp3"), "CollectLyrics returned lyrics for a non-song")
+	assert.Empty(t, lp.CollectLyrics("album.cue#01"), "CollectLyrics returned lyrics for a cue track")
+}
@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"github.com/LogicalOverflow/music-sync/playback"
 	"github.com/LogicalOverflow/music-sync/util"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // LyricsAtom describes a single word/element in the lyrics
@@ -22,9 +25,28 @@ type LyricsProvider interface {
 	CollectLyrics(song string) []LyricsLine
 }
 
-// GetLyricsProvider returns a new LyricsProvider
+// LyricsProviderChain is a LyricsProvider, which returns the lyrics of the first of its providers finding lyrics
+type LyricsProviderChain []LyricsProvider
+
+// CollectLyrics returns the first non-empty lyrics collected by the providers of the chain
+func (c LyricsProviderChain) CollectLyrics(song string) []LyricsLine {
+	for _, lp := range c {
+		if lyrics := lp.CollectLyrics(song); 0 < len(lyrics) {
+			return lyrics
+		}
+	}
+	return []LyricsLine{}
+}
+
+// GetLyricsProvider returns a new LyricsProvider, which reads the lyrics of a song from (in this order)
+// song.json, song.lrc, song.srt and the ID3 tag of the song
 func GetLyricsProvider() LyricsProvider {
-	return basicLyricsProvider{}
+	return LyricsProviderChain{
+		basicLyricsProvider{},
+		fileLyricsProvider{ext: ".lrc", parse: ParseLRC},
+		fileLyricsProvider{ext: ".srt", parse: ParseSRT},
+		id3LyricsProvider{},
+	}
 }
 
 type basicLyricsProvider struct{}
@@ -38,11 +60,119 @@ func (basicLyricsProvider) CollectLyrics(song string) []LyricsLine {
 	if err != nil {
 		return []LyricsLine{}
 	}
-	result := make([]LyricsLine, 0)
+	defer f.Close()
 
-	if err := json.NewDecoder(f).Decode(&result); err != nil {
+	lyrics, err := parseJSONLyrics(f)
+	if err != nil {
 		return []LyricsLine{}
 	}
+	return lyrics
+}
+
+func parseJSONLyrics(r io.Reader) ([]LyricsLine, error) {
+	result := make([]LyricsLine, 0)
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fileLyricsProvider reads lyrics from song.ext or, if that does not exist, the file with the extension of the
+// song replaced by ext (e.g. song.mp3.lrc or song.lrc)
+type fileLyricsProvider struct {
+	ext   string
+	parse func(io.Reader) ([]LyricsLine, error)
+}
+
+func (flp fileLyricsProvider) paths(song string) []string {
+	paths := []string{filepath.Join(playback.AudioDir, song+flp.ext)}
+	if _, _, ok := playback.ParseCueTrackSong(song); !ok {
+		if ext := filepath.Ext(song); ext != "" {
+			paths = append(paths, filepath.Join(playback.AudioDir, strings.TrimSuffix(song, ext)+flp.ext))
+		}
+	}
+	return paths
+}
+
+func (flp fileLyricsProvider) CollectLyrics(song string) []LyricsLine {
+	for _, path := range flp.paths(song) {
+		if !util.IsFile(path) {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		lyrics, err := flp.parse(f)
+		f.Close()
+		if err == nil && 0 < len(lyrics) {
+			return lyrics
+		}
+	}
+	return []LyricsLine{}
+}
+
+// ReadLyricsFile reads the lyrics from a file in the AudioDir. Files ending with .json, .lrc and .srt are parsed
+// in their format, the lyrics of other files are read from their ID3 tag.
+func ReadLyricsFile(filename string) ([]LyricsLine, error) {
+	path := filepath.Join(playback.AudioDir, filename)
+	parse := map[string]func(io.Reader) ([]LyricsLine, error){
+		".json": parseJSONLyrics,
+		".lrc":  ParseLRC,
+		".srt":  ParseSRT,
+	}[strings.ToLower(filepath.Ext(filename))]
+	if parse == nil {
+		return readID3Lyrics(path, songDuration(filename))
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parse(f)
+}
 
-	return result
+// SaveLyrics writes lyrics to the lyrics file of song (song.json) in the AudioDir
+func SaveLyrics(song string, lyrics []LyricsLine) error {
+	f, err := os.Create(filepath.Join(playback.AudioDir, song+".json"))
+	if err != nil {
+		return err
+	}
+	if err := WriteLyrics(f, lyrics); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteLyrics writes lyrics to w in the JSON format of lyrics files
+func WriteLyrics(w io.Writer, lyrics []LyricsLine) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(lyrics)
+}
+
+// ShiftLyrics returns a copy of lyrics with all timestamps moved by offset milliseconds
+func ShiftLyrics(lyrics []LyricsLine, offset int64) []LyricsLine {
+	shifted := make([]LyricsLine, len(lyrics))
+	for i, l := range lyrics {
+		shifted[i] = make(LyricsLine, len(l))
+		for j, a := range l {
+			shifted[i][j] = LyricsAtom{Timestamp: a.Timestamp + offset, Caption: a.Caption}
+		}
+	}
+	return shifted
+}
+
+// StretchLyrics returns a copy of lyrics with all timestamps divided by speed
+func StretchLyrics(lyrics []LyricsLine, speed float64) []LyricsLine {
+	stretched := make([]LyricsLine, len(lyrics))
+	for i, l := range lyrics {
+		stretched[i] = make(LyricsLine, len(l))
+		for j, a := range l {
+			stretched[i][j] = LyricsAtom{Timestamp: int64(math.Round(float64(a.Timestamp) / speed)), Caption: a.Caption}
+		}
+	}
+	return stretched
 }
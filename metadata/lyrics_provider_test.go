@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"bytes"
 	"github.com/LogicalOverflow/music-sync/playback"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -22,3 +23,79 @@ func TestBasicLyricsProvider_CollectLyrics(t *testing.T) {
 	}, l, "CollectLyrics did not return the correct lyrics")
 	assert.Zero(t, len(lp.CollectLyrics("non-song")), "CollectLyrics did not return an empty line slice for a non-song")
 }
+
+type fakeLyricsProvider map[string][]LyricsLine
+
+func (f fakeLyricsProvider) CollectLyrics(song string) []LyricsLine { return f[song] }
+
+func TestLyricsProviderChain_CollectLyrics(t *testing.T) {
+	first := []LyricsLine{{{Timestamp: 1, Caption: "first"}}}
+	second := []LyricsLine{{{Timestamp: 2, Caption: "second"}}}
+	chain := LyricsProviderChain{
+		fakeLyricsProvider{"a": first},
+		fakeLyricsProvider{"a": second, "b": second},
+	}
+
+	assert.Equal(t, first, chain.CollectLyrics("a"), "CollectLyrics did not return the lyrics of the first provider")
+	assert.Equal(t, second, chain.CollectLyrics("b"), "CollectLyrics did not fall back to the second provider")
+	assert.Equal(t, []LyricsLine{}, chain.CollectLyrics("c"), "CollectLyrics did not return empty lyrics")
+}
+
+func TestGetLyricsProvider_Formats(t *testing.T) {
+	playback.AudioDir = "_test_files"
+	lp := GetLyricsProvider()
+
+	assert.Equal(t, []LyricsLine{
+		{{Timestamp: 10000, Caption: "first line"}},
+		{{Timestamp: 19500, Caption: "second "}, {Timestamp: 20250, Caption: "line"}},
+		{{Timestamp: 29500, Caption: "repeated line"}},
+		{{Timestamp: 39500, Caption: "repeated line"}},
+	}, lp.CollectLyrics("lrc-song.mp3"), "CollectLyrics did not read the lrc file")
+	assert.Equal(t, []LyricsLine{
+		{{Timestamp: 1500, Caption: "first subtitle"}},
+		{{Timestamp: 1500, Caption: "second line"}},
+		{{Timestamp: 62250, Caption: "last subtitle"}},
+	}, lp.CollectLyrics("srt-song.mp3"), "CollectLyrics did not read the srt file")
+	assert.Equal(t, basicLyricsProvider{}.CollectLyrics("test-song.mp3"), lp.CollectLyrics("test-song.mp3"),
+		"CollectLyrics did not prefer the json file")
+	assert.Empty(t, lp.CollectLyrics("non-song.mp3"), "CollectLyrics returned lyrics for a non-song")
+}
+
+func TestReadLyricsFile(t *testing.T) {
+	playback.AudioDir = "_test_files"
+
+	for _, file := range []string{"lrc-song.lrc", "srt-song.mp3.srt", "test-song.mp3.json"} {
+		lyrics, err := ReadLyricsFile(file)
+		if assert.NoError(t, err, "ReadLyricsFile returned an error for %s", file) {
+			assert.NotEmpty(t, lyrics, "ReadLyricsFile returned no lyrics for %s", file)
+		}
+	}
+	_, err := ReadLyricsFile("test-song.mp3")
+	assert.Error(t, err, "ReadLyricsFile did not return an error for a song without lyrics frames")
+	_, err = ReadLyricsFile("non-song.lrc")
+	assert.Error(t, err, "ReadLyricsFile did not return an error for a missing file")
+}
+
+func TestWriteLyrics(t *testing.T) {
+	lyrics := []LyricsLine{{{Timestamp: 1000, Caption: "a "}, {Timestamp: 2000, Caption: "b"}}}
+	buf := &bytes.Buffer{}
+	if assert.NoError(t, WriteLyrics(buf, lyrics), "WriteLyrics returned an error") {
+		read, err := parseJSONLyrics(buf)
+		assert.NoError(t, err, "WriteLyrics did not write valid json")
+		assert.Equal(t, lyrics, read, "WriteLyrics did not write the lyrics")
+	}
+}
+
+func TestShiftLyrics(t *testing.T) {
+	lyrics := []LyricsLine{{{Timestamp: 1000, Caption: "a"}, {Timestamp: 2000, Caption: "b"}}, {{Timestamp: 3000, Caption: "c"}}}
+	assert.Equal(t, []LyricsLine{{{Timestamp: -500, Caption: "a"}, {Timestamp: 500, Caption: "b"}}, {{Timestamp: 1500, Caption: "c"}}},
+		ShiftLyrics(lyrics, -1500), "ShiftLyrics did not shift the lyrics")
+	assert.Equal(t, int64(1000), lyrics[0][0].Timestamp, "ShiftLyrics modified the original lyrics")
+}
+
+func TestStretchLyrics(t *testing.T) {
+	lyrics := []LyricsLine{{{Timestamp: 1000, Caption: "a"}, {Timestamp: 2000, Caption: "b"}}, {{Timestamp: 3000, Caption: "c"}}}
+	assert.Equal(t, []LyricsLine{{{Timestamp: 1250, Caption: "a"}, {Timestamp: 2500, Caption: "b"}}, {{Timestamp: 3750, Caption: "c"}}},
+		StretchLyrics(lyrics, 0.8), "StretchLyrics did not stretch the lyrics")
+	assert.Equal(t, int64(1000), lyrics[0][0].Timestamp, "StretchLyrics modified the original lyrics")
+}
@@ -0,0 +1,85 @@
+package metadata
+
+import (
+	"github.com/LogicalOverflow/music-sync/playback"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// metadataCheckInterval is the time an entry of the metadata cache is used without checking whether its file
+// changed. The api and mpd servers look up the metadata of the whole playlist every few hundred milliseconds.
+var metadataCheckInterval = time.Second
+
+// cachedMetadata is the metadata of a song read while the file it is stored in had modTime
+type cachedMetadata struct {
+	modTime time.Time
+	// checked is the time the modification time of the file was last compared to modTime
+	checked time.Time
+	md      SongMetadata
+	tagged  bool
+	// decoded is true, if md.Duration is set
+	decoded bool
+}
+
+// metadataCache holds the metadata read from the songs, keyed by their path. An entry is only used while the
+// modification time of the file the song is stored in does not change, which is checked at most once every
+// metadataCheckInterval.
+var metadataCache = struct {
+	sync.Mutex
+	entries map[string]cachedMetadata
+}{entries: make(map[string]cachedMetadata)}
+
+// songFile returns the path of the file song is stored in, for cue tracks this is the cue sheet
+func songFile(song string) string {
+	if cue, _, ok := playback.ParseCueTrackSong(song); ok {
+		song = cue
+	}
+	return filepath.Join(playback.AudioDir, song)
+}
+
+// readMetadata reads the metadata of song from its tags or its cue sheet, the duration only if decode is true.
+// tagged is false, if song is neither a cue track nor a file with tags.
+func readMetadata(song string, decode bool) (md SongMetadata, tagged bool) {
+	if _, _, ok := playback.ParseCueTrackSong(song); ok {
+		md, tagged = cueTrackMetadata(song), true
+	} else {
+		md, tagged = tagMetadata(song)
+	}
+	if tagged && decode {
+		md.Duration, _ = playback.SongDuration(song)
+	}
+	return md, tagged
+}
+
+// cachedSongMetadata returns the metadata of song like readMetadata, reading the song only if it is not cached
+// or changed since it was read
+func cachedSongMetadata(song string, decode bool) (SongMetadata, bool) {
+	path := filepath.Join(playback.AudioDir, song)
+	now := time.Now()
+	metadataCache.Lock()
+	cached, ok := metadataCache.entries[path]
+	metadataCache.Unlock()
+	if ok && now.Sub(cached.checked) < metadataCheckInterval && (cached.decoded || !decode) {
+		return cached.md, cached.tagged
+	}
+
+	info, err := os.Stat(songFile(song))
+	if err != nil {
+		return readMetadata(song, decode)
+	}
+	if ok && cached.modTime.Equal(info.ModTime()) && (cached.decoded || !decode) {
+		cached.checked = now
+		metadataCache.Lock()
+		metadataCache.entries[path] = cached
+		metadataCache.Unlock()
+		return cached.md, cached.tagged
+	}
+
+	md, tagged := readMetadata(song, decode)
+	metadataCache.Lock()
+	metadataCache.entries[path] = cachedMetadata{modTime: info.ModTime(), checked: now, md: md, tagged: tagged, decoded: decode}
+	metadataCache.Unlock()
+	return md, tagged
+}
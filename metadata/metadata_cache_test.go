@@ -0,0 +1,58 @@
+package metadata
+
+import (
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCachedSongMetadata(t *testing.T) {
+	dir, err := ioutil.TempDir("", "music-sync-metadata")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	playback.AudioDir = dir
+	defer func(interval time.Duration) { metadataCheckInterval = interval }(metadataCheckInterval)
+	metadataCheckInterval = 0
+	path := filepath.Join(dir, "song.mp3")
+	modTime := time.Unix(1000, 0)
+
+	writeID3TestSong(t, dir, "song.mp3", id3Tag(map[string][]byte{"TIT2": id3TextFrame("first")}))
+	os.Chtimes(path, modTime, modTime)
+	md, tagged := cachedSongMetadata("song.mp3", false)
+	assert.True(t, tagged, "cachedSongMetadata did not read the tags")
+	assert.Equal(t, SongMetadata{Title: "first"}, md, "cachedSongMetadata returned the wrong metadata")
+
+	writeID3TestSong(t, dir, "song.mp3", id3Tag(map[string][]byte{"TIT2": id3TextFrame("second")}))
+	os.Chtimes(path, modTime, modTime)
+	md, _ = cachedSongMetadata("song.mp3", false)
+	assert.Equal(t, "first", md.Title, "cachedSongMetadata read the song again without a change of its modification time")
+
+	md, _ = cachedSongMetadata("song.mp3", true)
+	assert.Equal(t, "second", md.Title, "cachedSongMetadata did not read the song again to decode it")
+	assert.NotZero(t, md.Duration, "cachedSongMetadata did not decode the song")
+	md, _ = cachedSongMetadata("song.mp3", false)
+	assert.NotZero(t, md.Duration, "cachedSongMetadata did not keep the decoded duration")
+
+	writeID3TestSong(t, dir, "song.mp3", id3Tag(map[string][]byte{"TIT2": id3TextFrame("third")}))
+	os.Chtimes(path, modTime.Add(time.Second), modTime.Add(time.Second))
+	md, _ = cachedSongMetadata("song.mp3", false)
+	assert.Equal(t, SongMetadata{Title: "third"}, md, "cachedSongMetadata did not read the changed song again")
+
+	metadataCheckInterval = time.Hour
+	writeID3TestSong(t, dir, "song.mp3", id3Tag(map[string][]byte{"TIT2": id3TextFrame("fourth")}))
+	os.Chtimes(path, modTime.Add(2*time.Second), modTime.Add(2*time.Second))
+	md, _ = cachedSongMetadata("song.mp3", false)
+	assert.Equal(t, "third", md.Title, "cachedSongMetadata checked the song for changes within the check interval")
+	md, _ = cachedSongMetadata("song.mp3", true)
+	assert.Equal(t, "fourth", md.Title, "cachedSongMetadata did not read the song again to decode it within the check interval")
+
+	md, tagged = cachedSongMetadata("missing.mp3", true)
+	assert.False(t, tagged, "cachedSongMetadata returned tags for a missing song")
+	assert.Equal(t, SongMetadata{}, md, "cachedSongMetadata returned metadata for a missing song")
+}
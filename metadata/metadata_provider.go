@@ -6,6 +6,8 @@ import (
 	"github.com/dhowden/tag"
 	"os"
 	"path/filepath"
+	"sort"
+	"time"
 )
 
 // Provider is used to get metadata for songs
@@ -13,36 +15,120 @@ type Provider interface {
 	CollectMetadata(song string) SongMetadata
 }
 
-// SongMetadata holds the metadata for a song
+// SongMetadata holds the metadata for a song. Numbers missing in the tags are 0.
 type SongMetadata struct {
-	Title  string
-	Artist string
-	Album  string
+	Title       string
+	Artist      string
+	Album       string
+	Genre       string
+	AlbumArtist string
+	Composer    string
+	Comment     string
+	Year        int
+	Track       int
+	TrackTotal  int
+	Disc        int
+	DiscTotal   int
+	// Duration is the length of the song as decoded
+	Duration time.Duration
 }
 
-// GetProvider returns a new Provider
-func GetProvider() Provider {
-	return basicProvider{}
+// cueTrackMetadata returns the metadata of a cue track. The performer of the cue sheet is used for tracks
+// without their own performer.
+func cueTrackMetadata(song string) SongMetadata {
+	sheet, i, err := playback.LookupCueTrack(song)
+	if err != nil {
+		return SongMetadata{}
+	}
+	track := sheet.Tracks[i]
+	md := SongMetadata{Title: track.Title, Artist: track.Performer, Album: sheet.Title, Genre: sheet.Genre,
+		AlbumArtist: sheet.Performer, Track: track.Number, TrackTotal: len(sheet.Tracks)}
+	if md.Artist == "" {
+		md.Artist = sheet.Performer
+	}
+	return md
 }
 
-type basicProvider struct{}
-
-func (basicProvider) CollectMetadata(song string) SongMetadata {
+// tagMetadata returns the metadata in the tags of the file song
+func tagMetadata(song string) (SongMetadata, bool) {
 	path := filepath.Join(playback.AudioDir, song)
 	if !util.IsFile(path) {
-		return SongMetadata{}
+		return SongMetadata{}, false
 	}
 	f, err := os.Open(path)
 	if err != nil {
-		return SongMetadata{}
+		return SongMetadata{}, false
 	}
+	defer f.Close()
 	md, err := tag.ReadFrom(f)
 	if err != nil {
-		return SongMetadata{}
+		return SongMetadata{}, false
 	}
+	track, trackTotal := md.Track()
+	disc, discTotal := md.Disc()
 	return SongMetadata{
-		Title:  md.Title(),
-		Artist: md.Artist(),
-		Album:  md.Album(),
+		Title:       md.Title(),
+		Artist:      md.Artist(),
+		Album:       md.Album(),
+		Genre:       md.Genre(),
+		AlbumArtist: md.AlbumArtist(),
+		Composer:    md.Composer(),
+		Comment:     md.Comment(),
+		Year:        md.Year(),
+		Track:       track,
+		TrackTotal:  trackTotal,
+		Disc:        disc,
+		DiscTotal:   discTotal,
+	}, true
+}
+
+// songNumbers returns the disc and track number of song from its tags or its cue sheet without decoding it
+func songNumbers(song string) (disc int, track int) {
+	if _, number, ok := playback.ParseCueTrackSong(song); ok {
+		return 0, number
+	}
+	md, _ := cachedSongMetadata(song, false)
+	return md.Disc, md.Track
+}
+
+// SortSongs sorts the songs of each directory by disc and track number and then by name. The directories keep
+// the order they first appear in. Songs without numbers come first in their directory.
+func SortSongs(songs []string) {
+	type numbers struct{ disc, track int }
+	songNumberCache := make(map[string]numbers, len(songs))
+	dirOrder := make(map[string]int)
+	for _, s := range songs {
+		disc, track := songNumbers(s)
+		songNumberCache[s] = numbers{disc: disc, track: track}
+		if _, ok := dirOrder[filepath.Dir(s)]; !ok {
+			dirOrder[filepath.Dir(s)] = len(dirOrder)
+		}
 	}
+	sort.SliceStable(songs, func(i, j int) bool {
+		if di, dj := dirOrder[filepath.Dir(songs[i])], dirOrder[filepath.Dir(songs[j])]; di != dj {
+			return di < dj
+		}
+		ni, nj := songNumberCache[songs[i]], songNumberCache[songs[j]]
+		if ni.disc != nj.disc {
+			return ni.disc < nj.disc
+		}
+		if ni.track != nj.track {
+			return ni.track < nj.track
+		}
+		return songs[i] < songs[j]
+	})
+}
+
+// GetProvider returns a new Provider
+func GetProvider() Provider {
+	return basicProvider{}
+}
+
+type basicProvider struct{}
+
+// CollectMetadata returns the metadata of song including its decoded duration. The metadata is cached until the
+// song file changes, so songs are only decoded once.
+func (basicProvider) CollectMetadata(song string) SongMetadata {
+	md, _ := cachedSongMetadata(song, true)
+	return md
 }
@@ -3,7 +3,11 @@ package metadata
 import (
 	"github.com/LogicalOverflow/music-sync/playback"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestGetProvider(t *testing.T) {
@@ -13,8 +17,76 @@ func TestGetProvider(t *testing.T) {
 func TestBasicProvider_CollectMetadata(t *testing.T) {
 	playback.AudioDir = "_test_files"
 	bp := basicProvider{}
+	songDuration, err := playback.SongDuration("test-song.mp3")
+	if err != nil {
+		t.Fatalf("failed to decode test-song.mp3: %v", err)
+	}
 
 	assert.Equal(t, SongMetadata{}, bp.CollectMetadata("non-song"), "CollectMetadata did not return empty metadata for non-song")
-	assert.Equal(t, SongMetadata{Title: "test-title", Artist: "test-artist", Album: "test-album"},
+	assert.Equal(t, SongMetadata{Title: "test-title", Artist: "test-artist", Album: "test-album", Duration: songDuration},
 		bp.CollectMetadata("test-song.mp3"), "CollectMetadata did not return the correct metadata")
+	assert.Equal(t, SongMetadata{Title: "first track", Artist: "test-performer", Album: "test-album", Genre: "Live",
+		AlbumArtist: "test-performer", Track: 1, TrackTotal: 2, Duration: time.Second},
+		bp.CollectMetadata("test-album.cue#01"), "CollectMetadata did not return the metadata of the cue track")
+	assert.Equal(t, SongMetadata{Title: "second track", Artist: "guest-performer", Album: "test-album", Genre: "Live",
+		AlbumArtist: "test-performer", Track: 2, TrackTotal: 2, Duration: songDuration - time.Second},
+		bp.CollectMetadata("test-album.cue#02"), "CollectMetadata did not return the performer of the cue track")
+	assert.Equal(t, SongMetadata{}, bp.CollectMetadata("test-album.cue#03"), "CollectMetadata did not return empty metadata for a missing cue track")
+}
+
+func id3TextFrame(text string) []byte {
+	return append([]byte{id3EncodingISO8859}, text...)
+}
+
+func TestBasicProvider_CollectMetadataAllTags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "music-sync-metadata")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	writeID3TestSong(t, dir, "tagged.mp3", id3Tag(map[string][]byte{
+		"TIT2": id3TextFrame("title"),
+		"TPE1": id3TextFrame("artist"),
+		"TALB": id3TextFrame("album"),
+		"TCON": id3TextFrame("Rock"),
+		"TPE2": id3TextFrame("album artist"),
+		"TCOM": id3TextFrame("composer"),
+		"COMM": append([]byte{id3EncodingISO8859, 'e', 'n', 'g', 0}, "comment"...),
+		"TYER": id3TextFrame("2019"),
+		"TRCK": id3TextFrame("3/12"),
+		"TPOS": id3TextFrame("1/2"),
+	}))
+	playback.AudioDir = dir
+
+	md := basicProvider{}.CollectMetadata("tagged.mp3")
+	assert.NotZero(t, md.Duration, "CollectMetadata did not decode the duration")
+	md.Duration = 0
+	assert.Equal(t, SongMetadata{Title: "title", Artist: "artist", Album: "album", Genre: "Rock", AlbumArtist: "album artist",
+		Composer: "composer", Comment: "comment", Year: 2019, Track: 3, TrackTotal: 12, Disc: 1, DiscTotal: 2}, md,
+		"CollectMetadata did not return all tags")
+}
+
+func TestSortSongs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "music-sync-sort")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	os.Mkdir(filepath.Join(dir, "album"), 0755)
+	for name, numbers := range map[string][2]string{
+		"a.mp3": {"1", "2"}, "b.mp3": {"1", "1"}, "c.mp3": {"2", "1"}, "d.mp3": {"1", "10"},
+	} {
+		writeID3TestSong(t, filepath.Join(dir, "album"), name, id3Tag(map[string][]byte{
+			"TPOS": id3TextFrame(numbers[0]), "TRCK": id3TextFrame(numbers[1]),
+		}))
+	}
+	ioutil.WriteFile(filepath.Join(dir, "album", "untagged.mp3"), []byte{}, 0644)
+	ioutil.WriteFile(filepath.Join(dir, "z.mp3"), []byte{}, 0644)
+	playback.AudioDir = dir
+
+	songs := []string{"z.mp3", "album/c.mp3", "album/d.mp3", "album/a.mp3", "album/untagged.mp3", "album/b.mp3",
+		"live.cue#02", "live.cue#01"}
+	SortSongs(songs)
+	assert.Equal(t, []string{"z.mp3", "live.cue#01", "live.cue#02",
+		"album/untagged.mp3", "album/b.mp3", "album/a.mp3", "album/d.mp3", "album/c.mp3"}, songs, "SortSongs sorted the songs wrongly")
 }
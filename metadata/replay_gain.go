@@ -0,0 +1,89 @@
+package metadata
+
+import (
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/util"
+	"github.com/dhowden/tag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ReplayGain holds the replay gain of a song in dB
+type ReplayGain struct {
+	TrackGain    float64
+	AlbumGain    float64
+	HasTrackGain bool
+	HasAlbumGain bool
+}
+
+// GainProvider is used to get the replay gain for songs
+type GainProvider interface {
+	CollectGain(song string) ReplayGain
+}
+
+// GetGainProvider returns a new GainProvider
+func GetGainProvider() GainProvider {
+	return basicGainProvider{}
+}
+
+type basicGainProvider struct{}
+
+func (basicGainProvider) CollectGain(song string) ReplayGain {
+	path := filepath.Join(playback.AudioDir, song)
+	if !util.IsFile(path) {
+		return ReplayGain{}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return ReplayGain{}
+	}
+	defer f.Close()
+	md, err := tag.ReadFrom(f)
+	if err != nil {
+		return ReplayGain{}
+	}
+	return replayGainFromRaw(md.Raw())
+}
+
+// replayGainFromRaw searches raw tags for replay gain values.
+// ID3 stores them in TXXX frames, vorbis comments and mp4 as plain (free form) tags.
+func replayGainFromRaw(raw map[string]interface{}) ReplayGain {
+	rg := ReplayGain{}
+	for k, v := range raw {
+		var name, value string
+		switch v := v.(type) {
+		case *tag.Comm:
+			name, value = v.Description, v.Text
+		case string:
+			name, value = k, v
+		default:
+			continue
+		}
+
+		name = strings.ToLower(name)
+		if gain, ok := parseGain(value); ok {
+			switch {
+			case strings.HasSuffix(name, "replaygain_track_gain"):
+				rg.TrackGain, rg.HasTrackGain = gain, true
+			case strings.HasSuffix(name, "replaygain_album_gain"):
+				rg.AlbumGain, rg.HasAlbumGain = gain, true
+			}
+		}
+	}
+	return rg
+}
+
+// parseGain parses replay gain values like "-6.20 dB"
+func parseGain(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(strings.ToLower(value), "db") {
+		value = strings.TrimSpace(value[:len(value)-2])
+	}
+	gain, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false
+	}
+	return gain, true
+}
@@ -0,0 +1,48 @@
+package metadata
+
+import (
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/dhowden/tag"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetGainProvider(t *testing.T) {
+	assert.NotNil(t, GetGainProvider(), "GetGainProvider returned nil")
+}
+
+func TestBasicGainProvider_CollectGain(t *testing.T) {
+	playback.AudioDir = "_test_files"
+	gp := basicGainProvider{}
+
+	assert.Equal(t, ReplayGain{}, gp.CollectGain("non-song"), "CollectGain did not return an empty gain for a non-song")
+	assert.Equal(t, ReplayGain{}, gp.CollectGain("test-song.mp3"), "CollectGain did not return an empty gain for a song without replay gain tags")
+}
+
+func TestReplayGainFromRaw(t *testing.T) {
+	cases := []struct {
+		raw    map[string]interface{}
+		result ReplayGain
+	}{
+		{
+			raw:    map[string]interface{}{},
+			result: ReplayGain{},
+		},
+		{
+			raw: map[string]interface{}{
+				"TXXX":   &tag.Comm{Description: "REPLAYGAIN_TRACK_GAIN", Text: "-6.20 dB"},
+				"TXXX_0": &tag.Comm{Description: "REPLAYGAIN_ALBUM_GAIN", Text: "+1.5 dB"},
+				"TIT2":   "a title",
+			},
+			result: ReplayGain{TrackGain: -6.2, AlbumGain: 1.5, HasTrackGain: true, HasAlbumGain: true},
+		},
+		{
+			raw:    map[string]interface{}{"replaygain_track_gain": "-3.00 dB", "replaygain_album_gain": "not a gain"},
+			result: ReplayGain{TrackGain: -3, HasTrackGain: true},
+		},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.result, replayGainFromRaw(c.raw), "replayGainFromRaw returned the wrong gain for %v", c.raw)
+	}
+}
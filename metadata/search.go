@@ -0,0 +1,35 @@
+package metadata
+
+import (
+	"strings"
+)
+
+// searchText returns the lower case text a song is searched in: its path and the names in its tags or cue sheet
+func searchText(song string) string {
+	md, _ := cachedSongMetadata(song, false)
+	return strings.ToLower(strings.Join([]string{song, md.Title, md.Artist, md.Album, md.AlbumArtist, md.Composer,
+		md.Genre}, "\n"))
+}
+
+// SearchSongs returns the songs, whose path, title, artist, album, album artist, composer or genre contain all
+// words of query, ignoring case. The songs are only read for their tags, not decoded, and the tags are cached.
+// The result is sorted like SortSongs sorts songs.
+func SearchSongs(songs []string, query string) []string {
+	words := strings.Fields(strings.ToLower(query))
+	matches := make([]string, 0)
+	for _, song := range songs {
+		text := searchText(song)
+		matched := true
+		for _, w := range words {
+			if !strings.Contains(text, w) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, song)
+		}
+	}
+	SortSongs(matches)
+	return matches
+}
@@ -0,0 +1,21 @@
+package metadata
+
+import (
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSearchSongs(t *testing.T) {
+	playback.AudioDir = "_test_files"
+	songs := []string{"test-song.mp3", "test-album.cue#02", "test-album.cue#01", "missing.mp3"}
+
+	assert.Equal(t, []string{"test-song.mp3"}, SearchSongs(songs, "TEST-Title"), "SearchSongs did not search the title")
+	assert.Equal(t, []string{"test-album.cue#02"}, SearchSongs(songs, "guest second"),
+		"SearchSongs did not match all words in the cue sheet")
+	assert.Equal(t, []string{"test-album.cue#01", "test-album.cue#02"}, SearchSongs(songs, "test-performer"),
+		"SearchSongs did not search the album artist or did not sort the tracks")
+	assert.Equal(t, []string{"missing.mp3"}, SearchSongs(songs, "missing"), "SearchSongs did not search the path")
+	assert.Empty(t, SearchSongs(songs, "test-title unknown"), "SearchSongs matched a song not containing all words")
+	assert.Len(t, SearchSongs(songs, " "), len(songs), "SearchSongs did not match all songs for an empty query")
+}
@@ -0,0 +1,45 @@
+package metadata
+
+import (
+	"encoding/json"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/util"
+	"os"
+	"path/filepath"
+)
+
+// TrimPoints are the positions a song starts and ends at in milliseconds. An end of 0 is the end of the song.
+type TrimPoints struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// TrimProvider is used to get the trim points of songs
+type TrimProvider interface {
+	CollectTrimPoints(song string) TrimPoints
+}
+
+// GetTrimProvider returns a new TrimProvider
+func GetTrimProvider() TrimProvider {
+	return basicTrimProvider{}
+}
+
+type basicTrimProvider struct{}
+
+func (basicTrimProvider) CollectTrimPoints(song string) TrimPoints {
+	path := filepath.Join(playback.AudioDir, song+".trim.json")
+	if !util.IsFile(path) {
+		return TrimPoints{}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return TrimPoints{}
+	}
+	defer f.Close()
+
+	var tp TrimPoints
+	if err := json.NewDecoder(f).Decode(&tp); err != nil {
+		return TrimPoints{}
+	}
+	return tp
+}
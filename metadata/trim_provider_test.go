@@ -0,0 +1,19 @@
+package metadata
+
+import (
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetTrimProvider(t *testing.T) {
+	assert.NotNil(t, GetTrimProvider(), "GetTrimProvider returned nil")
+}
+
+func TestBasicTrimProvider_CollectTrimPoints(t *testing.T) {
+	playback.AudioDir = "_test_files"
+	tp := basicTrimProvider{}
+
+	assert.Equal(t, TrimPoints{Start: 1500, End: 4000}, tp.CollectTrimPoints("test-song.mp3"), "CollectTrimPoints did not return the correct trim points")
+	assert.Equal(t, TrimPoints{}, tp.CollectTrimPoints("non-song"), "CollectTrimPoints did not return empty trim points for a non-song")
+}
@@ -0,0 +1,977 @@
+package mpd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/api"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"hash/crc32"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchLimit is the number of songs returned by searches
+const searchLimit = 1000
+
+// startTime is the time the server was started at, reported as uptime by stats
+var startTime = time.Now()
+
+// tagTypes are the tags of songs sent to clients
+var tagTypes = []string{"Artist", "Album", "AlbumArtist", "Title", "Track", "Genre", "Date", "Composer", "Disc"}
+
+// command is a command of the mpd protocol
+type command struct {
+	exec func(c *client, args []string, r *response) error
+	// public commands can be executed without authentication
+	public bool
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"ping":        {exec: func(*client, []string, *response) error { return nil }, public: true},
+		"password":    {exec: passwordCommand, public: true},
+		"commands":    {exec: commandsCommand(true), public: true},
+		"notcommands": {exec: commandsCommand(false), public: true},
+		"tagtypes":    {exec: tagTypesCommand, public: true},
+		"urlhandlers": {exec: urlHandlersCommand, public: true},
+
+		"status":         {exec: statusCommand},
+		"stats":          {exec: statsCommand},
+		"outputs":        {exec: outputsCommand},
+		"currentsong":    {exec: currentSongCommand},
+		"playlistinfo":   {exec: playlistInfoCommand},
+		"playlistid":     {exec: playlistIDCommand},
+		"plchanges":      {exec: plChangesCommand},
+		"plchangesposid": {exec: plChangesPosIDCommand},
+		"listplaylists":  {exec: func(*client, []string, *response) error { return nil }},
+
+		"add":      {exec: addCommand},
+		"addid":    {exec: addIDCommand},
+		"delete":   {exec: deleteCommand},
+		"deleteid": {exec: deleteIDCommand},
+		"clear":    {exec: clearCommand},
+		"move":     {exec: moveCommand},
+		"moveid":   {exec: moveIDCommand},
+
+		"play":     {exec: playCommand(byPosition)},
+		"playid":   {exec: playCommand(byID)},
+		"pause":    {exec: pauseCommand},
+		"stop":     {exec: stopCommand},
+		"next":     {exec: nextCommand(1)},
+		"previous": {exec: nextCommand(-1)},
+		"seek":     {exec: seekCommand(byPosition)},
+		"seekid":   {exec: seekCommand(byID)},
+		"seekcur":  {exec: seekCurCommand},
+		"setvol":   {exec: setVolCommand},
+		"volume":   {exec: volumeCommand},
+
+		"lsinfo":    {exec: lsInfoCommand},
+		"search":    {exec: searchCommand(false)},
+		"find":      {exec: findCommand(false)},
+		"searchadd": {exec: searchCommand(true)},
+		"findadd":   {exec: findCommand(true)},
+	}
+}
+
+var errNotReady = newAckError(ackErrorSystem, "the server is not ready")
+
+var errNoSuchSong = newAckError(ackErrorNoExist, "No such song")
+
+func checkArgs(args []string, min, max int) error {
+	if len(args) < min || max < len(args) {
+		return newAckError(ackErrorArg, "wrong number of arguments")
+	}
+	return nil
+}
+
+func currentStatus() (api.Status, error) {
+	if api.StatusHandler == nil {
+		return api.Status{}, errNotReady
+	}
+	return api.StatusHandler(), nil
+}
+
+func currentPlaylist() ([]api.PlaylistEntry, error) {
+	if api.PlaylistHandler == nil {
+		return nil, errNotReady
+	}
+	return api.PlaylistHandler(), nil
+}
+
+// playlistVersion returns the version of playlist reported to clients. Instead of counting the changes, it is a
+// checksum of the playlist, as clients only compare it to the version they know.
+func playlistVersion(playlist []api.PlaylistEntry) uint32 {
+	data, _ := json.Marshal(playlist)
+	return crc32.ChecksumIEEE(data)
+}
+
+// locator returns the position in playlist of the song given by the argument arg
+type locator func(playlist []api.PlaylistEntry, arg string) (int, error)
+
+// byPosition locates the song at the position arg
+func byPosition(playlist []api.PlaylistEntry, arg string) (int, error) {
+	pos, err := parseInt("position", arg)
+	if err != nil {
+		return 0, err
+	}
+	if pos < 0 || len(playlist) <= pos {
+		return 0, errNoSuchSong
+	}
+	return pos, nil
+}
+
+// byID locates the song with the id arg
+func byID(playlist []api.PlaylistEntry, arg string) (int, error) {
+	id, err := parseInt("song id", arg)
+	if err != nil {
+		return 0, err
+	}
+	for i, e := range playlist {
+		if e.ID == id {
+			return i, nil
+		}
+	}
+	return 0, errNoSuchSong
+}
+
+// run runs the ssh command name with args as the user of c and returns its message
+func (c *client) run(name string, args ...string) (string, error) {
+	logger.Infof("%s runs %s %v through mpd", c.user, name, args)
+	msg, err := ssh.RunCommand(c.user, name, args)
+	if err != nil {
+		return "", newAckError(ackErrorArg, "%v", err)
+	}
+	return msg, nil
+}
+
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 3, 64)
+}
+
+// writeSong writes the file and the tags of song to r
+func writeSong(r *response, song api.Song) {
+	r.add("file", song.File)
+	r.addNonZero("Title", song.Title)
+	r.addNonZero("Artist", song.Artist)
+	r.addNonZero("Album", song.Album)
+	r.addNonZero("AlbumArtist", song.AlbumArtist)
+	r.addNonZero("Genre", song.Genre)
+	r.addNonZero("Composer", song.Composer)
+	r.addNonZero("Date", song.Year)
+	r.addNonZero("Track", song.Track)
+	r.addNonZero("Disc", song.Disc)
+	if 0 < song.Duration {
+		r.add("Time", int(math.Round(song.Duration)))
+		r.add("duration", formatSeconds(song.Duration))
+	}
+}
+
+// writeEntry writes the song at pos in the playlist with the id of its entry to r
+func writeEntry(r *response, song api.Song, pos, id int) {
+	writeSong(r, song)
+	r.add("Pos", pos)
+	r.add("Id", id)
+}
+
+func passwordCommand(c *client, args []string, _ *response) error {
+	if err := checkArgs(args, 1, 1); err != nil {
+		return err
+	}
+	user, ok := ssh.UserByToken(c.users, args[0])
+	if !ok {
+		return newAckError(ackErrorPassword, "incorrect password")
+	}
+	logger.Infof("mpd client %s authenticated as %s", c.conn.RemoteAddr(), user)
+	c.user = user
+	return nil
+}
+
+// commandsCommand returns the command listing the commands the client is allowed to execute, if allowed is true,
+// or the ones it is not allowed to execute otherwise
+func commandsCommand(allowed bool) func(c *client, args []string, r *response) error {
+	return func(c *client, _ []string, r *response) error {
+		names := make([]string, 0)
+		for name, cmd := range commands {
+			if (cmd.public || c.user != "") == allowed {
+				names = append(names, name)
+			}
+		}
+		if allowed {
+			names = append(names, "close")
+		}
+		// idle is handled by the connection, but needs authentication as well
+		if (c.user != "") == allowed {
+			names = append(names, "idle", "noidle")
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			r.add("command", name)
+		}
+		return nil
+	}
+}
+
+func tagTypesCommand(_ *client, args []string, r *response) error {
+	// changing the tags sent is accepted, but all tags are sent anyway
+	if len(args) == 0 {
+		for _, tag := range tagTypes {
+			r.add("tagtype", tag)
+		}
+	}
+	return nil
+}
+
+func urlHandlersCommand(_ *client, _ []string, r *response) error {
+	r.add("handler", "http://")
+	r.add("handler", "https://")
+	return nil
+}
+
+func statusCommand(_ *client, args []string, r *response) error {
+	if err := checkArgs(args, 0, 0); err != nil {
+		return err
+	}
+	status, err := currentStatus()
+	if err != nil {
+		return err
+	}
+	playlist, err := currentPlaylist()
+	if err != nil {
+		return err
+	}
+
+	r.add("volume", int(math.Round(status.Volume*100)))
+	r.add("repeat", 0)
+	r.add("random", 0)
+	r.add("single", 0)
+	r.add("consume", 0)
+	r.add("playlist", playlistVersion(playlist))
+	r.add("playlistlength", len(playlist))
+	switch {
+	case status.Song == nil:
+		r.add("state", "stop")
+	case status.Playing:
+		r.add("state", "play")
+	default:
+		r.add("state", "pause")
+	}
+	if status.Song == nil {
+		return nil
+	}
+
+	if status.Position < len(playlist) {
+		r.add("song", status.Position)
+		r.add("songid", playlist[status.Position].ID)
+	}
+	if status.Position+1 < len(playlist) {
+		r.add("nextsong", status.Position+1)
+		r.add("nextsongid", playlist[status.Position+1].ID)
+	}
+	r.add("time", fmt.Sprintf("%d:%d", int(status.Elapsed), int(math.Round(status.Length))))
+	r.add("elapsed", formatSeconds(status.Elapsed))
+	if 0 < status.Length {
+		r.add("duration", formatSeconds(status.Length))
+	}
+	if api.PlayerHandler != nil {
+		r.add("audio", fmt.Sprintf("%d:f:2", api.PlayerHandler().SampleRate))
+	}
+	return nil
+}
+
+func statsCommand(_ *client, _ []string, r *response) error {
+	r.add("uptime", int(time.Since(startTime).Seconds()))
+	return nil
+}
+
+func outputsCommand(_ *client, _ []string, r *response) error {
+	r.add("outputid", 0)
+	r.add("outputname", "music-sync")
+	r.add("plugin", "music-sync")
+	r.add("outputenabled", 1)
+	return nil
+}
+
+func currentSongCommand(_ *client, _ []string, r *response) error {
+	status, err := currentStatus()
+	if err != nil {
+		return err
+	}
+	playlist, err := currentPlaylist()
+	if err != nil {
+		return err
+	}
+	if status.Song != nil && status.Position < len(playlist) {
+		writeEntry(r, *status.Song, status.Position, playlist[status.Position].ID)
+	}
+	return nil
+}
+
+func playlistInfoCommand(_ *client, args []string, r *response) error {
+	if err := checkArgs(args, 0, 1); err != nil {
+		return err
+	}
+	playlist, err := currentPlaylist()
+	if err != nil {
+		return err
+	}
+	start, end := 0, len(playlist)
+	if len(args) == 1 {
+		if start, end, err = parseRange(args[0], len(playlist)); err != nil {
+			return err
+		}
+	}
+	for i := start; i < end; i++ {
+		writeEntry(r, playlist[i].Song, i, playlist[i].ID)
+	}
+	return nil
+}
+
+func playlistIDCommand(_ *client, args []string, r *response) error {
+	if err := checkArgs(args, 0, 1); err != nil {
+		return err
+	}
+	playlist, err := currentPlaylist()
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		for i, e := range playlist {
+			writeEntry(r, e.Song, i, e.ID)
+		}
+		return nil
+	}
+	pos, err := byID(playlist, args[0])
+	if err != nil {
+		return err
+	}
+	writeEntry(r, playlist[pos].Song, pos, playlist[pos].ID)
+	return nil
+}
+
+// plChangesCommand lists the songs changed since a playlist version. As the versions are checksums, all songs are
+// listed.
+func plChangesCommand(c *client, args []string, r *response) error {
+	if err := checkArgs(args, 1, 2); err != nil {
+		return err
+	}
+	return playlistInfoCommand(c, args[1:], r)
+}
+
+func plChangesPosIDCommand(_ *client, args []string, r *response) error {
+	if err := checkArgs(args, 1, 2); err != nil {
+		return err
+	}
+	playlist, err := currentPlaylist()
+	if err != nil {
+		return err
+	}
+	for i, e := range playlist {
+		r.add("cpos", i)
+		r.add("Id", e.ID)
+	}
+	return nil
+}
+
+// escapeGlob escapes the characters of the glob patterns of the queue command in song
+func escapeGlob(song string) string {
+	if strings.Contains(song, "://") {
+		return song
+	}
+	var escaped bytes.Buffer
+	for _, r := range song {
+		if strings.ContainsRune(`*?[\`, r) {
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
+
+// libraryTree returns the songs with their tags in the directory dir of the music directory and all its sub
+// directories
+func libraryTree(dir string) []api.Song {
+	if api.LibraryHandler == nil || !api.ValidLibraryDir(dir) {
+		return nil
+	}
+	library := api.LibraryHandler(dir)
+	songs := append([]api.Song{}, library.Songs...)
+	for _, sub := range library.Dirs {
+		songs = append(songs, libraryTree(sub)...)
+	}
+	return songs
+}
+
+// librarySongs returns the songs in the directory dir of the music directory and all its sub directories
+func librarySongs(dir string) []string {
+	tree := libraryTree(dir)
+	songs := make([]string, len(tree))
+	for i, song := range tree {
+		songs[i] = song.File
+	}
+	return songs
+}
+
+// queue adds the song or all songs in the directory uri to the playlist, at pos if pos is not negative
+func (c *client) queue(uri string, pos int) error {
+	uri = strings.Trim(uri, "/")
+	songs := librarySongs(uri)
+	if len(songs) == 0 {
+		if uri == "" {
+			return newAckError(ackErrorNoExist, "No such directory")
+		}
+		songs = []string{uri}
+	}
+	for i, song := range songs {
+		args := []string{escapeGlob(song)}
+		if 0 <= pos {
+			args = append(args, strconv.Itoa(pos+i))
+		}
+		msg, err := c.run("queue", args...)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(msg, "no song matches") {
+			return errNoSuchSong
+		}
+	}
+	return nil
+}
+
+func addCommand(c *client, args []string, _ *response) error {
+	if err := checkArgs(args, 1, 2); err != nil {
+		return err
+	}
+	pos := -1
+	if len(args) == 2 {
+		var err error
+		if pos, err = parseInt("position", args[1]); err != nil {
+			return err
+		}
+	}
+	return c.queue(args[0], pos)
+}
+
+func addIDCommand(c *client, args []string, r *response) error {
+	if err := checkArgs(args, 1, 2); err != nil {
+		return err
+	}
+	playlist, err := currentPlaylist()
+	if err != nil {
+		return err
+	}
+	pos := len(playlist)
+	if len(args) == 2 {
+		if pos, err = parseInt("position", args[1]); err != nil {
+			return err
+		}
+		if pos < 0 || len(playlist) < pos {
+			return newAckError(ackErrorArg, "Bad song index")
+		}
+	}
+	if err := c.queue(args[0], pos); err != nil {
+		return err
+	}
+	if playlist, err = currentPlaylist(); err != nil {
+		return err
+	}
+	if len(playlist) <= pos {
+		return errNoSuchSong
+	}
+	r.add("Id", playlist[pos].ID)
+	return nil
+}
+
+// remove removes the songs at the positions [start, end) from the playlist
+func (c *client) remove(start, end int) error {
+	for i := end - 1; start <= i; i-- {
+		if _, err := c.run("remove", strconv.Itoa(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteCommand(c *client, args []string, _ *response) error {
+	if err := checkArgs(args, 1, 1); err != nil {
+		return err
+	}
+	playlist, err := currentPlaylist()
+	if err != nil {
+		return err
+	}
+	start, end, err := parseRange(args[0], len(playlist))
+	if err != nil {
+		return err
+	}
+	return c.remove(start, end)
+}
+
+func deleteIDCommand(c *client, args []string, _ *response) error {
+	if err := checkArgs(args, 1, 1); err != nil {
+		return err
+	}
+	playlist, err := currentPlaylist()
+	if err != nil {
+		return err
+	}
+	pos, err := byID(playlist, args[0])
+	if err != nil {
+		return err
+	}
+	return c.remove(pos, pos+1)
+}
+
+func clearCommand(c *client, args []string, _ *response) error {
+	if err := checkArgs(args, 0, 0); err != nil {
+		return err
+	}
+	playlist, err := currentPlaylist()
+	if err != nil {
+		return err
+	}
+	return c.remove(0, len(playlist))
+}
+
+// move moves the songs at [start, end) in the playlist, such that the first of them is at to afterwards
+func (c *client) move(start, end, to int) error {
+	if to == start {
+		return nil
+	}
+	n := end - start
+	for i := 0; i < n; i++ {
+		from, target := start, to+n-1
+		if to < start {
+			from, target = start+i, to+i
+		}
+		if _, err := c.run("move", strconv.Itoa(from), strconv.Itoa(target)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func moveCommand(c *client, args []string, _ *response) error {
+	if err := checkArgs(args, 2, 2); err != nil {
+		return err
+	}
+	playlist, err := currentPlaylist()
+	if err != nil {
+		return err
+	}
+	start, end, err := parseRange(args[0], len(playlist))
+	if err != nil {
+		return err
+	}
+	to, err := parseInt("position", args[1])
+	if err != nil {
+		return err
+	}
+	if to < 0 || len(playlist) < to+end-start {
+		return errNoSuchSong
+	}
+	return c.move(start, end, to)
+}
+
+func moveIDCommand(c *client, args []string, _ *response) error {
+	if err := checkArgs(args, 2, 2); err != nil {
+		return err
+	}
+	playlist, err := currentPlaylist()
+	if err != nil {
+		return err
+	}
+	pos, err := byID(playlist, args[0])
+	if err != nil {
+		return err
+	}
+	to, err := byPosition(playlist, args[1])
+	if err != nil {
+		return err
+	}
+	return c.move(pos, pos+1, to)
+}
+
+// playCommand returns the command starting playback, at the song located by locate from the argument, if there
+// is one
+func playCommand(locate locator) func(c *client, args []string, r *response) error {
+	return func(c *client, args []string, _ *response) error {
+		if err := checkArgs(args, 0, 1); err != nil {
+			return err
+		}
+		status, err := currentStatus()
+		if err != nil {
+			return err
+		}
+		if len(args) == 1 && args[0] != "-1" {
+			playlist, err := currentPlaylist()
+			if err != nil {
+				return err
+			}
+			pos, err := locate(playlist, args[0])
+			if err != nil {
+				return err
+			}
+			if _, err := c.run("jump", strconv.Itoa(pos)); err != nil {
+				return err
+			}
+		}
+		if !status.Playing {
+			_, err = c.run("resume")
+		}
+		return err
+	}
+}
+
+func pauseCommand(c *client, args []string, _ *response) error {
+	if err := checkArgs(args, 0, 1); err != nil {
+		return err
+	}
+	pause := true
+	if len(args) == 1 {
+		pause = args[0] == "1"
+	} else {
+		status, err := currentStatus()
+		if err != nil {
+			return err
+		}
+		pause = status.Playing
+	}
+	var err error
+	if pause {
+		_, err = c.run("pause")
+	} else {
+		_, err = c.run("resume")
+	}
+	return err
+}
+
+// stopCommand pauses playback, as songs cannot be stopped
+func stopCommand(c *client, args []string, _ *response) error {
+	if err := checkArgs(args, 0, 0); err != nil {
+		return err
+	}
+	_, err := c.run("pause")
+	return err
+}
+
+// nextCommand returns the command jumping by offset songs in the playlist
+func nextCommand(offset int) func(c *client, args []string, r *response) error {
+	return func(c *client, args []string, _ *response) error {
+		if err := checkArgs(args, 0, 0); err != nil {
+			return err
+		}
+		status, err := currentStatus()
+		if err != nil {
+			return err
+		}
+		pos := status.Position + offset
+		if pos < 0 {
+			pos = 0
+		}
+		_, err = c.run("jump", strconv.Itoa(pos))
+		return err
+	}
+}
+
+// seekCommand returns the command seeking in the song located by locate from the first argument, jumping to it if
+// it is not the current song
+func seekCommand(locate locator) func(c *client, args []string, r *response) error {
+	return func(c *client, args []string, _ *response) error {
+		if err := checkArgs(args, 2, 2); err != nil {
+			return err
+		}
+		playlist, err := currentPlaylist()
+		if err != nil {
+			return err
+		}
+		pos, err := locate(playlist, args[0])
+		if err != nil {
+			return err
+		}
+		seconds, err := parseFloat("time", args[1])
+		if err != nil {
+			return err
+		}
+		status, err := currentStatus()
+		if err != nil {
+			return err
+		}
+		if status.Song == nil || pos != status.Position {
+			if _, err := c.run("jump", strconv.Itoa(pos)); err != nil {
+				return err
+			}
+		}
+		_, err = c.run("seek", strconv.FormatFloat(seconds, 'f', -1, 64))
+		return err
+	}
+}
+
+func seekCurCommand(c *client, args []string, _ *response) error {
+	if err := checkArgs(args, 1, 1); err != nil {
+		return err
+	}
+	seconds, err := parseFloat("time", args[0])
+	if err != nil {
+		return err
+	}
+	value := strconv.FormatFloat(seconds, 'f', -1, 64)
+	if strings.HasPrefix(args[0], "+") || strings.HasPrefix(args[0], "-") {
+		// relative seeks keep their sign
+		value = fmt.Sprintf("%+g", seconds)
+	}
+	_, err = c.run("seek", value)
+	return err
+}
+
+// setVolume sets the volume to percent (0 to 100)
+func (c *client) setVolume(percent float64) error {
+	if percent < 0 || 100 < percent {
+		return newAckError(ackErrorArg, "Invalid volume value")
+	}
+	_, err := c.run("volume", strconv.FormatFloat(percent/100, 'f', -1, 64))
+	return err
+}
+
+func setVolCommand(c *client, args []string, _ *response) error {
+	if err := checkArgs(args, 1, 1); err != nil {
+		return err
+	}
+	percent, err := parseFloat("volume", args[0])
+	if err != nil {
+		return err
+	}
+	return c.setVolume(percent)
+}
+
+// volumeCommand changes the volume by the percentage given
+func volumeCommand(c *client, args []string, _ *response) error {
+	if err := checkArgs(args, 1, 1); err != nil {
+		return err
+	}
+	change, err := parseFloat("volume change", args[0])
+	if err != nil {
+		return err
+	}
+	status, err := currentStatus()
+	if err != nil {
+		return err
+	}
+	// the change is relative to the volume reported by status, which is rounded to whole percents
+	return c.setVolume(math.Max(0, math.Min(100, math.Round(status.Volume*100)+change)))
+}
+
+func lsInfoCommand(_ *client, args []string, r *response) error {
+	if err := checkArgs(args, 0, 1); err != nil {
+		return err
+	}
+	if api.LibraryHandler == nil {
+		return errNotReady
+	}
+	dir := ""
+	if len(args) == 1 {
+		dir = strings.Trim(args[0], "/")
+	}
+	if !api.ValidLibraryDir(dir) {
+		return newAckError(ackErrorNoExist, "No such directory")
+	}
+	library := api.LibraryHandler(dir)
+	for _, sub := range library.Dirs {
+		r.add("directory", sub)
+	}
+	for _, song := range library.Songs {
+		writeSong(r, song)
+	}
+	return nil
+}
+
+// filterValue matches the quoted values of filter expressions like (artist == 'value')
+var filterValue = regexp.MustCompile(`'((?:[^'\\]|\\.)*)'|"((?:[^"\\]|\\.)*)"`)
+
+// searchQuery returns the words searched for by the arguments of search, either type value pairs or a filter
+// expression. The types are ignored, the words are matched against all tags.
+func searchQuery(args []string) (string, error) {
+	words := make([]string, 0)
+	if len(args) == 1 || (0 < len(args) && strings.HasPrefix(args[0], "(")) {
+		for _, match := range filterValue.FindAllStringSubmatch(args[0], -1) {
+			words = append(words, match[1]+match[2])
+		}
+		return strings.Join(words, " "), nil
+	}
+	if len(args)%2 != 0 {
+		return "", newAckError(ackErrorArg, "incorrect number of filter arguments")
+	}
+	for i := 0; i < len(args); i += 2 {
+		switch strings.ToLower(args[i]) {
+		case "sort", "window":
+		default:
+			words = append(words, args[i+1])
+		}
+	}
+	return strings.Join(words, " "), nil
+}
+
+// tagValues returns the values of the tag named tag of song, which are matched by find. The tag any has the values
+// of all tags.
+func tagValues(song api.Song, tag string) ([]string, error) {
+	number := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.Itoa(n)
+	}
+	values := map[string]string{
+		"file":        song.File,
+		"artist":      song.Artist,
+		"album":       song.Album,
+		"albumartist": song.AlbumArtist,
+		"title":       song.Title,
+		"track":       number(song.Track),
+		"genre":       song.Genre,
+		"date":        number(song.Year),
+		"composer":    song.Composer,
+		"disc":        number(song.Disc),
+	}
+	tag = strings.ToLower(tag)
+	if tag == "any" {
+		all := make([]string, 0, len(values))
+		for _, v := range values {
+			all = append(all, v)
+		}
+		return all, nil
+	}
+	value, ok := values[tag]
+	if !ok {
+		return nil, newAckError(ackErrorArg, "Unknown filter type '%s'", tag)
+	}
+	return []string{value}, nil
+}
+
+// tagFilter matches songs, whose tag has exactly the value
+type tagFilter struct {
+	tag, value string
+}
+
+// filterComparison matches the comparisons (tag == 'value') of filter expressions
+var filterComparison = regexp.MustCompile(`\(\s*(\w+)\s*==\s*(?:'((?:[^'\\]|\\.)*)'|"((?:[^"\\]|\\.)*)")\s*\)`)
+
+// filterEscape matches the escaped characters of the values in filter expressions
+var filterEscape = regexp.MustCompile(`\\(.)`)
+
+// findFilters returns the filters of the arguments of find, either tag value pairs or a filter expression of
+// comparisons with == joined by AND
+func findFilters(args []string) ([]tagFilter, error) {
+	filters := make([]tagFilter, 0)
+	if len(args) == 1 || (0 < len(args) && strings.HasPrefix(args[0], "(")) {
+		for _, match := range filterComparison.FindAllStringSubmatch(args[0], -1) {
+			filters = append(filters, tagFilter{tag: match[1], value: filterEscape.ReplaceAllString(match[2]+match[3], "$1")})
+		}
+		rest := filterComparison.ReplaceAllString(args[0], "")
+		if len(filters) == 0 || strings.Trim(strings.Replace(rest, "AND", "", -1), "() ") != "" {
+			return nil, newAckError(ackErrorArg, "unsupported filter expression")
+		}
+		return filters, nil
+	}
+	if len(args)%2 != 0 {
+		return nil, newAckError(ackErrorArg, "incorrect number of filter arguments")
+	}
+	for i := 0; i < len(args); i += 2 {
+		switch strings.ToLower(args[i]) {
+		case "sort", "window":
+		default:
+			filters = append(filters, tagFilter{tag: args[i], value: args[i+1]})
+		}
+	}
+	return filters, nil
+}
+
+// matches returns true, if song has the value of each filter in its tag, comparing them case sensitive
+func matches(song api.Song, filters []tagFilter) (bool, error) {
+	for _, f := range filters {
+		values, err := tagValues(song, f.tag)
+		if err != nil {
+			return false, err
+		}
+		found := false
+		for _, v := range values {
+			if v == f.value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// findCommand returns the command listing the songs in the music directory, whose tags match the filters exactly,
+// or adding them to the playlist, if add is true
+func findCommand(add bool) func(c *client, args []string, r *response) error {
+	return func(c *client, args []string, r *response) error {
+		if err := checkArgs(args, 1, math.MaxInt32); err != nil {
+			return err
+		}
+		if api.LibraryHandler == nil {
+			return errNotReady
+		}
+		filters, err := findFilters(args)
+		if err != nil {
+			return err
+		}
+		for _, song := range libraryTree("") {
+			ok, err := matches(song, filters)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if !add {
+				writeSong(r, song)
+				continue
+			}
+			if err := c.queue(song.File, -1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// searchCommand returns the command listing the songs found by a search, or adding them to the playlist, if add
+// is true
+func searchCommand(add bool) func(c *client, args []string, r *response) error {
+	return func(c *client, args []string, r *response) error {
+		if err := checkArgs(args, 1, math.MaxInt32); err != nil {
+			return err
+		}
+		if api.SearchHandler == nil {
+			return errNotReady
+		}
+		query, err := searchQuery(args)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(query) == "" {
+			return nil
+		}
+		for _, song := range api.SearchHandler(query, searchLimit) {
+			if !add {
+				writeSong(r, song)
+				continue
+			}
+			if err := c.queue(song.File, -1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,230 @@
+// Package mpd contains a listener speaking a subset of the protocol of the Music Player Daemon, so mpd clients can
+// control the server. It reads the state of the server through the handlers of the api package and changes it with
+// the ssh commands, executed as the user authenticated with the password command.
+package mpd
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/LogicalOverflow/music-sync/logging"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"net"
+	"strings"
+	"time"
+)
+
+var logger = log.GetLogger("mpd")
+
+// protocolVersion is the version of the mpd protocol announced to clients
+const protocolVersion = "0.21.0"
+
+// IdleInterval is the interval in which idle clients check the state of the server for changes
+var IdleInterval = 500 * time.Millisecond
+
+// StartMPD starts the mpd listener at address. Clients authenticate with the api tokens of users as password.
+func StartMPD(address string, users map[string]ssh.UserAuth) {
+	logger.Infof("starting mpd listener at %s", address)
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		logger.Errorf("failed to start mpd listener at %s: %v", address, err)
+		return
+	}
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			logger.Errorf("failed to accept mpd connection: %v", err)
+			return
+		}
+		go serve(conn, users)
+	}
+}
+
+// client is a connected mpd client
+type client struct {
+	conn  net.Conn
+	users map[string]ssh.UserAuth
+	// user is the user authenticated with the password command or empty
+	user string
+	// lines are the request lines received
+	lines chan string
+	// done is closed when the client is disconnected
+	done chan struct{}
+	// seen is the state of the server the client was last notified about by idle
+	seen state
+}
+
+// serve serves the mpd client connected through conn until it disconnects
+func serve(conn net.Conn, users map[string]ssh.UserAuth) {
+	defer conn.Close()
+	logger.Infof("mpd client connected from %s", conn.RemoteAddr())
+
+	c := &client{conn: conn, users: users, lines: make(chan string), done: make(chan struct{}), seen: currentState()}
+	defer close(c.done)
+	go c.readLines()
+
+	if _, err := conn.Write([]byte("OK MPD " + protocolVersion + "\n")); err != nil {
+		return
+	}
+	for line := range c.lines {
+		var out bytes.Buffer
+		var closing bool
+		switch line {
+		case "command_list_begin", "command_list_ok_begin":
+			closing = c.handleList(&out, line == "command_list_ok_begin")
+		case "noidle":
+			// noidle without idle is ignored
+			continue
+		default:
+			closing = c.handleLine(&out, line, 0, false)
+			if !closing && !strings.HasPrefix(out.String(), "ACK ") {
+				out.WriteString("OK\n")
+			}
+		}
+		if _, err := conn.Write(out.Bytes()); err != nil || closing {
+			break
+		}
+	}
+	logger.Infof("mpd client %s disconnected", conn.RemoteAddr())
+}
+
+// readLines sends the lines received to c.lines, until the connection is closed
+func (c *client) readLines() {
+	defer close(c.lines)
+	scanner := bufio.NewScanner(c.conn)
+	for scanner.Scan() {
+		select {
+		case c.lines <- strings.TrimSuffix(scanner.Text(), "\r"):
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// handleList executes the commands of a command list, answering each with list_OK, if listOK is true.
+// It returns true, if the connection is to be closed.
+func (c *client) handleList(out *bytes.Buffer, listOK bool) bool {
+	commands := make([]string, 0)
+	for line := range c.lines {
+		if line == "command_list_end" {
+			for i, command := range commands {
+				before := out.Len()
+				if c.handleLine(out, command, i, true) {
+					return true
+				}
+				if strings.HasPrefix(out.String()[before:], "ACK ") {
+					return false
+				}
+				if listOK {
+					out.WriteString("list_OK\n")
+				}
+			}
+			out.WriteString("OK\n")
+			return false
+		}
+		commands = append(commands, line)
+	}
+	return true
+}
+
+// handleLine executes the command line, which is the command at index listIndex of a command list if inList is
+// true, and writes the response or the ACK to out. It returns true, if the connection is to be closed.
+func (c *client) handleLine(out *bytes.Buffer, line string, listIndex int, inList bool) bool {
+	args, err := parseArgs(line)
+	if err != nil {
+		out.WriteString(ackLine(err, listIndex, ""))
+		return false
+	}
+	if len(args) == 0 {
+		out.WriteString(ackLine(newAckError(ackErrorUnknown, "No command given"), listIndex, ""))
+		return false
+	}
+
+	name := args[0]
+	switch name {
+	case "close":
+		return true
+	case "idle":
+		if inList {
+			out.WriteString(ackLine(newAckError(ackErrorArg, "idle is not allowed in command lists"), listIndex, name))
+			return false
+		}
+		return c.idle(out, args[1:])
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		out.WriteString(ackLine(newAckError(ackErrorUnknown, "unknown command \"%s\"", name), listIndex, name))
+		return false
+	}
+	if !cmd.public && c.user == "" {
+		out.WriteString(ackLine(newAckError(ackErrorPermission, "you don't have permission for \"%s\"", name), listIndex, name))
+		return false
+	}
+	var r response
+	if err := cmd.exec(c, args[1:], &r); err != nil {
+		logger.Debugf("mpd command %s of %s failed: %v", line, c.conn.RemoteAddr(), err)
+		out.WriteString(ackLine(err, listIndex, name))
+		return false
+	}
+	out.Write(r.Bytes())
+	return false
+}
+
+// idle waits until one of the subsystems changes or the client sends noidle and writes the changed subsystems to
+// out. It returns true, if the connection is to be closed.
+func (c *client) idle(out *bytes.Buffer, subsystems []string) bool {
+	if c.user == "" {
+		out.WriteString(ackLine(newAckError(ackErrorPermission, "you don't have permission for \"idle\""), 0, "idle"))
+		return false
+	}
+	ticker := time.NewTicker(IdleInterval)
+	defer ticker.Stop()
+	for {
+		changed := c.changes(subsystems)
+		for _, subsystem := range changed {
+			out.WriteString("changed: " + subsystem + "\n")
+		}
+		if 0 < len(changed) {
+			return false
+		}
+
+		select {
+		case line, ok := <-c.lines:
+			if !ok {
+				return true
+			}
+			if line != "noidle" {
+				logger.Infof("mpd client %s sent %s while idle, closing the connection", c.conn.RemoteAddr(), line)
+				return true
+			}
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// changes returns the subsystems out of subsystems (or out of all, if subsystems is empty), which changed since the
+// client was last notified about them, and marks them as notified
+func (c *client) changes(subsystems []string) []string {
+	current := currentState()
+	changed := make([]string, 0)
+	for _, subsystem := range idleSubsystems {
+		if 0 < len(subsystems) && !containsString(subsystems, subsystem) {
+			continue
+		}
+		if c.seen.changed(current, subsystem) {
+			changed = append(changed, subsystem)
+			c.seen.update(current, subsystem)
+		}
+	}
+	return changed
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
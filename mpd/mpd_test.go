@@ -0,0 +1,366 @@
+package mpd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/api"
+	"github.com/LogicalOverflow/music-sync/logging"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"github.com/LogicalOverflow/music-sync/ssh/sshtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testToken = "test-token"
+
+var testUsers = map[string]ssh.UserAuth{"mpd-user": {Token: testToken}}
+
+var (
+	// testStatus is the status returned by the status handler of the tests
+	testStatus api.Status
+	// queuedPlaylist is the playlist returned by the playlist handler of the tests, the queue command adds to it
+	queuedPlaylist []api.PlaylistEntry
+	testMutex      sync.Mutex
+)
+
+// testPlaylist is the playlist of the tests, its ids differ from the positions of the songs
+var testPlaylist = []api.PlaylistEntry{
+	{Song: api.Song{File: "a.mp3", Title: "A", Artist: "Artist", Duration: 120}, ID: 10},
+	{Song: api.Song{File: "b.mp3", Title: "B", Artist: "Artist", Year: 2019, Track: 2, Duration: 180}, ID: 11},
+	{Song: api.Song{File: "c.mp3"}, ID: 12, AutoQueued: true},
+}
+
+// queuedEntryID is the id of the first entry added by the queue command of the tests
+const queuedEntryID = 20
+
+// queueTestSong adds the song of the arguments of a queue command to queuedPlaylist, at the position in the
+// arguments if there is one
+func queueTestSong(args []string) {
+	pos := len(queuedPlaylist)
+	if len(args) == 2 {
+		pos, _ = strconv.Atoi(args[1])
+	}
+	entry := api.PlaylistEntry{Song: api.Song{File: args[0]}, ID: queuedEntryID + len(queuedPlaylist) - len(testPlaylist)}
+	queuedPlaylist = append(queuedPlaylist[:pos], append([]api.PlaylistEntry{entry}, queuedPlaylist[pos:]...)...)
+}
+
+// resetTestPlaylist resets the playlist returned by the playlist handler of the tests to testPlaylist
+func resetTestPlaylist() {
+	queuedPlaylist = append([]api.PlaylistEntry{}, testPlaylist...)
+}
+
+var testLibrary = map[string]api.Library{
+	"":        {Dirs: []string{"dir"}, Songs: []api.Song{{File: "a.mp3", Title: "A", Artist: "A"}}},
+	"dir":     {Dirs: []string{"dir/sub"}, Songs: []api.Song{{File: "dir/d.mp3"}}},
+	"dir/sub": {Songs: []api.Song{{File: "dir/sub/e[1].mp3", Artist: "AB", Album: "it's", Track: 2}}},
+}
+
+// commandCalls records the calls of the commands run by the tests. The queue command adds to queuedPlaylist,
+// unless the song is missing.mp3.
+var commandCalls = sshtest.RegisterCommands(func(user, name string, args []string) (string, bool) {
+	testMutex.Lock()
+	defer testMutex.Unlock()
+	if len(args) == 1 && args[0] == "missing.mp3" {
+		return "no song matches the glob pattern missing.mp3", true
+	}
+	if name == "queue" {
+		queueTestSong(args)
+	}
+	return "", true
+}, "queue", "remove", "move", "jump", "seek", "pause", "resume", "volume")
+
+func init() {
+	log.DefaultCutoffLevel = log.LevelOff
+}
+
+// setTestHandlers sets the handlers of the api package to return the test state
+func setTestHandlers() {
+	testMutex.Lock()
+	defer testMutex.Unlock()
+	commandCalls.Reset()
+	resetTestPlaylist()
+	testStatus = api.Status{
+		Playing:  true,
+		Position: 1,
+		Song:     &testPlaylist[1].Song,
+		Elapsed:  12.5,
+		Length:   180,
+		Volume:   0.3,
+		Speed:    1,
+	}
+	api.StatusHandler = func() api.Status {
+		testMutex.Lock()
+		defer testMutex.Unlock()
+		return testStatus
+	}
+	api.PlaylistHandler = func() []api.PlaylistEntry {
+		testMutex.Lock()
+		defer testMutex.Unlock()
+		return append([]api.PlaylistEntry{}, queuedPlaylist...)
+	}
+	api.LibraryHandler = func(dir string) api.Library { return testLibrary[dir] }
+	api.SearchHandler = func(query string, limit int) []api.Song {
+		if query == "b" || query == "artist b" {
+			return []api.Song{testPlaylist[1].Song}
+		}
+		return []api.Song{}
+	}
+	api.PlayerHandler = func() api.PlayerConfig { return api.PlayerConfig{SampleRate: 44100} }
+}
+
+func resetHandlers() {
+	api.StatusHandler, api.PlaylistHandler, api.LibraryHandler, api.SearchHandler, api.PlayerHandler = nil, nil, nil, nil, nil
+}
+
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// connect connects a client to a new connection served by serve and authenticates it, if authenticate is true
+func connect(t *testing.T, authenticate bool) *testClient {
+	server, conn := net.Pipe()
+	go serve(server, testUsers)
+	c := &testClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+	greeting, err := c.r.ReadString('\n')
+	require.NoError(t, err, "reading the greeting failed")
+	assert.Equal(t, "OK MPD "+protocolVersion+"\n", greeting, "the server sent the wrong greeting")
+	if authenticate {
+		require.Equal(t, []string{"OK"}, c.request("password "+testToken), "authenticating failed")
+	}
+	return c
+}
+
+// request sends the lines and returns the lines of the response, up to and including the OK or ACK line
+func (c *testClient) request(lines ...string) []string {
+	_, err := c.conn.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	require.NoError(c.t, err, "sending %v failed", lines)
+	response := make([]string, 0)
+	for {
+		line, err := c.r.ReadString('\n')
+		require.NoError(c.t, err, "reading the response to %v failed", lines)
+		line = strings.TrimSuffix(line, "\n")
+		response = append(response, line)
+		if line == "OK" || strings.HasPrefix(line, "ACK ") {
+			return response
+		}
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	setTestHandlers()
+	defer resetHandlers()
+	c := connect(t, false)
+	defer c.conn.Close()
+
+	assert.Equal(t, []string{"OK"}, c.request("ping"), "ping failed without authentication")
+	assert.Equal(t, []string{`ACK [4@0] {status} you don't have permission for "status"`}, c.request("status"),
+		"status was allowed without authentication")
+	assert.Equal(t, []string{"ACK [3@0] {password} incorrect password"}, c.request("password wrong"),
+		"a wrong password was accepted")
+	assert.Contains(t, c.request("notcommands"), "command: status", "notcommands did not list status")
+	assert.Equal(t, []string{"OK"}, c.request("password "+testToken), "the password was not accepted")
+	assert.Contains(t, c.request("commands"), "command: status", "commands did not list status after authenticating")
+	assert.Contains(t, c.request("status"), "state: play", "status failed after authenticating")
+}
+
+func TestStateCommands(t *testing.T) {
+	setTestHandlers()
+	defer resetHandlers()
+	c := connect(t, true)
+	defer c.conn.Close()
+
+	assert.Equal(t, []string{
+		"volume: 30", "repeat: 0", "random: 0", "single: 0", "consume: 0",
+		"playlist: " + fmt.Sprint(playlistVersion(testPlaylist)), "playlistlength: 3", "state: play",
+		"song: 1", "songid: 11", "nextsong: 2", "nextsongid: 12", "time: 12:180", "elapsed: 12.500", "duration: 180.000",
+		"audio: 44100:f:2", "OK",
+	}, c.request("status"), "status returned the wrong status")
+
+	songB := []string{"file: b.mp3", "Title: B", "Artist: Artist", "Date: 2019", "Track: 2", "Time: 180", "duration: 180.000", "Pos: 1", "Id: 11"}
+	assert.Equal(t, append(songB, "OK"), c.request("currentsong"), "currentsong returned the wrong song")
+	assert.Equal(t, append(append(songB, "file: c.mp3", "Pos: 2", "Id: 12"), "OK"), c.request("playlistinfo 1:"),
+		"playlistinfo returned the wrong songs")
+	assert.Equal(t, append(songB, "OK"), c.request("playlistid 11"), "playlistid returned the wrong song")
+	assert.Equal(t, []string{"ACK [50@0] {playlistid} No such song"}, c.request("playlistid 1"),
+		"playlistid accepted an id not in the playlist")
+	assert.Equal(t, []string{"ACK [50@0] {playlistinfo} No such song"}, c.request("playlistinfo 3"),
+		"playlistinfo accepted a position outside the playlist")
+	assert.Equal(t, []string{"cpos: 0", "Id: 10", "cpos: 1", "Id: 11", "cpos: 2", "Id: 12", "OK"}, c.request("plchangesposid 0"),
+		"plchangesposid returned the wrong positions")
+
+	assert.Equal(t, []string{"directory: dir/sub", "file: dir/d.mp3", "OK"}, c.request("lsinfo dir"),
+		"lsinfo returned the wrong directory content")
+	assert.Equal(t, []string{"ACK [50@0] {lsinfo} No such directory"}, c.request("lsinfo ../secret"),
+		"lsinfo accepted a directory outside the music directory")
+
+	found := []string{"file: b.mp3", "Title: B", "Artist: Artist", "Date: 2019", "Track: 2", "Time: 180", "duration: 180.000", "OK"}
+	assert.Equal(t, found, c.request("search artist artist title b"), "search with tag value pairs found the wrong songs")
+	assert.Equal(t, found, c.request(`search "((artist == 'artist') AND (title == \"b\"))"`),
+		"search with a filter expression found the wrong songs")
+
+	songA := []string{"file: a.mp3", "Title: A", "Artist: A"}
+	songE := []string{"file: dir/sub/e[1].mp3", "Artist: AB", "Album: it's", "Track: 2"}
+	for _, fc := range []struct {
+		request string
+		found   []string
+	}{
+		{request: `find artist "A"`, found: songA},
+		{request: `find artist "AB"`, found: songE},
+		{request: `find artist "a"`},
+		{request: `find artist "A" title "A"`, found: songA},
+		{request: `find artist "A" title "B"`},
+		{request: `find "(artist == 'A')"`, found: songA},
+		{request: `find "((artist == \"AB\") AND (album == 'it\\'s'))"`, found: songE},
+		{request: `find any "2"`, found: songE},
+		{request: `find file "dir/d.mp3"`, found: []string{"file: dir/d.mp3"}},
+	} {
+		assert.Equal(t, append(fc.found, "OK"), c.request(fc.request), "%s found the wrong songs", fc.request)
+	}
+	assert.Equal(t, []string{"ACK [2@0] {find} Unknown filter type 'unknown'"}, c.request(`find unknown "A"`),
+		"find accepted an unknown tag")
+	assert.Equal(t, []string{"ACK [2@0] {find} unsupported filter expression"}, c.request(`find "(artist != 'A')"`),
+		"find accepted an unsupported filter expression")
+
+	testMutex.Lock()
+	testStatus.Song = nil
+	testMutex.Unlock()
+	assert.Contains(t, c.request("status"), "state: stop", "status without a song did not report playback as stopped")
+}
+
+func TestControlCommands(t *testing.T) {
+	setTestHandlers()
+	defer resetHandlers()
+	c := connect(t, true)
+	defer c.conn.Close()
+
+	cases := []struct {
+		request  string
+		calls    []string
+		response []string
+	}{
+		{request: "add dir", calls: []string{"queue dir/d.mp3", `queue dir/sub/e\[1].mp3`}},
+		{request: "add song.mp3 2", calls: []string{"queue song.mp3 2"}},
+		{request: "addid http://host/stream?id=1", calls: []string{"queue http://host/stream?id=1 3"}, response: []string{"Id: 20"}},
+		{request: "addid song.mp3 1", calls: []string{"queue song.mp3 1"}, response: []string{"Id: 20"}},
+		{request: "addid song.mp3 4", response: []string{"ACK [2@0] {addid} Bad song index"}},
+		{request: "add missing.mp3", response: []string{"ACK [50@0] {add} No such song"}, calls: []string{"queue missing.mp3"}},
+		{request: "delete 0:2", calls: []string{"remove 1", "remove 0"}},
+		{request: "delete 3", response: []string{"ACK [50@0] {delete} No such song"}},
+		{request: "deleteid 12", calls: []string{"remove 2"}},
+		{request: "deleteid 2", response: []string{"ACK [50@0] {deleteid} No such song"}},
+		{request: "deleteid -1", response: []string{"ACK [50@0] {deleteid} No such song"}},
+		{request: "clear", calls: []string{"remove 2", "remove 1", "remove 0"}},
+		{request: "move 0:2 1", calls: []string{"move 0 2", "move 0 2"}},
+		{request: "move 2 0", calls: []string{"move 2 0"}},
+		{request: "move 3 0", response: []string{"ACK [50@0] {move} No such song"}},
+		{request: "move 1:3 2", response: []string{"ACK [50@0] {move} No such song"}},
+		{request: "moveid 11 1"},
+		{request: "moveid 10 2", calls: []string{"move 0 2"}},
+		{request: "moveid 1 1", response: []string{"ACK [50@0] {moveid} No such song"}},
+		{request: "moveid 10 3", response: []string{"ACK [50@0] {moveid} No such song"}},
+		{request: "play 2", calls: []string{"jump 2"}},
+		{request: "play 3", response: []string{"ACK [50@0] {play} No such song"}},
+		{request: "playid 12", calls: []string{"jump 2"}},
+		{request: "playid 2", response: []string{"ACK [50@0] {playid} No such song"}},
+		{request: "pause", calls: []string{"pause"}},
+		{request: "pause 0", calls: []string{"resume"}},
+		{request: "stop", calls: []string{"pause"}},
+		{request: "next", calls: []string{"jump 2"}},
+		{request: "previous", calls: []string{"jump 0"}},
+		{request: "seek 1 30", calls: []string{"seek 30"}},
+		{request: "seekid 12 30.5", calls: []string{"jump 2", "seek 30.5"}},
+		{request: "seekid 2 30.5", response: []string{"ACK [50@0] {seekid} No such song"}},
+		{request: "seek -1 30", response: []string{"ACK [50@0] {seek} No such song"}},
+		{request: "seekcur +5", calls: []string{"seek +5"}},
+		{request: "seekcur -5", calls: []string{"seek -5"}},
+		{request: "seekcur 12.5", calls: []string{"seek 12.5"}},
+		{request: "setvol 50", calls: []string{"volume 0.5"}},
+		{request: "setvol 150", response: []string{"ACK [2@0] {setvol} Invalid volume value"}},
+		{request: "volume -10", calls: []string{"volume 0.2"}},
+		{request: "searchadd artist b", calls: []string{"queue b.mp3"}},
+		{request: `findadd artist "A"`, calls: []string{"queue a.mp3"}},
+		{request: "seek 1", response: []string{"ACK [2@0] {seek} wrong number of arguments"}},
+		{request: "unknown", response: []string{`ACK [5@0] {unknown} unknown command "unknown"`}},
+	}
+	for _, cc := range cases {
+		testMutex.Lock()
+		commandCalls.Reset()
+		resetTestPlaylist()
+		testMutex.Unlock()
+
+		response := cc.response
+		if response == nil || !strings.HasPrefix(response[len(response)-1], "ACK ") {
+			response = append(response, "OK")
+		}
+		assert.Equal(t, response, c.request(cc.request), "%s returned the wrong response", cc.request)
+
+		var calls []string
+		for _, call := range cc.calls {
+			calls = append(calls, "mpd-user "+call)
+		}
+		assert.Equal(t, calls, commandCalls.Calls(), "%s ran the wrong commands", cc.request)
+	}
+}
+
+func TestCommandLists(t *testing.T) {
+	setTestHandlers()
+	defer resetHandlers()
+	c := connect(t, true)
+	defer c.conn.Close()
+
+	assert.Equal(t, []string{"list_OK", "Id: 20", "list_OK", "OK"},
+		c.request("command_list_ok_begin", "ping", "addid song.mp3", "command_list_end"),
+		"command_list_ok_begin returned the wrong response")
+	assert.Equal(t, []string{"Id: 21", "OK"}, c.request("command_list_begin", "ping", "addid song.mp3", "command_list_end"),
+		"command_list_begin returned the wrong response")
+	assert.Equal(t, []string{"list_OK", `ACK [5@1] {unknown} unknown command "unknown"`},
+		c.request("command_list_ok_begin", "ping", "unknown", "ping", "command_list_end"),
+		"command lists did not stop at the first error")
+	assert.Equal(t, []string{"ACK [2@0] {idle} idle is not allowed in command lists"},
+		c.request("command_list_begin", "idle", "command_list_end"), "idle was allowed in a command list")
+}
+
+func TestIdle(t *testing.T) {
+	setTestHandlers()
+	defer resetHandlers()
+	defer func(interval time.Duration) { IdleInterval = interval }(IdleInterval)
+	IdleInterval = 10 * time.Millisecond
+	// elapsed stays the same, which would be reported as a seek while playing
+	testMutex.Lock()
+	testStatus.Playing = false
+	testMutex.Unlock()
+	c := connect(t, true)
+	defer c.conn.Close()
+
+	assert.Equal(t, []string{"OK"}, c.request("idle", "noidle"), "noidle did not end idle")
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		testMutex.Lock()
+		testStatus.Volume = 0.5
+		testMutex.Unlock()
+	}()
+	assert.Equal(t, []string{"changed: mixer", "OK"}, c.request("idle"), "idle did not report the volume change")
+
+	testMutex.Lock()
+	testStatus.Volume, testStatus.Position = 0.6, 2
+	testMutex.Unlock()
+	assert.Equal(t, []string{"changed: player", "OK"}, c.request("idle player"), "idle did not report only the player change")
+	assert.Equal(t, []string{"changed: mixer", "OK"}, c.request("idle"), "idle did not report the volume change later")
+}
+
+func TestClose(t *testing.T) {
+	c := connect(t, false)
+	_, err := c.conn.Write([]byte("close\n"))
+	require.NoError(t, err, "sending close failed")
+	_, err = c.r.ReadString('\n')
+	assert.Error(t, err, "the connection was not closed")
+}
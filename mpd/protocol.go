@@ -0,0 +1,152 @@
+package mpd
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// the error codes of ACK responses
+const (
+	ackErrorArg        = 2
+	ackErrorPassword   = 3
+	ackErrorPermission = 4
+	ackErrorUnknown    = 5
+	ackErrorNoExist    = 50
+	ackErrorSystem     = 52
+)
+
+// ackError is an error reported to the client as ACK response
+type ackError struct {
+	code    int
+	message string
+}
+
+func (e ackError) Error() string { return e.message }
+
+func newAckError(code int, format string, a ...interface{}) ackError {
+	return ackError{code: code, message: fmt.Sprintf(format, a...)}
+}
+
+// ackLine returns the ACK response reporting err of the command at index listIndex of a command list
+func ackLine(err error, listIndex int, command string) string {
+	code := ackErrorSystem
+	if ack, ok := err.(ackError); ok {
+		code = ack.code
+	}
+	return fmt.Sprintf("ACK [%d@%d] {%s} %s\n", code, listIndex, command, strings.Replace(err.Error(), "\n", " ", -1))
+}
+
+// parseArgs splits a request line into its arguments. Arguments are separated by spaces and can be quoted with
+// double quotes, inside which backslashes escape the next character.
+func parseArgs(line string) ([]string, error) {
+	args := make([]string, 0)
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == ' ' || line[i] == '\t':
+			i++
+		case line[i] == '"':
+			var arg bytes.Buffer
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' {
+					i++
+					if len(line) <= i {
+						break
+					}
+				}
+				arg.WriteByte(line[i])
+			}
+			if len(line) <= i {
+				return nil, newAckError(ackErrorArg, "missing closing '\"'")
+			}
+			args = append(args, arg.String())
+			i++
+		default:
+			end := strings.IndexAny(line[i:], " \t")
+			if end < 0 {
+				end = len(line) - i
+			}
+			args = append(args, line[i:i+end])
+			i += end
+		}
+	}
+	return args, nil
+}
+
+// parseInt parses the integer argument arg named name
+func parseInt(name, arg string) (int, error) {
+	v, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, newAckError(ackErrorArg, "invalid %s '%s'", name, arg)
+	}
+	return v, nil
+}
+
+// parseFloat parses the number argument arg named name
+func parseFloat(name, arg string) (float64, error) {
+	v, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, newAckError(ackErrorArg, "invalid %s '%s'", name, arg)
+	}
+	return v, nil
+}
+
+// parseRange parses the argument arg, a position or a range start:end (with an optional end), into the range of
+// positions [start, end), limited to length. The start has to be a position before length.
+func parseRange(arg string, length int) (int, int, error) {
+	parts := strings.SplitN(arg, ":", 2)
+	start, err := strconv.Atoi(parts[0])
+	if err != nil || start < 0 {
+		return 0, 0, newAckError(ackErrorArg, "invalid range '%s'", arg)
+	}
+	end := start + 1
+	if len(parts) == 2 {
+		end = length
+		if parts[1] != "" {
+			if end, err = strconv.Atoi(parts[1]); err != nil || end < start {
+				return 0, 0, newAckError(ackErrorArg, "invalid range '%s'", arg)
+			}
+		}
+	}
+	if length <= start {
+		return 0, 0, errNoSuchSong
+	}
+	if length < end {
+		end = length
+	}
+	if end <= start {
+		return 0, 0, newAckError(ackErrorArg, "bad song index")
+	}
+	return start, end, nil
+}
+
+// response is the response of a command, a list of key value pairs
+type response struct {
+	bytes.Buffer
+}
+
+// add adds the key value pair
+func (r *response) add(key string, value interface{}) {
+	fmt.Fprintf(r, "%s: %v\n", key, value)
+}
+
+// addNonZero adds the key value pair, if value is not the zero value of its type
+func (r *response) addNonZero(key string, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return
+		}
+	case int:
+		if v == 0 {
+			return
+		}
+	case float64:
+		if v == 0 {
+			return
+		}
+	}
+	r.add(key, value)
+}
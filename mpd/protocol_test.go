@@ -0,0 +1,77 @@
+package mpd
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	cases := []struct {
+		line  string
+		args  []string
+		valid bool
+	}{
+		{line: "status", args: []string{"status"}, valid: true},
+		{line: "  seek  1 30 ", args: []string{"seek", "1", "30"}, valid: true},
+		{line: `add "dir/song name.mp3"`, args: []string{"add", "dir/song name.mp3"}, valid: true},
+		{line: `search "(artist == \"a \\\"b\\\"\")"`, args: []string{"search", `(artist == "a \"b\"")`}, valid: true},
+		{line: `add ""`, args: []string{"add", ""}, valid: true},
+		{line: "", args: []string{}, valid: true},
+		{line: `add "unterminated`, valid: false},
+		{line: `add "escaped end\"`, valid: false},
+	}
+	for _, c := range cases {
+		args, err := parseArgs(c.line)
+		if c.valid {
+			assert.NoError(t, err, "parseArgs returned an error for %s", c.line)
+			assert.Equal(t, c.args, args, "parseArgs returned the wrong arguments for %s", c.line)
+		} else {
+			assert.Error(t, err, "parseArgs did not return an error for %s", c.line)
+		}
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		arg        string
+		length     int
+		start, end int
+		valid      bool
+	}{
+		{arg: "2", length: 5, start: 2, end: 3, valid: true},
+		{arg: "1:3", length: 5, start: 1, end: 3, valid: true},
+		{arg: "1:", length: 5, start: 1, end: 5, valid: true},
+		{arg: "3:10", length: 5, start: 3, end: 5, valid: true},
+		{arg: "5", length: 5, valid: false},
+		{arg: "3:1", length: 5, valid: false},
+		{arg: "-1", length: 5, valid: false},
+		{arg: "a:b", length: 5, valid: false},
+	}
+	for _, c := range cases {
+		start, end, err := parseRange(c.arg, c.length)
+		if c.valid {
+			assert.NoError(t, err, "parseRange returned an error for %s", c.arg)
+			assert.Equal(t, [2]int{c.start, c.end}, [2]int{start, end}, "parseRange returned the wrong range for %s", c.arg)
+		} else {
+			assert.Error(t, err, "parseRange did not return an error for %s", c.arg)
+		}
+	}
+}
+
+func TestAckLine(t *testing.T) {
+	assert.Equal(t, "ACK [50@2] {add} No such song\n", ackLine(newAckError(ackErrorNoExist, "No such song"), 2, "add"),
+		"ackLine returned the wrong line for an ackError")
+	assert.Equal(t, "ACK [52@0] {status} failed: twice\n", ackLine(fmt.Errorf("failed:\ntwice"), 0, "status"),
+		"ackLine returned the wrong line for another error")
+}
+
+func TestResponse_addNonZero(t *testing.T) {
+	var r response
+	r.addNonZero("Title", "")
+	r.addNonZero("Track", 0)
+	r.addNonZero("duration", 0.0)
+	r.addNonZero("Artist", "artist")
+	r.addNonZero("Date", 2019)
+	assert.Equal(t, "Artist: artist\nDate: 2019\n", r.String(), "addNonZero added the wrong pairs")
+}
@@ -0,0 +1,68 @@
+package mpd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/api"
+	"math"
+	"time"
+)
+
+// idleSubsystems are the subsystems reported by idle, in the order they are reported
+var idleSubsystems = []string{"playlist", "player", "mixer"}
+
+// seekThreshold is the difference in seconds between the elapsed time of the current song and the elapsed time
+// expected, above which the difference is reported as a seek
+const seekThreshold = 2
+
+// state is the state of the server as far as idle is concerned
+type state struct {
+	// subsystems holds a description of the state of each subsystem, which changes when the subsystem changes
+	subsystems map[string]string
+	// elapsed is the elapsed time of the current song at time, used to detect seeks
+	elapsed float64
+	playing bool
+	time    time.Time
+}
+
+// currentState returns the current state of the server. It is empty, if the server is not ready.
+func currentState() state {
+	s := state{subsystems: make(map[string]string), time: time.Now()}
+	if api.StatusHandler == nil || api.PlaylistHandler == nil {
+		return s
+	}
+	status := api.StatusHandler()
+	playlist, _ := json.Marshal(api.PlaylistHandler())
+	song := ""
+	if status.Song != nil {
+		song = status.Song.File
+	}
+	s.subsystems["playlist"] = string(playlist)
+	s.subsystems["player"] = fmt.Sprint(status.Playing, status.Position, song)
+	s.subsystems["mixer"] = fmt.Sprint(status.Volume)
+	s.elapsed, s.playing = status.Elapsed, status.Playing
+	return s
+}
+
+// changed returns true, if subsystem changed from s to current
+func (s state) changed(current state, subsystem string) bool {
+	if s.subsystems[subsystem] != current.subsystems[subsystem] {
+		return true
+	}
+	if subsystem != "player" {
+		return false
+	}
+	expected := s.elapsed
+	if s.playing {
+		expected += current.time.Sub(s.time).Seconds()
+	}
+	return seekThreshold < math.Abs(current.elapsed-expected)
+}
+
+// update updates subsystem of s to current
+func (s *state) update(current state, subsystem string) {
+	s.subsystems[subsystem] = current.subsystems[subsystem]
+	if subsystem == "player" {
+		s.elapsed, s.playing, s.time = current.elapsed, current.playing, current.time
+	}
+}
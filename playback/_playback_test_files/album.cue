@@ -0,0 +1,15 @@
+REM GENRE Rock
+PERFORMER "The Performers"
+TITLE "Live Album"
+FILE "okay.mp3" MP3
+  TRACK 01 AUDIO
+    TITLE "Opening"
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    TITLE "Second Song"
+    PERFORMER "A Guest"
+    INDEX 00 00:03:50
+    INDEX 01 00:04:00
+  TRACK 03 AUDIO
+    TITLE "Encore"
+    INDEX 01 00:08:30
@@ -0,0 +1,133 @@
+package playback
+
+import (
+	"fmt"
+	"github.com/faiface/beep"
+	"math"
+)
+
+const announcementQueueSize = 8
+
+// announcementDuckGain is the gain of the music while an announcement plays (-12 dB)
+const announcementDuckGain = 0.25
+
+// announcement is a clip mixed into the stream
+type announcement struct {
+	clip beep.StreamSeekCloser
+	buf  [][2]float64
+	pos  int
+	done bool
+}
+
+func newAnnouncement(clip beep.StreamSeekCloser) *announcement {
+	return &announcement{clip: clip, buf: make([][2]float64, 0, streamerBufferSize)}
+}
+
+// next returns the next sample of the clip and false, if the clip ended
+func (a *announcement) next() ([2]float64, bool) {
+	if a.pos == len(a.buf) {
+		if a.done {
+			return [2]float64{}, false
+		}
+		n, ok := a.clip.Stream(a.buf[:cap(a.buf)])
+		a.buf, a.pos = a.buf[:n], 0
+		if !ok || n < cap(a.buf) {
+			a.done = true
+		}
+		if n == 0 {
+			return [2]float64{}, false
+		}
+	}
+	a.pos++
+	return a.buf[a.pos-1], true
+}
+
+// ended returns true, if all samples of the clip were returned by next
+func (a *announcement) ended() bool {
+	return a.done && a.pos == len(a.buf)
+}
+
+// mixSample adds the clip sample to the music sample. Nan samples (silence) are replaced by the clip sample.
+func mixSample(music, clip float64) float64 {
+	if math.IsNaN(music) {
+		return clip
+	}
+	return music + clip
+}
+
+// Announce queues the clip filename to be mixed into the stream on top of the current song.
+// While the clip plays, the song is ducked: it keeps playing at announcementDuckGain and recovers after the clip.
+func (pl *Playlist) Announce(filename string) error {
+	clip, err := getStreamer(filename)
+	if err != nil {
+		return err
+	}
+	select {
+	case pl.announcements <- clip:
+		return nil
+	default:
+		clip.Close()
+		return fmt.Errorf("too many announcements queued")
+	}
+}
+
+// startPendingAnnouncement starts mixing the next queued announcement into the stream, if none is playing.
+func (pl *Playlist) startPendingAnnouncement() bool {
+	if pl.announcement != nil {
+		return false
+	}
+	select {
+	case clip := <-pl.announcements:
+		pl.announcement = newAnnouncement(clip)
+		if pl.announcementHandler != nil {
+			go pl.announcementHandler(pl.sampleIndexWrite, int64(clip.Len()))
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// mixAnnouncement mixes the playing announcement into the sample
+func (pl *Playlist) mixAnnouncement(low, high float64) (float64, float64) {
+	if pl.announcement == nil {
+		return low, high
+	}
+	s, ok := pl.announcement.next()
+	if !ok || pl.announcement.ended() {
+		pl.announcement.clip.Close()
+		pl.announcement = nil
+	}
+	if !ok {
+		return low, high
+	}
+	return mixSample(low, s[0]), mixSample(high, s[1])
+}
+
+// duck lowers the music sample to announcementDuckGain while an announcement plays. The gain changes over
+// fadeLength samples, so the music ducks and recovers smoothly.
+func (pl *Playlist) duck(low, high float64) (float64, float64) {
+	target := 0.0
+	if pl.announcement != nil {
+		target = 1 - announcementDuckGain
+	}
+	if pl.duckDepth != target {
+		step := 1 - announcementDuckGain
+		if 0 < pl.fadeLength {
+			step /= float64(pl.fadeLength)
+		}
+		if pl.duckDepth < target {
+			pl.duckDepth = math.Min(target, pl.duckDepth+step)
+		} else {
+			pl.duckDepth = math.Max(target, pl.duckDepth-step)
+		}
+	}
+	gain := 1 - pl.duckDepth
+	return low * gain, high * gain
+}
+
+// SetAnnouncementHandler sets the announcement handler, which is called every time an announcement starts.
+// It is passed the first sample index and the length of the announcement.
+func (pl *Playlist) SetAnnouncementHandler(ah func(startSampleIndex uint64, length int64)) {
+	pl.announcementHandler = ah
+}
@@ -0,0 +1,90 @@
+package playback
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+type announcementCall struct {
+	start  uint64
+	length int64
+}
+
+func storingAnnouncementHandler(calls chan announcementCall) func(uint64, int64) {
+	return func(start uint64, length int64) {
+		calls <- announcementCall{start: start, length: length}
+	}
+}
+
+// testClip is a closed constantTestStreamer, which can be closed again
+type testClip struct {
+	*testStreamer
+}
+
+func (tc testClip) Close() error { return nil }
+
+func TestMixSample(t *testing.T) {
+	assert.Equal(t, 0.75, mixSample(0.5, 0.25), "mixSample did not add the samples")
+	assert.Equal(t, 0.25, mixSample(math.NaN(), 0.25), "mixSample did not replace the nan sample")
+}
+
+func TestPlaylist_pushStreamerAnnouncement(t *testing.T) {
+	pl := NewPlaylist(4*streamerBufferSize, []string{}, 0)
+	calls := make(chan announcementCall, 1)
+	pl.SetAnnouncementHandler(storingAnnouncementHandler(calls))
+	pl.announcements <- testClip{constantTestStreamer([2]float64{0.25, -0.25}, 100)}
+
+	pl.SetPlaying(true)
+	pl.pushStreamer(constantTestStreamer([2]float64{0.5, 0.5}, 2*streamerBufferSize))
+
+	assert.Equal(t, announcementCall{start: 0, length: 100}, <-calls, "AnnouncementHandler called with the wrong arguments")
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, 0.375, <-pl.low, "%d-th low sample is not the announcement over the ducked song when using pushStreamer", i)
+		assert.Equal(t, -0.125, <-pl.high, "%d-th high sample is not the announcement over the ducked song when using pushStreamer", i)
+	}
+	for i := 100; i < 2*streamerBufferSize; i++ {
+		assert.Equal(t, 0.5, <-pl.low, "%d-th low sample of the song is wrong after the announcement", i)
+		assert.Equal(t, 0.5, <-pl.high, "%d-th high sample of the song is wrong after the announcement", i)
+	}
+	assert.Equal(t, 0, len(pl.low), "pushStreamer pushed too many samples with an announcement")
+	assert.Nil(t, pl.announcement, "pushStreamer did not end the announcement")
+}
+
+func TestPlaylist_pushNanSamplesAnnouncement(t *testing.T) {
+	pl := NewPlaylist(64, []string{}, 0)
+	calls := make(chan announcementCall, 1)
+	pl.SetAnnouncementHandler(storingAnnouncementHandler(calls))
+	pl.sampleIndexWrite = 10
+	pl.announcements <- testClip{constantTestStreamer([2]float64{0.25, -0.25}, 16)}
+
+	pl.pushNanSamples(32)
+
+	assert.Equal(t, announcementCall{start: 10, length: 16}, <-calls, "AnnouncementHandler called with the wrong arguments")
+	for i := 0; i < 16; i++ {
+		assert.Equal(t, 0.25, <-pl.low, "%d-th low sample is not the announcement when using pushNanSamples", i)
+		assert.Equal(t, -0.25, <-pl.high, "%d-th high sample is not the announcement when using pushNanSamples", i)
+	}
+	for i := 16; i < 32; i++ {
+		assert.True(t, math.IsNaN(<-pl.low), "%d-th low sample is not nan after the announcement", i)
+		assert.True(t, math.IsNaN(<-pl.high), "%d-th high sample is not nan after the announcement", i)
+	}
+}
+
+func TestPlaylist_duck(t *testing.T) {
+	pl := NewPlaylist(64, []string{}, 0)
+	pl.fadeLength = 4
+	pl.announcement = newAnnouncement(testClip{constantTestStreamer([2]float64{0, 0}, 8)})
+
+	levels := make([]float64, 0)
+	for i := 0; i < 16; i++ {
+		pl.pushSample(1, -1)
+		low, high := <-pl.low, <-pl.high
+		assert.Equal(t, -low, high, "%d-th sample was ducked differently on the two channels", i)
+		levels = append(levels, low)
+	}
+	assert.Equal(t, []float64{0.8125, 0.625, 0.4375, 0.25, 0.25, 0.25, 0.25, 0.25,
+		0.4375, 0.625, 0.8125, 1, 1, 1, 1, 1}, levels, "the music was not ducked to -12 dB while the announcement played")
+	low, _ := pl.duck(math.NaN(), 0)
+	assert.True(t, math.IsNaN(low), "duck changed a nan sample")
+}
@@ -0,0 +1,275 @@
+package playback
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/util"
+	"github.com/faiface/beep"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	cueExt          = ".cue"
+	cueFramesPerSec = 75
+)
+
+// CueSheet is a cue sheet describing the tracks of single-file albums
+type CueSheet struct {
+	Title     string
+	Performer string
+	Genre     string
+	Tracks    []CueTrack
+}
+
+// CueTrack is a track of a cue sheet. File is relative to the cue sheet and Start is the position of INDEX 01
+// in cue frames (75 per second).
+type CueTrack struct {
+	Number    int
+	Title     string
+	Performer string
+	File      string
+	Start     int
+}
+
+// ParseCueSheet parses a cue sheet
+func ParseCueSheet(r io.Reader) (*CueSheet, error) {
+	sheet := &CueSheet{Tracks: make([]CueTrack, 0)}
+	var track *CueTrack
+	file := ""
+	hasIndex := true
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := splitCueLine(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if len(fields) == 0 {
+			continue
+		}
+		command, args := strings.ToUpper(fields[0]), fields[1:]
+		switch {
+		case command == "FILE" && 0 < len(args):
+			file = args[0]
+		case command == "TRACK" && 0 < len(args):
+			if !hasIndex {
+				return nil, fmt.Errorf("track %d has no INDEX 01", track.Number)
+			}
+			n, err := strconv.Atoi(args[0])
+			if err != nil || file == "" {
+				return nil, fmt.Errorf("invalid track in line %d", line)
+			}
+			sheet.Tracks = append(sheet.Tracks, CueTrack{Number: n, File: file})
+			track, hasIndex = &sheet.Tracks[len(sheet.Tracks)-1], false
+		case command == "INDEX" && len(args) == 2 && track != nil:
+			if args[0] != "01" && args[0] != "1" {
+				continue
+			}
+			start, err := parseCueTime(args[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid index in line %d: %v", line, err)
+			}
+			track.Start, hasIndex = start, true
+		case command == "TITLE" && 0 < len(args):
+			if track != nil {
+				track.Title = args[0]
+			} else {
+				sheet.Title = args[0]
+			}
+		case command == "PERFORMER" && 0 < len(args):
+			if track != nil {
+				track.Performer = args[0]
+			} else {
+				sheet.Performer = args[0]
+			}
+		case command == "REM" && len(args) == 2 && strings.ToUpper(args[0]) == "GENRE":
+			sheet.Genre = args[1]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !hasIndex {
+		return nil, fmt.Errorf("track %d has no INDEX 01", track.Number)
+	}
+	return sheet, nil
+}
+
+// splitCueLine splits a line of a cue sheet into fields, keeping quoted fields together
+func splitCueLine(line string) []string {
+	fields := make([]string, 0)
+	for line = strings.TrimSpace(line); line != ""; line = strings.TrimSpace(line) {
+		if line[0] == '"' {
+			end := strings.Index(line[1:], "\"")
+			if end < 0 {
+				end = len(line) - 1
+			}
+			fields = append(fields, line[1:end+1])
+			line = line[min(end+2, len(line)):]
+			continue
+		}
+		end := strings.IndexAny(line, " \t")
+		if end < 0 {
+			end = len(line)
+		}
+		fields = append(fields, line[:end])
+		line = line[end:]
+	}
+	return fields
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// parseCueTime parses a cue time (mm:ss:ff) into frames
+func parseCueTime(value string) (int, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid time %s", value)
+	}
+	var mmssff [3]int
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid time %s", value)
+		}
+		mmssff[i] = v
+	}
+	if 60 <= mmssff[1] || cueFramesPerSec <= mmssff[2] {
+		return 0, fmt.Errorf("invalid time %s", value)
+	}
+	return (mmssff[0]*60+mmssff[1])*cueFramesPerSec + mmssff[2], nil
+}
+
+func cueFramesToSamples(frames int, sampleRate beep.SampleRate) int {
+	return frames * int(sampleRate) / cueFramesPerSec
+}
+
+// ReadCueSheet reads the cue sheet cue from the AudioDir
+func ReadCueSheet(cue string) (*CueSheet, error) {
+	f, err := os.Open(filepath.Join(AudioDir, cue))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cue sheet %s: %v", cue, err)
+	}
+	defer f.Close()
+	sheet, err := ParseCueSheet(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cue sheet %s: %v", cue, err)
+	}
+	return sheet, nil
+}
+
+// CueTrackSong returns the playlist entry of the track number of the cue sheet cue
+func CueTrackSong(cue string, number int) string {
+	return fmt.Sprintf("%s#%02d", cue, number)
+}
+
+// ParseCueTrackSong returns the cue sheet and the track number of a cue track entry like album.cue#03
+func ParseCueTrackSong(song string) (string, int, bool) {
+	i := strings.LastIndex(song, cueExt+"#")
+	if i < 0 {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(song[i+len(cueExt)+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return song[:i+len(cueExt)], n, true
+}
+
+// LookupCueTrack reads the cue sheet of a cue track entry and returns it with the index of the track
+func LookupCueTrack(song string) (*CueSheet, int, error) {
+	cue, number, ok := ParseCueTrackSong(song)
+	if !ok {
+		return nil, 0, fmt.Errorf("%s is not a cue track", song)
+	}
+	sheet, err := ReadCueSheet(cue)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i, t := range sheet.Tracks {
+		if t.Number == number {
+			return sheet, i, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("cue sheet %s has no track %d", cue, number)
+}
+
+// ExpandCueSheets returns the songs in files, replacing cue sheets with their tracks. Files referenced by
+// cue sheets are not included, as their tracks are.
+func ExpandCueSheets(files []string) []string {
+	tracks := make(map[string][]string)
+	referenced := make(map[string]bool)
+	for _, f := range files {
+		if filepath.Ext(f) != cueExt {
+			continue
+		}
+		sheet, err := ReadCueSheet(f)
+		if err != nil {
+			logger.Warnf("ignoring cue sheet: %v", err)
+			continue
+		}
+		for _, t := range sheet.Tracks {
+			tracks[f] = append(tracks[f], CueTrackSong(f, t.Number))
+			referenced[filepath.Join(filepath.Dir(f), t.File)] = true
+		}
+	}
+
+	songs := make([]string, 0, len(files))
+	for _, f := range files {
+		if t, ok := tracks[f]; ok {
+			songs = append(songs, t...)
+		} else if len(util.FilterSongs([]string{f})) != 0 && !referenced[f] {
+			songs = append(songs, f)
+		}
+	}
+	return songs
+}
+
+// ListSongs lists all songs and cue tracks in the music (sub) directory
+func ListSongs(subDir string) []string {
+	return ExpandCueSheets(util.ListAllFiles(AudioDir, subDir))
+}
+
+// decodeCueTrackFile decodes the file of a cue track and returns the samples the track starts and ends at
+func decodeCueTrackFile(song string) (s beep.StreamSeekCloser, format beep.Format, start, end int, err error) {
+	sheet, i, err := LookupCueTrack(song)
+	if err != nil {
+		return nil, beep.Format{}, 0, 0, err
+	}
+	cue, _, _ := ParseCueTrackSong(song)
+	track := sheet.Tracks[i]
+	s, format, err = decodeFile(filepath.Join(filepath.Dir(cue), track.File))
+	if err != nil {
+		return nil, beep.Format{}, 0, 0, err
+	}
+
+	start, end = cueFramesToSamples(track.Start, format.SampleRate), s.Len()
+	if i+1 < len(sheet.Tracks) && sheet.Tracks[i+1].File == track.File {
+		end = cueFramesToSamples(sheet.Tracks[i+1].Start, format.SampleRate)
+	}
+	if s.Len() < end || end <= start {
+		s.Close()
+		return nil, beep.Format{}, 0, 0, fmt.Errorf("cue track %s is not within its file", song)
+	}
+	return s, format, start, end, nil
+}
+
+// openCueTrack opens the file of a cue track, limited to the track
+func openCueTrack(song string) (beep.StreamSeekCloser, beep.Format, error) {
+	s, format, start, end, err := decodeCueTrackFile(song)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	section, err := newSectionStreamer(s, start, end)
+	if err != nil {
+		s.Close()
+		return nil, beep.Format{}, fmt.Errorf("failed to seek to cue track %s: %v", song, err)
+	}
+	return section, format, nil
+}
@@ -0,0 +1,102 @@
+package playback
+
+import (
+	"github.com/LogicalOverflow/music-sync/logging"
+	"github.com/faiface/beep"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestParseCueSheet(t *testing.T) {
+	sheet, err := ParseCueSheet(strings.NewReader("\ufeffPERFORMER \"Band\"\nTITLE \"Album\"\n" +
+		"FILE \"side a.mp3\" MP3\n  TRACK 01 AUDIO\n    TITLE \"One\"\n    INDEX 01 00:00:00\n" +
+		"  TRACK 02 AUDIO\n    TITLE \"Two\"\n    PERFORMER \"Guest\"\n    INDEX 01 03:25:50\n" +
+		"FILE \"side b.mp3\" MP3\n  TRACK 03 AUDIO\n    INDEX 01 00:00:10\n"))
+	if !assert.Nil(t, err, "ParseCueSheet returned an error") {
+		return
+	}
+	assert.Equal(t, &CueSheet{Title: "Album", Performer: "Band", Tracks: []CueTrack{
+		{Number: 1, Title: "One", File: "side a.mp3", Start: 0},
+		{Number: 2, Title: "Two", Performer: "Guest", File: "side a.mp3", Start: (3*60+25)*75 + 50},
+		{Number: 3, File: "side b.mp3", Start: 10},
+	}}, sheet, "ParseCueSheet returned the wrong cue sheet")
+
+	for _, cue := range []string{
+		"TRACK 01 AUDIO\nINDEX 01 00:00:00\n",
+		"FILE \"a.mp3\" MP3\nTRACK 01 AUDIO\nTRACK 02 AUDIO\nINDEX 01 00:00:00\n",
+		"FILE \"a.mp3\" MP3\nTRACK 01 AUDIO\nINDEX 00 00:00:00\n",
+		"FILE \"a.mp3\" MP3\nTRACK 01 AUDIO\nINDEX 01 00:60:00\n",
+	} {
+		_, err := ParseCueSheet(strings.NewReader(cue))
+		assert.NotNil(t, err, "ParseCueSheet did not return an error for the invalid cue sheet %q", cue)
+	}
+}
+
+func TestSplitCueLine(t *testing.T) {
+	assert.Equal(t, []string{"FILE", "my song.mp3", "MP3"}, splitCueLine(`  FILE "my song.mp3" MP3`), "splitCueLine did not keep quoted fields together")
+	assert.Equal(t, []string{"TITLE", "unterminated"}, splitCueLine(`TITLE "unterminated`), "splitCueLine did not handle an unterminated quote")
+	assert.Equal(t, []string{}, splitCueLine("   "), "splitCueLine returned fields for an empty line")
+}
+
+func TestParseCueTrackSong(t *testing.T) {
+	assert.Equal(t, "dir/album.cue#03", CueTrackSong("dir/album.cue", 3), "CueTrackSong returned the wrong entry")
+	cue, number, ok := ParseCueTrackSong("dir/album.cue#03")
+	assert.True(t, ok, "ParseCueTrackSong did not parse a cue track")
+	assert.Equal(t, "dir/album.cue", cue, "ParseCueTrackSong returned the wrong cue sheet")
+	assert.Equal(t, 3, number, "ParseCueTrackSong returned the wrong track number")
+	for _, song := range []string{"dir/album.mp3", "dir/album.cue", "album.cue#x", "song#01.mp3"} {
+		_, _, ok := ParseCueTrackSong(song)
+		assert.False(t, ok, "ParseCueTrackSong parsed %s as cue track", song)
+	}
+}
+
+func TestExpandCueSheets(t *testing.T) {
+	log.DefaultCutoffLevel = log.LevelOff
+	ad := AudioDir
+	defer func() { AudioDir = ad }()
+	AudioDir = "_playback_test_files"
+
+	assert.Equal(t, []string{"album.cue#01", "album.cue#02", "album.cue#03", "bad-format.mp3"},
+		ExpandCueSheets([]string{"album.cue", "bad-format.mp3", "missing.cue", "notes.txt", "okay.mp3"}),
+		"ExpandCueSheets returned the wrong songs")
+	assert.Equal(t, []string{"album.cue#01", "album.cue#02", "album.cue#03", "bad-format.mp3"}, ListSongs(""),
+		"ListSongs returned the wrong songs")
+}
+
+func TestOpenCueTrack(t *testing.T) {
+	ad := AudioDir
+	defer func() { AudioDir = ad }()
+	AudioDir = "_playback_test_files"
+
+	_, err := getStreamer("album.cue#04")
+	assert.NotNil(t, err, "getStreamer did not return an error for a missing cue track")
+
+	for _, c := range []struct {
+		song   string
+		length int
+	}{{"album.cue#01", 176400}, {"album.cue#02", 194040}, {"album.cue#03", 73080}} {
+		s, err := getStreamer(c.song)
+		if !assert.Nil(t, err, "getStreamer returned an error for %s", c.song) {
+			continue
+		}
+		assert.Equal(t, c.length, s.Len(), "cue track %s has the wrong length", c.song)
+		total := 0
+		samples := make([][2]float64, 4096)
+		for n, ok := s.Stream(samples); ok; n, ok = s.Stream(samples) {
+			total += n
+		}
+		assert.Equal(t, c.length, total, "cue track %s streamed the wrong number of samples", c.song)
+		assert.Equal(t, c.length, s.Position(), "cue track %s has the wrong position", c.song)
+
+		assert.Nil(t, s.Seek(100), "cue track %s failed to seek", c.song)
+		assert.Equal(t, 100, s.Position(), "cue track %s has the wrong position after seeking", c.song)
+		assert.NotNil(t, s.Seek(c.length+1), "cue track %s seeked beyond its end", c.song)
+		s.Close()
+
+		duration, err := SongDuration(c.song)
+		if assert.Nil(t, err, "SongDuration returned an error for %s", c.song) {
+			assert.Equal(t, beep.SampleRate(44100).D(c.length), duration, "SongDuration returned the wrong duration for %s", c.song)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package playback
+
+import (
+	"github.com/faiface/beep"
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	defaultEQBandQ     = 1.0
+	bassBoostFrequency = 100.0
+	bassBoostSlope     = 0.7071067811865476
+
+	// vocalLowCutoff and vocalHighCutoff limit the band the vocal reduction removes the centre channel in
+	vocalLowCutoff  = 150.0
+	vocalHighCutoff = 7000.0
+	butterworthQ    = 0.7071067811865476
+)
+
+// EQBand is a peaking filter of the equalizer
+type EQBand struct {
+	Frequency float64 // center frequency in Hz
+	Gain      float64 // gain in dB
+	Q         float64
+}
+
+// DSPConfig configures the effects applied to the audio
+type DSPConfig struct {
+	Bands     []EQBand
+	BassBoost float64 // gain of the bass shelf in dB
+	Width     float64 // stereo width: 0 is mono, 1 is unchanged and values above 1 widen the stereo image
+	Limiter   bool
+
+	VocalReduction bool // removes the centre channel (usually the vocals) between vocalLowCutoff and vocalHighCutoff
+}
+
+// DefaultDSPConfig returns a config, which does not change the audio
+func DefaultDSPConfig() DSPConfig {
+	return DSPConfig{Bands: []EQBand{}, Width: 1}
+}
+
+// IsNeutral returns whether the config does not change the audio
+func (c DSPConfig) IsNeutral() bool {
+	for _, b := range c.Bands {
+		if b.Gain != 0 {
+			return false
+		}
+	}
+	return c.BassBoost == 0 && c.Width == 1 && !c.Limiter && !c.VocalReduction
+}
+
+// WithBand returns a copy of the config with the gain of the band at frequency set to gain.
+// A new band is added, if the config does not have a band at frequency yet.
+func (c DSPConfig) WithBand(frequency, gain float64) DSPConfig {
+	bands := make([]EQBand, 0, len(c.Bands)+1)
+	found := false
+	for _, b := range c.Bands {
+		if b.Frequency == frequency {
+			b.Gain = gain
+			found = true
+		}
+		bands = append(bands, b)
+	}
+	if !found {
+		bands = append(bands, EQBand{Frequency: frequency, Gain: gain, Q: defaultEQBandQ})
+		sort.Slice(bands, func(i, j int) bool { return bands[i].Frequency < bands[j].Frequency })
+	}
+	c.Bands = bands
+	return c
+}
+
+var eqPresetFrequencies = []float64{60, 170, 310, 600, 1000, 3000, 6000, 12000, 14000, 16000}
+
+// eqPresets contains the gains (in dB) of the bands at eqPresetFrequencies
+var eqPresets = map[string][]float64{
+	"flat":      {0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+	"party":     {7, 7, 0, 0, 0, 0, 0, 0, 7, 7},
+	"rock":      {8, 5, -5, -8, -3, 4, 8, 11, 11, 11},
+	"pop":       {-1, 4, 7, 8, 5, 0, -2, -2, -1, -1},
+	"classical": {0, 0, 0, 0, 0, 0, -7, -7, -7, -9},
+	"vocal":     {-3, -3, -1, 2, 4, 4, 3, 1, 0, -1},
+	"bass":      {9, 9, 9, 5, 1, -4, -8, -10, -11, -11},
+	"treble":    {-9, -9, -9, -4, 2, 11, 16, 16, 16, 16},
+}
+
+// EQPresetNames returns the names of all equalizer presets
+func EQPresetNames() []string {
+	names := make([]string, 0, len(eqPresets))
+	for name := range eqPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EQPreset returns the bands of the equalizer preset name
+func EQPreset(name string) ([]EQBand, bool) {
+	gains, ok := eqPresets[name]
+	if !ok {
+		return nil, false
+	}
+	bands := make([]EQBand, len(gains))
+	for i, g := range gains {
+		bands[i] = EQBand{Frequency: eqPresetFrequencies[i], Gain: g, Q: defaultEQBandQ}
+	}
+	return bands, true
+}
+
+// newPeakingFilter creates a peaking equalizer filter (see the Audio EQ Cookbook by R. Bristow-Johnson)
+func newPeakingFilter(sampleRate, frequency, gain, q float64) biquad {
+	a := math.Pow(10, gain/40)
+	w := 2 * math.Pi * frequency / sampleRate
+	alpha := math.Sin(w) / (2 * q)
+	a0 := 1 + alpha/a
+	return biquad{
+		b0: (1 + alpha*a) / a0,
+		b1: -2 * math.Cos(w) / a0,
+		b2: (1 - alpha*a) / a0,
+		a1: -2 * math.Cos(w) / a0,
+		a2: (1 - alpha/a) / a0,
+	}
+}
+
+// newLowShelfFilter creates a low shelf filter (see the Audio EQ Cookbook by R. Bristow-Johnson)
+func newLowShelfFilter(sampleRate, frequency, gain, slope float64) biquad {
+	a := math.Pow(10, gain/40)
+	w := 2 * math.Pi * frequency / sampleRate
+	alpha := math.Sin(w) / 2 * math.Sqrt((a+1/a)*(1/slope-1)+2)
+	cos, sqrtA := math.Cos(w), math.Sqrt(a)
+	a0 := (a + 1) + (a-1)*cos + 2*sqrtA*alpha
+	return biquad{
+		b0: a * ((a + 1) - (a-1)*cos + 2*sqrtA*alpha) / a0,
+		b1: 2 * a * ((a - 1) - (a+1)*cos) / a0,
+		b2: a * ((a + 1) - (a-1)*cos - 2*sqrtA*alpha) / a0,
+		a1: -2 * ((a - 1) + (a+1)*cos) / a0,
+		a2: ((a + 1) + (a-1)*cos - 2*sqrtA*alpha) / a0,
+	}
+}
+
+// newHighPassFilter creates a high pass filter (see the Audio EQ Cookbook by R. Bristow-Johnson)
+func newHighPassFilter(sampleRate, frequency, q float64) biquad {
+	w := 2 * math.Pi * frequency / sampleRate
+	alpha := math.Sin(w) / (2 * q)
+	cos := math.Cos(w)
+	a0 := 1 + alpha
+	return biquad{
+		b0: (1 + cos) / 2 / a0,
+		b1: -(1 + cos) / a0,
+		b2: (1 + cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+// newLowPassFilter creates a low pass filter (see the Audio EQ Cookbook by R. Bristow-Johnson)
+func newLowPassFilter(sampleRate, frequency, q float64) biquad {
+	w := 2 * math.Pi * frequency / sampleRate
+	alpha := math.Sin(w) / (2 * q)
+	cos := math.Cos(w)
+	a0 := 1 + alpha
+	return biquad{
+		b0: (1 - cos) / 2 / a0,
+		b1: (1 - cos) / a0,
+		b2: (1 - cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+type filterStreamer struct {
+	s       beep.Streamer
+	filters [][2]biquad
+}
+
+func (f *filterStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = f.s.Stream(samples)
+	for i := range samples[:n] {
+		for j := range f.filters {
+			for c := range samples[i] {
+				samples[i][c] = f.filters[j][c].process(samples[i][c])
+			}
+		}
+	}
+	return
+}
+
+func (f *filterStreamer) Err() error { return f.s.Err() }
+
+// Equalizer applies a peaking filter for every band to s
+func Equalizer(s beep.Streamer, sampleRate beep.SampleRate, bands []EQBand) beep.Streamer {
+	filters := make([][2]biquad, 0, len(bands))
+	for _, b := range bands {
+		if b.Gain == 0 {
+			continue
+		}
+		q := b.Q
+		if q <= 0 {
+			q = defaultEQBandQ
+		}
+		f := newPeakingFilter(float64(sampleRate), b.Frequency, b.Gain, q)
+		filters = append(filters, [2]biquad{f, f})
+	}
+	return &filterStreamer{s: s, filters: filters}
+}
+
+// BassBoost applies a low shelf filter with gain (in dB) to s
+func BassBoost(s beep.Streamer, sampleRate beep.SampleRate, gain float64) beep.Streamer {
+	f := newLowShelfFilter(float64(sampleRate), bassBoostFrequency, gain, bassBoostSlope)
+	return &filterStreamer{s: s, filters: [][2]biquad{{f, f}}}
+}
+
+type widthStreamer struct {
+	s     beep.Streamer
+	width float64
+}
+
+func (w *widthStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = w.s.Stream(samples)
+	for i := range samples[:n] {
+		mid := (samples[i][0] + samples[i][1]) / 2
+		side := (samples[i][0] - samples[i][1]) / 2 * w.width
+		samples[i][0], samples[i][1] = mid+side, mid-side
+	}
+	return
+}
+
+func (w *widthStreamer) Err() error { return w.s.Err() }
+
+// StereoWidth scales the difference between the channels of s by width
+func StereoWidth(s beep.Streamer, width float64) beep.Streamer {
+	return &widthStreamer{s: s, width: width}
+}
+
+type vocalReductionStreamer struct {
+	s                 beep.Streamer
+	highPass, lowPass biquad
+}
+
+func (v *vocalReductionStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = v.s.Stream(samples)
+	for i := range samples[:n] {
+		mid := (samples[i][0] + samples[i][1]) / 2
+		vocals := v.lowPass.process(v.highPass.process(mid))
+		samples[i][0] -= vocals
+		samples[i][1] -= vocals
+	}
+	return
+}
+
+func (v *vocalReductionStreamer) Err() error { return v.s.Err() }
+
+// VocalReduction removes the centre channel of s, where vocals are usually mixed to. Only the band between
+// vocalLowCutoff and vocalHighCutoff is removed, so the (usually centred) bass and the highs are kept.
+func VocalReduction(s beep.Streamer, sampleRate beep.SampleRate) beep.Streamer {
+	return &vocalReductionStreamer{
+		s:        s,
+		highPass: newHighPassFilter(float64(sampleRate), vocalLowCutoff, butterworthQ),
+		lowPass:  newLowPassFilter(float64(sampleRate), vocalHighCutoff, butterworthQ),
+	}
+}
+
+type limiterStreamer struct {
+	s beep.Streamer
+	l *limiter
+}
+
+func (l *limiterStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = l.s.Stream(samples)
+	l.l.process(samples[:n])
+	return
+}
+
+func (l *limiterStreamer) Err() error { return l.s.Err() }
+
+// SoftLimiter keeps the peaks of s below full scale, smoothly recovering the gain after a peak
+func SoftLimiter(s beep.Streamer) beep.Streamer {
+	return &limiterStreamer{s: s, l: newLimiter()}
+}
+
+// DSPChain wraps s in all effects enabled in config
+func DSPChain(s beep.Streamer, sampleRate beep.SampleRate, config DSPConfig) beep.Streamer {
+	if config.VocalReduction {
+		s = VocalReduction(s, sampleRate)
+	}
+	if 0 < len(config.Bands) {
+		s = Equalizer(s, sampleRate, config.Bands)
+	}
+	if config.BassBoost != 0 {
+		s = BassBoost(s, sampleRate, config.BassBoost)
+	}
+	if config.Width != 1 {
+		s = StereoWidth(s, config.Width)
+	}
+	if config.Limiter {
+		s = SoftLimiter(s)
+	}
+	return s
+}
+
+type bufferStreamer struct{ samples [][2]float64 }
+
+func (b *bufferStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	n = copy(samples, b.samples)
+	b.samples = b.samples[n:]
+	return n, true
+}
+
+func (b *bufferStreamer) Err() error { return nil }
+
+// DSP processes buffers of samples with a DSPChain. Its config can be changed while processing.
+type DSP struct {
+	sampleRate beep.SampleRate
+	config     DSPConfig
+	input      *bufferStreamer
+	chain      beep.Streamer
+	mutex      sync.Mutex
+}
+
+// NewDSP creates a new DSP, which does not change the audio until it is configured
+func NewDSP(sampleRate int) *DSP {
+	d := &DSP{sampleRate: beep.SampleRate(sampleRate), input: &bufferStreamer{}}
+	d.SetConfig(DefaultDSPConfig())
+	return d
+}
+
+// SetSampleRate sets the sample rate of the processed audio
+func (d *DSP) SetSampleRate(sampleRate int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.sampleRate = beep.SampleRate(sampleRate)
+	d.buildChain()
+}
+
+// SetConfig replaces the config of the DSP
+func (d *DSP) SetConfig(config DSPConfig) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.config = config
+	d.buildChain()
+}
+
+// Config returns the current config of the DSP
+func (d *DSP) Config() DSPConfig {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.config
+}
+
+func (d *DSP) buildChain() {
+	if d.config.IsNeutral() {
+		d.chain = nil
+	} else {
+		d.chain = DSPChain(d.input, d.sampleRate, d.config)
+	}
+}
+
+// Process applies the effects to samples in place
+func (d *DSP) Process(samples [][2]float64) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.chain == nil {
+		return
+	}
+	d.input.samples = samples
+	d.chain.Stream(samples)
+}
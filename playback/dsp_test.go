@@ -0,0 +1,107 @@
+package playback
+
+import (
+	"github.com/faiface/beep"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+// peakAfter returns the peak of s after skipping the first second to let filters settle
+func peakAfter(s beep.Streamer, sampleRate beep.SampleRate) float64 {
+	buf := make([][2]float64, sampleRate.N(1e9))
+	s.Stream(buf)
+	peak := 0.0
+	for {
+		n, ok := s.Stream(buf)
+		for _, sample := range buf[:n] {
+			peak = math.Max(peak, math.Abs(sample[0]))
+		}
+		if !ok || n < len(buf) {
+			return peak
+		}
+	}
+}
+
+func TestDSPConfig_IsNeutral(t *testing.T) {
+	assert.True(t, DefaultDSPConfig().IsNeutral(), "default dsp config is not neutral")
+	assert.True(t, DSPConfig{Bands: []EQBand{{Frequency: 60}}, Width: 1}.IsNeutral(), "dsp config with a 0 dB band is not neutral")
+	assert.False(t, DefaultDSPConfig().WithBand(60, 3).IsNeutral(), "dsp config with a boosted band is neutral")
+	assert.False(t, DSPConfig{Width: 1, BassBoost: 3}.IsNeutral(), "dsp config with bass boost is neutral")
+	assert.False(t, DSPConfig{Width: 0}.IsNeutral(), "mono dsp config is neutral")
+	assert.False(t, DSPConfig{Width: 1, Limiter: true}.IsNeutral(), "dsp config with limiter is neutral")
+	assert.False(t, DSPConfig{Width: 1, VocalReduction: true}.IsNeutral(), "dsp config with vocal reduction is neutral")
+}
+
+func TestDSPConfig_WithBand(t *testing.T) {
+	c := DefaultDSPConfig().WithBand(1000, 2).WithBand(60, 3)
+	assert.Equal(t, []EQBand{{Frequency: 60, Gain: 3, Q: 1}, {Frequency: 1000, Gain: 2, Q: 1}}, c.Bands, "WithBand did not add the bands in order")
+
+	changed := c.WithBand(60, -4)
+	assert.Equal(t, []EQBand{{Frequency: 60, Gain: -4, Q: 1}, {Frequency: 1000, Gain: 2, Q: 1}}, changed.Bands, "WithBand did not change the existing band")
+	assert.Equal(t, 3.0, c.Bands[0].Gain, "WithBand modified the original config")
+}
+
+func TestEQPreset(t *testing.T) {
+	for _, name := range EQPresetNames() {
+		bands, ok := EQPreset(name)
+		if assert.True(t, ok, "EQPreset did not return preset %s", name) {
+			assert.Len(t, bands, len(eqPresetFrequencies), "EQPreset returned the wrong number of bands for %s", name)
+		}
+	}
+	_, ok := EQPreset("non-existent")
+	assert.False(t, ok, "EQPreset returned a non-existent preset")
+}
+
+func TestEqualizer(t *testing.T) {
+	bands := []EQBand{{Frequency: 1000, Gain: 6, Q: 1}}
+	peak := peakAfter(Equalizer(sineStreamer(44100, 1000, 0.25, 3), 44100, bands), 44100)
+	assert.InDelta(t, 0.25*GainToFactor(6), peak, 0.01, "Equalizer did not boost the band frequency")
+
+	peak = peakAfter(Equalizer(sineStreamer(44100, 15000, 0.25, 3), 44100, bands), 44100)
+	assert.InDelta(t, 0.25, peak, 0.01, "Equalizer changed a frequency far from the band")
+}
+
+func TestBassBoost(t *testing.T) {
+	peak := peakAfter(BassBoost(sineStreamer(44100, 30, 0.25, 3), 44100, 6), 44100)
+	assert.InDelta(t, 0.25*GainToFactor(6), peak, 0.02, "BassBoost did not boost the bass")
+
+	peak = peakAfter(BassBoost(sineStreamer(44100, 5000, 0.25, 3), 44100, 6), 44100)
+	assert.InDelta(t, 0.25, peak, 0.01, "BassBoost changed the treble")
+}
+
+func TestStereoWidth(t *testing.T) {
+	samples := [][2]float64{{1, 0}, {0.5, 0.5}}
+	s := StereoWidth(&bufferStreamer{samples: samples}, 2)
+	s.Stream(samples)
+	assert.Equal(t, [][2]float64{{1.5, -0.5}, {0.5, 0.5}}, samples, "StereoWidth did not widen the stereo image")
+}
+
+func TestVocalReduction(t *testing.T) {
+	peak := peakAfter(VocalReduction(sineStreamer(44100, 1000, 0.25, 3), 44100), 44100)
+	assert.True(t, peak < 0.05, "VocalReduction did not remove the centred vocal band (peak is %f)", peak)
+
+	peak = peakAfter(VocalReduction(sineStreamer(44100, 40, 0.25, 3), 44100), 44100)
+	assert.True(t, 0.2 < peak, "VocalReduction removed the centred bass (peak is %f)", peak)
+
+	samples := [][2]float64{{0.5, -0.5}, {-0.25, 0.25}}
+	VocalReduction(&bufferStreamer{samples: samples}, 44100).Stream(samples)
+	assert.Equal(t, [][2]float64{{0.5, -0.5}, {-0.25, 0.25}}, samples, "VocalReduction changed the side channel")
+}
+
+func TestSoftLimiter(t *testing.T) {
+	peak := peakAfter(SoftLimiter(sineStreamer(44100, 440, 2, 3)), 44100)
+	assert.True(t, peak <= limiterThreshold+1e-9, "SoftLimiter did not limit the peaks (peak is %f)", peak)
+}
+
+func TestDSP_Process(t *testing.T) {
+	d := NewDSP(44100)
+	samples := [][2]float64{{1, 0}, {0.5, 0.25}}
+	d.Process(samples)
+	assert.Equal(t, [][2]float64{{1, 0}, {0.5, 0.25}}, samples, "neutral DSP changed the samples")
+
+	d.SetConfig(DSPConfig{Width: 0})
+	assert.Equal(t, DSPConfig{Width: 0}, d.Config(), "DSP did not return the config set")
+	d.Process(samples)
+	assert.Equal(t, [][2]float64{{0.5, 0.5}, {0.375, 0.375}}, samples, "DSP did not process the samples")
+}
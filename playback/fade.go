@@ -0,0 +1,39 @@
+package playback
+
+// fade changes the gain linearly from 0 to 1 (fade in) or from 1 to 0 (fade out) over length samples
+type fade struct {
+	length int
+	pos    int
+	in     bool
+}
+
+func newFade(length int, in bool) *fade {
+	return &fade{length: length, in: in}
+}
+
+func (f *fade) gain() float64 {
+	if f.length <= f.pos {
+		if f.in {
+			return 1
+		}
+		return 0
+	}
+	g := float64(f.pos) / float64(f.length)
+	f.pos++
+	if f.in {
+		return g
+	}
+	return 1 - g
+}
+
+// apply applies the fade to samples. A nil fade does not change the samples.
+func (f *fade) apply(samples [][2]float64) {
+	if f == nil {
+		return
+	}
+	for i := range samples {
+		g := f.gain()
+		samples[i][0] *= g
+		samples[i][1] *= g
+	}
+}
@@ -0,0 +1,21 @@
+package playback
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFade_apply(t *testing.T) {
+	samples := [][2]float64{{1, -1}, {1, -1}, {1, -1}, {1, -1}, {1, -1}, {1, -1}}
+	newFade(4, true).apply(samples)
+	assert.Equal(t, [][2]float64{{0, 0}, {0.25, -0.25}, {0.5, -0.5}, {0.75, -0.75}, {1, -1}, {1, -1}}, samples, "fade in applied the wrong gain")
+
+	samples = [][2]float64{{1, -1}, {1, -1}, {1, -1}, {1, -1}, {1, -1}, {1, -1}}
+	newFade(4, false).apply(samples)
+	assert.Equal(t, [][2]float64{{1, -1}, {0.75, -0.75}, {0.5, -0.5}, {0.25, -0.25}, {0, 0}, {0, 0}}, samples, "fade out applied the wrong gain")
+
+	samples = [][2]float64{{1, -1}}
+	var f *fade
+	f.apply(samples)
+	assert.Equal(t, [][2]float64{{1, -1}}, samples, "nil fade changed the samples")
+}
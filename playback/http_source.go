@@ -0,0 +1,218 @@
+package playback
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/mp3"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// httpTimeout is the timeout for connecting to http sources and receiving their response header
+const httpTimeout = 10 * time.Second
+
+// httpIdleTimeout is the time after which a http source, which stopped sending audio, ends
+var httpIdleTimeout = httpTimeout
+
+var httpClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialICY,
+		TLSHandshakeTimeout:   httpTimeout,
+		ResponseHeaderTimeout: httpTimeout,
+	},
+}
+
+// IsURL returns true, if song is a http(s) url instead of a file in the AudioDir
+func IsURL(song string) bool {
+	return strings.HasPrefix(song, "http://") || strings.HasPrefix(song, "https://")
+}
+
+// openHTTPStream requests the mp3 stream or file at url and decodes it incrementally in the background.
+// Like live sources, gaps are filled with silence, so a stalled server never stalls the stream. If the server sends
+// no audio for httpIdleTimeout, the stream ends.
+// If the server sends ICY metadata (icecast/shoutcast), titleHandler is called every time the stream title changes.
+func openHTTPStream(url string, titleHandler func(title string)) (beep.StreamSeekCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %s: %v", url, err)
+	}
+	req.Header.Set("Icy-MetaData", "1")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request %s: %v", url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to request %s: %s", url, resp.Status)
+	}
+
+	body := resp.Body
+	if metaInt, err := strconv.Atoi(resp.Header.Get("Icy-Metaint")); err == nil && 0 < metaInt {
+		body = &icyReader{r: body, metaInt: metaInt, left: metaInt, titleHandler: titleHandler}
+	}
+
+	ls := newLiveSource(make(chan struct{}))
+	ls.idleTimeout, ls.closer = httpIdleTimeout, body
+	decoded := make(chan error, 1)
+	go ls.readMP3(body, decoded)
+	select {
+	case err := <-decoded:
+		if err != nil {
+			body.Close()
+			return nil, fmt.Errorf("failed to decode %s: %v", url, err)
+		}
+	case <-time.After(httpIdleTimeout):
+		ls.Close()
+		return nil, fmt.Errorf("failed to decode %s: no audio received for %s", url, httpIdleTimeout)
+	}
+	return ls, nil
+}
+
+// readMP3 decodes the mp3 stream r into blocks until it ends or the source is closed. The result of decoding the
+// header of the stream is sent to decoded.
+func (ls *liveSource) readMP3(r io.ReadCloser, decoded chan<- error) {
+	defer close(ls.blocks)
+	s, _, err := mp3.Decode(r)
+	decoded <- err
+	if err != nil {
+		return
+	}
+	ls.readBlocks(s)
+}
+
+// openSong opens a song from the AudioDir, a live source or a http source, passing stream titles to the
+// stream title handler
+func (pl *Playlist) openSong(song string) (beep.StreamSeekCloser, error) {
+	if IsLiveSource(song) {
+		return openLiveSource(song, pl.sampleRate)
+	}
+	if !IsURL(song) {
+		return getStreamer(song)
+	}
+	return openHTTPStream(song, func(title string) {
+		if pl.streamTitleHandler != nil {
+			go pl.streamTitleHandler(pl.sampleIndexWrite, song, title)
+		}
+	})
+}
+
+// SetStreamTitleHandler sets the stream title handler, which is called every time the title of a http stream
+// changes, with the index of the first sample pushed after the change.
+func (pl *Playlist) SetStreamTitleHandler(sth func(startSampleIndex uint64, url string, title string)) {
+	pl.streamTitleHandler = sth
+}
+
+// icyReader removes the metadata blocks from an ICY stream and passes the stream titles to titleHandler
+type icyReader struct {
+	r            io.ReadCloser
+	metaInt      int
+	left         int
+	title        string
+	titleHandler func(title string)
+}
+
+func (ir *icyReader) Read(b []byte) (int, error) {
+	if ir.left == 0 {
+		if err := ir.readMetadata(); err != nil {
+			return 0, err
+		}
+		ir.left = ir.metaInt
+	}
+	if ir.left < len(b) {
+		b = b[:ir.left]
+	}
+	n, err := ir.r.Read(b)
+	ir.left -= n
+	return n, err
+}
+
+// readMetadata reads a metadata block, which is a length byte (in 16 byte blocks) followed by the metadata
+func (ir *icyReader) readMetadata() error {
+	var length [1]byte
+	if _, err := io.ReadFull(ir.r, length[:]); err != nil {
+		return err
+	}
+	if length[0] == 0 {
+		return nil
+	}
+	meta := make([]byte, 16*int(length[0]))
+	if _, err := io.ReadFull(ir.r, meta); err != nil {
+		return err
+	}
+
+	title, ok := parseStreamTitle(string(bytes.TrimRight(meta, "\x00")))
+	if ok && title != ir.title {
+		ir.title = title
+		if ir.titleHandler != nil {
+			ir.titleHandler(title)
+		}
+	}
+	return nil
+}
+
+func (ir *icyReader) Close() error {
+	return ir.r.Close()
+}
+
+// parseStreamTitle returns the stream title of ICY metadata like StreamTitle='Artist - Title';
+func parseStreamTitle(meta string) (string, bool) {
+	const key = "StreamTitle='"
+	start := strings.Index(meta, key)
+	if start < 0 {
+		return "", false
+	}
+	meta = meta[start+len(key):]
+	end := strings.Index(meta, "';")
+	if end < 0 {
+		end = strings.LastIndex(meta, "'")
+	}
+	if end < 0 {
+		return "", false
+	}
+	return meta[:end], true
+}
+
+func dialICY(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := (&net.Dialer{Timeout: httpTimeout}).DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &icyConn{Conn: conn}, nil
+}
+
+// icyConn replaces the "ICY 200 OK" status line sent by shoutcast servers with a http status line
+type icyConn struct {
+	net.Conn
+	checked bool
+	pending []byte
+	err     error
+}
+
+func (c *icyConn) Read(b []byte) (int, error) {
+	if !c.checked {
+		c.checked = true
+		head := make([]byte, 4)
+		n, err := io.ReadFull(c.Conn, head)
+		c.pending, c.err = head[:n], err
+		if bytes.Equal(c.pending, []byte("ICY ")) {
+			c.pending = []byte("HTTP/1.0 ")
+		}
+	}
+	if 0 < len(c.pending) {
+		n := copy(b, c.pending)
+		c.pending = c.pending[n:]
+		return n, nil
+	}
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.Conn.Read(b)
+}
@@ -0,0 +1,187 @@
+package playback
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// icyStream inserts a metadata block after every metaInt bytes of audio, using the titles in order (empty for no title)
+func icyStream(audio []byte, metaInt int, titles ...string) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < len(audio); i += metaInt {
+		end := i + metaInt
+		if len(audio) < end {
+			end = len(audio)
+		}
+		buf.Write(audio[i:end])
+		if end == len(audio) {
+			break
+		}
+
+		meta := ""
+		if len(titles) != 0 {
+			if titles[0] != "" {
+				meta = fmt.Sprintf("StreamTitle='%s';StreamUrl='';", titles[0])
+			}
+			titles = titles[1:]
+		}
+		blocks := (len(meta) + 15) / 16
+		buf.WriteByte(byte(blocks))
+		buf.WriteString(meta)
+		buf.Write(make([]byte, 16*blocks-len(meta)))
+	}
+	return buf.Bytes()
+}
+
+func TestIsURL(t *testing.T) {
+	assert.True(t, IsURL("http://radio.example.com/stream"), "IsURL did not detect a http url")
+	assert.True(t, IsURL("https://radio.example.com/stream"), "IsURL did not detect a https url")
+	assert.False(t, IsURL("dir/song.mp3"), "IsURL detected a file as url")
+}
+
+func TestParseStreamTitle(t *testing.T) {
+	title, ok := parseStreamTitle("StreamTitle='Artist - It's a Title';StreamUrl='';")
+	assert.True(t, ok, "parseStreamTitle did not find the title")
+	assert.Equal(t, "Artist - It's a Title", title, "parseStreamTitle returned the wrong title")
+
+	title, ok = parseStreamTitle("StreamTitle='Title'")
+	assert.True(t, ok, "parseStreamTitle did not find the title without a trailing semicolon")
+	assert.Equal(t, "Title", title, "parseStreamTitle returned the wrong title without a trailing semicolon")
+
+	_, ok = parseStreamTitle("StreamUrl='';")
+	assert.False(t, ok, "parseStreamTitle found a title in metadata without a title")
+}
+
+func TestIcyReader(t *testing.T) {
+	titles := make([]string, 0)
+	ir := &icyReader{
+		r:            ioutil.NopCloser(bytes.NewReader(icyStream([]byte("0123456789"), 4, "A - B", "", "A - B"))),
+		metaInt:      4,
+		left:         4,
+		titleHandler: func(title string) { titles = append(titles, title) },
+	}
+	audio, err := ioutil.ReadAll(ir)
+	assert.Nil(t, err, "icyReader returned an error")
+	assert.Equal(t, "0123456789", string(audio), "icyReader did not remove the metadata")
+	assert.Equal(t, []string{"A - B"}, titles, "icyReader did not pass the changed titles")
+}
+
+func TestOpenHTTPStream(t *testing.T) {
+	audio, err := ioutil.ReadFile(filepath.Join("_playback_test_files", "okay.mp3"))
+	if !assert.Nil(t, err, "failed to read test file") {
+		return
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/radio":
+			if r.Header.Get("Icy-MetaData") != "1" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("icy-metaint", "8192")
+			w.Write(icyStream(audio, 8192, "Artist - First", "Artist - Second"))
+		case "/file.mp3":
+			w.Write(audio)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	_, err = openHTTPStream(server.URL+"/missing", nil)
+	assert.NotNil(t, err, "openHTTPStream did not return an error for a missing stream")
+
+	s, err := openHTTPStream(server.URL+"/file.mp3", nil)
+	if assert.Nil(t, err, "openHTTPStream returned an error for a file") {
+		samples := make([][2]float64, 1024)
+		n, ok := s.Stream(samples)
+		assert.True(t, ok && n == len(samples), "http file stream did not return samples")
+		s.Close()
+	}
+
+	titles := make([]string, 0)
+	s, err = openHTTPStream(server.URL+"/radio", func(title string) { titles = append(titles, title) })
+	if assert.Nil(t, err, "openHTTPStream returned an error for a radio stream") {
+		samples := make([][2]float64, 4096)
+		total := 0
+		for n, ok := s.Stream(samples); ok; n, ok = s.Stream(samples) {
+			total += n
+		}
+		assert.Nil(t, s.Err(), "radio stream returned an error")
+		assert.Equal(t, 443520, total, "radio stream returned the wrong number of samples")
+		assert.Equal(t, []string{"Artist - First", "Artist - Second"}, titles, "radio stream did not pass the stream titles")
+		s.Close()
+	}
+}
+
+func TestOpenHTTPStream_stalled(t *testing.T) {
+	defer func(timeout time.Duration) { httpIdleTimeout = timeout }(httpIdleTimeout)
+	httpIdleTimeout = 200 * time.Millisecond
+	audio, err := ioutil.ReadFile(filepath.Join("_playback_test_files", "okay.mp3"))
+	if !assert.Nil(t, err, "failed to read test file") {
+		return
+	}
+	stalled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stalling" {
+			w.Write(audio[:len(audio)/4])
+		}
+		w.(http.Flusher).Flush()
+		<-stalled
+	}))
+	defer server.Close()
+	defer close(stalled)
+
+	_, err = openHTTPStream(server.URL+"/silent", nil)
+	assert.NotNil(t, err, "openHTTPStream did not return an error for a stream without audio")
+
+	s, err := openHTTPStream(server.URL+"/stalling", nil)
+	if !assert.Nil(t, err, "openHTTPStream returned an error for a stalling stream") {
+		return
+	}
+	samples := make([][2]float64, 4096)
+	start := time.Now()
+	silent := false
+	for n, ok := s.Stream(samples); ok; n, ok = s.Stream(samples) {
+		silent = silent || samples[n-1] == [2]float64{}
+	}
+	assert.True(t, silent, "stalling stream was not filled with silence")
+	assert.True(t, time.Since(start) < 10*httpIdleTimeout, "stalling stream did not end after the idle timeout")
+	s.Close()
+}
+
+func TestIcyConn(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err, "failed to listen") {
+		return
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 1024)
+		conn.Read(buf)
+		conn.Write([]byte("ICY 200 OK\r\nicy-name: test radio\r\n\r\naudio"))
+	}()
+
+	resp, err := httpClient.Get("http://" + l.Addr().String() + "/")
+	if !assert.Nil(t, err, "http client failed to parse an ICY response") {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "icyConn did not replace the status line")
+	assert.Equal(t, "test radio", resp.Header.Get("icy-name"), "icyConn changed the headers")
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, "audio", string(body), "icyConn changed the body")
+}
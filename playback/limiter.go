@@ -0,0 +1,31 @@
+package playback
+
+import "math"
+
+const (
+	limiterThreshold = 0.98
+	limiterRelease   = 0.0005
+)
+
+// limiter reduces the gain instantly when a sample would exceed the threshold and slowly recovers afterwards
+type limiter struct {
+	threshold float64
+	release   float64
+	gain      float64
+}
+
+func newLimiter() *limiter {
+	return &limiter{threshold: limiterThreshold, release: limiterRelease, gain: 1}
+}
+
+func (l *limiter) process(samples [][2]float64) {
+	for i := range samples {
+		peak := math.Max(math.Abs(samples[i][0]), math.Abs(samples[i][1]))
+		if l.threshold < peak*l.gain {
+			l.gain = l.threshold / peak
+		}
+		samples[i][0] *= l.gain
+		samples[i][1] *= l.gain
+		l.gain += (1 - l.gain) * l.release
+	}
+}
@@ -0,0 +1,284 @@
+package playback
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/wav"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LivePrefix is the prefix of playlist entries, which are live sources instead of songs.
+// Live sources are live:stdin, live:fifo:path and live:device:name (captured with arecord).
+const LivePrefix = "live:"
+
+const (
+	liveBlockCount   = 8
+	liveUnderrunWait = 50 * time.Millisecond
+)
+
+var (
+	stdinSource     *liveSource
+	stdinSourceOnce sync.Once
+)
+
+// IsLiveSource returns true, if song is a live source instead of a file in the AudioDir
+func IsLiveSource(song string) bool {
+	return strings.HasPrefix(song, LivePrefix)
+}
+
+// liveSource streams raw pcm (signed 16 bit little endian stereo) or wav audio read from a live source.
+// The source is read in the background and gaps are filled with silence, so a slow or stalled source never
+// stalls the stream. A live source has no length and ends when the source is closed by its writer.
+type liveSource struct {
+	blocks  chan [][2]float64
+	closed  chan struct{}
+	partial [][2]float64
+
+	// idleTimeout is the time without samples from the source after which the source ends, 0 for never
+	idleTimeout time.Duration
+	// lastBlock is the time the last block was received from the source
+	lastBlock time.Time
+
+	closeOnce sync.Once
+	closer    io.Closer
+	closerMu  sync.Mutex
+	position  int
+}
+
+// openLiveSource opens the live source described by song, resampling wav input to sampleRate (if not 0)
+func openLiveSource(song string, sampleRate int) (beep.StreamSeekCloser, error) {
+	source := strings.TrimPrefix(song, LivePrefix)
+	kind, arg := source, ""
+	if i := strings.Index(source, ":"); 0 <= i {
+		kind, arg = source[:i], source[i+1:]
+	}
+
+	switch {
+	case kind == "stdin" && arg == "":
+		// stdin can only be read once, so all live:stdin entries share one source
+		stdinSourceOnce.Do(func() {
+			stdinSource = newLiveSource(nil)
+			go stdinSource.read(func() (io.ReadCloser, error) { return os.Stdin, nil }, sampleRate)
+		})
+		return &sharedLiveSource{stdinSource}, nil
+	case kind == "fifo" && arg != "":
+		ls := newLiveSource(make(chan struct{}))
+		// opening a fifo blocks until a writer opens it, so it is opened in the background
+		go ls.read(func() (io.ReadCloser, error) { return os.Open(arg) }, sampleRate)
+		return ls, nil
+	case kind == "device" && arg != "":
+		ls := newLiveSource(make(chan struct{}))
+		go ls.read(func() (io.ReadCloser, error) { return captureDevice(arg, sampleRate) }, sampleRate)
+		return ls, nil
+	}
+	return nil, fmt.Errorf("invalid live source %s, use live:stdin, live:fifo:path or live:device:name", song)
+}
+
+func newLiveSource(closed chan struct{}) *liveSource {
+	return &liveSource{blocks: make(chan [][2]float64, liveBlockCount), closed: closed, lastBlock: time.Now()}
+}
+
+// read opens the source and decodes it into blocks until it ends or the live source is closed
+func (ls *liveSource) read(open func() (io.ReadCloser, error), sampleRate int) {
+	defer close(ls.blocks)
+	rc, err := open()
+	if err != nil {
+		logger.Warnf("failed to open live source: %v", err)
+		return
+	}
+	ls.closerMu.Lock()
+	ls.closer = rc
+	ls.closerMu.Unlock()
+	if ls.isClosed() {
+		rc.Close()
+		return
+	}
+
+	s, err := decodeLive(bufio.NewReader(rc), sampleRate)
+	if err != nil {
+		logger.Warnf("failed to decode live source: %v", err)
+		return
+	}
+	ls.readBlocks(s)
+}
+
+// readBlocks streams s into blocks until it ends or the live source is closed
+func (ls *liveSource) readBlocks(s beep.Streamer) {
+	for {
+		block := make([][2]float64, streamerBufferSize)
+		n, ok := s.Stream(block)
+		if 0 < n {
+			select {
+			case ls.blocks <- block[:n]:
+			case <-ls.closed:
+				return
+			}
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// decodeLive decodes wav (detected by its RIFF header) or raw pcm from r
+func decodeLive(r *bufio.Reader, sampleRate int) (beep.Streamer, error) {
+	if head, _ := r.Peek(4); string(head) != "RIFF" {
+		return &rawPCMStreamer{r: r}, nil
+	}
+	s, format, err := wav.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	if sampleRate != 0 && int(format.SampleRate) != sampleRate {
+		return beep.Resample(4, format.SampleRate, beep.SampleRate(sampleRate), s), nil
+	}
+	return s, nil
+}
+
+func (ls *liveSource) isClosed() bool {
+	if ls.closed == nil {
+		return false
+	}
+	select {
+	case <-ls.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stream streams the samples read from the source. If the source does not deliver samples in time,
+// the missing samples are filled with silence. If it did not deliver samples for idleTimeout, it is closed.
+func (ls *liveSource) Stream(samples [][2]float64) (n int, ok bool) {
+	for n < len(samples) {
+		if len(ls.partial) == 0 {
+			select {
+			case block, open := <-ls.blocks:
+				if !open {
+					ls.position += n
+					return n, 0 < n
+				}
+				ls.partial, ls.lastBlock = block, time.Now()
+			case <-time.After(liveUnderrunWait):
+				if 0 < ls.idleTimeout && ls.idleTimeout <= time.Since(ls.lastBlock) {
+					logger.Warnf("source sent no audio for %s, ending it", ls.idleTimeout)
+					ls.Close()
+					ls.position += n
+					return n, 0 < n
+				}
+				for ; n < len(samples); n++ {
+					samples[n] = [2]float64{}
+				}
+				ls.position += n
+				return n, true
+			}
+		}
+		c := copy(samples[n:], ls.partial)
+		ls.partial = ls.partial[c:]
+		n += c
+	}
+	ls.position += n
+	return n, true
+}
+
+func (ls *liveSource) Err() error { return nil }
+
+// Len returns 0, as live sources have no length
+func (ls *liveSource) Len() int { return 0 }
+
+func (ls *liveSource) Position() int { return ls.position }
+
+func (ls *liveSource) Seek(int) error { return fmt.Errorf("live sources can not seek") }
+
+// Close stops reading the source and closes it
+func (ls *liveSource) Close() error {
+	ls.closeOnce.Do(func() {
+		if ls.closed != nil {
+			close(ls.closed)
+		}
+	})
+	ls.closerMu.Lock()
+	defer ls.closerMu.Unlock()
+	if ls.closer != nil {
+		return ls.closer.Close()
+	}
+	return nil
+}
+
+// sharedLiveSource is a live source, which keeps reading when closed, so it can be reopened
+type sharedLiveSource struct {
+	*liveSource
+}
+
+func (sharedLiveSource) Close() error { return nil }
+
+// rawPCMStreamer decodes raw signed 16 bit little endian stereo pcm. It returns the samples available
+// without waiting for a full buffer, so live input is passed on as soon as it arrives.
+type rawPCMStreamer struct {
+	r      io.Reader
+	buf    []byte
+	filled int
+	err    error
+}
+
+func (rs *rawPCMStreamer) Stream(samples [][2]float64) (int, bool) {
+	if len(rs.buf) < 4*len(samples) {
+		buf := make([]byte, 4*len(samples))
+		copy(buf, rs.buf[:rs.filled])
+		rs.buf = buf
+	}
+	for rs.filled < 4 && rs.err == nil {
+		var read int
+		read, rs.err = rs.r.Read(rs.buf[rs.filled : 4*len(samples)])
+		rs.filled += read
+	}
+
+	n := rs.filled / 4
+	if len(samples) < n {
+		n = len(samples)
+	}
+	for i := 0; i < n; i++ {
+		samples[i][0] = float64(int16(binary.LittleEndian.Uint16(rs.buf[4*i:]))) / (1 << 15)
+		samples[i][1] = float64(int16(binary.LittleEndian.Uint16(rs.buf[4*i+2:]))) / (1 << 15)
+	}
+	rs.filled = copy(rs.buf, rs.buf[4*n:rs.filled])
+	return n, 0 < n
+}
+
+func (rs *rawPCMStreamer) Err() error { return nil }
+
+// captureProcess is a running arecord process capturing from a device
+type captureProcess struct {
+	io.ReadCloser
+	cmd *exec.Cmd
+}
+
+func (cp *captureProcess) Close() error {
+	cp.cmd.Process.Kill()
+	cp.ReadCloser.Close()
+	return cp.cmd.Wait()
+}
+
+// captureDevice starts arecord to capture wav audio from the alsa device name
+func captureDevice(name string, sampleRate int) (io.ReadCloser, error) {
+	if sampleRate == 0 {
+		sampleRate = 44100
+	}
+	cmd := exec.Command("arecord", "-q", "-D", name, "-t", "wav", "-f", "S16_LE", "-c", "2", "-r", strconv.Itoa(sampleRate))
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start arecord: %v", err)
+	}
+	return &captureProcess{ReadCloser: out, cmd: cmd}, nil
+}
@@ -0,0 +1,120 @@
+package playback
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func rawPCM(samples [][2]int16) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, samples)
+	return buf.Bytes()
+}
+
+func wavPCM(sampleRate int, samples [][2]int16) []byte {
+	data := rawPCM(samples)
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+len(data)))
+	buf.WriteString("WAVEfmt ")
+	for _, v := range []interface{}{uint32(16), uint16(1), uint16(2), uint32(sampleRate), uint32(4 * sampleRate), uint16(4), uint16(16)} {
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func TestIsLiveSource(t *testing.T) {
+	assert.True(t, IsLiveSource("live:stdin"), "IsLiveSource did not detect a live source")
+	assert.False(t, IsLiveSource("dir/live.mp3"), "IsLiveSource detected a file as live source")
+}
+
+func TestOpenLiveSource(t *testing.T) {
+	for _, song := range []string{"live:", "live:stdin:x", "live:fifo", "live:fifo:", "live:device", "live:tv"} {
+		_, err := openLiveSource(song, 44100)
+		assert.NotNil(t, err, "openLiveSource accepted the invalid source %s", song)
+	}
+}
+
+func TestRawPCMStreamer(t *testing.T) {
+	rs := &rawPCMStreamer{r: bytes.NewReader(append(rawPCM([][2]int16{{16384, -16384}, {-32768, 0}}), 1))}
+	samples := make([][2]float64, 4)
+	n, ok := rs.Stream(samples)
+	assert.Equal(t, 2, n, "rawPCMStreamer decoded the wrong number of samples")
+	assert.True(t, ok, "rawPCMStreamer ended early")
+	assert.Equal(t, [][2]float64{{0.5, -0.5}, {-1, 0}}, samples[:n], "rawPCMStreamer decoded the wrong samples")
+	n, ok = rs.Stream(samples)
+	assert.Equal(t, 0, n, "rawPCMStreamer decoded an incomplete sample")
+	assert.False(t, ok, "rawPCMStreamer did not end with the input")
+}
+
+func TestDecodeLive(t *testing.T) {
+	s, err := decodeLive(bufio.NewReader(bytes.NewReader(wavPCM(44100, [][2]int16{{16384, -16384}}))), 44100)
+	if assert.Nil(t, err, "decodeLive returned an error for wav") {
+		samples := make([][2]float64, 2)
+		n, _ := s.Stream(samples)
+		if assert.Equal(t, 1, n, "decodeLive decoded the wrong number of wav samples") {
+			assert.InDelta(t, 0.5, samples[0][0], 1e-4, "decodeLive decoded the wrong wav sample")
+			assert.InDelta(t, -0.5, samples[0][1], 1e-4, "decodeLive decoded the wrong wav sample")
+		}
+	}
+
+	s, err = decodeLive(bufio.NewReader(bytes.NewReader(wavPCM(22050, make([][2]int16, 1000)))), 44100)
+	if assert.Nil(t, err, "decodeLive returned an error for wav with another sample rate") {
+		total := 0
+		samples := make([][2]float64, 512)
+		for n, ok := s.Stream(samples); ok; n, ok = s.Stream(samples) {
+			total += n
+		}
+		assert.InDelta(t, 2000, total, 8, "decodeLive did not resample the wav")
+	}
+
+	s, err = decodeLive(bufio.NewReader(bytes.NewReader(rawPCM([][2]int16{{0, 16384}}))), 44100)
+	if assert.Nil(t, err, "decodeLive returned an error for raw pcm") {
+		assert.IsType(t, &rawPCMStreamer{}, s, "decodeLive did not decode raw pcm")
+	}
+}
+
+func TestLiveSource(t *testing.T) {
+	pr, pw := io.Pipe()
+	ls := newLiveSource(make(chan struct{}))
+	go ls.read(func() (io.ReadCloser, error) { return pr, nil }, 44100)
+
+	go pw.Write(rawPCM([][2]int16{{16384, 16384}, {16384, 16384}}))
+	samples := make([][2]float64, 4)
+	n, ok := ls.Stream(samples[:2])
+	assert.True(t, ok, "live source ended early")
+	assert.Equal(t, [][2]float64{{0.5, 0.5}, {0.5, 0.5}}, samples[:n], "live source streamed the wrong samples")
+
+	samples[0] = [2]float64{1, 1}
+	n, ok = ls.Stream(samples)
+	assert.True(t, ok, "live source ended while the source was stalled")
+	assert.Equal(t, make([][2]float64, 4), samples[:n], "live source did not fill a stalled source with silence")
+	assert.Equal(t, 6, ls.Position(), "live source has the wrong position")
+
+	pw.Close()
+	n, ok = ls.Stream(samples)
+	assert.False(t, ok, "live source did not end with the source")
+	assert.Equal(t, 0, n, "live source streamed samples after the source ended")
+}
+
+func TestLiveSource_Close(t *testing.T) {
+	pr, pw := io.Pipe()
+	ls := newLiveSource(make(chan struct{}))
+	go ls.read(func() (io.ReadCloser, error) { return pr, nil }, 44100)
+	go pw.Write(rawPCM(make([][2]int16, (liveBlockCount+2)*streamerBufferSize)))
+
+	samples := make([][2]float64, 1)
+	ls.Stream(samples)
+	assert.Nil(t, ls.Close(), "live source Close returned an error")
+	for range ls.blocks {
+	}
+	_, err := pw.Write([]byte{0})
+	assert.NotNil(t, err, "live source Close did not close the source")
+}
@@ -0,0 +1,143 @@
+package playback
+
+import (
+	"fmt"
+	"github.com/faiface/beep"
+	"math"
+	"time"
+)
+
+// ReferenceLoudness is the loudness in LUFS songs are normalized to. It matches the replay gain reference level.
+const ReferenceLoudness = -18.0
+
+const (
+	blockStepDuration      = 100 * time.Millisecond
+	loudnessBlockSteps     = 4 // a gating block (400ms) consists of 4 steps (100ms each)
+	loudnessAbsoluteGate   = -70.0
+	loudnessRelativeGateLU = -10.0
+)
+
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	z1, z2             float64
+}
+
+func (bq *biquad) process(x float64) float64 {
+	y := bq.b0*x + bq.z1
+	bq.z1 = bq.b1*x - bq.a1*y + bq.z2
+	bq.z2 = bq.b2*x - bq.a2*y
+	return y
+}
+
+// newKWeightingFilters creates the two filter stages of the K-weighting described in ITU-R BS.1770
+func newKWeightingFilters(sampleRate float64) (shelf, highPass biquad) {
+	f0, gain, q := 1681.974450955533, 3.999843853973347, 0.7071752369554196
+	k := math.Tan(math.Pi * f0 / sampleRate)
+	vh := math.Pow(10, gain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	shelf = biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	f0, q = 38.13547087602444, 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / sampleRate)
+	a0 = 1 + k/q + k*k
+	highPass = biquad{
+		b0: 1, b1: -2, b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+	return
+}
+
+// MeasureLoudness measures the integrated loudness of a stereo streamer in LUFS as specified by EBU R128.
+// It returns -Inf for silence.
+func MeasureLoudness(s beep.Streamer, sampleRate beep.SampleRate) float64 {
+	stepSize := sampleRate.N(blockStepDuration)
+	var filters [2][2]biquad
+	for c := range filters {
+		filters[c][0], filters[c][1] = newKWeightingFilters(float64(sampleRate))
+	}
+
+	steps := make([]float64, 0)
+	buf := make([][2]float64, stepSize)
+	for {
+		n, ok := s.Stream(buf)
+		if n == stepSize {
+			energy := 0.0
+			for _, sample := range buf[:n] {
+				for c := range sample {
+					v := filters[c][1].process(filters[c][0].process(sample[c]))
+					energy += v * v
+				}
+			}
+			steps = append(steps, energy/float64(stepSize))
+		}
+		if !ok || n < stepSize {
+			break
+		}
+	}
+
+	return integrateLoudness(steps)
+}
+
+func blockLoudness(energy float64) float64 {
+	return -0.691 + 10*math.Log10(energy)
+}
+
+func integrateLoudness(steps []float64) float64 {
+	blocks := make([]float64, 0, len(steps))
+	for i := 0; i+loudnessBlockSteps <= len(steps); i++ {
+		energy := 0.0
+		for _, e := range steps[i : i+loudnessBlockSteps] {
+			energy += e
+		}
+		blocks = append(blocks, energy/loudnessBlockSteps)
+	}
+
+	gated := func(threshold float64) (float64, int) {
+		sum, count := 0.0, 0
+		for _, b := range blocks {
+			if threshold < blockLoudness(b) {
+				sum += b
+				count++
+			}
+		}
+		return sum, count
+	}
+
+	sum, count := gated(loudnessAbsoluteGate)
+	if count == 0 {
+		return math.Inf(-1)
+	}
+	sum, count = gated(blockLoudness(sum/float64(count)) + loudnessRelativeGateLU)
+	if count == 0 {
+		return math.Inf(-1)
+	}
+	return blockLoudness(sum / float64(count))
+}
+
+// MeasureSongLoudness decodes a song from the AudioDir and measures its integrated loudness in LUFS
+func MeasureSongLoudness(filename string, sampleRate int) (float64, error) {
+	s, err := getStreamer(filename)
+	if err != nil {
+		return 0, err
+	}
+	defer s.Close()
+
+	loudness := MeasureLoudness(s, beep.SampleRate(sampleRate))
+	if err := s.Err(); err != nil {
+		return 0, fmt.Errorf("failed to decode %s: %v", filename, err)
+	}
+	return loudness, nil
+}
+
+// GainToFactor converts a gain in dB to a linear factor
+func GainToFactor(gain float64) float64 {
+	return math.Pow(10, gain/20)
+}
@@ -0,0 +1,69 @@
+package playback
+
+import (
+	"github.com/faiface/beep"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func sineStreamer(sampleRate beep.SampleRate, freq, amplitude float64, seconds int) beep.Streamer {
+	i := 0
+	n := sampleRate.N(1e9) * seconds
+	return beep.StreamerFunc(func(samples [][2]float64) (int, bool) {
+		if n <= i {
+			return 0, false
+		}
+		c := 0
+		for ; c < len(samples) && i < n; c++ {
+			v := amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate))
+			samples[c] = [2]float64{v, v}
+			i++
+		}
+		return c, true
+	})
+}
+
+func TestMeasureLoudness(t *testing.T) {
+	l := MeasureLoudness(sineStreamer(48000, 997, 1, 5), 48000)
+	assert.InDelta(t, 0, l, 0.1, "MeasureLoudness returned the wrong loudness for a full scale sine")
+
+	l = MeasureLoudness(sineStreamer(44100, 997, 0.1, 5), 44100)
+	assert.InDelta(t, -20, l, 0.1, "MeasureLoudness returned the wrong loudness for a -20 dB sine")
+
+	l = MeasureLoudness(sineStreamer(48000, 997, 0, 5), 48000)
+	assert.True(t, math.IsInf(l, -1), "MeasureLoudness did not return -Inf for silence")
+}
+
+func TestMeasureSongLoudness(t *testing.T) {
+	ad := AudioDir
+	AudioDir = "_playback_test_files"
+	defer func() { AudioDir = ad }()
+
+	_, err := MeasureSongLoudness("non-existent.mp3", 44100)
+	assert.NotNil(t, err, "MeasureSongLoudness did not return an error for a non-existent song")
+
+	_, err = MeasureSongLoudness("okay.mp3", 44100)
+	assert.Nil(t, err, "MeasureSongLoudness returned an error for a valid song")
+}
+
+func TestGainToFactor(t *testing.T) {
+	assert.InDelta(t, 1, GainToFactor(0), 1e-9, "GainToFactor returned the wrong factor for 0 dB")
+	assert.InDelta(t, 0.1, GainToFactor(-20), 1e-9, "GainToFactor returned the wrong factor for -20 dB")
+}
+
+func TestLimiter_process(t *testing.T) {
+	l := newLimiter()
+	samples := make([][2]float64, 4096)
+	for i := range samples {
+		samples[i] = [2]float64{4 * math.Sin(float64(i)/10), -2 * math.Sin(float64(i)/10)}
+	}
+	l.process(samples)
+	for i, s := range samples {
+		assert.True(t, math.Abs(s[0]) <= limiterThreshold+1e-9 && math.Abs(s[1]) <= limiterThreshold+1e-9, "limiter did not limit sample %d: %v", i, s)
+	}
+
+	quiet := [][2]float64{{0.1, -0.1}}
+	newLimiter().process(quiet)
+	assert.Equal(t, [][2]float64{{0.1, -0.1}}, quiet, "limiter changed a quiet sample")
+}
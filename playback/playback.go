@@ -24,6 +24,7 @@ var (
 	streamer   *timedMultiStreamer
 	bufferSize int
 	volume     float64
+	dsp        = NewDSP(0)
 )
 
 var logger = log.GetLogger("play")
@@ -32,29 +33,61 @@ var logger = log.GetLogger("play")
 var AudioDir string
 
 func getStreamer(filename string) (beep.StreamSeekCloser, error) {
+	if IsURL(filename) {
+		return openHTTPStream(filename, nil)
+	}
+	if _, _, ok := ParseCueTrackSong(filename); ok {
+		s, _, err := openCueTrack(filename)
+		return s, err
+	}
+	s, _, err := decodeFile(filename)
+	return s, err
+}
+
+// decodeFile decodes the file filename in the AudioDir
+func decodeFile(filename string) (beep.StreamSeekCloser, beep.Format, error) {
 	filename = path.Join(AudioDir, filename)
 	f, err := os.Open(filename)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file %s: %v", filename, err)
+		return nil, beep.Format{}, fmt.Errorf("failed to open file %s: %v", filename, err)
 	}
 
-	s, _, err := mp3.Decode(f)
+	s, format, err := mp3.Decode(f)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode file %s: %v", filename, err)
+		return nil, beep.Format{}, fmt.Errorf("failed to decode file %s: %v", filename, err)
 	}
 
-	return s, nil
+	return s, format, nil
+}
+
+// SongDuration returns the duration of the file filename in the AudioDir. For cue tracks, it is the duration of
+// the track. The duration is the length of the decoder, which only reads the frame headers of the file.
+func SongDuration(filename string) (time.Duration, error) {
+	if _, _, ok := ParseCueTrackSong(filename); ok {
+		s, format, start, end, err := decodeCueTrackFile(filename)
+		if err != nil {
+			return 0, err
+		}
+		defer s.Close()
+		return format.SampleRate.D(end - start), nil
+	}
+	s, format, err := decodeFile(filename)
+	if err != nil {
+		return 0, err
+	}
+	defer s.Close()
+	return format.SampleRate.D(s.Len()), nil
 }
 
 // QueueChunk queue a chunk for playback
-func QueueChunk(startTime int64, chunkID int64, samples [][2]float64) {
+func QueueChunk(startTime int64, chunkID int64, firstSampleIndex uint64, samples [][2]float64) {
 	if streamer == nil {
 		logger.Infof("not queuing chunk %d: streamer not ready", chunkID)
 		return
 	}
 	logger.Debugf("queueing chunk %d at %d", chunkID, startTime)
 
-	q := newQueuedStream(startTime, samples)
+	q := newQueuedStream(startTime, firstSampleIndex, samples)
 	streamer.chunksMutex.Lock()
 	streamer.chunks = append(streamer.chunks, q)
 	streamer.chunksMutex.Unlock()
@@ -80,8 +113,9 @@ func Init(sampleRate int) error {
 	logger.Infof("initializing playback")
 	var err error
 
-	volume = .1
+	SetVolume(.1)
 	format = beep.Format{SampleRate: beep.SampleRate(sampleRate), NumChannels: 2, Precision: 2}
+	dsp.SetSampleRate(sampleRate)
 
 	bufferSize = format.SampleRate.N(time.Second / 10)
 	ctx, err := oto.NewContext(int(format.SampleRate), format.NumChannels, format.Precision,
@@ -112,12 +146,21 @@ func initStreamer() {
 	}
 }
 
-// SetVolume sets the playback volume of the player
+// SetVolume sets the playback volume of the player immediately
 func SetVolume(v float64) {
+	volumeMutex.Lock()
 	volume = v
+	ramp = volumeRamp{from: v, to: v}
+	volumeMutex.Unlock()
 	logger.Infof("volume set to %.3f", v)
 }
 
+// SetDSPConfig sets the effects applied by the player
+func SetDSPConfig(config DSPConfig) {
+	dsp.SetConfig(config)
+	logger.Infof("dsp config set to %+v", config)
+}
+
 func playLoop(ctx context.Context) {
 	numBytes := bufferSize * format.NumChannels * format.Precision
 	samples := make([][2]float64, bufferSize)
@@ -125,15 +168,17 @@ func playLoop(ctx context.Context) {
 
 	for !util.IsCanceled(ctx) {
 		streamer.Stream(samples)
+		dsp.Process(samples)
 		samplesToAudioBuf(samples, buf)
 		player.Write(buf)
 	}
 }
 
+// samplesToAudioBuf converts samples to bytes. The volume is applied by the streamer.
 func samplesToAudioBuf(samples [][2]float64, buf []byte) {
 	for i := range samples {
 		for c := range samples[i] {
-			buf[i*4+c*2+0], buf[i*4+c*2+1] = convertSampleToBytes(samples[i][c] * volume)
+			buf[i*4+c*2+0], buf[i*4+c*2+1] = convertSampleToBytes(samples[i][c])
 		}
 	}
 }
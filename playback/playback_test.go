@@ -88,7 +88,7 @@ func TestQueueChunk(t *testing.T) {
 	}
 
 	for i := 0; i < 16; i++ {
-		QueueChunk(startTime[i], int64(i), samples[i])
+		QueueChunk(startTime[i], int64(i), uint64(i*512), samples[i])
 		if !assert.Equal(t, i+1, len(streamer.chunks), "streamer.chunks has wrong length after queueing %d chunks", i+1) {
 			continue
 		}
@@ -101,7 +101,7 @@ func TestQueueChunk(t *testing.T) {
 	}
 
 	streamer = nil
-	QueueChunk(startTime[0], 0, samples[0])
+	QueueChunk(startTime[0], 0, 0, samples[0])
 
 	if oldStreamer != nil {
 		streamer = oldStreamer
@@ -109,23 +109,17 @@ func TestQueueChunk(t *testing.T) {
 }
 
 func TestSamplesToAudioBuf(t *testing.T) {
-	oldVolume := volume
-
 	samples := createSampleSlice(0, 1024)
 	buf := make([]byte, 4*len(samples))
-	for volume = 0; volume <= 1; volume += 0.125 {
-		samplesToAudioBuf(samples, buf)
-		for i := 0; i < len(samples); i++ {
-			ell, elh := convertSampleToBytes(samples[i][0] * volume)
-			ehl, ehh := convertSampleToBytes(samples[i][1] * volume)
-			assert.Equal(t, ell, buf[4*i+0], "samplesToAudioBuf has the wrong lower byte for the lower sample at index %d with volume %f", i, volume)
-			assert.Equal(t, elh, buf[4*i+1], "samplesToAudioBuf has the wrong higher byte for the lower sample at index %d with volume %f", i, volume)
-			assert.Equal(t, ehl, buf[4*i+2], "samplesToAudioBuf has the wrong lower byte for the higher sample at index %d with volume %f", i, volume)
-			assert.Equal(t, ehh, buf[4*i+3], "samplesToAudioBuf has the wrong higher byte for the higher sample at index %d with volume %f", i, volume)
-		}
+	samplesToAudioBuf(samples, buf)
+	for i := 0; i < len(samples); i++ {
+		ell, elh := convertSampleToBytes(samples[i][0])
+		ehl, ehh := convertSampleToBytes(samples[i][1])
+		assert.Equal(t, ell, buf[4*i+0], "samplesToAudioBuf has the wrong lower byte for the lower sample at index %d", i)
+		assert.Equal(t, elh, buf[4*i+1], "samplesToAudioBuf has the wrong higher byte for the lower sample at index %d", i)
+		assert.Equal(t, ehl, buf[4*i+2], "samplesToAudioBuf has the wrong lower byte for the higher sample at index %d", i)
+		assert.Equal(t, ehh, buf[4*i+3], "samplesToAudioBuf has the wrong higher byte for the higher sample at index %d", i)
 	}
-
-	volume = oldVolume
 }
 
 func TestInitStreamer(t *testing.T) {
@@ -11,25 +11,63 @@ import (
 const streamerBufferSize = 512
 const noSongNanSampleCount = 32768
 
+// PlaylistEntry is a song in the playlist
+type PlaylistEntry struct {
+	// ID identifies the entry for as long as it is in the playlist. IDs are not reused.
+	ID   int
+	Song string
+	// AutoQueued is true, if the song was added by the auto queue handler
+	AutoQueued bool
+}
+
 // Playlist is a array of songs, which can then be streamed.
 // After reaching the end of the playlist, playback will resume at the start.
 type Playlist struct {
-	songs        []string
+	songs        []PlaylistEntry
 	songsMutex   sync.RWMutex
+	lastEntryID  int
 	position     int
 	low          chan float64
 	high         chan float64
 	forceNext    chan bool
 	nanBreakSize int
 
-	playing     bool
-	currentSong string
+	playing      bool
+	playingMutex sync.RWMutex
+	currentSong  string
 
 	sampleIndexRead  uint64
 	sampleIndexWrite uint64
 
-	newSongHandler     func(startSampleIndex uint64, filename string, songLength int64)
+	newSongHandler     func(startSampleIndex uint64, filename string, songLength int64, trimStart int64, speed float64)
 	pauseToggleHandler func(playing bool, sample uint64)
+	autoQueueHandler   func(lastSong string, upcoming int) []string
+	gainHandler        func(song string) float64
+	streamTitleHandler func(startSampleIndex uint64, url string, title string)
+	trimHandler        func(song string) (start, end int)
+	speedHandler       func(sampleIndex uint64, speed float64, songPosition int64, streamed int64)
+	seekHandler        func(sampleIndex uint64, speed float64, songPosition int64, streamed int64)
+
+	silenceThreshold float64
+	// silence caches the detected silence of the songs
+	silence      map[silenceKey]silenceBounds
+	silenceMutex sync.Mutex
+	speed        float64
+
+	pendingSeek *seekRequest
+	seekMutex   sync.Mutex
+
+	announcements       chan beep.StreamSeekCloser
+	announcement        *announcement
+	announcementHandler func(startSampleIndex uint64, length int64)
+	// duckDepth is the part of the music volume taken away while announcements play
+	duckDepth float64
+
+	dsp        *DSP
+	sampleRate int
+
+	fadeLength int
+	fadeInNext bool
 
 	playingLast bool
 }
@@ -40,6 +78,7 @@ type Playlist struct {
 // the context was canceled.
 func (pl *Playlist) StreamLoop(ctx context.Context) {
 	for !util.IsCanceled(ctx) {
+		pl.autoQueue()
 		filename := pl.nextSong()
 		if filename == "" {
 			pl.pushNanSamples(noSongNanSampleCount)
@@ -48,18 +87,37 @@ func (pl *Playlist) StreamLoop(ctx context.Context) {
 
 		pl.currentSong = filename
 
-		s, err := getStreamer(filename)
+		s, err := pl.openSong(filename)
 		if err != nil {
 			logger.Warnf("skipping song %s in playlist: failed to get streamer: %v", filename, err)
 			pl.position++
 			continue
 		}
 
+		pl.prefetchSilence()
 		pl.pushStreamer(s)
+		s.Close()
 		pl.pushNanBreak()
 	}
 }
 
+func (pl *Playlist) autoQueue() {
+	if pl.autoQueueHandler == nil {
+		return
+	}
+
+	pl.songsMutex.RLock()
+	upcoming := len(pl.songs) - pl.position
+	pl.songsMutex.RUnlock()
+	if upcoming < 0 {
+		upcoming = 0
+	}
+
+	for _, song := range pl.autoQueueHandler(pl.currentSong, upcoming) {
+		pl.addSong(song, true)
+	}
+}
+
 func (pl *Playlist) nextSong() (song string) {
 	pl.songsMutex.RLock()
 	defer pl.songsMutex.RUnlock()
@@ -68,31 +126,142 @@ func (pl *Playlist) nextSong() (song string) {
 	}
 	pos := pl.position % len(pl.songs)
 	pl.position = pos
-	return pl.songs[pos]
+	return pl.songs[pos].Song
 }
 
 func (pl *Playlist) pushStreamer(s beep.StreamSeekCloser) {
 	buf := make([][2]float64, streamerBufferSize)
+	s, trimStart := pl.trim(s)
+	stretcher := newTimeStretcher(s, 1)
+	if 0 < s.Len() {
+		stretcher.speed = pl.speed
+	}
 	if pl.newSongHandler != nil {
-		go pl.newSongHandler(pl.sampleIndexWrite, pl.currentSong, int64(s.Len()))
+		go pl.newSongHandler(pl.sampleIndexWrite, pl.currentSong, int64(s.Len()), int64(trimStart), stretcher.speed)
+	}
+	stream := pl.songStreamer(stretcher)
+	// seek requests made before the song started refer to the previous song
+	pl.takeSeek()
+
+	var fadeIn *fade
+	if pl.fadeInNext {
+		fadeIn = newFade(pl.fadeLength, true)
+		pl.fadeInNext = false
 	}
+	wasPlaying := pl.Playing()
 
 	for {
 		n, ok := streamerBufferSize, true
+		playing := pl.Playing()
+		if wasPlaying && !playing {
+			ok = pl.pushFadeOut(stream, buf)
+		} else if !wasPlaying && playing {
+			fadeIn = newFade(pl.fadeLength, true)
+		}
+		wasPlaying = playing
 		pl.callPauseToggleHandler()
-		if pl.playing {
-			n, ok = s.Stream(buf)
+		if 0 < s.Len() {
+			pl.applySpeed(stretcher)
+		}
+		if request, seeking := pl.takeSeek(); seeking && 0 < s.Len() {
+			if playing {
+				ok = pl.pushFadeOut(stream, buf)
+				fadeIn = newFade(pl.fadeLength, true)
+			}
+			if ok {
+				pl.seek(s, stretcher, request)
+			}
+		}
+
+		if playing {
+			// the song keeps playing ducked below the announcement
+			pl.startPendingAnnouncement()
+		}
+
+		if !ok {
+			// the song ended while fading out
+			pl.position++
+			break
+		}
+
+		if playing {
+			n, ok = stream(buf)
+			fadeIn.apply(buf[:n])
 			pl.pushBuffer(buf[:n])
 		} else {
 			pl.pushNanSamples(streamerBufferSize)
 		}
 
+		if playing && ok && n == streamerBufferSize && 0 < len(pl.forceNext) {
+			pl.pushFadeOut(stream, buf)
+			pl.fadeInNext = true
+		}
 		if pl.shouldBreakStreamerPushLoop(n, ok, streamerBufferSize) {
 			break
 		}
 	}
 }
 
+// applySpeed changes the speed of the stretcher to the speed of the playlist, beginning with the next sample
+// written. Songs without a known length (streams and live sources) are never stretched.
+func (pl *Playlist) applySpeed(stretcher *timeStretcher) {
+	speed := pl.speed
+	if speed == stretcher.speed {
+		return
+	}
+	stretcher.speed = speed
+	if pl.speedHandler != nil {
+		go pl.speedHandler(pl.sampleIndexWrite, speed, int64(stretcher.position()), int64(stretcher.produced))
+	}
+}
+
+// songStreamer returns a function streaming from s, which applies the gain and dsp to the samples
+func (pl *Playlist) songStreamer(s beep.Streamer) func([][2]float64) (int, bool) {
+	gain := 1.0
+	if pl.gainHandler != nil {
+		gain = pl.gainHandler(pl.currentSong)
+	}
+	lim := newLimiter()
+
+	return func(samples [][2]float64) (int, bool) {
+		n, ok := s.Stream(samples)
+		if gain != 1 {
+			applyGain(samples[:n], gain)
+			lim.process(samples[:n])
+		}
+		if pl.dsp != nil {
+			pl.dsp.Process(samples[:n])
+		}
+		return n, ok
+	}
+}
+
+// pushFadeOut pushes the next fadeLength samples with a fade out and returns false, if the song ended
+func (pl *Playlist) pushFadeOut(stream func([][2]float64) (int, bool), buf [][2]float64) bool {
+	f := newFade(pl.fadeLength, false)
+	for left := pl.fadeLength; 0 < left; {
+		size := left
+		if len(buf) < size {
+			size = len(buf)
+		}
+		n, ok := stream(buf[:size])
+		f.apply(buf[:n])
+		pl.pushBuffer(buf[:n])
+		left -= n
+		if !ok || n < size {
+			return false
+		}
+	}
+	return true
+}
+
+func applyGain(samples [][2]float64, gain float64) {
+	for i := range samples {
+		samples[i][0] *= gain
+		samples[i][1] *= gain
+	}
+}
+
 func (pl *Playlist) shouldBreakStreamerPushLoop(n int, ok bool, bufSize int) bool {
 	if 0 < len(pl.forceNext) && <-pl.forceNext {
 		return true
@@ -106,6 +275,7 @@ func (pl *Playlist) shouldBreakStreamerPushLoop(n int, ok bool, bufSize int) boo
 }
 
 func (pl *Playlist) pushNanSamples(count int) {
+	pl.startPendingAnnouncement()
 	for i := 0; i < count; i++ {
 		pl.pushSample(math.NaN(), math.NaN())
 	}
@@ -118,21 +288,25 @@ func (pl *Playlist) pushBuffer(buffer [][2]float64) {
 }
 
 func (pl *Playlist) pushNanBreak() {
+	pl.startPendingAnnouncement()
 	for i := 0; i < pl.nanBreakSize; i++ {
 		pl.pushSample(math.NaN(), math.NaN())
 	}
 }
 
 func (pl *Playlist) pushSample(low, high float64) {
+	low, high = pl.duck(low, high)
+	low, high = pl.mixAnnouncement(low, high)
 	pl.low <- low
 	pl.high <- high
 	pl.sampleIndexWrite++
 }
 
 func (pl *Playlist) callPauseToggleHandler() {
-	if pl.playing != pl.playingLast && pl.pauseToggleHandler != nil {
-		pl.playingLast = pl.playing
-		go pl.pauseToggleHandler(pl.playing, pl.sampleIndexWrite)
+	playing := pl.Playing()
+	if playing != pl.playingLast && pl.pauseToggleHandler != nil {
+		pl.playingLast = playing
+		go pl.pauseToggleHandler(playing, pl.sampleIndexWrite)
 	}
 }
 
@@ -157,15 +331,45 @@ func (pl *Playlist) Songs() []string {
 	pl.songsMutex.RLock()
 	defer pl.songsMutex.RUnlock()
 	r := make([]string, len(pl.songs))
+	for i, e := range pl.songs {
+		r[i] = e.Song
+	}
+	return r
+}
+
+// Entries returns all entries of the playlist.
+func (pl *Playlist) Entries() []PlaylistEntry {
+	pl.songsMutex.RLock()
+	defer pl.songsMutex.RUnlock()
+	r := make([]PlaylistEntry, len(pl.songs))
 	copy(r, pl.songs)
 	return r
 }
 
+// Replace replaces all songs in the playlist and starts playing the first new song.
+func (pl *Playlist) Replace(songs []string) {
+	pl.songsMutex.Lock()
+	pl.songs = pl.newEntries(songs)
+	pl.position = 0
+	pl.songsMutex.Unlock()
+
+	if pl.currentSong != "" {
+		select {
+		case pl.forceNext <- true:
+		default:
+		}
+	}
+}
+
 // AddSong adds a song at the end of the playlist.
 func (pl *Playlist) AddSong(song string) {
+	pl.addSong(song, false)
+}
+
+func (pl *Playlist) addSong(song string, autoQueued bool) {
 	pl.songsMutex.Lock()
 	defer pl.songsMutex.Unlock()
-	pl.songs = append(pl.songs, song)
+	pl.songs = append(pl.songs, pl.newEntry(song, autoQueued))
 }
 
 // InsertSong inserts a song into the playlist.
@@ -176,12 +380,13 @@ func (pl *Playlist) InsertSong(song string, index int) {
 	if index < 0 {
 		index = 0
 	}
+	entry := pl.newEntry(song, false)
 	if len(pl.songs) < index {
-		pl.songs = append(pl.songs, song)
+		pl.songs = append(pl.songs, entry)
 	} else {
-		pl.songs = append(pl.songs, "")
+		pl.songs = append(pl.songs, PlaylistEntry{})
 		copy(pl.songs[index+1:], pl.songs[index:])
-		pl.songs[index] = song
+		pl.songs[index] = entry
 	}
 }
 
@@ -198,18 +403,54 @@ func (pl *Playlist) RemoveSong(index int) string {
 		index = 0
 	}
 	var removed string
-	if len(pl.songs) < index {
-		removed = pl.songs[len(pl.songs)-1]
+	if len(pl.songs) <= index {
+		removed = pl.songs[len(pl.songs)-1].Song
 		pl.songs = pl.songs[:len(pl.songs)-1]
 	} else {
-		removed = pl.songs[index]
+		removed = pl.songs[index].Song
 		copy(pl.songs[index:], pl.songs[index+1:])
-		pl.songs[len(pl.songs)-1] = ""
+		pl.songs[len(pl.songs)-1] = PlaylistEntry{}
 		pl.songs = pl.songs[:len(pl.songs)-1]
 	}
+
+	if index == pl.position && removed == pl.currentSong {
+		// skip the removed song, the next song moved to its position
+		select {
+		case pl.forceNext <- true:
+		default:
+		}
+	}
 	return removed
 }
 
+// MoveSong moves the song at from to the index to, shifting the songs in between. The current song keeps playing.
+// It returns false, if from or to are not within the bounds of the playlist.
+func (pl *Playlist) MoveSong(from, to int) bool {
+	pl.songsMutex.Lock()
+	defer pl.songsMutex.Unlock()
+	if from < 0 || len(pl.songs) <= from || to < 0 || len(pl.songs) <= to {
+		return false
+	}
+
+	entry := pl.songs[from]
+	if from < to {
+		copy(pl.songs[from:to], pl.songs[from+1:to+1])
+	} else {
+		copy(pl.songs[to+1:from+1], pl.songs[to:from])
+	}
+	pl.songs[to] = entry
+
+	switch {
+	case pl.position == from:
+		pl.position = to
+	case from < pl.position && pl.position <= to:
+		pl.position--
+	case to <= pl.position && pl.position < from:
+		pl.position++
+	}
+	return true
+}
+
 // Fill reads the samples from the internal buffer and fills low and high with them.
 // low and high must have the same length.
 // returns the sampleIndex of the first read
@@ -225,11 +466,15 @@ func (pl *Playlist) Fill(low []float64, high []float64) uint64 {
 
 // Playing returns true if the playlist is currently playing audio.
 func (pl *Playlist) Playing() bool {
+	pl.playingMutex.RLock()
+	defer pl.playingMutex.RUnlock()
 	return pl.playing
 }
 
 // SetPlaying can set whether or not the playlist should be playing audio.
 func (pl *Playlist) SetPlaying(p bool) {
+	pl.playingMutex.Lock()
+	defer pl.playingMutex.Unlock()
 	pl.playing = p
 }
 
@@ -238,8 +483,10 @@ func (pl *Playlist) CurrentSong() string {
 	return pl.currentSong
 }
 
-// SetNewSongHandler sets the new song handler, which is called every time the playlist begins playing a new song
-func (pl *Playlist) SetNewSongHandler(nsh func(startSampleIndex uint64, filename string, songLength int64)) {
+// SetNewSongHandler sets the new song handler, which is called every time the playlist begins playing a new song.
+// songLength is the length of the trimmed song and trimStart the number of samples trimmed from its start.
+// The song is played at speed, so it is streamed for songLength/speed samples.
+func (pl *Playlist) SetNewSongHandler(nsh func(startSampleIndex uint64, filename string, songLength int64, trimStart int64, speed float64)) {
 	pl.newSongHandler = nsh
 }
 
@@ -248,21 +495,99 @@ func (pl *Playlist) SetPauseToggleHandler(psh func(playing bool, sample uint64))
 	pl.pauseToggleHandler = psh
 }
 
+// SetAutoQueueHandler sets the auto queue handler, which is called before a new song is selected.
+// It is passed the last song played and the number of songs left in the playlist, including the next song,
+// and returns songs to append to the playlist. Those songs are marked as auto queued.
+func (pl *Playlist) SetAutoQueueHandler(aqh func(lastSong string, upcoming int) []string) {
+	pl.autoQueueHandler = aqh
+}
+
+// SetGainHandler sets the gain handler, which is called when a song starts and returns the (linear) gain
+// to apply to the song. A limiter prevents clipping caused by the gain.
+func (pl *Playlist) SetGainHandler(gh func(song string) float64) {
+	pl.gainHandler = gh
+}
+
+// SetTrimHandler sets the trim handler, which is called when a song starts and returns the samples the song
+// starts and ends at. An end of 0 plays the song until its end.
+func (pl *Playlist) SetTrimHandler(th func(song string) (start, end int)) {
+	pl.trimHandler = th
+}
+
+// SetSpeedHandler sets the speed handler, which is called when the speed of the current song changes.
+// It is passed the index of the first sample played at speed, the position in the (trimmed) song at that sample
+// and the number of samples of the song streamed before it.
+func (pl *Playlist) SetSpeedHandler(sh func(sampleIndex uint64, speed float64, songPosition int64, streamed int64)) {
+	pl.speedHandler = sh
+}
+
+// SetSpeed sets the playback speed. The pitch of the songs is preserved.
+// The speed is applied to the current song with the next sample written.
+func (pl *Playlist) SetSpeed(speed float64) {
+	pl.speed = speed
+}
+
+// Speed returns the playback speed
+func (pl *Playlist) Speed() float64 {
+	return pl.speed
+}
+
+// SetSilenceThreshold sets the (linear) amplitude below which leading and trailing silence of songs is trimmed.
+// A threshold of 0 disables silence trimming.
+func (pl *Playlist) SetSilenceThreshold(threshold float64) {
+	pl.silenceThreshold = threshold
+}
+
+// SetFadeLength sets the number of samples to fade in/out when playback is paused, resumed or the song is skipped
+func (pl *Playlist) SetFadeLength(samples int) {
+	pl.fadeLength = samples
+}
+
+// SetSampleRate sets the sample rate of the stream, which live sources are resampled to
+func (pl *Playlist) SetSampleRate(sampleRate int) {
+	pl.sampleRate = sampleRate
+}
+
+// SetDSP sets the DSP, which processes the samples of all songs before they are streamed
+func (pl *Playlist) SetDSP(d *DSP) {
+	pl.dsp = d
+}
+
 // NewPlaylist create a new playlist with the given buffer size and songs in it, which
 // inserts nanBreakSize nan-samples between songs, which players use to realign playback.
 func NewPlaylist(bufferSize int, songs []string, nanBreakSize int) *Playlist {
-	return &Playlist{
-		songs:            songs,
+	pl := &Playlist{
 		position:         0,
 		low:              make(chan float64, bufferSize),
 		high:             make(chan float64, bufferSize),
 		forceNext:        make(chan bool, 2),
+		announcements:    make(chan beep.StreamSeekCloser, announcementQueueSize),
+		silence:          make(map[silenceKey]silenceBounds),
 		nanBreakSize:     nanBreakSize,
+		speed:            1,
 		playing:          false,
 		playingLast:      true,
 		sampleIndexRead:  0,
 		sampleIndexWrite: 0,
 	}
+	pl.songs = pl.newEntries(songs)
+	return pl
+}
+
+// newEntry returns the entry of song with a new id. It must be called with songsMutex locked.
+func (pl *Playlist) newEntry(song string, autoQueued bool) PlaylistEntry {
+	pl.lastEntryID++
+	return PlaylistEntry{ID: pl.lastEntryID, Song: song, AutoQueued: autoQueued}
+}
+
+// newEntries returns the entries of the songs, which were not auto queued. It must be called with songsMutex
+// locked.
+func (pl *Playlist) newEntries(songs []string) []PlaylistEntry {
+	entries := make([]PlaylistEntry, len(songs))
+	for i, song := range songs {
+		entries[i] = pl.newEntry(song, false)
+	}
+	return entries
 }
 
 func copyFloatChannel(dst []float64, src chan float64) {
@@ -7,8 +7,8 @@ import (
 	"testing"
 )
 
-func storingNewSongHandler(startSampleIndex *uint64, filename *string, songLength *int64) func(uint64, string, int64) {
-	return func(ssi uint64, fn string, sl int64) {
+func storingNewSongHandler(startSampleIndex *uint64, filename *string, songLength *int64) func(uint64, string, int64, int64, float64) {
+	return func(ssi uint64, fn string, sl int64, _ int64, _ float64) {
 		*startSampleIndex = ssi
 		*filename = fn
 		*songLength = sl
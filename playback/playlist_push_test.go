@@ -92,3 +92,111 @@ func TestPlaylist_pushNanBreak(t *testing.T) {
 		assert.True(t, math.IsNaN(<-pl.high), "%d-th high sample is not nan when pushNanBreak", i)
 	}
 }
+
+func TestPlaylist_pushStreamerGain(t *testing.T) {
+	pl := NewPlaylist(2*streamerBufferSize, []string{}, 0)
+	pl.currentSong = "the-song"
+	var gainSong string
+	pl.SetGainHandler(func(song string) float64 {
+		gainSong = song
+		return 2
+	})
+
+	s := &testStreamer{samples: make(chan [2]float64, streamerBufferSize), position: 0, length: streamerBufferSize}
+	for i := 0; i < streamerBufferSize-1; i++ {
+		s.samples <- [2]float64{0.25, -0.25}
+	}
+	s.samples <- [2]float64{0.75, -0.75}
+	s.Close()
+
+	pl.SetPlaying(true)
+	pl.pushStreamer(s)
+
+	assert.Equal(t, "the-song", gainSong, "GainHandler called with the wrong song")
+	for i := 0; i < streamerBufferSize-1; i++ {
+		assert.InDelta(t, 0.5, <-pl.low, 1e-9, "%d-th low sample has the wrong gain when using pushStreamer", i)
+		assert.InDelta(t, -0.5, <-pl.high, 1e-9, "%d-th high sample has the wrong gain when using pushStreamer", i)
+	}
+	assert.InDelta(t, limiterThreshold, <-pl.low, 1e-9, "loud low sample was not limited when using pushStreamer")
+	assert.InDelta(t, -limiterThreshold, <-pl.high, 1e-9, "loud high sample was not limited when using pushStreamer")
+}
+
+func TestPlaylist_pushStreamerDSP(t *testing.T) {
+	pl := NewPlaylist(2*streamerBufferSize, []string{}, 0)
+	d := NewDSP(44100)
+	d.SetConfig(DSPConfig{Width: 0})
+	pl.SetDSP(d)
+
+	s := &testStreamer{samples: make(chan [2]float64, streamerBufferSize), position: 0, length: streamerBufferSize}
+	for i := 0; i < streamerBufferSize; i++ {
+		s.samples <- [2]float64{0.5, -0.25}
+	}
+	s.Close()
+
+	pl.SetPlaying(true)
+	pl.pushStreamer(s)
+
+	for i := 0; i < streamerBufferSize; i++ {
+		assert.InDelta(t, 0.125, <-pl.low, 1e-9, "%d-th low sample was not processed by the dsp when using pushStreamer", i)
+		assert.InDelta(t, 0.125, <-pl.high, 1e-9, "%d-th high sample was not processed by the dsp when using pushStreamer", i)
+	}
+}
+
+func constantTestStreamer(sample [2]float64, length int) *testStreamer {
+	s := &testStreamer{samples: make(chan [2]float64, length), position: 0, length: length}
+	for i := 0; i < length; i++ {
+		s.samples <- sample
+	}
+	s.Close()
+	return s
+}
+
+func TestPlaylist_pushStreamerFadeIn(t *testing.T) {
+	pl := NewPlaylist(2*streamerBufferSize, []string{}, 0)
+	pl.SetFadeLength(streamerBufferSize / 2)
+	pl.fadeInNext = true
+
+	pl.SetPlaying(true)
+	pl.pushStreamer(constantTestStreamer([2]float64{1, -1}, streamerBufferSize))
+
+	assert.False(t, pl.fadeInNext, "pushStreamer did not reset fadeInNext")
+	for i := 0; i < streamerBufferSize; i++ {
+		expected := 1.0
+		if i < streamerBufferSize/2 {
+			expected = float64(i) / float64(streamerBufferSize/2)
+		}
+		assert.InDelta(t, expected, <-pl.low, 1e-9, "%d-th low sample was not faded in when using pushStreamer", i)
+		assert.InDelta(t, -expected, <-pl.high, 1e-9, "%d-th high sample was not faded in when using pushStreamer", i)
+	}
+}
+
+func TestPlaylist_pushFadeOut(t *testing.T) {
+	pl := NewPlaylist(4*streamerBufferSize, []string{}, 0)
+	pl.SetFadeLength(2 * streamerBufferSize)
+	buf := make([][2]float64, streamerBufferSize)
+
+	s := constantTestStreamer([2]float64{1, 1}, 3*streamerBufferSize)
+	assert.True(t, pl.pushFadeOut(s.Stream, buf), "pushFadeOut returned false before the end of the song")
+	assert.Equal(t, 2*streamerBufferSize, len(pl.low), "pushFadeOut pushed the wrong number of samples")
+	for i := 0; i < 2*streamerBufferSize; i++ {
+		expected := 1 - float64(i)/float64(2*streamerBufferSize)
+		assert.InDelta(t, expected, <-pl.low, 1e-9, "%d-th low sample was not faded out by pushFadeOut", i)
+		<-pl.high
+	}
+
+	s = constantTestStreamer([2]float64{1, 1}, streamerBufferSize)
+	assert.False(t, pl.pushFadeOut(s.Stream, buf), "pushFadeOut did not return false at the end of the song")
+}
+
+func TestPlaylist_RemoveSongSkipsCurrent(t *testing.T) {
+	pl := NewPlaylist(16, []string{"a", "b", "c"}, 0)
+	pl.currentSong = "b"
+	pl.position = 1
+
+	pl.RemoveSong(2)
+	assert.Equal(t, 0, len(pl.forceNext), "RemoveSong skipped the current song when removing another song")
+
+	pl.RemoveSong(1)
+	assert.Equal(t, 1, len(pl.forceNext), "RemoveSong did not skip the removed current song")
+	assert.Equal(t, 1, pl.position, "RemoveSong changed the position when removing the current song")
+}
@@ -19,9 +19,9 @@ func TestPlaylist_SetPos(t *testing.T) {
 func TestPlaylist_Songs(t *testing.T) {
 	pl := NewPlaylist(16, []string{}, 0)
 	for i := 0; i < 16; i++ {
-		pl.songs = make([]string, i+1)
+		pl.songs = make([]PlaylistEntry, i+1)
 		for j := range pl.songs {
-			pl.songs[j] = songName(i)
+			pl.songs[j] = PlaylistEntry{Song: songName(i)}
 		}
 		songs := pl.Songs()
 		if assert.Equal(t, i+1, len(songs), "playlist Songs returned a slice of incorrect length when holding %d songs", i+1) {
@@ -39,7 +39,7 @@ func TestPlaylist_AddSong(t *testing.T) {
 		pl.AddSong(songName(i))
 		if assert.Equal(t, i+1, len(pl.songs), "after adding %d songs, playlist does not hold the right amount of songs", i+1) {
 			for j := 0; j <= i; j++ {
-				assert.Equal(t, songName(i), pl.songs[i], "after adding %d songs, the song with index %d is incorrect", i+1, j)
+				assert.Equal(t, songName(i), pl.songs[i].Song, "after adding %d songs, the song with index %d is incorrect", i+1, j)
 			}
 		}
 	}
@@ -47,31 +47,31 @@ func TestPlaylist_AddSong(t *testing.T) {
 
 func TestPlaylist_InsertSong(t *testing.T) {
 	pl := NewPlaylist(16, []string{}, 0)
-	pl.songs = make([]string, 8)
+	pl.songs = make([]PlaylistEntry, 8)
 	for i := range pl.songs {
-		pl.songs[i] = songName(2 * i)
+		pl.songs[i] = PlaylistEntry{Song: songName(2 * i)}
 	}
 
 	for i := 1; i < 16; i += 2 {
 		pl.InsertSong(songName(i), i)
 		if assert.Equal(t, i/2+9, len(pl.songs), "after inserting %d test songs, songs length is incorrect", i/2+1) {
 			for j := 0; j <= i; j++ {
-				assert.Equal(t, songName(j), pl.songs[j], "after inserting %d test songs, song at index %d is incorrect", i/2+1, j)
+				assert.Equal(t, songName(j), pl.songs[j].Song, "after inserting %d test songs, song at index %d is incorrect", i/2+1, j)
 			}
 		}
 	}
 
 	expectedSongs := newSongsList(16)
 
-	assert.Equal(t, expectedSongs, pl.songs, "after inserting 8 songs, songs is incorrect")
+	assert.Equal(t, expectedSongs, pl.Songs(), "after inserting 8 songs, songs is incorrect")
 
 	pl.InsertSong("song-low", -1)
 	expectedSongs = append([]string{"song-low"}, expectedSongs...)
-	assert.Equal(t, expectedSongs, pl.songs, "after inserting 9 songs, songs is incorrect")
+	assert.Equal(t, expectedSongs, pl.Songs(), "after inserting 9 songs, songs is incorrect")
 
 	pl.InsertSong("song-high", 32)
 	expectedSongs = append(expectedSongs, "song-high")
-	assert.Equal(t, expectedSongs, pl.songs, "after inserting 10 songs, songs is incorrect")
+	assert.Equal(t, expectedSongs, pl.Songs(), "after inserting 10 songs, songs is incorrect")
 }
 
 func TestPlaylist_RemoveSong(t *testing.T) {
@@ -92,10 +92,46 @@ func TestPlaylist_RemoveSong(t *testing.T) {
 	assert.Equal(t, songName(15), pl.RemoveSong(22), "remove returned the wrong song name")
 	assertRemoved(t, []int{0, 1, 8, 11, 15}, pl)
 
-	pl.songs = []string{}
+	assert.Equal(t, songName(14), pl.RemoveSong(len(pl.songs)), "remove returned the wrong song name")
+	assertRemoved(t, []int{0, 1, 8, 11, 14, 15}, pl)
+
+	pl.songs = []PlaylistEntry{}
 	assert.Equal(t, "", pl.RemoveSong(0), "remove returned the wrong song name for playlist without songs")
 }
 
+func TestPlaylist_MoveSong(t *testing.T) {
+	pl := NewPlaylist(16, []string{"a", "b", "c", "d"}, 0)
+	pl.songs[1].AutoQueued = true
+	pl.position = 2
+
+	assert.True(t, pl.MoveSong(1, 3), "MoveSong failed to move a song down")
+	assert.Equal(t, []string{"a", "c", "d", "b"}, pl.Songs(), "MoveSong did not move the song down")
+	assert.Equal(t, []bool{false, false, false, true}, autoQueuedFlags(pl), "MoveSong did not move the auto queued flag")
+	assert.Equal(t, 1, pl.position, "MoveSong did not keep the position on the current song moving a song past it")
+
+	assert.True(t, pl.MoveSong(3, 0), "MoveSong failed to move a song up")
+	assert.Equal(t, []string{"b", "a", "c", "d"}, pl.Songs(), "MoveSong did not move the song up")
+	assert.Equal(t, 2, pl.position, "MoveSong did not keep the position on the current song moving a song before it")
+
+	assert.True(t, pl.MoveSong(2, 3), "MoveSong failed to move the current song")
+	assert.Equal(t, []string{"b", "a", "d", "c"}, pl.Songs(), "MoveSong did not move the current song")
+	assert.Equal(t, 3, pl.position, "MoveSong did not move the position with the current song")
+
+	assert.False(t, pl.MoveSong(4, 0), "MoveSong moved a song from outside the playlist")
+	assert.False(t, pl.MoveSong(0, -1), "MoveSong moved a song outside the playlist")
+	assert.Equal(t, []string{"b", "a", "d", "c"}, pl.Songs(), "MoveSong changed the playlist moving from or to outside of it")
+}
+
+// autoQueuedFlags returns the auto queued flags of the entries of pl
+func autoQueuedFlags(pl *Playlist) []bool {
+	entries := pl.Entries()
+	flags := make([]bool, len(entries))
+	for i, e := range entries {
+		flags[i] = e.AutoQueued
+	}
+	return flags
+}
+
 func assertRemoved(t *testing.T, removed []int, pl *Playlist) {
 	expected := make([]string, 16-len(removed))
 	skipped := 0
@@ -107,7 +143,7 @@ func assertRemoved(t *testing.T, removed []int, pl *Playlist) {
 		}
 	}
 
-	assert.Equal(t, expected, pl.songs, "after removing %v, playlist songs are incorrect", removed)
+	assert.Equal(t, expected, pl.Songs(), "after removing %v, playlist songs are incorrect", removed)
 }
 
 func intSliceContains(ints []int, t int) bool {
@@ -195,7 +231,7 @@ func TestNewPlaylist(t *testing.T) {
 		pl := NewPlaylist(c.bufferSize, c.songs, c.nanBreakSize)
 		assert.Equal(t, c.bufferSize, cap(pl.low), "playlist low chan has wrong capacity for case %v", c)
 		assert.Equal(t, c.bufferSize, cap(pl.high), "playlist high chan has wrong capacity for case %v", c)
-		assert.Equal(t, c.songs, pl.songs, "playlist has wrong songs for case %v", c)
+		assert.Equal(t, c.songs, pl.Songs(), "playlist has wrong songs for case %v", c)
 		assert.Equal(t, c.nanBreakSize, pl.nanBreakSize, "playlist has wrong nanBreakSize for case %v", c)
 	}
 }
@@ -223,6 +259,81 @@ func TestPlaylist_nextSong(t *testing.T) {
 		assert.Equal(t, i%16, pl.position, "nextSong returned set the position incorrectly after calling it %d times", i)
 	}
 
-	pl.songs = []string{}
+	pl.songs = []PlaylistEntry{}
 	assert.Equal(t, "", pl.nextSong(), "nextSong returned the wrong song name for playlist with no songs")
 }
+
+func TestPlaylist_autoQueue(t *testing.T) {
+	pl := NewPlaylist(16, newSongsList(2), 0)
+	pl.autoQueue()
+	assert.Equal(t, newSongsList(2), pl.Songs(), "playlist autoQueue changed the songs without an auto queue handler")
+
+	var lastSong string
+	var upcoming int
+	pl.SetAutoQueueHandler(func(ls string, u int) []string {
+		lastSong, upcoming = ls, u
+		return []string{"auto-song"}
+	})
+	pl.currentSong = songName(1)
+	pl.position = 1
+	pl.autoQueue()
+
+	assert.Equal(t, songName(1), lastSong, "playlist autoQueue passed the wrong last song")
+	assert.Equal(t, 1, upcoming, "playlist autoQueue passed the wrong number of upcoming songs")
+	assert.Equal(t, append(newSongsList(2), "auto-song"), pl.Songs(), "playlist autoQueue did not add the auto queued song")
+	assert.Equal(t, []bool{false, false, true}, autoQueuedFlags(pl), "playlist autoQueue did not mark the song as auto queued")
+}
+
+func TestPlaylist_Entries(t *testing.T) {
+	pl := NewPlaylist(16, newSongsList(4), 0)
+	assert.Equal(t, []bool{false, false, false, false}, autoQueuedFlags(pl), "playlist Entries returned the wrong flags for a new playlist")
+
+	pl.addSong("auto-song", true)
+	pl.InsertSong("inserted-song", 1)
+	assert.Equal(t, []bool{false, false, false, false, false, true}, autoQueuedFlags(pl), "playlist Entries returned the wrong flags after inserting")
+
+	pl.RemoveSong(0)
+	assert.Equal(t, []bool{false, false, false, false, true}, autoQueuedFlags(pl), "playlist Entries returned the wrong flags after removing")
+}
+
+func TestPlaylist_EntryIDs(t *testing.T) {
+	pl := NewPlaylist(16, newSongsList(3), 0)
+	assert.Equal(t, []int{1, 2, 3}, entryIDs(pl), "a new playlist has the wrong entry ids")
+
+	pl.AddSong(songName(1))
+	pl.addSong("auto-song", true)
+	pl.InsertSong("inserted-song", 0)
+	assert.Equal(t, []int{6, 1, 2, 3, 4, 5}, entryIDs(pl), "the added songs have the wrong entry ids")
+
+	pl.RemoveSong(2)
+	pl.MoveSong(0, 4)
+	assert.Equal(t, []int{1, 3, 4, 5, 6}, entryIDs(pl), "removing and moving songs changed the entry ids")
+
+	pl.Replace([]string{"a", "b"})
+	assert.Equal(t, []int{7, 8}, entryIDs(pl), "playlist Replace reused entry ids")
+}
+
+// entryIDs returns the ids of the entries of pl
+func entryIDs(pl *Playlist) []int {
+	entries := pl.Entries()
+	ids := make([]int, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func TestPlaylist_Replace(t *testing.T) {
+	pl := NewPlaylist(16, []string{"a", "b", "c"}, 0)
+	pl.position = 2
+
+	pl.Replace([]string{"d", "e"})
+	assert.Equal(t, []string{"d", "e"}, pl.Songs(), "playlist Replace did not replace the songs")
+	assert.Equal(t, []bool{false, false}, autoQueuedFlags(pl), "playlist Replace did not reset auto queued songs")
+	assert.Equal(t, 0, pl.position, "playlist Replace did not reset the position")
+	assert.Equal(t, 0, len(pl.forceNext), "playlist Replace skipped while no song is playing")
+
+	pl.currentSong = "d"
+	pl.Replace([]string{"f"})
+	assert.Equal(t, 1, len(pl.forceNext), "playlist Replace did not skip the current song")
+}
@@ -0,0 +1,65 @@
+package playback
+
+import (
+	"github.com/faiface/beep"
+)
+
+// seekRequest is a pending change of the position in the current song
+type seekRequest struct {
+	position int
+	relative bool
+}
+
+// Seek moves the playback position of the current song to position (in samples of the trimmed song). If relative is
+// true, position is added to the current position instead. Streams without a known length can not be seeked.
+// Seeking beyond the end of the song ends it.
+func (pl *Playlist) Seek(position int, relative bool) {
+	pl.seekMutex.Lock()
+	defer pl.seekMutex.Unlock()
+	if relative && pl.pendingSeek != nil && pl.pendingSeek.relative {
+		pl.pendingSeek.position += position
+		return
+	}
+	pl.pendingSeek = &seekRequest{position: position, relative: relative}
+}
+
+// takeSeek returns and removes the pending seek request
+func (pl *Playlist) takeSeek() (seekRequest, bool) {
+	pl.seekMutex.Lock()
+	defer pl.seekMutex.Unlock()
+	if pl.pendingSeek == nil {
+		return seekRequest{}, false
+	}
+	request := *pl.pendingSeek
+	pl.pendingSeek = nil
+	return request, true
+}
+
+// SetSeekHandler sets the seek handler, which is called when the current song is seeked. It is passed the same
+// arguments as the speed handler: the index of the first sample played after the seek, the speed, the position in
+// the (trimmed) song at that sample and the number of samples of the song streamed before it.
+func (pl *Playlist) SetSeekHandler(sh func(sampleIndex uint64, speed float64, songPosition int64, streamed int64)) {
+	pl.seekHandler = sh
+}
+
+// seek moves s and the stretcher streaming it to the position of request
+func (pl *Playlist) seek(s beep.StreamSeeker, stretcher *timeStretcher, request seekRequest) {
+	position := request.position
+	if request.relative {
+		position += stretcher.position()
+	}
+	if position < 0 {
+		position = 0
+	}
+	if s.Len() < position {
+		position = s.Len()
+	}
+	if err := s.Seek(position); err != nil {
+		logger.Warnf("failed to seek to sample %d of %s: %v", position, pl.currentSong, err)
+		return
+	}
+	stretcher.seek(position)
+	if pl.seekHandler != nil {
+		go pl.seekHandler(pl.sampleIndexWrite, stretcher.speed, int64(position), int64(stretcher.produced))
+	}
+}
@@ -0,0 +1,93 @@
+package playback
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func rampTestStreamer(length int) *sliceStreamer {
+	samples := make([][2]float64, length)
+	for i := range samples {
+		samples[i] = [2]float64{float64(i), float64(i)}
+	}
+	return &sliceStreamer{samples: samples}
+}
+
+func TestPlaylist_Seek(t *testing.T) {
+	pl := NewPlaylist(0, []string{}, 0)
+	_, ok := pl.takeSeek()
+	assert.False(t, ok, "takeSeek returned a request without a seek")
+
+	pl.Seek(100, true)
+	pl.Seek(-30, true)
+	request, ok := pl.takeSeek()
+	assert.True(t, ok, "takeSeek did not return the request")
+	assert.Equal(t, seekRequest{position: 70, relative: true}, request, "Seek did not add relative seeks")
+	_, ok = pl.takeSeek()
+	assert.False(t, ok, "takeSeek did not remove the request")
+
+	pl.Seek(100, true)
+	pl.Seek(500, false)
+	pl.Seek(20, true)
+	request, _ = pl.takeSeek()
+	assert.Equal(t, seekRequest{position: 20, relative: true}, request, "Seek did not replace the request")
+}
+
+func TestPlaylist_seek(t *testing.T) {
+	pl := NewPlaylist(0, []string{}, 0)
+	pl.sampleIndexWrite = 1234
+
+	type seekCall struct {
+		sampleIndex        uint64
+		speed              float64
+		position, streamed int64
+	}
+	calls := make(chan seekCall, 1)
+	pl.SetSeekHandler(func(sampleIndex uint64, speed float64, position int64, streamed int64) {
+		calls <- seekCall{sampleIndex, speed, position, streamed}
+	})
+
+	s := rampTestStreamer(10000)
+	ts := newTimeStretcher(s, 1)
+	buf := make([][2]float64, 1000)
+	ts.Stream(buf)
+
+	pl.seek(s, ts, seekRequest{position: 2000, relative: true})
+	assert.Equal(t, seekCall{1234, 1, 3000, 1000}, <-calls, "seek called the seek handler with the wrong arguments")
+	ts.Stream(buf)
+	assert.Equal(t, [2]float64{3000, 3000}, buf[0], "seek did not continue at the new position")
+
+	pl.seek(s, ts, seekRequest{position: 500})
+	assert.Equal(t, seekCall{1234, 1, 500, 2000}, <-calls, "seek did not seek to the absolute position")
+
+	pl.seek(s, ts, seekRequest{position: -5000, relative: true})
+	assert.Equal(t, int64(0), (<-calls).position, "seek did not stop at the start of the song")
+	pl.seek(s, ts, seekRequest{position: 20000})
+	assert.Equal(t, int64(10000), (<-calls).position, "seek did not stop at the end of the song")
+}
+
+func TestTimeStretcher_seek(t *testing.T) {
+	s := sineTestStreamer(44100, 100)
+	ts := newTimeStretcher(s, 0.5)
+	ts.Stream(make([][2]float64, 10000))
+
+	s.Seek(20000)
+	ts.seek(20000)
+	assert.Equal(t, 20000, ts.position(), "timeStretcher is at the wrong position after seeking")
+	rest := streamAll(ts)
+	assert.InDelta(t, 2*(44100-20000), len(rest), stretchHop, "timeStretcher streamed the wrong number of samples after seeking")
+}
+
+func TestPlaylist_pushStreamerSeek(t *testing.T) {
+	pl := NewPlaylist(4*8192, []string{}, 0)
+	pl.currentSong = "the-song"
+	pl.SetPlaying(true)
+
+	positions := make(chan int64, 1)
+	pl.SetSeekHandler(func(_ uint64, _ float64, position int64, _ int64) { positions <- position })
+
+	pl.Seek(1000, false)
+	pl.pushStreamer(rampTestStreamer(8192))
+	assert.Equal(t, 8192, len(pl.low), "pushStreamer applied a seek requested before the song")
+	assert.Zero(t, len(positions), "pushStreamer called the seek handler for a seek requested before the song")
+}
@@ -0,0 +1,89 @@
+package playback
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+const (
+	spectrumMinFrequency = 40.0
+	spectrumMaxFrequency = 16000.0
+	// spectrumFloor is the level in dBFS shown as an empty band
+	spectrumFloor = -60.0
+)
+
+// fft replaces x with its discrete fourier transform. The length of x has to be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		step := -2 * math.Pi / float64(size)
+		for start := 0; start < n; start += size {
+			for k := 0; k < size/2; k++ {
+				w := cmplx.Rect(1, step*float64(k))
+				a, b := x[start+k], w*x[start+k+size/2]
+				x[start+k], x[start+k+size/2] = a+b, a-b
+			}
+		}
+	}
+}
+
+// spectrumBandEdges returns the bands+1 log-spaced frequencies separating the bands of a spectrum
+func spectrumBandEdges(bands int, sampleRate int) []float64 {
+	maxFrequency := math.Min(spectrumMaxFrequency, float64(sampleRate)/2)
+	edges := make([]float64, bands+1)
+	for i := range edges {
+		edges[i] = spectrumMinFrequency * math.Pow(maxFrequency/spectrumMinFrequency, float64(i)/float64(bands))
+	}
+	return edges
+}
+
+// Spectrum returns the levels of the samples in bands log-spaced frequency bands between 40 Hz and 16 kHz.
+// A level is the peak of its band scaled from -60 dBFS (0) to 0 dBFS (1). Both channels are mixed and the
+// largest power of two of samples fitting into the samples is transformed, using a hann window.
+func Spectrum(low, high []float64, sampleRate int, bands int) []float64 {
+	levels := make([]float64, bands)
+	n := 1
+	for n*2 <= len(low) && n*2 <= len(high) {
+		n *= 2
+	}
+	if n < 2 || bands <= 0 {
+		return levels
+	}
+
+	x := make([]complex128, n)
+	for i := range x {
+		window := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1))
+		x[i] = complex((low[i]+high[i])/2*window, 0)
+	}
+	fft(x)
+
+	edges := spectrumBandEdges(bands, sampleRate)
+	binOf := func(frequency float64) int {
+		return int(frequency * float64(n) / float64(sampleRate))
+	}
+	for b := range levels {
+		first, last := binOf(edges[b]), binOf(edges[b+1])
+		if last <= first {
+			last = first + 1
+		}
+		peak := 0.0
+		for i := first; i < last && i < n/2; i++ {
+			peak = math.Max(peak, cmplx.Abs(x[i]))
+		}
+		// a full scale sine peaks at n/4 with the hann window
+		level := 20 * math.Log10(peak*4/float64(n))
+		levels[b] = math.Max(0, math.Min(1, 1-level/spectrumFloor))
+	}
+	return levels
+}
@@ -0,0 +1,58 @@
+package playback
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestFFT(t *testing.T) {
+	x := []complex128{1, 2, 3, 4, 0, 0, 0, 0}
+	expected := make([]complex128, len(x))
+	for k := range expected {
+		for i, v := range x {
+			expected[k] += v * cmplx.Rect(1, -2*math.Pi*float64(k*i)/float64(len(x)))
+		}
+	}
+
+	fft(x)
+	for k := range x {
+		assert.InDelta(t, real(expected[k]), real(x[k]), 1e-9, "fft returned the wrong real part of bin %d", k)
+		assert.InDelta(t, imag(expected[k]), imag(x[k]), 1e-9, "fft returned the wrong imaginary part of bin %d", k)
+	}
+}
+
+func TestSpectrumBandEdges(t *testing.T) {
+	edges := spectrumBandEdges(4, 44100)
+	assert.Len(t, edges, 5, "spectrumBandEdges returned the wrong number of edges")
+	assert.InDelta(t, spectrumMinFrequency, edges[0], 1e-9, "spectrumBandEdges did not start at the minimal frequency")
+	assert.InDelta(t, spectrumMaxFrequency, edges[4], 1e-9, "spectrumBandEdges did not end at the maximal frequency")
+	assert.InDelta(t, edges[1]/edges[0], edges[3]/edges[2], 1e-9, "spectrumBandEdges are not log-spaced")
+
+	assert.InDelta(t, 4000, spectrumBandEdges(4, 8000)[4], 1e-9, "spectrumBandEdges did not stop at the nyquist frequency")
+}
+
+func TestSpectrum(t *testing.T) {
+	sampleRate := 44100
+	low, high := make([]float64, 2205), make([]float64, 2205)
+	for i := range low {
+		v := math.Sin(2 * math.Pi * 1000 * float64(i) / float64(sampleRate))
+		low[i], high[i] = v, v
+	}
+
+	levels := Spectrum(low, high, sampleRate, 8)
+	edges := spectrumBandEdges(8, sampleRate)
+	for b, l := range levels {
+		if edges[b] <= 1000 && 1000 < edges[b+1] {
+			assert.InDelta(t, 1, l, 0.05, "Spectrum returned the wrong level for the band of a full scale sine")
+		} else if 1000 < edges[b]*0.7 || edges[b+1]*1.3 < 1000 {
+			assert.True(t, l < 0.5, "Spectrum returned a high level %f for band %d far from the sine", l, b)
+		}
+	}
+
+	assert.Equal(t, make([]float64, 8), Spectrum(make([]float64, 2205), make([]float64, 2205), sampleRate, 8),
+		"Spectrum returned levels for silence")
+	assert.Equal(t, make([]float64, 4), Spectrum([]float64{1}, []float64{1}, sampleRate, 4),
+		"Spectrum returned levels for a single sample")
+}
@@ -13,6 +13,8 @@ import (
 type timedSample struct {
 	sample [2]float64
 	time   int64
+	// index is the index of the sample in the stream of the server
+	index uint64
 }
 
 type timedMultiStreamer struct {
@@ -29,38 +31,44 @@ func (tms *timedMultiStreamer) Stream(samples [][2]float64) {
 	var n int
 	var drained bool
 	now := timing.GetSyncedTime()
+	r := currentRamp()
 	for 0 < len(samples) {
 		if tms.syncing {
 			n, drained = tms.streamSync(samples, now)
 		} else {
-			n, drained = tms.streamDirect(samples)
+			n, drained = tms.streamDirect(samples, r)
 		}
 		now += tms.samplesDuration(n)
 		samples = samples[n:]
 		if drained {
 			tms.syncing = !tms.syncing
-			_, t := tms.samples.Peek()
+			_, t, _ := tms.samples.Peek()
 			logger.Debugf("playback error: %s", time.Duration(t-now)*time.Nanosecond)
 		}
 	}
 }
 
-func (tms *timedMultiStreamer) streamDirect(samples [][2]float64) (n int, drained bool) {
+// streamDirect streams the queued samples, applying the volume of r at the index of each sample
+func (tms *timedMultiStreamer) streamDirect(samples [][2]float64, r volumeRamp) (n int, drained bool) {
+	v := r.to
 	for i := range samples {
-		s, _ := tms.samples.Remove()
+		s, _, index := tms.samples.Remove()
 		if math.IsNaN(s[0]) {
 			return i, true
 		}
-		samples[i] = s
+		if !r.constant() {
+			v = r.at(index)
+		}
+		samples[i] = [2]float64{s[0] * v, s[1] * v}
 	}
 	return len(samples), false
 }
 
 func (tms *timedMultiStreamer) streamSync(samples [][2]float64, now int64) (n int, drained bool) {
-	s, t := tms.samples.Peek()
+	s, t, _ := tms.samples.Peek()
 	for math.IsNaN(s[0]) {
 		tms.samples.Remove()
-		s, t = tms.samples.Peek()
+		s, t, _ = tms.samples.Peek()
 	}
 	if now < t+tms.samplesDuration(len(samples)) {
 		tms.background.Stream(samples)
@@ -72,7 +80,7 @@ func (tms *timedMultiStreamer) streamSync(samples [][2]float64, now int64) (n in
 	} else {
 		for t < now {
 			tms.samples.Remove()
-			_, t = tms.samples.Peek()
+			_, t, _ = tms.samples.Peek()
 		}
 		return 0, true
 	}
@@ -82,9 +90,9 @@ func (tms *timedMultiStreamer) ReadChunks(ctx context.Context) {
 	for !util.IsCanceled(ctx) {
 		if 0 < len(tms.chunks) {
 			tms.chunksMutex.RLock()
-			st := tms.chunks[0].startTime
+			st, first := tms.chunks[0].startTime, tms.chunks[0].firstSampleIndex
 			for i, s := range tms.chunks[0].samples {
-				tms.samples.Add(s, st+tms.samplesDuration(i))
+				tms.samples.Add(s, st+tms.samplesDuration(i), first+uint64(i))
 			}
 			tms.chunksMutex.RUnlock()
 			tms.chunksMutex.Lock()
@@ -108,9 +116,11 @@ func (tms *timedMultiStreamer) Err() error { return nil }
 
 type queuedChunk struct {
 	startTime int64
-	samples   [][2]float64
-	sampleN   int
-	pos       int
+	// firstSampleIndex is the index of the first sample of the chunk in the stream of the server
+	firstSampleIndex uint64
+	samples          [][2]float64
+	sampleN          int
+	pos              int
 }
 
 func (q *queuedChunk) copySamples(target [][2]float64) (n int) {
@@ -127,6 +137,6 @@ func (q *queuedChunk) drained() bool {
 	return q.sampleN <= q.pos
 }
 
-func newQueuedStream(startTime int64, samples [][2]float64) *queuedChunk {
-	return &queuedChunk{startTime: startTime, samples: samples, sampleN: len(samples)}
+func newQueuedStream(startTime int64, firstSampleIndex uint64, samples [][2]float64) *queuedChunk {
+	return &queuedChunk{startTime: startTime, firstSampleIndex: firstSampleIndex, samples: samples, sampleN: len(samples)}
 }
@@ -11,7 +11,7 @@ func TestQueuedChunk(t *testing.T) {
 	for i := 0; i < 16; i++ {
 		samples := createSampleSlice(1024*i, 1024)
 
-		qs := newQueuedStream(int64(i), samples)
+		qs := newQueuedStream(int64(i), uint64(1024*i), samples)
 		assert.Equal(t, 0, qs.pos, "the %d-th chunk is not initialized with position 0", i+1)
 		assert.Equal(t, 1024, qs.sampleN, "the %d-th chunk is not initialized sampleN 1024", i+1)
 		for j := 0; j < 7; j++ {
@@ -67,9 +67,10 @@ func TestTimedMultiStreamer_ReadChunks(t *testing.T) {
 	tms.chunksMutex.Unlock()
 
 	for i := 0; i < 2048; i++ {
-		sample, time := tms.samples.Remove()
+		sample, time, index := tms.samples.Remove()
 		assert.Equal(t, [2]float64{-float64(i), float64(i)}, sample, "timeDuration ReadChunks pushed wrong sample at index %d", i)
 		assert.Equal(t, int64(i*1e9), time, "timeDuration ReadChunks pushed wrong times at index %d", i)
+		assert.Equal(t, uint64(i), index, "timeDuration ReadChunks pushed wrong sample indices at index %d", i)
 	}
 
 	cancel()
@@ -77,9 +78,10 @@ func TestTimedMultiStreamer_ReadChunks(t *testing.T) {
 
 func newTestChunk(chunkSize, chunkNum int) *queuedChunk {
 	qc := &queuedChunk{
-		startTime: int64(chunkSize * chunkNum * 1e9),
-		samples:   createSampleSlice(chunkSize*chunkNum, chunkSize),
-		sampleN:   chunkSize,
+		startTime:        int64(chunkSize * chunkNum * 1e9),
+		firstSampleIndex: uint64(chunkSize * chunkNum),
+		samples:          createSampleSlice(chunkSize*chunkNum, chunkSize),
+		sampleN:          chunkSize,
 	}
 	return qc
 }
@@ -0,0 +1,173 @@
+package playback
+
+import (
+	"github.com/faiface/beep"
+	"math"
+)
+
+const (
+	// stretchHop is the number of samples each segment of the time-stretch outputs
+	stretchHop = 2048
+	// stretchOverlap is the number of samples crossfaded between two segments
+	stretchOverlap = 512
+	// stretchTolerance is the maximal number of samples a segment is moved to match the previous segment
+	stretchTolerance = 512
+)
+
+// timeStretcher changes the speed of a stream without changing its pitch (WSOLA).
+// The input is cut into segments, which are spaced according to the speed. Each segment is moved to the
+// position within the tolerance, which continues the previous segment best, and crossfaded with it.
+// At a speed of 1, the input is passed through until the speed is changed for the first time.
+type timeStretcher struct {
+	s     beep.Streamer
+	speed float64
+
+	stretching bool
+	in         [][2]float64
+	inBase     int
+	ended      bool
+	pos        float64
+	prevEnd    int
+	out        [][2]float64
+	buf        [][2]float64
+
+	produced int
+}
+
+func newTimeStretcher(s beep.Streamer, speed float64) *timeStretcher {
+	return &timeStretcher{s: s, speed: speed, prevEnd: -1, buf: make([][2]float64, streamerBufferSize)}
+}
+
+func (ts *timeStretcher) Stream(samples [][2]float64) (int, bool) {
+	if !ts.stretching && ts.speed == 1 {
+		n, ok := ts.s.Stream(samples)
+		ts.pos += float64(n)
+		ts.produced += n
+		return n, ok
+	}
+	if !ts.stretching {
+		ts.stretching = true
+		ts.inBase = int(ts.pos)
+	}
+
+	for len(ts.out) < len(samples) && ts.step() {
+	}
+	n := copy(samples, ts.out)
+	ts.out = append(ts.out[:0], ts.out[n:]...)
+	ts.produced += n
+	return n, 0 < n
+}
+
+func (ts *timeStretcher) Err() error { return ts.s.Err() }
+
+// position returns the position in the input of the next sample streamed
+func (ts *timeStretcher) position() int {
+	p := int(ts.pos - float64(len(ts.out))*ts.speed)
+	if p < 0 {
+		return 0
+	}
+	return p
+}
+
+// sample returns the input sample at index p, samples outside of the buffered input are silent
+func (ts *timeStretcher) sample(p int) [2]float64 {
+	if p < ts.inBase || ts.inBase+len(ts.in) <= p {
+		return [2]float64{}
+	}
+	return ts.in[p-ts.inBase]
+}
+
+// fill reads the input until the sample before end is buffered or the input ended
+func (ts *timeStretcher) fill(end int) {
+	for !ts.ended && ts.inBase+len(ts.in) < end {
+		n, ok := ts.s.Stream(ts.buf)
+		ts.in = append(ts.in, ts.buf[:n]...)
+		if !ok || n == 0 {
+			ts.ended = true
+		}
+	}
+}
+
+// step appends the next segment to the output and returns false, if the input ended
+func (ts *timeStretcher) step() bool {
+	start := int(math.Round(ts.pos))
+	ts.fill(start + stretchTolerance + stretchHop + stretchOverlap)
+	if ts.inBase+len(ts.in) <= start {
+		return false
+	}
+
+	best := start
+	if 0 <= ts.prevEnd {
+		best = ts.bestMatch(start)
+	}
+	length := stretchHop
+	if end := ts.inBase + len(ts.in); ts.ended && end-best < length {
+		// the last segment ends with the input
+		length = end - best
+	}
+	for i := 0; i < length; i++ {
+		v := ts.sample(best + i)
+		if 0 <= ts.prevEnd && i < stretchOverlap {
+			w := float64(i) / stretchOverlap
+			p := ts.sample(ts.prevEnd + i)
+			v = [2]float64{p[0]*(1-w) + v[0]*w, p[1]*(1-w) + v[1]*w}
+		}
+		ts.out = append(ts.out, v)
+	}
+	ts.prevEnd = best + stretchHop
+	ts.pos += stretchHop * ts.speed
+	ts.discard()
+	return true
+}
+
+// bestMatch returns the position within the tolerance around start, which continues the previous segment best
+func (ts *timeStretcher) bestMatch(start int) int {
+	best, bestScore := start, math.Inf(-1)
+	for c := start - stretchTolerance; c <= start+stretchTolerance; c++ {
+		if c < ts.inBase {
+			continue
+		}
+		var dot, energy float64
+		for i := 0; i < stretchOverlap; i++ {
+			a, b := ts.sample(c+i), ts.sample(ts.prevEnd+i)
+			x := a[0] + a[1]
+			dot += x * (b[0] + b[1])
+			energy += x * x
+		}
+		score := dot
+		if 0 < energy {
+			score = dot / math.Sqrt(energy)
+		}
+		if bestScore < score {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
+
+// discard drops the buffered input, which can not be part of the next segment anymore
+func (ts *timeStretcher) discard() {
+	keep := int(math.Round(ts.pos)) - stretchTolerance
+	if ts.prevEnd < keep {
+		keep = ts.prevEnd
+	}
+	drop := keep - ts.inBase
+	if len(ts.in) < drop {
+		drop = len(ts.in)
+	}
+	if 0 < drop {
+		ts.in = append(ts.in[:0], ts.in[drop:]...)
+		ts.inBase += drop
+	}
+}
+
+// seek continues the stretcher at position after its input was seeked there
+func (ts *timeStretcher) seek(position int) {
+	ts.stretching = false
+	ts.in = ts.in[:0]
+	ts.inBase = 0
+	ts.ended = false
+	ts.pos = float64(position)
+	ts.prevEnd = -1
+	ts.out = ts.out[:0]
+}
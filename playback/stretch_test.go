@@ -0,0 +1,119 @@
+package playback
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+// sineTestStreamer returns a streamer of a sine with period samples
+func sineTestStreamer(length int, period float64) *sliceStreamer {
+	samples := make([][2]float64, length)
+	for i := range samples {
+		v := 0.5 * math.Sin(2*math.Pi*float64(i)/period)
+		samples[i] = [2]float64{v, v}
+	}
+	return &sliceStreamer{samples: samples}
+}
+
+func streamAll(s interface {
+	Stream([][2]float64) (int, bool)
+}) [][2]float64 {
+	result := make([][2]float64, 0)
+	buf := make([][2]float64, streamerBufferSize)
+	for {
+		n, ok := s.Stream(buf)
+		result = append(result, buf[:n]...)
+		if !ok || n < len(buf) {
+			return result
+		}
+	}
+}
+
+// zeroCrossingPeriod returns the average distance between two zero crossings in samples times two
+func zeroCrossingPeriod(samples [][2]float64) float64 {
+	crossings := 0
+	for i := 1; i < len(samples); i++ {
+		if (samples[i-1][0] < 0) != (samples[i][0] < 0) {
+			crossings++
+		}
+	}
+	return 2 * float64(len(samples)) / float64(crossings)
+}
+
+func TestTimeStretcher_bypass(t *testing.T) {
+	s := sineTestStreamer(10000, 100)
+	out := streamAll(newTimeStretcher(s, 1))
+	assert.Equal(t, s.samples, out, "timeStretcher changed the samples at speed 1")
+}
+
+func TestTimeStretcher_Stream(t *testing.T) {
+	for _, speed := range []float64{0.5, 0.8, 1.25, 2} {
+		s := sineTestStreamer(44100, 100)
+		ts := newTimeStretcher(s, speed)
+		out := streamAll(ts)
+
+		assert.InDelta(t, 44100/speed, len(out), stretchHop, "timeStretcher streamed the wrong number of samples at speed %.2f", speed)
+		assert.Equal(t, len(out), ts.produced, "timeStretcher counted the wrong number of samples at speed %.2f", speed)
+		assert.InDelta(t, 100, zeroCrossingPeriod(out), 1, "timeStretcher changed the pitch at speed %.2f", speed)
+	}
+}
+
+func TestTimeStretcher_speedChange(t *testing.T) {
+	s := sineTestStreamer(44100, 100)
+	ts := newTimeStretcher(s, 1)
+	buf := make([][2]float64, 10000)
+
+	n, ok := ts.Stream(buf)
+	assert.True(t, ok, "timeStretcher ended early")
+	assert.Equal(t, 10000, n, "timeStretcher streamed the wrong number of samples")
+	assert.Equal(t, 10000, ts.position(), "timeStretcher is at the wrong position")
+
+	ts.speed = 0.5
+	n, _ = ts.Stream(buf)
+	assert.Equal(t, 10000, n, "timeStretcher streamed the wrong number of samples")
+	assert.Equal(t, [2]float64{0.5 * math.Sin(2*math.Pi*100), 0.5 * math.Sin(2*math.Pi*100)}, buf[0], "timeStretcher did not continue at its position")
+	assert.InDelta(t, 15000, ts.position(), 1, "timeStretcher is at the wrong position after stretching")
+
+	rest := streamAll(ts)
+	assert.InDelta(t, 2*(44100-15000), len(rest), stretchHop, "timeStretcher streamed the wrong number of samples after the speed change")
+}
+
+func TestPlaylist_pushStreamerSpeed(t *testing.T) {
+	pl := NewPlaylist(4*8192, []string{}, 0)
+	pl.currentSong = "the-song"
+	pl.SetPlaying(true)
+	pl.SetSpeed(0.5)
+
+	done := make(chan float64, 1)
+	pl.SetNewSongHandler(func(_ uint64, _ string, _ int64, _ int64, speed float64) { done <- speed })
+
+	pl.pushStreamer(sineTestStreamer(8192, 100))
+	assert.Equal(t, 0.5, <-done, "NewSongHandler called with the wrong speed")
+	assert.InDelta(t, 16384, len(pl.low), stretchHop, "pushStreamer did not stretch the song")
+}
+
+func TestPlaylist_applySpeed(t *testing.T) {
+	pl := NewPlaylist(0, []string{}, 0)
+	pl.sampleIndexWrite = 1234
+
+	type speedCall struct {
+		sampleIndex        uint64
+		speed              float64
+		position, streamed int64
+	}
+	calls := make(chan speedCall, 1)
+	pl.SetSpeedHandler(func(sampleIndex uint64, speed float64, position int64, streamed int64) {
+		calls <- speedCall{sampleIndex, speed, position, streamed}
+	})
+
+	ts := newTimeStretcher(sineTestStreamer(44100, 100), 1)
+	ts.Stream(make([][2]float64, 1000))
+	pl.applySpeed(ts)
+	assert.Zero(t, len(calls), "applySpeed called the speed handler without a speed change")
+
+	pl.SetSpeed(0.8)
+	pl.applySpeed(ts)
+	assert.Equal(t, 0.8, ts.speed, "applySpeed did not change the speed of the stretcher")
+	assert.Equal(t, speedCall{1234, 0.8, 1000, 1000}, <-calls, "applySpeed called the speed handler with the wrong arguments")
+}
@@ -12,19 +12,20 @@ type timedSampleQueue struct {
 	tailMutex sync.RWMutex
 }
 
-func (q *timedSampleQueue) Add(sample [2]float64, time int64) {
+// Add adds the sample with the index in the stream, which is played at the synced time, to the queue
+func (q *timedSampleQueue) Add(sample [2]float64, time int64, index uint64) {
 	q.headMutex.Lock()
 	defer q.headMutex.Unlock()
 
 	q.waitNotFull()
 
-	q.buffer[q.head%len(q.buffer)] = timedSample{sample: sample, time: time}
+	q.buffer[q.head%len(q.buffer)] = timedSample{sample: sample, time: time, index: index}
 	q.head = q.inc(q.head)
 
 	q.cond.Broadcast()
 }
 
-func (q *timedSampleQueue) Remove() (sample [2]float64, time int64) {
+func (q *timedSampleQueue) Remove() (sample [2]float64, time int64, index uint64) {
 	q.tailMutex.Lock()
 	defer q.tailMutex.Unlock()
 
@@ -34,17 +35,17 @@ func (q *timedSampleQueue) Remove() (sample [2]float64, time int64) {
 	q.tail = q.inc(q.tail)
 
 	q.cond.Broadcast()
-	return v.sample, v.time
+	return v.sample, v.time, v.index
 }
 
-func (q *timedSampleQueue) Peek() (sample [2]float64, time int64) {
+func (q *timedSampleQueue) Peek() (sample [2]float64, time int64, index uint64) {
 	q.tailMutex.RLock()
 	defer q.tailMutex.RUnlock()
 
 	q.waitNotEmpty()
 
 	v := q.buffer[q.tail%len(q.buffer)]
-	return v.sample, v.time
+	return v.sample, v.time, v.index
 }
 
 func (q *timedSampleQueue) Len() int {
@@ -0,0 +1,229 @@
+package playback
+
+import (
+	"fmt"
+	"github.com/faiface/beep"
+	"math"
+	"time"
+)
+
+// trailingSilenceScanDuration is the duration at the end of a song, which is scanned for trailing silence
+const trailingSilenceScanDuration = 30 * time.Second
+
+// silenceKey identifies the section of a song scanned for silence with a threshold
+type silenceKey struct {
+	song       string
+	start, end int
+	threshold  float64
+}
+
+// silenceBounds are the first and the last (exclusive) sample of a section, which are not silent
+type silenceBounds struct {
+	first, last int
+}
+
+// sectionStreamer streams the samples of s between start and end
+type sectionStreamer struct {
+	s             beep.StreamSeekCloser
+	start, length int
+	position      int
+}
+
+// newSectionStreamer seeks s to start and returns a streamer, which streams s until end
+func newSectionStreamer(s beep.StreamSeekCloser, start, end int) (*sectionStreamer, error) {
+	if err := s.Seek(start); err != nil {
+		return nil, err
+	}
+	return &sectionStreamer{s: s, start: start, length: end - start}, nil
+}
+
+func (ss *sectionStreamer) Stream(samples [][2]float64) (int, bool) {
+	if left := ss.length - ss.position; left < len(samples) {
+		samples = samples[:left]
+	}
+	if len(samples) == 0 {
+		return 0, false
+	}
+	n, ok := ss.s.Stream(samples)
+	ss.position += n
+	return n, ok
+}
+
+func (ss *sectionStreamer) Err() error { return ss.s.Err() }
+
+func (ss *sectionStreamer) Len() int { return ss.length }
+
+func (ss *sectionStreamer) Position() int { return ss.position }
+
+func (ss *sectionStreamer) Seek(p int) error {
+	if p < 0 || ss.length < p {
+		return fmt.Errorf("position %d is not within the section", p)
+	}
+	if err := ss.s.Seek(ss.start + p); err != nil {
+		return err
+	}
+	ss.position = p
+	return nil
+}
+
+func (ss *sectionStreamer) Close() error { return ss.s.Close() }
+
+func silent(sample [2]float64, threshold float64) bool {
+	return math.Abs(sample[0]) < threshold && math.Abs(sample[1]) < threshold
+}
+
+// detectSilence returns the first and the last (exclusive) sample between start and end, which is not silent.
+// The samples before start and after end are ignored. Only the last scanLength samples are scanned for trailing
+// silence, all samples if scanLength is 0. If all samples are silent, start and end are returned.
+func detectSilence(s beep.StreamSeeker, start, end int, threshold float64, scanLength int) (int, int, error) {
+	buf := make([][2]float64, streamerBufferSize)
+
+	if err := s.Seek(start); err != nil {
+		return start, end, err
+	}
+	first := -1
+	for p := start; first < 0 && p < end; {
+		size := end - p
+		if len(buf) < size {
+			size = len(buf)
+		}
+		n, ok := s.Stream(buf[:size])
+		for i := 0; i < n; i++ {
+			if !silent(buf[i], threshold) {
+				first = p + i
+				break
+			}
+		}
+		p += n
+		if !ok || n == 0 {
+			break
+		}
+	}
+	if first < 0 {
+		return start, end, nil
+	}
+
+	scanStart := end - scanLength
+	if scanLength == 0 || scanStart < first {
+		scanStart = first
+	}
+	if err := s.Seek(scanStart); err != nil {
+		return start, end, err
+	}
+	last := scanStart
+	for p := scanStart; p < end; {
+		size := end - p
+		if len(buf) < size {
+			size = len(buf)
+		}
+		n, ok := s.Stream(buf[:size])
+		for i := 0; i < n; i++ {
+			if !silent(buf[i], threshold) {
+				last = p + i + 1
+			}
+		}
+		p += n
+		if !ok || n == 0 {
+			break
+		}
+	}
+	return first, last, nil
+}
+
+// trailingSilenceScanLength returns the number of samples scanned for trailing silence, 0 (all samples) if the
+// sample rate is not set
+func (pl *Playlist) trailingSilenceScanLength() int {
+	return beep.SampleRate(pl.sampleRate).N(trailingSilenceScanDuration)
+}
+
+// trimBounds returns the section of song, which is played: the section between its trim points without its leading
+// and trailing silence. s is only read, if the silence of the section is not cached yet.
+func (pl *Playlist) trimBounds(song string, s beep.StreamSeeker) (int, int) {
+	length := s.Len()
+	start, end := 0, length
+	if pl.trimHandler != nil {
+		ts, te := pl.trimHandler(song)
+		if 0 < ts {
+			start = ts
+		}
+		if 0 < te && te < end {
+			end = te
+		}
+		if end <= start {
+			logger.Warnf("ignoring trim points %d-%d of %s: the song has %d samples", ts, te, song, length)
+			start, end = 0, length
+		}
+	}
+	if pl.silenceThreshold <= 0 {
+		return start, end
+	}
+
+	key := silenceKey{song: song, start: start, end: end, threshold: pl.silenceThreshold}
+	pl.silenceMutex.Lock()
+	bounds, ok := pl.silence[key]
+	pl.silenceMutex.Unlock()
+	if ok {
+		return bounds.first, bounds.last
+	}
+	first, last, err := detectSilence(s, start, end, pl.silenceThreshold, pl.trailingSilenceScanLength())
+	if err != nil {
+		logger.Warnf("failed to detect the silence of %s: %v", song, err)
+		return start, end
+	}
+	pl.silenceMutex.Lock()
+	pl.silence[key] = silenceBounds{first: first, last: last}
+	pl.silenceMutex.Unlock()
+	return first, last
+}
+
+// prefetchSilence detects the silence of the song after the current one in the background, so it is cached when
+// the song starts
+func (pl *Playlist) prefetchSilence() {
+	if pl.silenceThreshold <= 0 {
+		return
+	}
+	pl.songsMutex.RLock()
+	song := ""
+	if 0 < len(pl.songs) {
+		song = pl.songs[(pl.position+1)%len(pl.songs)].Song
+	}
+	pl.songsMutex.RUnlock()
+	if song == "" || IsURL(song) || IsLiveSource(song) {
+		return
+	}
+	go func() {
+		s, err := getStreamer(song)
+		if err != nil {
+			return
+		}
+		defer s.Close()
+		if 0 < s.Len() {
+			pl.trimBounds(song, s)
+		}
+	}()
+}
+
+// trim applies the trim points of the current song to s and removes its leading and trailing silence.
+// It returns the trimmed streamer and the number of samples trimmed from the start of the song.
+// Streams without a known length are not trimmed.
+func (pl *Playlist) trim(s beep.StreamSeekCloser) (beep.StreamSeekCloser, int) {
+	length := s.Len()
+	if length <= 0 || (pl.trimHandler == nil && pl.silenceThreshold <= 0) {
+		return s, 0
+	}
+	start, end := pl.trimBounds(pl.currentSong, s)
+
+	if start == 0 && end == length {
+		if err := s.Seek(0); err != nil {
+			logger.Warnf("failed to seek to the start of %s: %v", pl.currentSong, err)
+		}
+		return s, 0
+	}
+	section, err := newSectionStreamer(s, start, end)
+	if err != nil {
+		logger.Warnf("failed to trim %s: %v", pl.currentSong, err)
+		return s, 0
+	}
+	logger.Debugf("trimmed %s to samples %d-%d of %d", pl.currentSong, start, end, length)
+	return section, start
+}
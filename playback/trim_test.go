@@ -0,0 +1,167 @@
+package playback
+
+import (
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/logging"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// sliceStreamer streams samples and can seek within them
+type sliceStreamer struct {
+	samples  [][2]float64
+	position int
+}
+
+func (ss *sliceStreamer) Err() error    { return nil }
+func (ss *sliceStreamer) Len() int      { return len(ss.samples) }
+func (ss *sliceStreamer) Position() int { return ss.position }
+func (ss *sliceStreamer) Close() error  { return nil }
+
+func (ss *sliceStreamer) Seek(p int) error {
+	if p < 0 || len(ss.samples) < p {
+		return fmt.Errorf("position %d out of range", p)
+	}
+	ss.position = p
+	return nil
+}
+
+func (ss *sliceStreamer) Stream(samples [][2]float64) (int, bool) {
+	if len(ss.samples) <= ss.position {
+		return 0, false
+	}
+	n := copy(samples, ss.samples[ss.position:])
+	ss.position += n
+	return n, true
+}
+
+// silenceTestStreamer returns a streamer with lead silent samples, loud samples of value 0.5 and trail silent samples
+func silenceTestStreamer(lead, loud, trail int) *sliceStreamer {
+	samples := make([][2]float64, lead+loud+trail)
+	for i := range samples {
+		samples[i] = [2]float64{0.001, -0.001}
+		if lead <= i && i < lead+loud {
+			samples[i] = [2]float64{0.5, -0.5}
+		}
+	}
+	return &sliceStreamer{samples: samples}
+}
+
+func TestDetectSilence(t *testing.T) {
+	for _, c := range []struct {
+		lead, loud, trail int
+		start, end        int
+		scanLength        int
+		first, last       int
+	}{
+		{lead: 1000, loud: 500, trail: 2000, start: 0, end: 3500, first: 1000, last: 1500},
+		{lead: 0, loud: 1500, trail: 0, start: 0, end: 1500, first: 0, last: 1500},
+		{lead: 1000, loud: 500, trail: 2000, start: 1200, end: 1400, first: 1200, last: 1400},
+		{lead: 3000, loud: 0, trail: 0, start: 0, end: 3000, first: 0, last: 3000},
+		{lead: 10, loud: 10, trail: 1100, start: 0, end: 1120, scanLength: 1000, first: 10, last: 120},
+		{lead: 10, loud: 10, trail: 1100, start: 0, end: 1120, scanLength: 2000, first: 10, last: 20},
+	} {
+		first, last, err := detectSilence(silenceTestStreamer(c.lead, c.loud, c.trail), c.start, c.end, 0.01, c.scanLength)
+		assert.Nil(t, err, "detectSilence returned an error for %+v", c)
+		assert.Equal(t, c.first, first, "detectSilence returned the wrong first sample for %+v", c)
+		assert.Equal(t, c.last, last, "detectSilence returned the wrong last sample for %+v", c)
+	}
+}
+
+func TestPlaylist_trim(t *testing.T) {
+	log.DefaultCutoffLevel = log.LevelOff
+	pl := NewPlaylist(0, []string{}, 0)
+	pl.currentSong = "the-song"
+
+	s, start := pl.trim(silenceTestStreamer(1000, 500, 2000))
+	assert.Equal(t, 0, start, "trim trimmed a song without trim points or silence threshold")
+	assert.Equal(t, 3500, s.Len(), "trim changed the length of a song without trim points or silence threshold")
+
+	pl.SetSilenceThreshold(0.01)
+	s, start = pl.trim(silenceTestStreamer(1000, 500, 2000))
+	assert.Equal(t, 1000, start, "trim did not trim the leading silence")
+	assert.Equal(t, 500, s.Len(), "trim did not trim the trailing silence")
+	assert.Equal(t, 0, s.Position(), "trimmed song does not start at its first sample")
+	buf := make([][2]float64, 1024)
+	n, _ := s.Stream(buf)
+	assert.Equal(t, 500, n, "trimmed song streamed the wrong number of samples")
+	assert.Equal(t, [2]float64{0.5, -0.5}, buf[0], "trimmed song streamed the wrong first sample")
+
+	s, start = pl.trim(silenceTestStreamer(0, 3500, 0))
+	assert.Equal(t, 1000, start, "trim did not use the cached leading silence")
+	assert.Equal(t, 500, s.Len(), "trim did not use the cached trailing silence")
+
+	var trimmedSong string
+	pl.SetTrimHandler(func(song string) (int, int) { trimmedSong = song; return 1200, 1400 })
+	s, start = pl.trim(silenceTestStreamer(1000, 500, 2000))
+	assert.Equal(t, "the-song", trimmedSong, "trim called the trim handler with the wrong song")
+	assert.Equal(t, 1200, start, "trim did not apply the start trim point")
+	assert.Equal(t, 200, s.Len(), "trim did not apply the end trim point")
+
+	pl.SetTrimHandler(func(string) (int, int) { return 3000, 2000 })
+	s, start = pl.trim(silenceTestStreamer(1000, 500, 2000))
+	assert.Equal(t, 1000, start, "trim did not ignore invalid trim points")
+	assert.Equal(t, 500, s.Len(), "trim did not ignore invalid trim points")
+
+	s, start = pl.trim(&sliceStreamer{})
+	assert.Equal(t, 0, start, "trim trimmed a stream without length")
+	assert.Equal(t, 0, s.Len(), "trim trimmed a stream without length")
+}
+
+func TestPlaylist_pushStreamerTrimmed(t *testing.T) {
+	pl := NewPlaylist(2*streamerBufferSize, []string{}, 0)
+	pl.currentSong = "the-song"
+	pl.SetPlaying(true)
+	pl.SetSilenceThreshold(0.01)
+
+	done := make(chan bool)
+	var songLength, trimStart int64
+	pl.SetNewSongHandler(func(_ uint64, _ string, sl int64, ts int64, _ float64) {
+		songLength, trimStart = sl, ts
+		done <- true
+	})
+
+	pl.pushStreamer(silenceTestStreamer(1000, 300, 1000))
+	<-done
+	assert.Equal(t, int64(300), songLength, "NewSongHandler called with the wrong songLength")
+	assert.Equal(t, int64(1000), trimStart, "NewSongHandler called with the wrong trimStart")
+	assert.Equal(t, 300, len(pl.low), "pushStreamer pushed the wrong number of samples")
+	for i := 0; i < 300; i++ {
+		assert.Equal(t, 0.5, <-pl.low, "pushStreamer pushed the wrong %d-th sample", i)
+	}
+}
+
+func TestPlaylist_trailingSilenceScanLength(t *testing.T) {
+	pl := NewPlaylist(0, []string{}, 0)
+	assert.Equal(t, 0, pl.trailingSilenceScanLength(), "the whole song is not scanned without a sample rate")
+	pl.SetSampleRate(48000)
+	assert.Equal(t, 30*48000, pl.trailingSilenceScanLength(), "the scan length does not use the sample rate")
+}
+
+func TestPlaylist_prefetchSilence(t *testing.T) {
+	defer func(ad string) { AudioDir = ad }(AudioDir)
+	AudioDir = "_playback_test_files"
+	pl := NewPlaylist(0, []string{"current.mp3", "okay.mp3", "http://host/stream"}, 0)
+	pl.SetSilenceThreshold(0.01)
+
+	pl.prefetchSilence()
+	s, err := getStreamer("okay.mp3")
+	if !assert.NoError(t, err, "failed to open okay.mp3") {
+		return
+	}
+	defer s.Close()
+	key := silenceKey{song: "okay.mp3", start: 0, end: s.Len(), threshold: 0.01}
+	assert.Eventually(t, func() bool {
+		pl.silenceMutex.Lock()
+		defer pl.silenceMutex.Unlock()
+		_, ok := pl.silence[key]
+		return ok
+	}, time.Second, 10*time.Millisecond, "prefetchSilence did not detect the silence of the next song")
+
+	pl.position = 1
+	pl.prefetchSilence()
+	pl.silenceMutex.Lock()
+	assert.Len(t, pl.silence, 1, "prefetchSilence detected the silence of a http stream")
+	pl.silenceMutex.Unlock()
+}
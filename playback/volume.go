@@ -0,0 +1,45 @@
+package playback
+
+import "sync"
+
+// volumeRamp changes the volume linearly from from to to between the sample indices start and end of the stream
+type volumeRamp struct {
+	from, to   float64
+	start, end uint64
+}
+
+func (r volumeRamp) at(index uint64) float64 {
+	switch {
+	case index <= r.start:
+		return r.from
+	case r.end <= index:
+		return r.to
+	}
+	return r.from + (r.to-r.from)*float64(index-r.start)/float64(r.end-r.start)
+}
+
+func (r volumeRamp) constant() bool {
+	return r.from == r.to
+}
+
+var (
+	ramp        volumeRamp
+	volumeMutex sync.RWMutex
+)
+
+// currentRamp returns the current volume ramp
+func currentRamp() volumeRamp {
+	volumeMutex.RLock()
+	defer volumeMutex.RUnlock()
+	return ramp
+}
+
+// RampVolume changes the volume linearly to v over length samples, beginning with the sample at sampleIndex.
+// As the ramp is given in samples of the stream, all players apply the same volume to the same sample.
+func RampVolume(v float64, sampleIndex uint64, length uint64) {
+	volumeMutex.Lock()
+	ramp = volumeRamp{from: ramp.at(sampleIndex), to: v, start: sampleIndex, end: sampleIndex + length}
+	volume = v
+	volumeMutex.Unlock()
+	logger.Infof("ramping volume to %.3f over %d samples, starting at sample %d", v, length, sampleIndex)
+}
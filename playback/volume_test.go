@@ -0,0 +1,53 @@
+package playback
+
+import (
+	"github.com/LogicalOverflow/music-sync/logging"
+	"github.com/faiface/beep"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVolumeRamp_at(t *testing.T) {
+	r := volumeRamp{from: 0.2, to: 0.6, start: 100, end: 200}
+	assert.Equal(t, 0.2, r.at(0), "volumeRamp returned the wrong volume before the ramp")
+	assert.Equal(t, 0.2, r.at(100), "volumeRamp returned the wrong volume at the start of the ramp")
+	assert.InDelta(t, 0.4, r.at(150), 1e-9, "volumeRamp returned the wrong volume in the middle of the ramp")
+	assert.Equal(t, 0.6, r.at(200), "volumeRamp returned the wrong volume at the end of the ramp")
+	assert.Equal(t, 0.6, r.at(1000), "volumeRamp returned the wrong volume after the ramp")
+}
+
+func TestRampVolume(t *testing.T) {
+	log.DefaultCutoffLevel = log.LevelOff
+	defer SetVolume(.1)
+
+	SetVolume(1)
+	RampVolume(0.5, 1100, 100)
+	assert.Equal(t, 0.5, volume, "RampVolume did not set the target volume")
+	assert.Equal(t, volumeRamp{from: 1, to: 0.5, start: 1100, end: 1200}, ramp, "RampVolume did not ramp at the sample")
+
+	RampVolume(0, 1300, 0)
+	assert.Equal(t, volumeRamp{from: 0.5, to: 0, start: 1300, end: 1300}, ramp, "RampVolume did not change the volume at the sample for a length of 0")
+	assert.Equal(t, 0.5, ramp.at(1300), "volume changed before the sample for a length of 0")
+	assert.Equal(t, 0.0, ramp.at(1301), "volume did not change after the sample for a length of 0")
+
+	RampVolume(1, 1150, 100)
+	assert.Equal(t, volumeRamp{from: 0.5, to: 1, start: 1150, end: 1250}, ramp, "RampVolume did not start at the volume of the previous ramp")
+}
+
+func TestTimedMultiStreamer_streamDirect(t *testing.T) {
+	log.DefaultCutoffLevel = log.LevelOff
+	defer SetVolume(.1)
+	tms := &timedMultiStreamer{format: beep.Format{SampleRate: 1000}, samples: newTimedSampleQueue(16)}
+	for i := 0; i < 4; i++ {
+		// the synced times do not matter for the volume, only the sample indices do
+		tms.samples.Add([2]float64{1, -1}, int64(i*7), uint64(100+i))
+	}
+
+	SetVolume(0)
+	RampVolume(1, 101, 2)
+	samples := make([][2]float64, 4)
+	n, drained := tms.streamDirect(samples, currentRamp())
+	assert.Equal(t, 4, n, "streamDirect streamed the wrong number of samples")
+	assert.False(t, drained, "streamDirect drained without a nan sample")
+	assert.Equal(t, [][2]float64{{0, 0}, {0, 0}, {0.5, -0.5}, {1, -1}}, samples, "streamDirect did not apply the volume at the sample indices")
+}
@@ -0,0 +1,46 @@
+package schedule
+
+import (
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"strings"
+)
+
+// createAnnouncementHandler returns the announcement handler logging the announcements. The song keeps playing
+// during announcements, so the infoers need no update.
+func (ss *serverState) createAnnouncementHandler() func(uint64, int64) {
+	return func(startSampleIndex uint64, length int64) {
+		logger.Infof("announcement starts at sample %d and lasts %d samples", startSampleIndex, length)
+	}
+}
+
+func (ss *serverState) announceCommand() ssh.Command {
+	return ssh.Command{
+		Name:  "announce",
+		Usage: "filename",
+		Info:  "plays a clip over the music, ducking the current song",
+		ExecFunc: func(args []string) (string, bool) {
+			clip, ok := parseStringParam(args, 0)
+			if !ok {
+				return "", false
+			}
+			if err := ss.playlist.Announce(clip); err != nil {
+				return fmt.Sprintf("failed to announce %s: %v", clip, err), true
+			}
+			return fmt.Sprintf("announcing %s", clip), true
+		},
+		OptionsFunc: func(prefix string, arg int) []string {
+			if arg != 0 {
+				return []string{}
+			}
+			options := make([]string, 0)
+			for _, clip := range playback.ListSongs("") {
+				if strings.HasPrefix(clip, prefix) {
+					options = append(options, clip)
+				}
+			}
+			return options
+		},
+	}
+}
@@ -0,0 +1,40 @@
+package schedule
+
+import (
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/testutil"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestServerState_createAnnouncementHandler(t *testing.T) {
+	ss := newTestServerState([]string{}, true)
+	fs := &fakeSender{}
+	ss.sender = fs
+	ss.pauses = make([]*comm.PauseInfo, 0)
+	handler := ss.createAnnouncementHandler()
+
+	handler(1000, 500)
+	assert.Nil(t, fs.lastMessage, "announcement handler sent a message for an announcement")
+	assert.Empty(t, ss.pauses, "announcement handler paused the song during an announcement")
+}
+
+func TestServerState_announceCommand(t *testing.T) {
+	playback.AudioDir = "_queue_test_files"
+	ss := newTestServerState([]string{}, true)
+
+	testutil.CommandTesters{
+		Command: ss.announceCommand(),
+		Testers: []testutil.CommandTester{
+			noArgsError,
+			testutil.OptionsTestCase{Prefix: "song", Arg: 0, Result: []string{"song1.mp3", "song2.mp3", "song3.mp3"}},
+			testutil.OptionsTestCase{Prefix: "", Arg: 1, Result: []string{}},
+		},
+	}.Test(t)
+
+	result, ok := ss.announceCommand().ExecFunc([]string{"missing.mp3"})
+	assert.True(t, ok, "announce command failed for a missing clip")
+	assert.True(t, strings.HasPrefix(result, "failed to announce missing.mp3: "), "announce command returned the wrong result for a missing clip: %s", result)
+}
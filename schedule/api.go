@@ -0,0 +1,184 @@
+package schedule
+
+import (
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/api"
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"github.com/LogicalOverflow/music-sync/timing"
+	"github.com/LogicalOverflow/music-sync/util"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// searchLibrary returns the songs in the music directory matching query
+func searchLibrary(query string) []string {
+	return metadata.SearchSongs(playback.ListSongs(""), query)
+}
+
+func searchCommand() ssh.Command {
+	return ssh.Command{
+		Name:  "search",
+		Usage: "words...",
+		Info:  "lists all songs with all words in their path, title, artist, album, composer or genre",
+		ExecFunc: func(args []string) (string, bool) {
+			if len(args) == 0 {
+				return "", false
+			}
+			songs := searchLibrary(strings.Join(args, " "))
+			if len(songs) == 0 {
+				return fmt.Sprintf("no song matches %s", strings.Join(args, " ")), true
+			}
+			return strings.Join(songs, "\n"), true
+		},
+	}
+}
+
+// apiSong returns song with its metadata as returned by the api
+func (ss *serverState) apiSong(song string) api.Song {
+	return api.NewSong(song, ss.songMetadata(song))
+}
+
+// pausedSamples returns the number of samples between from and to, during which playback was paused
+func pausedSamples(pauses []*comm.PauseInfo, from, to uint64) uint64 {
+	playing, pausedAt, paused := true, from, uint64(0)
+	for _, p := range pauses {
+		if to < p.ToggleSampleIndex {
+			break
+		}
+		index := p.ToggleSampleIndex
+		if index < from {
+			index = from
+		}
+		if playing && !p.Playing {
+			pausedAt = index
+		} else if !playing && p.Playing {
+			paused += index - pausedAt
+		}
+		playing = p.Playing
+	}
+	if !playing {
+		paused += to - pausedAt
+	}
+	return paused
+}
+
+// songTime returns the time the song of info has been playing for at sample and its length
+func (ss *serverState) songTime(info *comm.NewSongInfo, sample uint64) (elapsed, length time.Duration) {
+	toDuration := func(samples int64) time.Duration {
+		return time.Duration(samples) * time.Second / time.Duration(SampleRate)
+	}
+	if !info.Live {
+		length = toDuration(info.SongLength)
+	}
+	if sample <= info.FirstSampleOfSongIndex {
+		return 0, length
+	}
+
+	ss.pausesMutex.RLock()
+	paused := pausedSamples(ss.pauses, info.FirstSampleOfSongIndex, sample)
+	ss.pausesMutex.RUnlock()
+
+	elapsed = toDuration(int64(sample-info.FirstSampleOfSongIndex-paused) + info.SongOffset)
+	if 0 < length && length < elapsed {
+		elapsed = length
+	}
+	return elapsed, length
+}
+
+// apiStatus returns the current status as returned by the api. The song is the song last announced to the infoers.
+func (ss *serverState) apiStatus() api.Status {
+	status := api.Status{
+		Playing:  ss.playlist.Playing(),
+		Position: ss.playlist.Pos(),
+		Volume:   ss.volume,
+		Speed:    ss.playlist.Speed(),
+	}
+	if ss.autoDJ != nil {
+		status.AutoDJMode = ss.autoDJ.playMode()
+	}
+	if ss.party != nil {
+		status.Party = ss.party.isEnabled()
+	}
+
+	ss.songMutex.Lock()
+	info := ss.newestSong
+	ss.songMutex.Unlock()
+	if info == nil {
+		if song := ss.playlist.CurrentSong(); song != "" {
+			s := ss.apiSong(song)
+			status.Song = &s
+		}
+		return status
+	}
+
+	s := ss.apiSong(info.SongFileName)
+	if info.Metadata != nil && s.Title == "" {
+		// http streams only have the metadata announced
+		s.Title, s.Artist = info.Metadata.Title, info.Metadata.Artist
+	}
+	status.Song = &s
+	status.Lyrics = fromWireLyrics(info.Lyrics)
+	elapsed, length := ss.songTime(info, ss.sampleIndexAt(timing.GetSyncedTime()))
+	status.Elapsed, status.Length = elapsed.Seconds(), length.Seconds()
+	return status
+}
+
+// apiPlaylist returns the playlist as returned by the api
+func (ss *serverState) apiPlaylist() []api.PlaylistEntry {
+	entries := ss.playlistState().entries
+	playlist := make([]api.PlaylistEntry, len(entries))
+	for i, e := range entries {
+		playlist[i] = api.PlaylistEntry{Song: ss.apiSong(e.song), ID: e.id, AutoQueued: e.autoQueued}
+	}
+	return playlist
+}
+
+// apiLibrary returns the sub directories and songs of the directory dir in the music directory as returned by the api
+func (ss *serverState) apiLibrary(dir string) api.Library {
+	dir = filepath.Clean(dir)
+	if dir == "." {
+		dir = ""
+	}
+	library := api.Library{Dirs: make([]string, 0), Songs: make([]api.Song, 0)}
+	for _, sub := range util.ListAllSubDirs(filepath.Join(playback.AudioDir, dir)) {
+		if !strings.ContainsRune(sub, filepath.Separator) {
+			library.Dirs = append(library.Dirs, filepath.ToSlash(filepath.Join(dir, sub)))
+		}
+	}
+	sort.Strings(library.Dirs)
+
+	songs := make([]string, 0)
+	for _, song := range playback.ListSongs(dir) {
+		if songDir := filepath.Dir(song); songDir == dir || (dir == "" && songDir == ".") {
+			songs = append(songs, song)
+		}
+	}
+	metadata.SortSongs(songs)
+	for _, song := range songs {
+		library.Songs = append(library.Songs, ss.apiSong(song))
+	}
+	return library
+}
+
+// apiSearch returns at most limit songs in the music directory matching query as returned by the api
+func (ss *serverState) apiSearch(query string, limit int) []api.Song {
+	songs := searchLibrary(query)
+	if limit < len(songs) {
+		songs = songs[:limit]
+	}
+	result := make([]api.Song, len(songs))
+	for i, s := range songs {
+		result[i] = ss.apiSong(s)
+	}
+	return result
+}
+
+// apiPlayer returns the configuration of the browser player
+func apiPlayer() api.PlayerConfig {
+	return api.PlayerConfig{SampleRate: SampleRate}
+}
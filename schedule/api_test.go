@@ -0,0 +1,126 @@
+package schedule
+
+import (
+	"github.com/LogicalOverflow/music-sync/api"
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPausedSamples(t *testing.T) {
+	pauses := []*comm.PauseInfo{
+		{Playing: false, ToggleSampleIndex: 50},
+		{Playing: true, ToggleSampleIndex: 150},
+		{Playing: false, ToggleSampleIndex: 300},
+		{Playing: true, ToggleSampleIndex: 400},
+	}
+	assert.Equal(t, uint64(0), pausedSamples(nil, 100, 200), "pausedSamples returned pauses without pauses")
+	assert.Equal(t, uint64(50), pausedSamples(pauses, 100, 200), "pausedSamples did not start at from")
+	assert.Equal(t, uint64(100), pausedSamples(pauses, 100, 350), "pausedSamples did not stop at to")
+	assert.Equal(t, uint64(150), pausedSamples(pauses, 100, 500), "pausedSamples did not add all pauses")
+	assert.Equal(t, uint64(0), pausedSamples(pauses, 200, 250), "pausedSamples returned pauses between pauses")
+}
+
+func TestServerState_songTime(t *testing.T) {
+	oldSampleRate := SampleRate
+	defer func() { SampleRate = oldSampleRate }()
+	SampleRate = 1000
+
+	ss := newTestServerState(nil, true)
+	ss.pauses = []*comm.PauseInfo{{Playing: false, ToggleSampleIndex: 3000}, {Playing: true, ToggleSampleIndex: 4000}}
+	info := &comm.NewSongInfo{FirstSampleOfSongIndex: 1000, SongLength: 10000, SongOffset: 500}
+
+	elapsed, length := ss.songTime(info, 500)
+	assert.Equal(t, time.Duration(0), elapsed, "songTime returned a time before the song started")
+	assert.Equal(t, 10*time.Second, length, "songTime returned the wrong length")
+
+	elapsed, _ = ss.songTime(info, 5000)
+	assert.Equal(t, 3500*time.Millisecond, elapsed, "songTime did not subtract the pause or add the offset")
+	elapsed, _ = ss.songTime(info, 20000)
+	assert.Equal(t, 10*time.Second, elapsed, "songTime did not stop at the length")
+
+	info.Live = true
+	elapsed, length = ss.songTime(info, 20000)
+	assert.Equal(t, time.Duration(0), length, "songTime returned a length for a live song")
+	assert.Equal(t, 18500*time.Millisecond, elapsed, "songTime did not return the time of a live song")
+}
+
+func TestServerState_apiStatus(t *testing.T) {
+	ss := newTestServerState([]string{"a.mp3", "b.mp3"}, true)
+	ss.metadataProvider = fakeMetadataProvider{"a.mp3": metadata.SongMetadata{Title: "A", Duration: time.Minute}}
+	ss.volume = 0.5
+	ss.playlist.SetSpeed(1)
+
+	status := ss.apiStatus()
+	assert.Equal(t, api.Status{Playing: true, Volume: 0.5, Speed: 1}, status, "apiStatus returned a song before playback")
+
+	ss.newestSong = &comm.NewSongInfo{SongFileName: "http://stream", Metadata: &comm.NewSongInfo_SongMetadata{Title: "T", Artist: "A"}}
+	status = ss.apiStatus()
+	assert.Equal(t, &api.Song{File: "http://stream", Title: "T", Artist: "A"}, status.Song,
+		"apiStatus did not return the announced metadata of a stream")
+
+	lyrics := []metadata.LyricsLine{{{Timestamp: 1000, Caption: "first "}, {Timestamp: 1500, Caption: "line"}}}
+	ss.newestSong = &comm.NewSongInfo{SongFileName: "a.mp3", Lyrics: toWireLyrics(lyrics)}
+	status = ss.apiStatus()
+	assert.Equal(t, &api.Song{File: "a.mp3", Title: "A", Duration: 60}, status.Song, "apiStatus did not return the song metadata")
+	assert.Equal(t, lyrics, status.Lyrics, "apiStatus did not return the announced lyrics")
+}
+
+func TestServerState_apiLibrary(t *testing.T) {
+	playback.AudioDir = "_queue_test_files"
+	ss := newTestServerState(nil, true)
+
+	library := ss.apiLibrary("")
+	assert.Equal(t, []string{"dir1", "dir2", "dir3"}, library.Dirs, "apiLibrary returned the wrong directories of the root")
+	assert.Equal(t, []api.Song{{File: "song1.mp3"}, {File: "song2.mp3"}, {File: "song3.mp3"}}, library.Songs,
+		"apiLibrary returned the wrong songs of the root")
+
+	library = ss.apiLibrary("dir1/")
+	assert.Equal(t, []string{"dir1/subdir1", "dir1/subdir2", "dir1/subdir3"}, library.Dirs,
+		"apiLibrary returned the wrong directories of a sub directory")
+	assert.Equal(t, []api.Song{{File: "dir1/song1.mp3"}, {File: "dir1/song2.mp3"}, {File: "dir1/song3.mp3"}}, library.Songs,
+		"apiLibrary returned the wrong songs of a sub directory")
+
+	library = ss.apiLibrary("no-such-dir")
+	assert.Empty(t, library.Dirs, "apiLibrary returned directories of a missing directory")
+	assert.Empty(t, library.Songs, "apiLibrary returned songs of a missing directory")
+}
+
+func TestServerState_apiPlaylist(t *testing.T) {
+	ss := newTestServerState([]string{"a.mp3", "b.mp3"}, true)
+	ss.metadataProvider = fakeMetadataProvider{"b.mp3": metadata.SongMetadata{Title: "B", Track: 2}}
+	assert.Equal(t, []api.PlaylistEntry{{Song: api.Song{File: "a.mp3"}, ID: 1}, {Song: api.Song{File: "b.mp3", Title: "B", Track: 2}, ID: 2}},
+		ss.apiPlaylist(), "apiPlaylist returned the wrong playlist")
+}
+
+func TestServerState_apiSearch(t *testing.T) {
+	playback.AudioDir = "_queue_test_files"
+	ss := newTestServerState(nil, true)
+	assert.Equal(t, []api.Song{{File: "dir1/song1.mp3"}, {File: "dir1/subdir1/song1.mp3"}}, ss.apiSearch("dir1 song1", 2),
+		"apiSearch did not limit the results")
+	assert.Empty(t, ss.apiSearch("no-such-song", 10), "apiSearch returned songs for an unknown song")
+}
+
+func TestApiPlayer(t *testing.T) {
+	assert.Equal(t, api.PlayerConfig{SampleRate: SampleRate}, apiPlayer(), "apiPlayer returned the wrong configuration")
+}
+
+func TestSearchCommand(t *testing.T) {
+	playback.AudioDir = "_queue_test_files"
+	cmd := searchCommand()
+	_, ok := cmd.Exec([]string{})
+	assert.False(t, ok, "search command accepted no words")
+
+	result, ok := cmd.Exec([]string{"dir2", "SONG3"})
+	assert.True(t, ok, "search command failed")
+	assert.Equal(t, strings.Join(searchLibrary("dir2 song3"), "\n"), result, "search command returned the wrong songs")
+	assert.Contains(t, result, "dir2/song3.mp3", "search command did not find the song")
+
+	result, ok = cmd.Exec([]string{"no-such-song"})
+	assert.True(t, ok, "search command failed")
+	assert.Equal(t, "no song matches no-such-song", result, "search command did not report no matches")
+}
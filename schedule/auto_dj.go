@@ -0,0 +1,290 @@
+package schedule
+
+import (
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type autoDJMode int
+
+const (
+	autoDJRandom autoDJMode = iota
+	autoDJLeastRecentlyPlayed
+	autoDJSameArtist
+	autoDJSameAlbum
+	autoDJSameGenre
+)
+
+var autoDJModeNames = map[autoDJMode]string{
+	autoDJRandom:              "random",
+	autoDJLeastRecentlyPlayed: "lru",
+	autoDJSameArtist:          "artist",
+	autoDJSameAlbum:           "album",
+	autoDJSameGenre:           "genre",
+}
+
+func (m autoDJMode) String() string {
+	return autoDJModeNames[m]
+}
+
+func autoDJModeByName(name string) (autoDJMode, bool) {
+	for m, n := range autoDJModeNames {
+		if n == name {
+			return m, true
+		}
+	}
+	return autoDJRandom, false
+}
+
+func autoDJModeList() []string {
+	names := make([]string, 0, len(autoDJModeNames))
+	for m := autoDJRandom; m <= autoDJSameGenre; m++ {
+		names = append(names, m.String())
+	}
+	return names
+}
+
+// AutoDJThreshold is the default number of upcoming songs below which the auto-dj adds songs to the playlist
+var AutoDJThreshold = 2
+
+// AutoDJLibraryInterval is the time for which the auto-dj reuses the songs of the music directory it listed, before
+// listing them again
+var AutoDJLibraryInterval = time.Minute
+
+type autoDJ struct {
+	enabled   bool
+	mode      autoDJMode
+	threshold int
+
+	lastPlayed map[string]time.Time
+	mutex      sync.RWMutex
+
+	// library is the last listing of the songs of the music directory, listed at libraryTime
+	library     []string
+	libraryTime time.Time
+
+	metadataProvider metadata.Provider
+	rand             *rand.Rand
+}
+
+func newAutoDJ(metadataProvider metadata.Provider) *autoDJ {
+	return &autoDJ{
+		enabled:          false,
+		mode:             autoDJRandom,
+		threshold:        AutoDJThreshold,
+		lastPlayed:       make(map[string]time.Time),
+		metadataProvider: metadataProvider,
+		rand:             rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// songPlayed records that song started playing at t
+func (dj *autoDJ) songPlayed(song string, t time.Time) {
+	dj.mutex.Lock()
+	defer dj.mutex.Unlock()
+	dj.lastPlayed[song] = t
+}
+
+// songMetadata returns the metadata of song, the metadata provider caches it until the song file changes
+func (dj *autoDJ) songMetadata(song string) metadata.SongMetadata {
+	if dj.metadataProvider == nil {
+		return metadata.SongMetadata{}
+	}
+	return dj.metadataProvider.CollectMetadata(song)
+}
+
+func (dj *autoDJ) queueHandler() func(string, int) []string {
+	return func(lastSong string, upcoming int) []string {
+		dj.mutex.RLock()
+		enabled, threshold := dj.enabled, dj.threshold
+		dj.mutex.RUnlock()
+
+		if !enabled || threshold <= upcoming {
+			return []string{}
+		}
+
+		library := dj.librarySongs()
+		picked := make([]string, 0, threshold-upcoming)
+		for i := upcoming; i < threshold; i++ {
+			song, ok := dj.pick(library, lastSong)
+			if !ok {
+				break
+			}
+			picked = append(picked, song)
+			lastSong = song
+		}
+		if 0 < len(picked) {
+			logger.Infof("auto-dj added %d song(s) to the playlist: %s", len(picked), strings.Join(picked, ", "))
+		}
+		return picked
+	}
+}
+
+// librarySongs returns the songs of the music directory, listing them at most once every AutoDJLibraryInterval
+func (dj *autoDJ) librarySongs() []string {
+	dj.mutex.Lock()
+	defer dj.mutex.Unlock()
+	if dj.libraryTime.IsZero() || AutoDJLibraryInterval <= time.Since(dj.libraryTime) {
+		dj.library, dj.libraryTime = playback.ListSongs(""), time.Now()
+	}
+	return dj.library
+}
+
+// pick selects the next song from library according to the current mode.
+// Modes matching the previous song fall back to a random song if no other song matches.
+func (dj *autoDJ) pick(library []string, previous string) (string, bool) {
+	candidates := make([]string, 0, len(library))
+	for _, s := range library {
+		if s != previous {
+			candidates = append(candidates, s)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = library
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	dj.mutex.RLock()
+	mode := dj.mode
+	dj.mutex.RUnlock()
+
+	switch mode {
+	case autoDJLeastRecentlyPlayed:
+		return dj.leastRecentlyPlayed(candidates), true
+	case autoDJSameArtist, autoDJSameAlbum, autoDJSameGenre:
+		if matching := dj.matching(candidates, previous, mode); 0 < len(matching) {
+			candidates = matching
+		}
+	}
+	return dj.randomSong(candidates), true
+}
+
+func (dj *autoDJ) randomSong(candidates []string) string {
+	dj.mutex.Lock()
+	defer dj.mutex.Unlock()
+	return candidates[dj.rand.Intn(len(candidates))]
+}
+
+func (dj *autoDJ) leastRecentlyPlayed(candidates []string) string {
+	dj.mutex.RLock()
+	defer dj.mutex.RUnlock()
+	sorted := make([]string, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return dj.lastPlayed[sorted[i]].Before(dj.lastPlayed[sorted[j]])
+	})
+	return sorted[0]
+}
+
+func (dj *autoDJ) matching(candidates []string, previous string, mode autoDJMode) []string {
+	if previous == "" {
+		return []string{}
+	}
+	key := func(md metadata.SongMetadata) string {
+		switch mode {
+		case autoDJSameArtist:
+			return md.Artist
+		case autoDJSameAlbum:
+			return md.Album
+		default:
+			return md.Genre
+		}
+	}
+
+	target := key(dj.songMetadata(previous))
+	if target == "" {
+		return []string{}
+	}
+	matching := make([]string, 0)
+	for _, c := range candidates {
+		if key(dj.songMetadata(c)) == target {
+			matching = append(matching, c)
+		}
+	}
+	return matching
+}
+
+func (dj *autoDJ) status() string {
+	dj.mutex.RLock()
+	defer dj.mutex.RUnlock()
+	state := "off"
+	if dj.enabled {
+		state = "on"
+	}
+	return fmt.Sprintf("auto-dj is %s (mode: %s, threshold: %d)", state, dj.mode, dj.threshold)
+}
+
+// playMode returns the name of the mode of the auto-dj or "" if it is disabled
+func (dj *autoDJ) playMode() string {
+	dj.mutex.RLock()
+	defer dj.mutex.RUnlock()
+	if !dj.enabled {
+		return ""
+	}
+	return dj.mode.String()
+}
+
+func (dj *autoDJ) commandExec(args []string) (string, bool) {
+	action, ok := parseStringParam(args, 0)
+	if !ok {
+		return dj.status(), true
+	}
+
+	switch action {
+	case "on", "off":
+		dj.mutex.Lock()
+		dj.enabled = action == "on"
+		dj.mutex.Unlock()
+	case "mode":
+		name, ok := parseStringParam(args, 1)
+		if !ok {
+			return "", false
+		}
+		mode, ok := autoDJModeByName(name)
+		if !ok {
+			return fmt.Sprintf("unknown auto-dj mode %s (modes: %s)", name, strings.Join(autoDJModeList(), ", ")), true
+		}
+		dj.mutex.Lock()
+		dj.mode = mode
+		dj.mutex.Unlock()
+	case "threshold":
+		threshold, ok := parseIntParam(args, 1)
+		if !ok || threshold < 0 {
+			return "", false
+		}
+		dj.mutex.Lock()
+		dj.threshold = threshold
+		dj.mutex.Unlock()
+	default:
+		return "", false
+	}
+	return dj.status(), true
+}
+
+func (ss *serverState) autoDJCommand() ssh.Command {
+	return ssh.Command{
+		Name:     "autodj",
+		Usage:    "[on|off|mode mode|threshold songs]",
+		Info:     "configures the auto-dj, which adds songs when the playlist runs low",
+		ExecFunc: ss.autoDJ.commandExec,
+		OptionsFunc: func(prefix string, arg int) []string {
+			switch arg {
+			case 0:
+				return []string{"on", "off", "mode", "threshold"}
+			case 1:
+				return autoDJModeList()
+			default:
+				return []string{}
+			}
+		},
+	}
+}
@@ -0,0 +1,102 @@
+package schedule
+
+import (
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/testutil"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeMetadataProvider map[string]metadata.SongMetadata
+
+func (fmp fakeMetadataProvider) CollectMetadata(song string) metadata.SongMetadata {
+	return fmp[song]
+}
+
+func TestAutoDJ_queueHandler(t *testing.T) {
+	playback.AudioDir = "_queue_test_files"
+	dj := newAutoDJ(nil)
+	h := dj.queueHandler()
+
+	assert.Empty(t, h("", 0), "auto-dj added songs while disabled")
+
+	dj.enabled = true
+	dj.threshold = 3
+	assert.Empty(t, h("", 3), "auto-dj added songs while enough songs are upcoming")
+	picked := h("", 1)
+	assert.Len(t, picked, 2, "auto-dj did not fill the playlist up to the threshold")
+	for _, p := range picked {
+		assert.Contains(t, []string{"song1.mp3", "song2.mp3", "song3.mp3"}, filepath.Base(p), "auto-dj picked a non-song")
+	}
+}
+
+func TestAutoDJ_librarySongs(t *testing.T) {
+	defer func(interval time.Duration) { AutoDJLibraryInterval = interval }(AutoDJLibraryInterval)
+	playback.AudioDir = "_queue_test_files"
+	dj := newAutoDJ(nil)
+
+	AutoDJLibraryInterval = time.Hour
+	library := dj.librarySongs()
+	assert.Len(t, library, 21, "auto-dj listed the wrong songs")
+	playback.AudioDir = "_missing_test_files"
+	assert.Equal(t, library, dj.librarySongs(), "auto-dj listed the songs again within the interval")
+
+	AutoDJLibraryInterval = 0
+	assert.Empty(t, dj.librarySongs(), "auto-dj did not list the songs again after the interval")
+}
+
+func TestAutoDJ_pick(t *testing.T) {
+	fmp := fakeMetadataProvider{
+		"a1": {Artist: "artist-a", Album: "album-1", Genre: "genre-x"},
+		"a2": {Artist: "artist-a", Album: "album-2", Genre: "genre-y"},
+		"b1": {Artist: "artist-b", Album: "album-1", Genre: "genre-y"},
+	}
+	library := []string{"a1", "a2", "b1"}
+	dj := newAutoDJ(fmp)
+
+	dj.mode = autoDJLeastRecentlyPlayed
+	dj.songPlayed("a2", time.Unix(10, 0))
+	dj.songPlayed("b1", time.Unix(5, 0))
+	song, ok := dj.pick(library, "a1")
+	assert.True(t, ok, "auto-dj could not pick a song")
+	assert.Equal(t, "b1", song, "auto-dj did not pick the least recently played song")
+
+	for mode, expected := range map[autoDJMode]string{autoDJSameArtist: "a2", autoDJSameAlbum: "b1", autoDJSameGenre: "a2"} {
+		dj.mode = mode
+		previous := "a1"
+		if mode == autoDJSameGenre {
+			previous = "b1"
+		}
+		song, _ := dj.pick(library, previous)
+		assert.Equal(t, expected, song, "auto-dj picked the wrong song in mode %s", mode)
+	}
+
+	_, ok = dj.pick([]string{}, "")
+	assert.False(t, ok, "auto-dj picked a song from an empty library")
+}
+
+func TestServerState_autoDJCommand(t *testing.T) {
+	ss := newTestServerState([]string{}, false)
+	ss.autoDJ = newAutoDJ(nil)
+	ss.autoDJ.threshold = 2
+
+	ct := testutil.CommandTesters{
+		Command: ss.autoDJCommand(),
+		Testers: []testutil.CommandTester{
+			testutil.OptionsTestCase{Prefix: "", Arg: 0, Result: []string{"on", "off", "mode", "threshold"}},
+			testutil.OptionsTestCase{Prefix: "", Arg: 1, Result: []string{"random", "lru", "artist", "album", "genre"}},
+			testutil.ExecTestCase{Args: []string{}, Result: "auto-dj is off (mode: random, threshold: 2)", Success: true},
+			testutil.ExecTestCase{Args: []string{"on"}, Result: "auto-dj is on (mode: random, threshold: 2)", Success: true},
+			testutil.ExecTestCase{Args: []string{"mode", "album"}, Result: "auto-dj is on (mode: album, threshold: 2)", Success: true},
+			testutil.ExecTestCase{Args: []string{"mode", "polka"}, Result: "unknown auto-dj mode polka (modes: random, lru, artist, album, genre)", Success: true},
+			testutil.ExecTestCase{Args: []string{"threshold", "5"}, Result: "auto-dj is on (mode: album, threshold: 5)", Success: true},
+			testutil.ExecTestCase{Args: []string{"threshold", "-1"}, Success: false},
+			testutil.ExecTestCase{Args: []string{"sideways"}, Success: false},
+			testutil.ExecTestCase{Args: []string{"off"}, Result: "auto-dj is off (mode: album, threshold: 5)", Success: true},
+		},
+	}
+	ct.Test(t)
+}
@@ -0,0 +1,70 @@
+package schedule
+
+import (
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"strconv"
+)
+
+// ControlUsers are the users allowed to send control requests, usually the users of the ssh control interface
+var ControlUsers map[string]ssh.UserAuth
+
+// defaultVolumeStep is the volume change of volume up/down requests without a value
+const defaultVolumeStep = 0.05
+
+// defaultSeekStep is the number of seconds seek requests without a value seek forward. Seek requests are relative.
+const defaultSeekStep = 10.0
+
+// controlCommand returns the ssh command and arguments, which execute the action of request
+func (ss *serverState) controlCommand(request *comm.ControlRequest) (string, []string, error) {
+	switch request.Action {
+	case comm.ControlRequest_PAUSE:
+		return "pause", nil, nil
+	case comm.ControlRequest_RESUME:
+		return "resume", nil, nil
+	case comm.ControlRequest_NEXT:
+		return "jump", []string{strconv.Itoa(ss.playlist.Pos() + 1)}, nil
+	case comm.ControlRequest_PREVIOUS:
+		pos := ss.playlist.Pos() - 1
+		if pos < 0 {
+			pos = 0
+		}
+		return "jump", []string{strconv.Itoa(pos)}, nil
+	case comm.ControlRequest_VOLUME_UP, comm.ControlRequest_VOLUME_DOWN:
+		step := request.Value
+		if step <= 0 {
+			step = defaultVolumeStep
+		}
+		if request.Action == comm.ControlRequest_VOLUME_DOWN {
+			step = -step
+		}
+		volume := ss.volume + step
+		if volume < 0 {
+			volume = 0
+		}
+		return "volume", []string{strconv.FormatFloat(volume, 'f', -1, 64)}, nil
+	case comm.ControlRequest_SEEK:
+		seconds := request.Value
+		if seconds == 0 {
+			seconds = defaultSeekStep
+		}
+		return "seek", []string{fmt.Sprintf("%+g", seconds)}, nil
+	}
+	return "", nil, fmt.Errorf("unknown action %v", request.Action)
+}
+
+// control runs the action of a control request through the ssh command executing it, if the request contains
+// valid credentials. It returns the output of the command.
+func (ss *serverState) control(request *comm.ControlRequest) (string, error) {
+	if !ssh.CheckPassword(ControlUsers, request.User, request.Password) {
+		logger.Warnf("rejected control request as %s: invalid credentials", request.User)
+		return "", fmt.Errorf("invalid credentials")
+	}
+	name, args, err := ss.controlCommand(request)
+	if err != nil {
+		return "", err
+	}
+	logger.Infof("%s controls playback: %s %v", request.User, name, args)
+	return ssh.RunCommand(request.User, name, args)
+}
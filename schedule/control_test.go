@@ -0,0 +1,67 @@
+package schedule
+
+import (
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var controlCommandCases = []struct {
+	action comm.ControlRequest_Action
+	value  float64
+	name   string
+	args   []string
+}{
+	{action: comm.ControlRequest_PAUSE, name: "pause"},
+	{action: comm.ControlRequest_RESUME, name: "resume"},
+	{action: comm.ControlRequest_NEXT, name: "jump", args: []string{"2"}},
+	{action: comm.ControlRequest_PREVIOUS, name: "jump", args: []string{"0"}},
+	{action: comm.ControlRequest_VOLUME_UP, name: "volume", args: []string{"0.55"}},
+	{action: comm.ControlRequest_VOLUME_UP, value: 0.2, name: "volume", args: []string{"0.7"}},
+	{action: comm.ControlRequest_VOLUME_DOWN, name: "volume", args: []string{"0.45"}},
+	{action: comm.ControlRequest_VOLUME_DOWN, value: 0.8, name: "volume", args: []string{"0"}},
+	{action: comm.ControlRequest_SEEK, name: "seek", args: []string{"+10"}},
+	{action: comm.ControlRequest_SEEK, value: -2.5, name: "seek", args: []string{"-2.5"}},
+}
+
+func TestServerState_controlCommand(t *testing.T) {
+	ss := newTestServerState([]string{"song-0", "song-1", "song-2"}, true)
+	ss.volume = 0.5
+	ss.playlist.SetPos(1)
+
+	for _, c := range controlCommandCases {
+		name, args, err := ss.controlCommand(&comm.ControlRequest{Action: c.action, Value: c.value})
+		if assert.NoError(t, err, "controlCommand returned an error for %v", c.action) {
+			assert.Equal(t, c.name, name, "controlCommand returned the wrong command for %v", c.action)
+			assert.Equal(t, c.args, args, "controlCommand returned the wrong arguments for %v (value %v)", c.action, c.value)
+		}
+	}
+
+	_, _, err := ss.controlCommand(&comm.ControlRequest{Action: comm.ControlRequest_Action(100)})
+	assert.Error(t, err, "controlCommand did not return an error for an unknown action")
+}
+
+func TestServerState_control(t *testing.T) {
+	oldUsers := ControlUsers
+	defer func() { ControlUsers = oldUsers }()
+	ControlUsers = map[string]ssh.UserAuth{"user": {Password: "password"}, "key-user": {PubKey: []byte{1}}}
+
+	ss := newTestServerState([]string{"song-0"}, true)
+	ssh.RegisterCommand(ss.pauseCommand())
+
+	for _, credentials := range [][2]string{{"user", "wrong"}, {"other", "password"}, {"key-user", ""}} {
+		_, err := ss.control(&comm.ControlRequest{User: credentials[0], Password: credentials[1], Action: comm.ControlRequest_PAUSE})
+		assert.EqualError(t, err, "invalid credentials", "control accepted the credentials %v", credentials)
+	}
+	assert.True(t, ss.playlist.Playing(), "control executed a request with invalid credentials")
+
+	result, err := ss.control(&comm.ControlRequest{User: "user", Password: "password", Action: comm.ControlRequest_PAUSE})
+	if assert.NoError(t, err, "control returned an error") {
+		assert.Equal(t, "playback paused", result, "control returned the wrong result")
+		assert.False(t, ss.playlist.Playing(), "control did not execute the pause command")
+	}
+
+	_, err = ss.control(&comm.ControlRequest{User: "user", Password: "password", Action: comm.ControlRequest_SEEK})
+	assert.Error(t, err, "control did not return an error for an unregistered command")
+}
@@ -0,0 +1,62 @@
+package schedule
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"image/png"
+	"sync"
+)
+
+// CoverArtSize is the size in pixels covers are scaled to, before they are sent to the infoers
+var CoverArtSize = 64
+
+// coverState holds the cover last sent to the infoers
+type coverState struct {
+	mutex sync.Mutex
+	cover *comm.CoverArt
+}
+
+// coverArt returns the scaled cover of song encoded as png or nil, if it has none
+func (ss *serverState) coverArt(song string) *comm.CoverArt {
+	if ss.coverProvider == nil {
+		return nil
+	}
+	img := ss.coverProvider.CollectCover(song)
+	if img == nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, metadata.ScaleCover(img, CoverArtSize)); err != nil {
+		return nil
+	}
+	hash := sha1.Sum(buf.Bytes())
+	return &comm.CoverArt{Id: hex.EncodeToString(hash[:6]), Png: buf.Bytes()}
+}
+
+// updateCover sends the cover of song to all infoers, unless it was the last cover sent, and returns its id.
+// If song has no cover, the empty string is returned.
+func (ss *serverState) updateCover(song string) string {
+	cover := ss.coverArt(song)
+	if cover == nil {
+		return ""
+	}
+	ss.cover.mutex.Lock()
+	defer ss.cover.mutex.Unlock()
+	if ss.cover.cover == nil || ss.cover.cover.Id != cover.Id {
+		ss.cover.cover = cover
+		ss.sender.SendMessage(cover)
+	}
+	return cover.Id
+}
+
+// sendCover sends the cover last sent to all infoers to s
+func (ss *serverState) sendCover(s comm.MessageSender) {
+	ss.cover.mutex.Lock()
+	defer ss.cover.mutex.Unlock()
+	if ss.cover.cover != nil {
+		s.SendMessage(ss.cover.cover)
+	}
+}
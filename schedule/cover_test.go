@@ -0,0 +1,78 @@
+package schedule
+
+import (
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"testing"
+)
+
+type fakeCoverProvider map[string]image.Image
+
+func (fcp fakeCoverProvider) CollectCover(song string) image.Image {
+	if img, ok := fcp[song]; ok {
+		return img
+	}
+	return nil
+}
+
+func testCoverImage(c color.RGBA, size int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestServerState_coverArt(t *testing.T) {
+	oldCoverArtSize := CoverArtSize
+	defer func() { CoverArtSize = oldCoverArtSize }()
+	CoverArtSize = 4
+
+	ss := &serverState{coverProvider: fakeCoverProvider{
+		"red.mp3":  testCoverImage(color.RGBA{R: 255, A: 255}, 16),
+		"blue.mp3": testCoverImage(color.RGBA{B: 255, A: 255}, 16),
+	}}
+	red := ss.coverArt("red.mp3")
+	if assert.NotNil(t, red, "coverArt did not return the cover") {
+		assert.Len(t, red.Id, 12, "coverArt returned an id of the wrong length")
+		assert.NotEmpty(t, red.Png, "coverArt returned an empty png")
+		assert.Equal(t, red, ss.coverArt("red.mp3"), "coverArt is not deterministic")
+		assert.NotEqual(t, red.Id, ss.coverArt("blue.mp3").Id, "coverArt returned the same id for different covers")
+	}
+	assert.Nil(t, ss.coverArt("none.mp3"), "coverArt returned a cover for a song without one")
+	assert.Nil(t, (&serverState{}).coverArt("red.mp3"), "coverArt returned a cover without a cover provider")
+}
+
+func TestServerState_createNewSongHandlerCover(t *testing.T) {
+	fms := &fakeMessageSender{}
+	cover := testCoverImage(color.RGBA{G: 255, A: 255}, 8)
+	ss := &serverState{
+		sender:           fms,
+		lyricsProvider:   fakeLyricsProvider{},
+		metadataProvider: fakeMetadataProvider{},
+		coverProvider:    fakeCoverProvider{"a.mp3": cover, "b.mp3": cover},
+	}
+	coverArt := ss.coverArt("a.mp3")
+
+	ss.createNewSongHandler()(100, "a.mp3", 1000, 0, 1)
+	ss.createNewSongHandler()(1100, "b.mp3", 1000, 0, 1)
+	assert.Equal(t, coverArt.Id, ss.song.timed(1100, 10, 10, 2).CoverId, "timed did not keep the cover id")
+	ss.createNewSongHandler()(2100, "c.mp3", 1000, 0, 1)
+
+	messages := fms.Messages()
+	if assert.Len(t, messages, 4, "new song handler sent the wrong number of messages") {
+		assert.Equal(t, coverArt, messages[0], "new song handler did not send the cover first")
+		assert.Equal(t, coverArt.Id, messages[1].(*comm.NewSongInfo).CoverId, "new song handler did not set the cover id")
+		assert.Equal(t, coverArt.Id, messages[2].(*comm.NewSongInfo).CoverId, "new song handler did not set the cover id of a cached cover")
+		assert.Empty(t, messages[3].(*comm.NewSongInfo).CoverId, "new song handler set a cover id for a song without a cover")
+	}
+
+	newClient := &fakeMessageSender{}
+	ss.sendCover(newClient)
+	assertFakeMessageSenderMessages(t, newClient, []proto.Message{coverArt}, "sendCover")
+}
@@ -0,0 +1,99 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchLimit is the time span searched for the next time matching a cron spec
+const cronSearchLimit = 4 * 366 * 24 * time.Hour
+
+// cronSpec is a cron-style schedule with the fields minute, hour, day of month, month and day of week
+type cronSpec struct {
+	minutes, hours, days, months, weekdays map[int]bool
+	spec                                   string
+}
+
+var cronFieldRanges = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+
+// parseCronSpec parses the five fields of a cron spec. Fields can be *, numbers, ranges (a-b),
+// lists (a,b) and steps (*/n or a-b/n).
+func parseCronSpec(fields []string) (*cronSpec, error) {
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("a cron spec needs 5 fields, got %d", len(fields))
+	}
+	var parsed [5]map[int]bool
+	for i, f := range fields {
+		values, err := parseCronField(f, cronFieldRanges[i][0], cronFieldRanges[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron field %s: %v", f, err)
+		}
+		parsed[i] = values
+	}
+	return &cronSpec{
+		minutes:  parsed[0],
+		hours:    parsed[1],
+		days:     parsed[2],
+		months:   parsed[3],
+		weekdays: parsed[4],
+		spec:     strings.Join(fields, " "),
+	}, nil
+}
+
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	values := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); 0 <= i {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s < 1 {
+				return nil, fmt.Errorf("invalid step %s", part[i+1:])
+			}
+			step, part = s, part[:i]
+		}
+
+		from, to := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if from, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, fmt.Errorf("invalid value %s", bounds[0])
+			}
+			to = from
+			if len(bounds) == 2 {
+				if to, err = strconv.Atoi(bounds[1]); err != nil {
+					return nil, fmt.Errorf("invalid value %s", bounds[1])
+				}
+			}
+		}
+		if from < min || max < to || to < from {
+			return nil, fmt.Errorf("%d-%d is not within %d-%d", from, to, min, max)
+		}
+		for v := from; v <= to; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+func (c *cronSpec) matches(t time.Time) bool {
+	return c.minutes[t.Minute()] && c.hours[t.Hour()] && c.days[t.Day()] &&
+		c.months[int(t.Month())] && c.weekdays[int(t.Weekday())]
+}
+
+// next returns the first time after t matching the spec
+func (c *cronSpec) next(t time.Time) (time.Time, bool) {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for end := t.Add(cronSearchLimit); t.Before(end); t = t.Add(time.Minute) {
+		if c.matches(t) {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (c *cronSpec) String() string {
+	return c.spec
+}
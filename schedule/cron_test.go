@@ -0,0 +1,41 @@
+package schedule
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCronSpec(t *testing.T) {
+	for _, spec := range []string{"* * * *", "60 * * * *", "a * * * *", "*/0 * * * *", "5-1 * * * *", "* * 0 * *"} {
+		_, err := parseCronSpec(strings.Fields(spec))
+		assert.NotNil(t, err, "parseCronSpec accepted the invalid spec %s", spec)
+	}
+
+	c, err := parseCronSpec(strings.Fields("0,30 7-9/2 * 1 1-5"))
+	if !assert.Nil(t, err, "parseCronSpec returned an error") {
+		return
+	}
+	assert.Equal(t, map[int]bool{0: true, 30: true}, c.minutes, "parseCronSpec parsed the wrong minutes")
+	assert.Equal(t, map[int]bool{7: true, 9: true}, c.hours, "parseCronSpec parsed the wrong hours")
+	assert.Equal(t, 31, len(c.days), "parseCronSpec parsed the wrong days")
+	assert.Equal(t, map[int]bool{1: true}, c.months, "parseCronSpec parsed the wrong months")
+	assert.Equal(t, map[int]bool{1: true, 2: true, 3: true, 4: true, 5: true}, c.weekdays, "parseCronSpec parsed the wrong weekdays")
+	assert.Equal(t, "0,30 7-9/2 * 1 1-5", c.String(), "cronSpec String returned the wrong spec")
+}
+
+func TestCronSpec_next(t *testing.T) {
+	c, _ := parseCronSpec(strings.Fields("0 7 * * 1-5"))
+	friday := time.Date(2026, 10, 16, 7, 0, 0, 0, time.UTC)
+	next, ok := c.next(friday)
+	assert.True(t, ok, "cronSpec next did not find a time")
+	assert.Equal(t, time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC), next, "cronSpec next did not skip the weekend")
+
+	next, _ = c.next(friday.Add(-30 * time.Second))
+	assert.Equal(t, friday, next, "cronSpec next did not return the next matching minute")
+
+	never, _ := parseCronSpec(strings.Fields("0 0 31 2 *"))
+	_, ok = never.next(friday)
+	assert.False(t, ok, "cronSpec next found a time for a spec never matching")
+}
@@ -0,0 +1,209 @@
+package schedule
+
+import (
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PlayerName is the name a player sends to the server, which is used to configure this player only
+var PlayerName = ""
+
+// dspSettings holds the dsp config of the server, applied for all players, and the configs of single players
+type dspSettings struct {
+	server  *playback.DSP
+	players map[string]playback.DSPConfig
+	senders map[string]comm.MessageSender
+	mutex   sync.RWMutex
+}
+
+func newDSPSettings(server *playback.DSP) *dspSettings {
+	return &dspSettings{
+		server:  server,
+		players: make(map[string]playback.DSPConfig),
+		senders: make(map[string]comm.MessageSender),
+	}
+}
+
+// playerConnected stores the sender of the player and sends it its config, if one was set
+func (ds *dspSettings) playerConnected(name string, sender comm.MessageSender) {
+	ds.mutex.Lock()
+	ds.senders[name] = sender
+	config, ok := ds.players[name]
+	ds.mutex.Unlock()
+
+	logger.Infof("player %s connected", name)
+	if ok {
+		if err := sender.SendMessage(toWireDSPConfig(config)); err != nil {
+			logger.Warnf("failed to send dsp config to player %s: %v", name, err)
+		}
+	}
+}
+
+func (ds *dspSettings) playerNames() []string {
+	ds.mutex.RLock()
+	defer ds.mutex.RUnlock()
+	names := make([]string, 0, len(ds.senders))
+	for name := range ds.senders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// config returns the config of player or the server config, if player is empty
+func (ds *dspSettings) config(player string) playback.DSPConfig {
+	if player == "" {
+		return ds.server.Config()
+	}
+	ds.mutex.RLock()
+	defer ds.mutex.RUnlock()
+	if config, ok := ds.players[player]; ok {
+		return config
+	}
+	return playback.DefaultDSPConfig()
+}
+
+// setConfig sets the config of player or the server config, if player is empty
+func (ds *dspSettings) setConfig(player string, config playback.DSPConfig) error {
+	if player == "" {
+		ds.server.SetConfig(config)
+		return nil
+	}
+
+	ds.mutex.Lock()
+	ds.players[player] = config
+	sender, ok := ds.senders[player]
+	ds.mutex.Unlock()
+	if !ok {
+		return nil
+	}
+	return sender.SendMessage(toWireDSPConfig(config))
+}
+
+func toWireDSPConfig(config playback.DSPConfig) *comm.SetDSPRequest {
+	bands := make([]*comm.SetDSPRequest_EQBand, len(config.Bands))
+	for i, b := range config.Bands {
+		bands[i] = &comm.SetDSPRequest_EQBand{Frequency: b.Frequency, Gain: b.Gain, Q: b.Q}
+	}
+	return &comm.SetDSPRequest{
+		Bands:     bands,
+		BassBoost: config.BassBoost,
+		Width:     config.Width,
+		Limiter:   config.Limiter,
+	}
+}
+
+// DSPConfigFromWire converts a SetDSPRequest to the DSPConfig it describes
+func DSPConfigFromWire(r *comm.SetDSPRequest) playback.DSPConfig {
+	bands := make([]playback.EQBand, len(r.Bands))
+	for i, b := range r.Bands {
+		bands[i] = playback.EQBand{Frequency: b.Frequency, Gain: b.Gain, Q: b.Q}
+	}
+	return playback.DSPConfig{
+		Bands:     bands,
+		BassBoost: r.BassBoost,
+		Width:     r.Width,
+		Limiter:   r.Limiter,
+	}
+}
+
+func formatDSPConfig(target string, config playback.DSPConfig) string {
+	bands := make([]string, 0, len(config.Bands))
+	for _, b := range config.Bands {
+		bands = append(bands, fmt.Sprintf("%gHz %+.1fdB", b.Frequency, b.Gain))
+	}
+	if len(bands) == 0 {
+		bands = append(bands, "none")
+	}
+	limiter := "off"
+	if config.Limiter {
+		limiter = "on"
+	}
+	return fmt.Sprintf("dsp of %s: bands: %s; bass boost: %+.1fdB; stereo width: %.2f; limiter: %s",
+		target, strings.Join(bands, ", "), config.BassBoost, config.Width, limiter)
+}
+
+// applyDSPArgs applies the eq command args to config
+func applyDSPArgs(config playback.DSPConfig, args []string) (playback.DSPConfig, bool) {
+	action, _ := parseStringParam(args, 0)
+	switch action {
+	case "preset":
+		name, _ := parseStringParam(args, 1)
+		bands, ok := playback.EQPreset(name)
+		if !ok {
+			return config, false
+		}
+		config.Bands = bands
+	case "band":
+		freq, okFreq := parseFloatParam(args, 1)
+		gain, okGain := parseFloatParam(args, 2)
+		if !okFreq || !okGain || freq <= 0 || float64(SampleRate)/2 <= freq {
+			return config, false
+		}
+		config = config.WithBand(freq, gain)
+	case "bass":
+		gain, ok := parseFloatParam(args, 1)
+		if !ok {
+			return config, false
+		}
+		config.BassBoost = gain
+	case "width":
+		width, ok := parseFloatParam(args, 1)
+		if !ok || width < 0 {
+			return config, false
+		}
+		config.Width = width
+	case "limiter":
+		state, _ := parseStringParam(args, 1)
+		if state != "on" && state != "off" {
+			return config, false
+		}
+		config.Limiter = state == "on"
+	case "reset":
+		config = playback.DefaultDSPConfig()
+	default:
+		return config, false
+	}
+	return config, true
+}
+
+func (ss *serverState) eqCommand() ssh.Command {
+	return ssh.Command{
+		Name:  "eq",
+		Usage: "[player name] [preset name|band frequency gain|bass gain|width width|limiter on|off|reset]",
+		Info:  "configures the equalizer and effects of the server or a single player",
+		ExecFunc: func(args []string) (string, bool) {
+			player, target := "", "the server"
+			if first, _ := parseStringParam(args, 0); first == "player" {
+				if player, _ = parseStringParam(args, 1); player == "" {
+					return "", false
+				}
+				target, args = "player "+player, args[2:]
+			}
+
+			if len(args) == 0 {
+				return formatDSPConfig(target, ss.dsp.config(player)), true
+			}
+
+			config, ok := applyDSPArgs(ss.dsp.config(player), args)
+			if !ok {
+				return "", false
+			}
+			if err := ss.dsp.setConfig(player, config); err != nil {
+				return fmt.Sprintf("failed to send the dsp config to %s: %v", target, err), true
+			}
+			return formatDSPConfig(target, config), true
+		},
+		OptionsFunc: func(prefix string, arg int) []string {
+			if arg != 0 {
+				return []string{}
+			}
+			return []string{"player", "preset", "band", "bass", "width", "limiter", "reset"}
+		},
+	}
+}
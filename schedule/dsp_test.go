@@ -0,0 +1,72 @@
+package schedule
+
+import (
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/testutil"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDSPConfigWireConversion(t *testing.T) {
+	config := playback.DSPConfig{
+		Bands:     []playback.EQBand{{Frequency: 60, Gain: 3, Q: 1}, {Frequency: 8000, Gain: -2, Q: 0.7}},
+		BassBoost: 4,
+		Width:     1.5,
+		Limiter:   true,
+	}
+	assert.Equal(t, config, DSPConfigFromWire(toWireDSPConfig(config)), "dsp config changed during wire conversion")
+}
+
+func TestDSPSettings_playerConnected(t *testing.T) {
+	ds := newDSPSettings(playback.NewDSP(44100))
+
+	fs := &fakeSender{}
+	ds.playerConnected("kitchen", fs)
+	assert.Nil(t, fs.lastMessage, "playerConnected sent a config to a player without config")
+	assert.Equal(t, []string{"kitchen"}, ds.playerNames(), "playerConnected did not store the player")
+
+	config := playback.DSPConfig{Width: 0.5}
+	assert.Nil(t, ds.setConfig("kitchen", config), "setConfig returned an error")
+	assert.Equal(t, toWireDSPConfig(config), fs.lastMessage, "setConfig did not send the config to the player")
+
+	fs = &fakeSender{}
+	ds.playerConnected("kitchen", fs)
+	assert.Equal(t, toWireDSPConfig(config), fs.lastMessage, "playerConnected did not send the config to a reconnected player")
+}
+
+func TestServerState_eqCommand(t *testing.T) {
+	SampleRate = 44100
+	ss := newTestServerState([]string{}, false)
+	ss.dsp = newDSPSettings(playback.NewDSP(SampleRate))
+	fs := &fakeSender{}
+	ss.dsp.playerConnected("kitchen", fs)
+
+	testutil.CommandTesters{
+		Command: ss.eqCommand(),
+		Testers: []testutil.CommandTester{
+			testutil.OptionsTestCase{Prefix: "", Arg: 0, Result: []string{"player", "preset", "band", "bass", "width", "limiter", "reset"}},
+			testutil.OptionsTestCase{Prefix: "", Arg: 1, Result: []string{}},
+			testutil.ExecTestCase{Args: []string{}, Result: "dsp of the server: bands: none; bass boost: +0.0dB; stereo width: 1.00; limiter: off", Success: true},
+			testutil.ExecTestCase{Args: []string{"band", "60", "+3"}, Result: "dsp of the server: bands: 60Hz +3.0dB; bass boost: +0.0dB; stereo width: 1.00; limiter: off", Success: true},
+			testutil.ExecTestCase{Args: []string{"bass", "2"}, Result: "dsp of the server: bands: 60Hz +3.0dB; bass boost: +2.0dB; stereo width: 1.00; limiter: off", Success: true},
+			testutil.ExecTestCase{Args: []string{"width", "1.5"}, Result: "dsp of the server: bands: 60Hz +3.0dB; bass boost: +2.0dB; stereo width: 1.50; limiter: off", Success: true},
+			testutil.ExecTestCase{Args: []string{"limiter", "on"}, Result: "dsp of the server: bands: 60Hz +3.0dB; bass boost: +2.0dB; stereo width: 1.50; limiter: on", Success: true},
+			testutil.ExecTestCase{Args: []string{"reset"}, Result: "dsp of the server: bands: none; bass boost: +0.0dB; stereo width: 1.00; limiter: off", Success: true},
+			testutil.ExecTestCase{Args: []string{"player", "kitchen", "preset", "party"}, Result: "dsp of player kitchen: bands: 60Hz +7.0dB, 170Hz +7.0dB, 310Hz +0.0dB, 600Hz +0.0dB, 1000Hz +0.0dB, 3000Hz +0.0dB, 6000Hz +0.0dB, 12000Hz +0.0dB, 14000Hz +7.0dB, 16000Hz +7.0dB; bass boost: +0.0dB; stereo width: 1.00; limiter: off", Success: true},
+			testutil.ExecTestCase{Args: []string{"player", "garden"}, Result: "dsp of player garden: bands: none; bass boost: +0.0dB; stereo width: 1.00; limiter: off", Success: true},
+			testutil.ExecTestCase{Args: []string{"player"}, Success: false},
+			testutil.ExecTestCase{Args: []string{"preset", "non-existent"}, Success: false},
+			testutil.ExecTestCase{Args: []string{"band", "60"}, Success: false},
+			testutil.ExecTestCase{Args: []string{"band", "30000", "3"}, Success: false},
+			testutil.ExecTestCase{Args: []string{"width", "-1"}, Success: false},
+			testutil.ExecTestCase{Args: []string{"limiter", "maybe"}, Success: false},
+			testutil.ExecTestCase{Args: []string{"louder"}, Success: false},
+		},
+	}.Test(t)
+
+	assert.True(t, ss.dsp.server.Config().IsNeutral(), "eq command did not reset the server dsp")
+	if sdr, ok := fs.lastMessage.(*comm.SetDSPRequest); assert.True(t, ok, "eq command did not send a SetDSPRequest to the player") {
+		assert.Len(t, sdr.Bands, 10, "eq command sent the wrong preset to the player")
+	}
+}
@@ -0,0 +1,56 @@
+package schedule
+
+import (
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/ssh"
+)
+
+// karaoke returns whether karaoke mode (vocal reduction of the server stream) is enabled
+func (ss *serverState) karaoke() bool {
+	return ss.dsp.config("").VocalReduction
+}
+
+func (ss *serverState) sendKaraoke(s comm.MessageSender) {
+	s.SendMessage(&comm.KaraokeInfo{Enabled: ss.karaoke()})
+}
+
+// setKaraoke enables or disables the vocal reduction and tells all infoers to switch their layout
+func (ss *serverState) setKaraoke(enabled bool) error {
+	config := ss.dsp.config("")
+	config.VocalReduction = enabled
+	if err := ss.dsp.setConfig("", config); err != nil {
+		return err
+	}
+	return ss.sender.SendMessage(&comm.KaraokeInfo{Enabled: enabled})
+}
+
+func (ss *serverState) karaokeCommand() ssh.Command {
+	return ssh.Command{
+		Name:  "karaoke",
+		Usage: "[on|off]",
+		Info:  "removes the vocals from the stream and shows the lyrics in karaoke layout on all infoers",
+		ExecFunc: func(args []string) (string, bool) {
+			state, ok := parseStringParam(args, 0)
+			if !ok {
+				if ss.karaoke() {
+					return "karaoke mode is on", true
+				}
+				return "karaoke mode is off", true
+			}
+			if state != "on" && state != "off" {
+				return "", false
+			}
+			if err := ss.setKaraoke(state == "on"); err != nil {
+				return fmt.Sprintf("failed to turn karaoke mode %s: %v", state, err), true
+			}
+			return fmt.Sprintf("karaoke mode is %s", state), true
+		},
+		OptionsFunc: func(prefix string, arg int) []string {
+			if arg != 0 {
+				return []string{}
+			}
+			return []string{"on", "off"}
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package schedule
+
+import (
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/testutil"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestServerState_karaokeCommand(t *testing.T) {
+	fms := &fakeMessageSender{}
+	ss := newTestServerState([]string{}, false)
+	ss.sender = fms
+	ss.dsp = newDSPSettings(playback.NewDSP(44100))
+
+	cmd := ss.karaokeCommand()
+	assert.NotNil(t, cmd, "serverState karaokeCommand is nil")
+
+	ct := testutil.CommandTesters{
+		Command: cmd,
+		Testers: []testutil.CommandTester{
+			testutil.OptionsTestCase{Prefix: "", Arg: 0, Result: []string{"on", "off"}},
+			testutil.OptionsTestCase{Prefix: "", Arg: 1, Result: []string{}},
+			testutil.ExecTestCase{Args: []string{}, Result: "karaoke mode is off", Success: true},
+			testutil.ExecTestCase{Args: []string{"maybe"}, Result: "", Success: false},
+			testutil.ExecTestCase{Args: []string{"on"}, Result: "karaoke mode is on", Success: true},
+			testutil.ExecTestCase{Args: []string{}, Result: "karaoke mode is on", Success: true},
+		},
+	}
+	ct.Test(t)
+	assert.True(t, ss.dsp.config("").VocalReduction, "karaoke command did not enable the vocal reduction")
+
+	testutil.ExecTestCase{Args: []string{"off"}, Result: "karaoke mode is off", Success: true}.Test(t, cmd)
+	assert.False(t, ss.dsp.config("").VocalReduction, "karaoke command did not disable the vocal reduction")
+
+	assertFakeMessageSenderMessages(t, fms, []proto.Message{
+		&comm.KaraokeInfo{Enabled: true},
+		&comm.KaraokeInfo{Enabled: false},
+	}, "karaoke command")
+}
@@ -0,0 +1,75 @@
+package schedule
+
+import (
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"github.com/LogicalOverflow/music-sync/util"
+	"path/filepath"
+	"strings"
+)
+
+func fromWireLyrics(wireLyrics []*comm.NewSongInfo_SongLyricsLine) []metadata.LyricsLine {
+	lyrics := make([]metadata.LyricsLine, len(wireLyrics))
+	for i, l := range wireLyrics {
+		lyrics[i] = make(metadata.LyricsLine, len(l.Atoms))
+		for j, a := range l.Atoms {
+			lyrics[i][j] = metadata.LyricsAtom{Timestamp: a.Timestamp, Caption: a.Caption}
+		}
+	}
+	return lyrics
+}
+
+// checkLyricsSong returns an error, if song is not a song in the AudioDir lyrics can be stored for
+func checkLyricsSong(song string) error {
+	if song == "" || playback.IsURL(song) || playback.IsLiveSource(song) {
+		return fmt.Errorf("can not store lyrics for %s", song)
+	}
+	if filepath.IsAbs(song) || strings.HasPrefix(filepath.Clean(song), "..") {
+		return fmt.Errorf("%s is not in the music directory", song)
+	}
+	if _, _, ok := playback.ParseCueTrackSong(song); ok {
+		if _, _, err := playback.LookupCueTrack(song); err != nil {
+			return fmt.Errorf("unknown song %s", song)
+		}
+		return nil
+	}
+	if !util.IsFile(filepath.Join(playback.AudioDir, song)) {
+		return fmt.Errorf("unknown song %s", song)
+	}
+	return nil
+}
+
+// uploadLyrics stores lyrics uploaded by a client next to the song, if the request contains valid credentials.
+// If the song is playing, its lyrics are announced again.
+func (ss *serverState) uploadLyrics(request *comm.UploadLyricsRequest) error {
+	if !ssh.CheckPassword(ControlUsers, request.User, request.Password) {
+		logger.Warnf("rejected lyrics upload as %s: invalid credentials", request.User)
+		return fmt.Errorf("invalid credentials")
+	}
+	song := request.SongFileName
+	if err := checkLyricsSong(song); err != nil {
+		return err
+	}
+	lyrics := fromWireLyrics(request.Lyrics)
+	if err := metadata.SaveLyrics(song, lyrics); err != nil {
+		logger.Warnf("failed to save the lyrics of %s: %v", song, err)
+		return fmt.Errorf("failed to save the lyrics of %s", song)
+	}
+	logger.Infof("%s saved %d lines of lyrics for %s", request.User, len(lyrics), song)
+
+	ss.songMutex.Lock()
+	info := ss.newestSong
+	if ss.song.info == nil || ss.song.info.SongFileName != song || info == nil || info.SongFileName != song {
+		ss.songMutex.Unlock()
+		return nil
+	}
+	ss.song.lyrics = lyrics
+	ss.newestSong = ss.song.timed(info.FirstSampleOfSongIndex, info.FilePosition-ss.song.trimStart, info.SongOffset, info.Speed)
+	info = ss.newestSong
+	ss.songMutex.Unlock()
+	ss.sender.SendMessage(info)
+	return nil
+}
@@ -0,0 +1,111 @@
+package schedule
+
+import (
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var checkLyricsSongCases = []struct {
+	song  string
+	valid bool
+}{
+	{song: "song.mp3", valid: true},
+	{song: "other.mp3", valid: false},
+	{song: "", valid: false},
+	{song: "../song.mp3", valid: false},
+	{song: "/song.mp3", valid: false},
+	{song: "http://example.com/stream.mp3", valid: false},
+	{song: "live:stdin", valid: false},
+	{song: "album.cue#01", valid: false},
+}
+
+func TestCheckLyricsSong(t *testing.T) {
+	dir, err := ioutil.TempDir("", "music-sync-lyrics")
+	if !assert.Nil(t, err, "failed to create temp dir") {
+		return
+	}
+	defer os.RemoveAll(dir)
+	playback.AudioDir = dir
+	ioutil.WriteFile(filepath.Join(dir, "song.mp3"), []byte{}, 0644)
+
+	for _, c := range checkLyricsSongCases {
+		if c.valid {
+			assert.NoError(t, checkLyricsSong(c.song), "checkLyricsSong returned an error for %s", c.song)
+		} else {
+			assert.Error(t, checkLyricsSong(c.song), "checkLyricsSong did not return an error for %s", c.song)
+		}
+	}
+}
+
+func TestServerState_uploadLyrics(t *testing.T) {
+	oldSampleRate := SampleRate
+	defer func() { SampleRate = oldSampleRate }()
+	SampleRate = 1000
+	oldUsers := ControlUsers
+	defer func() { ControlUsers = oldUsers }()
+	ControlUsers = map[string]ssh.UserAuth{"user": {Password: "password"}}
+
+	dir, err := ioutil.TempDir("", "music-sync-lyrics")
+	if !assert.Nil(t, err, "failed to create temp dir") {
+		return
+	}
+	defer os.RemoveAll(dir)
+	playback.AudioDir = dir
+	ioutil.WriteFile(filepath.Join(dir, "song.mp3"), []byte{}, 0644)
+	ioutil.WriteFile(filepath.Join(dir, "other.mp3"), []byte{}, 0644)
+
+	fms := &fakeMessageSender{}
+	ss := &serverState{
+		sender:           fms,
+		lyricsProvider:   fakeLyricsProvider{},
+		metadataProvider: fakeMetadataProvider{},
+	}
+	ss.createNewSongHandler()(100, "song.mp3", 5000, 1000, 1)
+	fms.messages = nil
+
+	wireLyrics := []*comm.NewSongInfo_SongLyricsLine{{Atoms: []*comm.NewSongInfo_SongLyricsAtom{
+		{Timestamp: 2000, Caption: "a "}, {Timestamp: 2500, Caption: "b"},
+	}}}
+	lyrics := []metadata.LyricsLine{{{Timestamp: 2000, Caption: "a "}, {Timestamp: 2500, Caption: "b"}}}
+
+	assert.Error(t, ss.uploadLyrics(&comm.UploadLyricsRequest{SongFileName: "other.mp3", Lyrics: wireLyrics, User: "user", Password: "wrong"}),
+		"uploadLyrics did not return an error for invalid credentials")
+	assert.Error(t, ss.uploadLyrics(&comm.UploadLyricsRequest{SongFileName: "other.mp3", Lyrics: wireLyrics}),
+		"uploadLyrics did not return an error without credentials")
+	assert.Empty(t, metadata.GetLyricsProvider().CollectLyrics("other.mp3"), "uploadLyrics saved lyrics with invalid credentials")
+
+	assert.Error(t, ss.uploadLyrics(&comm.UploadLyricsRequest{SongFileName: "missing.mp3", Lyrics: wireLyrics, User: "user", Password: "password"}),
+		"uploadLyrics did not return an error for a missing song")
+
+	if assert.NoError(t, ss.uploadLyrics(&comm.UploadLyricsRequest{SongFileName: "other.mp3", Lyrics: wireLyrics, User: "user", Password: "password"}),
+		"uploadLyrics returned an error") {
+		assert.Equal(t, lyrics, metadata.GetLyricsProvider().CollectLyrics("other.mp3"), "uploadLyrics did not save the lyrics")
+		assert.Empty(t, fms.messages, "uploadLyrics announced the lyrics of a song not playing")
+	}
+
+	if assert.NoError(t, ss.uploadLyrics(&comm.UploadLyricsRequest{SongFileName: "song.mp3", Lyrics: wireLyrics, User: "user", Password: "password"}),
+		"uploadLyrics returned an error") {
+		assert.Equal(t, lyrics, metadata.GetLyricsProvider().CollectLyrics("song.mp3"), "uploadLyrics did not save the lyrics")
+		assertFakeMessageSenderMessages(t, fms, []proto.Message{
+			&comm.NewSongInfo{
+				FirstSampleOfSongIndex: 100,
+				SongFileName:           "song.mp3",
+				SongLength:             5000,
+				Lyrics: []*comm.NewSongInfo_SongLyricsLine{{Atoms: []*comm.NewSongInfo_SongLyricsAtom{
+					{Timestamp: 1000, Caption: "a "}, {Timestamp: 1500, Caption: "b"},
+				}}},
+				Metadata:     &comm.NewSongInfo_SongMetadata{},
+				Speed:        1,
+				FilePosition: 1000,
+			},
+		}, "upload lyrics")
+	}
+}
@@ -0,0 +1,259 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"github.com/LogicalOverflow/music-sync/util"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoudnessCacheFile is the file the measured loudness of songs is cached in (no caching if empty)
+var LoudnessCacheFile = ""
+
+// LoudnessAnalysisInterval is the time between scans of the music directory for songs to analyze
+var LoudnessAnalysisInterval = 10 * time.Minute
+
+type normalizationMode int
+
+const (
+	normalizationOff normalizationMode = iota
+	normalizationTrack
+	normalizationAlbum
+)
+
+var normalizationModeNames = map[normalizationMode]string{
+	normalizationOff:   "off",
+	normalizationTrack: "track",
+	normalizationAlbum: "album",
+}
+
+func (m normalizationMode) String() string {
+	return normalizationModeNames[m]
+}
+
+type normalizer struct {
+	mode         normalizationMode
+	gainProvider metadata.GainProvider
+
+	loudness      map[string]float64
+	loudnessMutex sync.RWMutex
+	modeMutex     sync.RWMutex
+
+	analysisStarted sync.Once
+	measure         func(song string) (float64, error)
+}
+
+func newNormalizer(gainProvider metadata.GainProvider) *normalizer {
+	n := &normalizer{
+		mode:         normalizationOff,
+		gainProvider: gainProvider,
+		loudness:     make(map[string]float64),
+		measure: func(song string) (float64, error) {
+			return playback.MeasureSongLoudness(song, SampleRate)
+		},
+	}
+	n.loadCache()
+	return n
+}
+
+func (n *normalizer) getMode() normalizationMode {
+	n.modeMutex.RLock()
+	defer n.modeMutex.RUnlock()
+	return n.mode
+}
+
+func (n *normalizer) setMode(mode normalizationMode) {
+	n.modeMutex.Lock()
+	n.mode = mode
+	n.modeMutex.Unlock()
+	if mode != normalizationOff {
+		n.analysisStarted.Do(func() { go n.analysisLoop(context.Background()) })
+	}
+}
+
+// gain returns the gain in dB for song according to the current mode.
+// Replay gain tags are preferred over measured loudness; album mode falls back to track gain.
+func (n *normalizer) gain(song string) float64 {
+	mode := n.getMode()
+	if mode == normalizationOff {
+		return 0
+	}
+
+	rg := n.gainProvider.CollectGain(song)
+	if mode == normalizationAlbum {
+		if rg.HasAlbumGain {
+			return rg.AlbumGain
+		}
+		if loudness, ok := n.albumLoudness(song); ok {
+			return playback.ReferenceLoudness - loudness
+		}
+	}
+	if rg.HasTrackGain {
+		return rg.TrackGain
+	}
+	if loudness, ok := n.songLoudness(song); ok {
+		return playback.ReferenceLoudness - loudness
+	}
+	return 0
+}
+
+func (n *normalizer) gainHandler() func(string) float64 {
+	return func(song string) float64 {
+		gain := n.gain(song)
+		if gain != 0 {
+			logger.Debugf("applying gain of %.2f dB to %s", gain, song)
+		}
+		return playback.GainToFactor(gain)
+	}
+}
+
+func (n *normalizer) songLoudness(song string) (float64, bool) {
+	n.loudnessMutex.RLock()
+	defer n.loudnessMutex.RUnlock()
+	l, ok := n.loudness[song]
+	return l, ok && !math.IsInf(l, 0)
+}
+
+// albumLoudness averages the measured loudness of all songs in the directory of song
+func (n *normalizer) albumLoudness(song string) (float64, bool) {
+	n.loudnessMutex.RLock()
+	defer n.loudnessMutex.RUnlock()
+	dir := filepath.Dir(song)
+	energy, count := 0.0, 0
+	for s, l := range n.loudness {
+		if filepath.Dir(s) == dir && !math.IsInf(l, 0) {
+			energy += math.Pow(10, l/10)
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return 10 * math.Log10(energy/float64(count)), true
+}
+
+// analysisLoop measures the loudness of all songs without replay gain tags, which were not measured yet
+func (n *normalizer) analysisLoop(ctx context.Context) {
+	for !util.IsCanceled(ctx) {
+		n.analyze(util.FilterSongs(util.ListAllFiles(playback.AudioDir, "")))
+		time.Sleep(LoudnessAnalysisInterval)
+	}
+}
+
+func (n *normalizer) analyze(songs []string) {
+	measured := 0
+	for _, song := range songs {
+		n.loudnessMutex.RLock()
+		_, known := n.loudness[song]
+		n.loudnessMutex.RUnlock()
+		if known || n.gainProvider.CollectGain(song).HasTrackGain {
+			continue
+		}
+
+		l, err := n.measure(song)
+		if err != nil {
+			logger.Warnf("failed to measure loudness of %s: %v", song, err)
+			continue
+		}
+		n.loudnessMutex.Lock()
+		n.loudness[song] = l
+		n.loudnessMutex.Unlock()
+		measured++
+	}
+	if 0 < measured {
+		logger.Infof("measured the loudness of %d song(s)", measured)
+		n.saveCache()
+	}
+}
+
+func (n *normalizer) loadCache() {
+	if LoudnessCacheFile == "" || !util.IsFile(LoudnessCacheFile) {
+		return
+	}
+	f, err := os.Open(LoudnessCacheFile)
+	if err != nil {
+		logger.Warnf("failed to open loudness cache %s: %v", LoudnessCacheFile, err)
+		return
+	}
+	defer f.Close()
+
+	cache := make(map[string]float64)
+	if err := json.NewDecoder(f).Decode(&cache); err != nil {
+		logger.Warnf("failed to decode loudness cache %s: %v", LoudnessCacheFile, err)
+		return
+	}
+	n.loudnessMutex.Lock()
+	defer n.loudnessMutex.Unlock()
+	for song, l := range cache {
+		n.loudness[song] = l
+	}
+}
+
+func (n *normalizer) saveCache() {
+	if LoudnessCacheFile == "" {
+		return
+	}
+	n.loudnessMutex.RLock()
+	cache := make(map[string]float64, len(n.loudness))
+	for song, l := range n.loudness {
+		// json can not encode infinity, silent songs are measured again after a restart
+		if !math.IsInf(l, 0) {
+			cache[song] = l
+		}
+	}
+	n.loudnessMutex.RUnlock()
+
+	f, err := os.Create(LoudnessCacheFile)
+	if err != nil {
+		logger.Warnf("failed to create loudness cache %s: %v", LoudnessCacheFile, err)
+		return
+	}
+	defer f.Close()
+	if err := json.NewEncoder(f).Encode(cache); err != nil {
+		logger.Warnf("failed to write loudness cache %s: %v", LoudnessCacheFile, err)
+	}
+}
+
+func (n *normalizer) status() string {
+	n.loudnessMutex.RLock()
+	measured := len(n.loudness)
+	n.loudnessMutex.RUnlock()
+	return fmt.Sprintf("loudness normalization is set to %s (%d song(s) measured)", n.getMode(), measured)
+}
+
+func (ss *serverState) normalizeCommand() ssh.Command {
+	modes := []string{normalizationOff.String(), normalizationTrack.String(), normalizationAlbum.String()}
+	return ssh.Command{
+		Name:  "normalize",
+		Usage: "[" + strings.Join(modes, "|") + "]",
+		Info:  "sets the loudness normalization mode",
+		ExecFunc: func(args []string) (string, bool) {
+			name, ok := parseStringParam(args, 0)
+			if !ok {
+				return ss.normalizer.status(), true
+			}
+			for m, n := range normalizationModeNames {
+				if n == name {
+					ss.normalizer.setMode(m)
+					return ss.normalizer.status(), true
+				}
+			}
+			return "", false
+		},
+		OptionsFunc: func(prefix string, arg int) []string {
+			if arg != 0 {
+				return []string{}
+			}
+			return modes
+		},
+	}
+}
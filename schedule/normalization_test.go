@@ -0,0 +1,88 @@
+package schedule
+
+import (
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/testutil"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type fakeGainProvider map[string]metadata.ReplayGain
+
+func (fgp fakeGainProvider) CollectGain(song string) metadata.ReplayGain {
+	return fgp[song]
+}
+
+func newTestNormalizer(gains fakeGainProvider, loudness map[string]float64) *normalizer {
+	n := newNormalizer(gains)
+	n.analysisStarted.Do(func() {})
+	n.measure = func(song string) (float64, error) {
+		if l, ok := loudness[song]; ok {
+			return l, nil
+		}
+		return 0, fmt.Errorf("no loudness for %s", song)
+	}
+	return n
+}
+
+func TestNormalizer_gain(t *testing.T) {
+	gains := fakeGainProvider{
+		"album/tagged.mp3":  {TrackGain: -4, AlbumGain: -2, HasTrackGain: true, HasAlbumGain: true},
+		"album/loud.mp3":    {},
+		"album/quiet.mp3":   {},
+		"other/unknown.mp3": {},
+	}
+	n := newTestNormalizer(gains, map[string]float64{"album/loud.mp3": -8, "album/quiet.mp3": -28})
+	n.analyze([]string{"album/tagged.mp3", "album/loud.mp3", "album/quiet.mp3", "other/unknown.mp3"})
+	assert.Equal(t, map[string]float64{"album/loud.mp3": -8, "album/quiet.mp3": -28}, n.loudness, "normalizer analyze measured the wrong songs")
+
+	assert.Equal(t, 0.0, n.gain("album/tagged.mp3"), "normalizer applied gain while off")
+
+	n.setMode(normalizationTrack)
+	assert.Equal(t, -4.0, n.gain("album/tagged.mp3"), "normalizer did not use the track gain tag")
+	assert.Equal(t, -10.0, n.gain("album/loud.mp3"), "normalizer did not use the measured track loudness")
+	assert.Equal(t, 0.0, n.gain("other/unknown.mp3"), "normalizer applied gain to an unknown song")
+
+	n.setMode(normalizationAlbum)
+	assert.Equal(t, -2.0, n.gain("album/tagged.mp3"), "normalizer did not use the album gain tag")
+	assert.InDelta(t, -18-(-10.9670), n.gain("album/quiet.mp3"), 1e-3, "normalizer did not use the measured album loudness")
+	assert.Equal(t, 0.0, n.gain("other/unknown.mp3"), "normalizer applied album gain to an unknown song")
+}
+
+func TestNormalizer_cache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "music-sync-loudness")
+	if !assert.Nil(t, err, "failed to create temp dir") {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	LoudnessCacheFile = filepath.Join(dir, "cache.json")
+	defer func() { LoudnessCacheFile = "" }()
+
+	n := newTestNormalizer(fakeGainProvider{}, map[string]float64{"song.mp3": -12.5})
+	n.analyze([]string{"song.mp3"})
+
+	cached := newNormalizer(fakeGainProvider{})
+	assert.Equal(t, map[string]float64{"song.mp3": -12.5}, cached.loudness, "normalizer did not load the loudness cache")
+}
+
+func TestServerState_normalizeCommand(t *testing.T) {
+	playback.AudioDir = "_queue_test_files"
+	ss := newTestServerState([]string{}, false)
+	ss.normalizer = newTestNormalizer(fakeGainProvider{}, map[string]float64{})
+
+	testutil.CommandTesters{
+		Command: ss.normalizeCommand(),
+		Testers: []testutil.CommandTester{
+			testutil.OptionsTestCase{Prefix: "", Arg: 0, Result: []string{"off", "track", "album"}},
+			testutil.ExecTestCase{Args: []string{}, Result: "loudness normalization is set to off (0 song(s) measured)", Success: true},
+			testutil.ExecTestCase{Args: []string{"album"}, Result: "loudness normalization is set to album (0 song(s) measured)", Success: true},
+			testutil.ExecTestCase{Args: []string{"loud"}, Success: false},
+		},
+	}.Test(t)
+}
@@ -0,0 +1,208 @@
+package schedule
+
+import (
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"strings"
+	"sync"
+)
+
+// PartySkipRatio is the default ratio of connected users which have to vote to skip a song in party mode
+var PartySkipRatio = 0.5
+
+type party struct {
+	enabled   bool
+	skipRatio float64
+
+	queues map[string][]string
+	users  []string
+	next   int
+	votes  map[string]bool
+	mutex  sync.RWMutex
+
+	// added is the last requested song added to the playlist
+	added string
+
+	connectedUsers func() []string
+}
+
+func newParty() *party {
+	return &party{
+		enabled:        false,
+		skipRatio:      PartySkipRatio,
+		queues:         make(map[string][]string),
+		users:          make([]string, 0),
+		votes:          make(map[string]bool),
+		connectedUsers: ssh.ConnectedUsers,
+	}
+}
+
+func (p *party) isEnabled() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.enabled
+}
+
+// request appends songs to the sub-queue of user
+func (p *party) request(user string, songs []string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, ok := p.queues[user]; !ok {
+		p.users = append(p.users, user)
+	}
+	p.queues[user] = append(p.queues[user], songs...)
+}
+
+// nextRequest removes the next song from the sub-queues, taking turns between the users
+func (p *party) nextRequest() (user string, song string, ok bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for i := 0; i < len(p.users); i++ {
+		index := (p.next + i) % len(p.users)
+		user = p.users[index]
+		if q := p.queues[user]; 0 < len(q) {
+			song = q[0]
+			p.queues[user] = q[1:]
+			p.next = index + 1
+			return user, song, true
+		}
+	}
+	return "", "", false
+}
+
+// fill inserts the next requested song into the playlist before the upcoming songs, if party mode is enabled and
+// lastSong was not a requested song, so requested songs and the songs of the playlist take turns. If no song is
+// upcoming, the next requested song is added anyway. It returns the number of songs added.
+func (p *party) fill(pl *playback.Playlist, lastSong string, upcoming int) int {
+	if !p.isEnabled() {
+		return 0
+	}
+	p.mutex.RLock()
+	added := p.added
+	p.mutex.RUnlock()
+	if 0 < upcoming && added != "" && lastSong == added {
+		return 0
+	}
+
+	user, song, ok := p.nextRequest()
+	if !ok {
+		return 0
+	}
+	logger.Infof("party mode added %s requested by %s to the playlist", song, user)
+	p.mutex.Lock()
+	p.added = song
+	p.mutex.Unlock()
+	pl.InsertSong(song, len(pl.Songs())-upcoming)
+	return 1
+}
+
+func (p *party) requiredVotes() int {
+	connected := len(p.connectedUsers())
+	required := int(p.skipRatio*float64(connected)) + 1
+	if connected < required {
+		required = connected
+	}
+	if required < 1 {
+		required = 1
+	}
+	return required
+}
+
+// voteSkip registers the vote of user to skip the current song and returns whether enough users voted to skip it
+func (p *party) voteSkip(user string) (votes int, required int, skip bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.votes[user] = true
+	votes, required = len(p.votes), p.requiredVotes()
+	if required <= votes {
+		p.votes = make(map[string]bool)
+		skip = true
+	}
+	return
+}
+
+func (p *party) resetVotes() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.votes = make(map[string]bool)
+}
+
+func (p *party) status() string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	state := "off"
+	if p.enabled {
+		state = "on"
+	}
+	lines := []string{fmt.Sprintf("party mode is %s (skip ratio: %.2f)", state, p.skipRatio)}
+	for _, u := range p.users {
+		if q := p.queues[u]; 0 < len(q) {
+			lines = append(lines, fmt.Sprintf("  %s: %s", u, strings.Join(q, ", ")))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p *party) commandExec(args []string) (string, bool) {
+	action, ok := parseStringParam(args, 0)
+	if !ok {
+		return p.status(), true
+	}
+
+	switch action {
+	case "on", "off":
+		p.mutex.Lock()
+		p.enabled = action == "on"
+		p.mutex.Unlock()
+	case "skip-ratio":
+		ratio, ok := parseFloatParam(args, 1)
+		if !ok || ratio < 0 || 1 < ratio {
+			return "", false
+		}
+		p.mutex.Lock()
+		p.skipRatio = ratio
+		p.mutex.Unlock()
+	default:
+		return "", false
+	}
+	return p.status(), true
+}
+
+func (ss *serverState) partyCommand() ssh.Command {
+	return ssh.Command{
+		Name:     "party",
+		Usage:    "[on|off|skip-ratio ratio]",
+		Info:     "configures party mode, which gives every user their own queue",
+		ExecFunc: ss.party.commandExec,
+		OptionsFunc: func(prefix string, arg int) []string {
+			if arg != 0 {
+				return []string{}
+			}
+			return []string{"on", "off", "skip-ratio"}
+		},
+	}
+}
+
+func (ss *serverState) voteSkipCommand() ssh.Command {
+	return ssh.Command{
+		Name:  "vote-skip",
+		Usage: "",
+		Info:  "votes to skip the current song in party mode",
+		UserExecFunc: func(user string, _ []string) (string, bool) {
+			if !ss.party.isEnabled() {
+				return "vote-skip is only available in party mode", true
+			}
+			song := ss.playlist.CurrentSong()
+			if song == "" {
+				return "no song is playing", true
+			}
+			votes, required, skip := ss.party.voteSkip(user)
+			if skip {
+				ss.playlist.SetPos(ss.playlist.Pos() + 1)
+				return fmt.Sprintf("%d/%d votes, skipping %s", votes, required, song), true
+			}
+			return fmt.Sprintf("%d/%d votes to skip %s", votes, required, song), true
+		},
+	}
+}
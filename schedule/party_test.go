@@ -0,0 +1,115 @@
+package schedule
+
+import (
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/LogicalOverflow/music-sync/testutil"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newTestParty(connected ...string) *party {
+	p := newParty()
+	p.connectedUsers = func() []string { return connected }
+	return p
+}
+
+func TestParty_nextRequest(t *testing.T) {
+	p := newTestParty()
+	p.request("user-a", []string{"a1", "a2", "a3"})
+	p.request("user-b", []string{"b1"})
+	p.request("user-c", []string{"c1", "c2"})
+
+	expected := []string{"a1", "b1", "c1", "a2", "c2", "a3"}
+	for i, e := range expected {
+		_, song, ok := p.nextRequest()
+		assert.True(t, ok, "party nextRequest returned no song at index %d", i)
+		assert.Equal(t, e, song, "party nextRequest returned the wrong song at index %d", i)
+	}
+	_, _, ok := p.nextRequest()
+	assert.False(t, ok, "party nextRequest returned a song after all queues are empty")
+}
+
+func TestParty_fill(t *testing.T) {
+	pl := playback.NewPlaylist(0, []string{}, 0)
+	p := newTestParty()
+	p.request("user-a", []string{"a1", "a2"})
+
+	assert.Equal(t, 0, p.fill(pl, "", 0), "party fill added a song while disabled")
+	p.enabled = true
+	assert.Equal(t, 1, p.fill(pl, "", 0), "party fill did not add a song")
+	assert.Equal(t, []string{"a1"}, pl.Songs(), "party fill added the wrong song")
+}
+
+func TestParty_fillInterleaves(t *testing.T) {
+	pl := playback.NewPlaylist(0, []string{"s1", "s2", "s3"}, 0)
+	p := newTestParty()
+	p.enabled = true
+	p.request("user-a", []string{"a1", "a2"})
+	p.request("user-b", []string{"b1"})
+
+	// s1 played, s2 and s3 are upcoming
+	assert.Equal(t, 1, p.fill(pl, "s1", 2), "party fill did not insert a song after a song of the playlist")
+	assert.Equal(t, []string{"s1", "a1", "s2", "s3"}, pl.Songs(), "party fill did not insert the song before the upcoming songs")
+	assert.Equal(t, 0, p.fill(pl, "a1", 2), "party fill inserted a song after a requested song")
+	assert.Equal(t, 1, p.fill(pl, "s2", 1), "party fill did not insert a song after the next song of the playlist")
+	assert.Equal(t, []string{"s1", "a1", "s2", "b1", "s3"}, pl.Songs(), "party fill did not take turns between the users")
+	assert.Equal(t, 1, p.fill(pl, "b1", 0), "party fill did not add a song to a playlist without upcoming songs")
+	assert.Equal(t, []string{"s1", "a1", "s2", "b1", "s3", "a2"}, pl.Songs(), "party fill did not add the song at the end")
+	assert.Equal(t, 0, p.fill(pl, "s3", 1), "party fill added a song without requests")
+}
+
+func TestParty_voteSkip(t *testing.T) {
+	p := newTestParty("user-a", "user-b", "user-c")
+	votes, required, skip := p.voteSkip("user-a")
+	assert.Equal(t, 1, votes, "party voteSkip returned the wrong vote count")
+	assert.Equal(t, 2, required, "party voteSkip returned the wrong required vote count")
+	assert.False(t, skip, "party voteSkip skipped without a majority")
+
+	_, _, skip = p.voteSkip("user-a")
+	assert.False(t, skip, "party voteSkip counted a vote twice")
+	_, _, skip = p.voteSkip("user-b")
+	assert.True(t, skip, "party voteSkip did not skip with a majority")
+	assert.Empty(t, p.votes, "party voteSkip did not reset the votes after skipping")
+
+	p.skipRatio = 1
+	_, required, _ = p.voteSkip("user-a")
+	assert.Equal(t, 3, required, "party voteSkip required more votes than connected users")
+}
+
+func TestServerState_partyCommands(t *testing.T) {
+	playback.AudioDir = "_queue_test_files"
+	ss := newTestServerState([]string{}, false)
+	ss.party = newTestParty("user-a", "user-b")
+
+	testutil.CommandTesters{
+		Command: ss.partyCommand(),
+		Testers: []testutil.CommandTester{
+			testutil.ExecTestCase{Args: []string{}, Result: "party mode is off (skip ratio: 0.50)", Success: true},
+			testutil.ExecTestCase{Args: []string{"skip-ratio", "2"}, Success: false},
+			testutil.ExecTestCase{Args: []string{"skip-ratio", "0.25"}, Result: "party mode is off (skip ratio: 0.25)", Success: true},
+			testutil.ExecTestCase{Args: []string{"on"}, Result: "party mode is on (skip ratio: 0.25)", Success: true},
+		},
+	}.Test(t)
+
+	testutil.CommandTesters{
+		Command: ss.queueCommand(),
+		Testers: []testutil.CommandTester{
+			testutil.ExecTestCase{User: "user-a", Args: []string{"song1.mp3"}, Result: "1 song(s) added to the party queue of user-a: song1.mp3", Success: true},
+		},
+	}.Test(t)
+	assert.Empty(t, ss.playlist.Songs(), "queue command added a song to the playlist in party mode")
+
+	testutil.CommandTesters{
+		Command: ss.partyCommand(),
+		Testers: []testutil.CommandTester{
+			testutil.ExecTestCase{Args: []string{}, Result: "party mode is on (skip ratio: 0.25)\n  user-a: song1.mp3", Success: true},
+		},
+	}.Test(t)
+
+	testutil.CommandTesters{
+		Command: ss.voteSkipCommand(),
+		Testers: []testutil.CommandTester{
+			testutil.ExecTestCase{User: "user-a", Args: []string{}, Result: "no song is playing", Success: true},
+		},
+	}.Test(t)
+}
@@ -24,7 +24,7 @@ func Player(sender comm.MessageSender) {
 	}()
 
 	go func() {
-		if err := sender.SendMessage(&comm.SubscribeChannelRequest{Channel: comm.Channel_AUDIO}); err != nil {
+		if err := sender.SendMessage(&comm.SubscribeChannelRequest{Channel: comm.Channel_AUDIO, Name: PlayerName}); err != nil {
 			logger.Errorf("failed to subscribe to audio channel")
 			os.Exit(1)
 		}
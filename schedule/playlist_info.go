@@ -0,0 +1,168 @@
+package schedule
+
+import (
+	"context"
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/LogicalOverflow/music-sync/util"
+	"sync"
+	"time"
+)
+
+// PlaylistInfoInterval is the interval in which the playlist is checked for changes, which are sent to the infoers
+var PlaylistInfoInterval = time.Second
+
+// playlistEntry is a song in the playlist as sent to the infoers
+type playlistEntry struct {
+	id         int
+	song       string
+	autoQueued bool
+}
+
+// playlistState is the state of the playlist sent to the infoers
+type playlistState struct {
+	entries    []playlistEntry
+	position   int
+	playing    bool
+	autoDJMode string
+	party      bool
+}
+
+func (ps playlistState) equal(other playlistState) bool {
+	if len(ps.entries) != len(other.entries) {
+		return false
+	}
+	for i := range ps.entries {
+		if ps.entries[i] != other.entries[i] {
+			return false
+		}
+	}
+	return ps.position == other.position && ps.playing == other.playing &&
+		ps.autoDJMode == other.autoDJMode && ps.party == other.party
+}
+
+// playlistTracker remembers the playlist last sent to the infoers, so only the changes have to be sent
+type playlistTracker struct {
+	mutex   sync.Mutex
+	version uint64
+	state   playlistState
+}
+
+// diffPlaylist returns the change turning old into new: removed entries at index are replaced by inserted
+func diffPlaylist(old, new []playlistEntry) (index int, removed int, inserted []playlistEntry) {
+	for index < len(old) && index < len(new) && old[index] == new[index] {
+		index++
+	}
+	suffix := 0
+	for suffix < len(old)-index && suffix < len(new)-index && old[len(old)-1-suffix] == new[len(new)-1-suffix] {
+		suffix++
+	}
+	return index, len(old) - index - suffix, new[index : len(new)-suffix]
+}
+
+// playlistState returns the current state of the playlist
+func (ss *serverState) playlistState() playlistState {
+	songs := ss.playlist.Entries()
+	state := playlistState{
+		entries:  make([]playlistEntry, len(songs)),
+		position: ss.playlist.Pos(),
+		playing:  ss.playlist.Playing(),
+	}
+	for i, s := range songs {
+		state.entries[i] = playlistEntry{id: s.ID, song: s.Song, autoQueued: s.AutoQueued}
+	}
+	if ss.autoDJ != nil {
+		state.autoDJMode = ss.autoDJ.playMode()
+	}
+	if ss.party != nil {
+		state.party = ss.party.isEnabled()
+	}
+	return state
+}
+
+// songMetadata returns the metadata of song, the metadata provider caches it until the song file changes
+func (ss *serverState) songMetadata(song string) metadata.SongMetadata {
+	if ss.metadataProvider != nil {
+		return ss.metadataProvider.CollectMetadata(song)
+	}
+	return metadata.SongMetadata{}
+}
+
+// playlistSongMetadata returns the metadata of song as sent to the infoers
+func (ss *serverState) playlistSongMetadata(song string) *comm.NewSongInfo_SongMetadata {
+	return toWireMetadata(ss.songMetadata(song))
+}
+
+func (ss *serverState) toWirePlaylistEntries(entries []playlistEntry) []*comm.PlaylistInfo_Entry {
+	wireEntries := make([]*comm.PlaylistInfo_Entry, len(entries))
+	for i, e := range entries {
+		wireEntries[i] = &comm.PlaylistInfo_Entry{
+			SongFileName: e.song,
+			Metadata:     ss.playlistSongMetadata(e.song),
+			AutoQueued:   e.autoQueued,
+		}
+	}
+	return wireEntries
+}
+
+// playlistInfo returns the PlaylistInfo of state without any entries
+func playlistInfo(version uint64, state playlistState) *comm.PlaylistInfo {
+	return &comm.PlaylistInfo{
+		Version:    version,
+		Position:   int32(state.position),
+		Playing:    state.playing,
+		AutoDJMode: state.autoDJMode,
+		Party:      state.party,
+	}
+}
+
+// updatePlaylistInfo sends the changes of the playlist since the last update to all infoers.
+// The first update is sent as a snapshot.
+func (ss *serverState) updatePlaylistInfo() {
+	pt := ss.playlistTracker
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	state := ss.playlistState()
+	if 0 < pt.version && state.equal(pt.state) {
+		return
+	}
+	info := playlistInfo(pt.version+1, state)
+	if pt.version == 0 {
+		info.Snapshot = true
+		info.Entries = ss.toWirePlaylistEntries(state.entries)
+	} else {
+		index, removed, inserted := diffPlaylist(pt.state.entries, state.entries)
+		info.BaseVersion = pt.version
+		info.ChangeIndex = int32(index)
+		info.ChangeRemoved = int32(removed)
+		info.Entries = ss.toWirePlaylistEntries(inserted)
+	}
+	pt.version, pt.state = info.Version, state
+	ss.sender.SendMessage(info)
+}
+
+// sendPlaylistInfo sends a snapshot of the playlist last sent to all infoers to s
+func (ss *serverState) sendPlaylistInfo(s comm.MessageSender) {
+	pt := ss.playlistTracker
+	if pt == nil {
+		return
+	}
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	if pt.version == 0 {
+		return
+	}
+	info := playlistInfo(pt.version, pt.state)
+	info.Snapshot = true
+	info.Entries = ss.toWirePlaylistEntries(pt.state.entries)
+	s.SendMessage(info)
+}
+
+// playlistInfoLoop sends the changes of the playlist to the infoers every PlaylistInfoInterval until ctx is canceled
+func (ss *serverState) playlistInfoLoop(ctx context.Context) {
+	for !util.IsCanceled(ctx) {
+		ss.updatePlaylistInfo()
+		time.Sleep(PlaylistInfoInterval)
+	}
+}
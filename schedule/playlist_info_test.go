@@ -0,0 +1,94 @@
+package schedule
+
+import (
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func testPlaylistEntries(songs ...string) []playlistEntry {
+	entries := make([]playlistEntry, len(songs))
+	for i, s := range songs {
+		entries[i] = playlistEntry{song: s}
+	}
+	return entries
+}
+
+var diffPlaylistCases = []struct {
+	old, new []playlistEntry
+	index    int
+	removed  int
+	inserted []playlistEntry
+}{
+	{old: testPlaylistEntries("a", "b"), new: testPlaylistEntries("a", "b"), index: 2, removed: 0, inserted: testPlaylistEntries()},
+	{old: testPlaylistEntries("a", "b"), new: testPlaylistEntries("a", "b", "c"), index: 2, removed: 0, inserted: testPlaylistEntries("c")},
+	{old: testPlaylistEntries("a", "b", "c"), new: testPlaylistEntries("a", "c"), index: 1, removed: 1, inserted: testPlaylistEntries()},
+	{old: testPlaylistEntries("a", "c"), new: testPlaylistEntries("a", "b", "c"), index: 1, removed: 0, inserted: testPlaylistEntries("b")},
+	{old: testPlaylistEntries("a", "b", "c"), new: testPlaylistEntries("x", "y"), index: 0, removed: 3, inserted: testPlaylistEntries("x", "y")},
+	{old: testPlaylistEntries(), new: testPlaylistEntries("a"), index: 0, removed: 0, inserted: testPlaylistEntries("a")},
+	{old: testPlaylistEntries("a", "a"), new: testPlaylistEntries("a"), index: 1, removed: 1, inserted: testPlaylistEntries()},
+	{old: testPlaylistEntries("a", "b"), new: []playlistEntry{{song: "a"}, {song: "b", autoQueued: true}}, index: 1, removed: 1,
+		inserted: []playlistEntry{{song: "b", autoQueued: true}}},
+}
+
+func TestDiffPlaylist(t *testing.T) {
+	for _, c := range diffPlaylistCases {
+		index, removed, inserted := diffPlaylist(c.old, c.new)
+		assert.Equal(t, c.index, index, "diffPlaylist returned the wrong index for %v -> %v", c.old, c.new)
+		assert.Equal(t, c.removed, removed, "diffPlaylist returned the wrong number of removed entries for %v -> %v", c.old, c.new)
+		assert.Equal(t, c.inserted, inserted, "diffPlaylist returned the wrong inserted entries for %v -> %v", c.old, c.new)
+	}
+}
+
+func TestServerState_updatePlaylistInfo(t *testing.T) {
+	fms := &fakeMessageSender{}
+	ss := &serverState{
+		sender:           fms,
+		playlist:         playback.NewPlaylist(0, []string{"a.mp3", "b.mp3"}, 0),
+		metadataProvider: fakeMetadataProvider{"a.mp3": metadata.SongMetadata{Title: "A", Artist: "Artist"}},
+		autoDJ:           newAutoDJ(nil),
+		party:            newParty(),
+		playlistTracker:  &playlistTracker{},
+	}
+	metadataA := &comm.NewSongInfo_SongMetadata{Title: "A", Artist: "Artist"}
+
+	ss.updatePlaylistInfo()
+	ss.updatePlaylistInfo()
+	ss.playlist.AddSong("c.mp3")
+	ss.autoDJ.enabled = true
+	ss.playlist.SetPlaying(true)
+	ss.updatePlaylistInfo()
+	ss.playlist.RemoveSong(0)
+	ss.updatePlaylistInfo()
+
+	assertFakeMessageSenderMessages(t, fms, []proto.Message{
+		&comm.PlaylistInfo{Version: 1, Snapshot: true, Entries: []*comm.PlaylistInfo_Entry{
+			{SongFileName: "a.mp3", Metadata: metadataA},
+			{SongFileName: "b.mp3", Metadata: &comm.NewSongInfo_SongMetadata{}},
+		}},
+		&comm.PlaylistInfo{Version: 2, BaseVersion: 1, ChangeIndex: 2, Playing: true, AutoDJMode: "random", Entries: []*comm.PlaylistInfo_Entry{
+			{SongFileName: "c.mp3", Metadata: &comm.NewSongInfo_SongMetadata{}},
+		}},
+		&comm.PlaylistInfo{Version: 3, BaseVersion: 2, ChangeIndex: 0, ChangeRemoved: 1, Playing: true, AutoDJMode: "random",
+			Entries: []*comm.PlaylistInfo_Entry{}},
+	}, "updatePlaylistInfo")
+
+	snapshot := &fakeMessageSender{}
+	ss.sendPlaylistInfo(snapshot)
+	assertFakeMessageSenderMessages(t, snapshot, []proto.Message{
+		&comm.PlaylistInfo{Version: 3, Snapshot: true, Playing: true, AutoDJMode: "random", Entries: []*comm.PlaylistInfo_Entry{
+			{SongFileName: "b.mp3", Metadata: &comm.NewSongInfo_SongMetadata{}},
+			{SongFileName: "c.mp3", Metadata: &comm.NewSongInfo_SongMetadata{}},
+		}},
+	}, "sendPlaylistInfo")
+}
+
+func TestServerState_sendPlaylistInfoWithoutUpdate(t *testing.T) {
+	fms := &fakeMessageSender{}
+	(&serverState{}).sendPlaylistInfo(fms)
+	(&serverState{playlistTracker: &playlistTracker{}}).sendPlaylistInfo(fms)
+	assert.Empty(t, fms.Messages(), "sendPlaylistInfo sent a playlist before the first update")
+}
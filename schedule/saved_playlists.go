@@ -0,0 +1,145 @@
+package schedule
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PlaylistDir is the directory saved playlists are stored in
+var PlaylistDir = "playlists"
+
+const savedPlaylistExt = ".m3u"
+
+var savedPlaylistNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+func savedPlaylistPath(name string) (string, error) {
+	if !savedPlaylistNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid playlist name %s", name)
+	}
+	return filepath.Join(PlaylistDir, name+savedPlaylistExt), nil
+}
+
+// savedPlaylists returns the names of all saved playlists
+func savedPlaylists() []string {
+	files, err := ioutil.ReadDir(PlaylistDir)
+	if err != nil {
+		return []string{}
+	}
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		if !f.IsDir() && filepath.Ext(f.Name()) == savedPlaylistExt {
+			names = append(names, strings.TrimSuffix(f.Name(), savedPlaylistExt))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// savePlaylist saves songs as a playlist with one song per line
+func savePlaylist(name string, songs []string) error {
+	p, err := savedPlaylistPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(PlaylistDir, 0755); err != nil {
+		return fmt.Errorf("failed to create playlist dir %s: %v", PlaylistDir, err)
+	}
+	content := strings.Join(songs, "\n")
+	if 0 < len(songs) {
+		content += "\n"
+	}
+	if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write playlist %s: %v", name, err)
+	}
+	return nil
+}
+
+// loadPlaylist reads the songs of a saved playlist, ignoring empty lines and m3u comments
+func loadPlaylist(name string) ([]string, error) {
+	p, err := savedPlaylistPath(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open playlist %s: %v", name, err)
+	}
+	defer f.Close()
+
+	songs := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			songs = append(songs, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read playlist %s: %v", name, err)
+	}
+	return songs, nil
+}
+
+// loadSavedPlaylist replaces the playlist with the saved playlist name
+func (ss *serverState) loadSavedPlaylist(name string) (int, error) {
+	songs, err := loadPlaylist(name)
+	if err != nil {
+		return 0, err
+	}
+	ss.playlist.Replace(songs)
+	return len(songs), nil
+}
+
+func (ss *serverState) playlistsCommand() ssh.Command {
+	return ssh.Command{
+		Name:  "playlists",
+		Usage: "[save name|load name]",
+		Info:  "lists, saves and loads playlists",
+		ExecFunc: func(args []string) (string, bool) {
+			action, ok := parseStringParam(args, 0)
+			if !ok {
+				names := savedPlaylists()
+				if len(names) == 0 {
+					return "no saved playlists", true
+				}
+				return "saved playlists: " + strings.Join(names, ", "), true
+			}
+			name, ok := parseStringParam(args, 1)
+			if !ok {
+				return "", false
+			}
+
+			switch action {
+			case "save":
+				songs := ss.playlist.Songs()
+				if err := savePlaylist(name, songs); err != nil {
+					return err.Error(), true
+				}
+				return fmt.Sprintf("saved %d song(s) as playlist %s", len(songs), name), true
+			case "load":
+				n, err := ss.loadSavedPlaylist(name)
+				if err != nil {
+					return err.Error(), true
+				}
+				return fmt.Sprintf("loaded %d song(s) from playlist %s", n, name), true
+			}
+			return "", false
+		},
+		OptionsFunc: func(prefix string, arg int) []string {
+			switch arg {
+			case 0:
+				return []string{"save", "load"}
+			case 1:
+				return savedPlaylists()
+			}
+			return []string{}
+		},
+	}
+}
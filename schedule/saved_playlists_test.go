@@ -0,0 +1,49 @@
+package schedule
+
+import (
+	"github.com/LogicalOverflow/music-sync/testutil"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSavedPlaylists(t *testing.T) {
+	dir, err := ioutil.TempDir("", "music-sync-playlists")
+	if !assert.Nil(t, err, "failed to create temp dir") {
+		return
+	}
+	defer os.RemoveAll(dir)
+	oldDir := PlaylistDir
+	defer func() { PlaylistDir = oldDir }()
+	PlaylistDir = filepath.Join(dir, "playlists")
+
+	assert.Equal(t, []string{}, savedPlaylists(), "savedPlaylists returned playlists for a missing dir")
+	assert.NotNil(t, savePlaylist("../evil", []string{"a.mp3"}), "savePlaylist accepted an invalid name")
+
+	assert.Nil(t, savePlaylist("morning", []string{"a.mp3", "b.mp3"}), "savePlaylist returned an error")
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(PlaylistDir, "evening.m3u"), []byte("#EXTM3U\nc.mp3\n\n"), 0644), "failed to write playlist")
+	assert.Equal(t, []string{"evening", "morning"}, savedPlaylists(), "savedPlaylists returned the wrong playlists")
+
+	songs, err := loadPlaylist("evening")
+	assert.Nil(t, err, "loadPlaylist returned an error")
+	assert.Equal(t, []string{"c.mp3"}, songs, "loadPlaylist did not ignore comments and empty lines")
+	_, err = loadPlaylist("missing")
+	assert.NotNil(t, err, "loadPlaylist did not return an error for a missing playlist")
+
+	ss := newTestServerState([]string{"x.mp3"}, false)
+	testutil.CommandTesters{
+		Command: ss.playlistsCommand(),
+		Testers: []testutil.CommandTester{
+			testutil.OptionsTestCase{Prefix: "", Arg: 0, Result: []string{"save", "load"}},
+			testutil.OptionsTestCase{Prefix: "", Arg: 1, Result: []string{"evening", "morning"}},
+			testutil.ExecTestCase{Args: []string{}, Result: "saved playlists: evening, morning", Success: true},
+			testutil.ExecTestCase{Args: []string{"save"}, Result: "", Success: false},
+			testutil.ExecTestCase{Args: []string{"delete", "morning"}, Result: "", Success: false},
+			testutil.ExecTestCase{Args: []string{"save", "current"}, Result: "saved 1 song(s) as playlist current", Success: true},
+			testutil.ExecTestCase{Args: []string{"load", "morning"}, Result: "loaded 2 song(s) from playlist morning", Success: true},
+		},
+	}.Test(t)
+	assert.Equal(t, []string{"a.mp3", "b.mp3"}, ss.playlist.Songs(), "playlists load did not replace the playlist")
+}
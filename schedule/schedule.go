@@ -34,3 +34,12 @@ var StreamDelay = 15 * time.Second
 
 // SampleRate is the sample rate of the stream
 var SampleRate = 44100
+
+// FadeDuration is the duration of the fades when pausing, resuming or skipping a song
+var FadeDuration = 50 * time.Millisecond
+
+// SilenceThreshold is the level in dBFS below which leading and trailing silence of songs is trimmed (0 to disable)
+var SilenceThreshold = -60.0
+
+// VolumeRampDuration is the default duration of a volume change
+var VolumeRampDuration = 500 * time.Millisecond
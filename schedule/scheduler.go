@@ -0,0 +1,493 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"github.com/LogicalOverflow/music-sync/util"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScheduleFile is the file scheduled actions are persisted in (not persisted if empty)
+var ScheduleFile = ""
+
+// SleepFadeDuration is the duration of the fade out of the sleep timer
+var SleepFadeDuration = 30 * time.Second
+
+const schedulerInterval = time.Second
+
+// sleepAction is the scheduled action used by the sleep timer
+const sleepAction = "fade-out"
+
+const scheduleTimeFormat = "2006-01-02 15:04:05"
+
+type scheduleEntry struct {
+	ID      int       `json:"id"`
+	Next    time.Time `json:"next"`
+	Cron    string    `json:"cron,omitempty"`
+	Actions []string  `json:"actions"`
+
+	cron *cronSpec
+}
+
+func (e scheduleEntry) String() string {
+	when := "at " + e.Next.Format(scheduleTimeFormat)
+	if e.cron != nil {
+		when = fmt.Sprintf("cron %s (next %s)", e.cron, e.Next.Format(scheduleTimeFormat))
+	}
+	return fmt.Sprintf("[%d] %s: %s", e.ID, when, strings.Join(e.Actions, " "))
+}
+
+// scheduler runs actions at given times, once or recurring
+type scheduler struct {
+	entries []*scheduleEntry
+	lastID  int
+	mutex   sync.Mutex
+
+	now      func() time.Time
+	validate func(actions []string) error
+	run      func(actions []string) error
+}
+
+func newScheduler(validate, run func(actions []string) error) *scheduler {
+	s := &scheduler{
+		entries:  make([]*scheduleEntry, 0),
+		now:      time.Now,
+		validate: validate,
+		run:      run,
+	}
+	s.load()
+	return s
+}
+
+// add schedules actions to run at next (if cron is nil) or every time matching cron
+func (s *scheduler) add(next time.Time, cron *cronSpec, actions []string) (scheduleEntry, error) {
+	if err := s.validate(actions); err != nil {
+		return scheduleEntry{}, err
+	}
+	if cron != nil {
+		var ok bool
+		if next, ok = cron.next(s.now()); !ok {
+			return scheduleEntry{}, fmt.Errorf("cron spec %s never matches", cron)
+		}
+	}
+
+	s.mutex.Lock()
+	s.lastID++
+	e := &scheduleEntry{ID: s.lastID, Next: next, Actions: actions, cron: cron}
+	if cron != nil {
+		e.Cron = cron.String()
+	}
+	s.entries = append(s.entries, e)
+	s.mutex.Unlock()
+
+	s.save()
+	return *e, nil
+}
+
+// cancel removes the entry with id and returns whether it existed
+func (s *scheduler) cancel(id int) bool {
+	s.mutex.Lock()
+	found := false
+	for i, e := range s.entries {
+		if e.ID == id {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			found = true
+			break
+		}
+	}
+	s.mutex.Unlock()
+
+	if found {
+		s.save()
+	}
+	return found
+}
+
+// list returns all entries ordered by the time they run next
+func (s *scheduler) list() []scheduleEntry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entries := make([]scheduleEntry, len(s.entries))
+	for i, e := range s.entries {
+		entries[i] = *e
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Next.Before(entries[j].Next) })
+	return entries
+}
+
+// due returns the actions of all entries due, removing one-shot entries and advancing recurring entries
+func (s *scheduler) due() [][]string {
+	now := s.now()
+	s.mutex.Lock()
+	due := make([][]string, 0)
+	remaining := make([]*scheduleEntry, 0, len(s.entries))
+	for _, e := range s.entries {
+		if e.Next.After(now) {
+			remaining = append(remaining, e)
+			continue
+		}
+		due = append(due, e.Actions)
+		if e.cron != nil {
+			if next, ok := e.cron.next(now); ok {
+				e.Next = next
+				remaining = append(remaining, e)
+			}
+		}
+	}
+	s.entries = remaining
+	s.mutex.Unlock()
+
+	if 0 < len(due) {
+		s.save()
+	}
+	return due
+}
+
+func (s *scheduler) tick() {
+	for _, actions := range s.due() {
+		logger.Infof("running scheduled actions: %s", strings.Join(actions, " "))
+		if err := s.run(actions); err != nil {
+			logger.Warnf("failed to run scheduled actions %s: %v", strings.Join(actions, " "), err)
+		}
+	}
+}
+
+func (s *scheduler) loop(ctx context.Context) {
+	for !util.IsCanceled(ctx) {
+		s.tick()
+		time.Sleep(schedulerInterval)
+	}
+}
+
+func (s *scheduler) load() {
+	if ScheduleFile == "" || !util.IsFile(ScheduleFile) {
+		return
+	}
+	f, err := os.Open(ScheduleFile)
+	if err != nil {
+		logger.Warnf("failed to open schedule file %s: %v", ScheduleFile, err)
+		return
+	}
+	defer f.Close()
+
+	entries := make([]*scheduleEntry, 0)
+	if err := json.NewDecoder(f).Decode(&entries); err != nil {
+		logger.Warnf("failed to decode schedule file %s: %v", ScheduleFile, err)
+		return
+	}
+
+	now := s.now()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, e := range entries {
+		if s.lastID < e.ID {
+			s.lastID = e.ID
+		}
+		if err := s.validate(e.Actions); err != nil {
+			logger.Warnf("dropping scheduled actions %s: %v", strings.Join(e.Actions, " "), err)
+			continue
+		}
+		if e.Cron != "" {
+			cron, err := parseCronSpec(strings.Fields(e.Cron))
+			if err != nil {
+				logger.Warnf("dropping scheduled actions with invalid cron spec %s: %v", e.Cron, err)
+				continue
+			}
+			e.cron = cron
+			if e.Next.Before(now) {
+				e.Next, _ = cron.next(now)
+			}
+		} else if e.Next.Before(now) {
+			logger.Warnf("dropping scheduled actions %s missed at %s", strings.Join(e.Actions, " "), e.Next.Format(scheduleTimeFormat))
+			continue
+		}
+		s.entries = append(s.entries, e)
+	}
+}
+
+func (s *scheduler) save() {
+	if ScheduleFile == "" {
+		return
+	}
+	s.mutex.Lock()
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	s.mutex.Unlock()
+	if err != nil {
+		logger.Warnf("failed to encode schedules: %v", err)
+		return
+	}
+
+	f, err := os.Create(ScheduleFile)
+	if err != nil {
+		logger.Warnf("failed to create schedule file %s: %v", ScheduleFile, err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		logger.Warnf("failed to write schedule file %s: %v", ScheduleFile, err)
+	}
+}
+
+// parseScheduledActions parses a sequence of actions. Valid actions are pause, resume,
+// volume volume [ramp duration], load playlist, jump position and fade-out (used by the sleep timer).
+func (ss *serverState) parseScheduledActions(words []string) ([]func() error, error) {
+	actions := make([]func() error, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		switch words[i] {
+		case "pause", "resume":
+			playing := words[i] == "resume"
+			actions = append(actions, func() error { ss.setPlaying(playing); return nil })
+		case "volume":
+			volume, ok := parseFloatParam(words, i+1)
+			if !ok {
+				return nil, fmt.Errorf("volume requires a volume")
+			}
+			i++
+			rampDuration := VolumeRampDuration
+			if next, _ := parseStringParam(words, i+1); next == "ramp" {
+				value, _ := parseStringParam(words, i+2)
+				d, err := time.ParseDuration(value)
+				if err != nil || d < 0 {
+					return nil, fmt.Errorf("ramp requires a duration like 10m")
+				}
+				rampDuration = d
+				i += 2
+			}
+			actions = append(actions, func() error { return ss.setVolume(volume, rampDuration) })
+		case "load":
+			name, ok := parseStringParam(words, i+1)
+			if !ok {
+				return nil, fmt.Errorf("load requires a playlist name")
+			}
+			i++
+			actions = append(actions, func() error { _, err := ss.loadSavedPlaylist(name); return err })
+		case "jump":
+			pos, ok := parseIntParam(words, i+1)
+			if !ok {
+				return nil, fmt.Errorf("jump requires a position")
+			}
+			i++
+			actions = append(actions, func() error { ss.playlist.SetPos(pos); return nil })
+		case sleepAction:
+			actions = append(actions, ss.fadeOutAndPause)
+		default:
+			return nil, fmt.Errorf("unknown action %s", words[i])
+		}
+	}
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("no actions given")
+	}
+	return actions, nil
+}
+
+func (ss *serverState) validateScheduledActions(words []string) error {
+	_, err := ss.parseScheduledActions(words)
+	return err
+}
+
+func (ss *serverState) runScheduledActions(words []string) error {
+	actions, err := ss.parseScheduledActions(words)
+	if err != nil {
+		return err
+	}
+	for _, a := range actions {
+		if err := a(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fadeOutAndPause fades out the volume over SleepFadeDuration and pauses playback afterwards.
+// The volume is restored when playback is resumed.
+func (ss *serverState) fadeOutAndPause() error {
+	volume := ss.volume
+	if err := ss.setVolume(0, SleepFadeDuration); err != nil {
+		return err
+	}
+	ss.sleepMutex.Lock()
+	defer ss.sleepMutex.Unlock()
+	ss.sleepVolume, ss.sleeping = volume, true
+	var pause *time.Timer
+	pause = time.AfterFunc(SleepFadeDuration+volumeRampLeadTime, func() {
+		ss.sleepMutex.Lock()
+		current := ss.sleepPause == pause
+		if current {
+			ss.sleepPause = nil
+		}
+		ss.sleepMutex.Unlock()
+		// the pause may have been stopped while the timer fired
+		if current {
+			ss.playlist.SetPlaying(false)
+		}
+	})
+	ss.sleepPause = pause
+	return nil
+}
+
+// stopSleepPause stops the pause after the fade out of the sleep timer. sleepMutex must be locked when calling it.
+func (ss *serverState) stopSleepPause() {
+	if ss.sleepPause != nil {
+		ss.sleepPause.Stop()
+		ss.sleepPause = nil
+	}
+}
+
+// cancelSleep stops the fade out of the sleep timer and restores the volume faded out
+func (ss *serverState) cancelSleep() error {
+	ss.sleepMutex.Lock()
+	volume, sleeping := ss.sleepVolume, ss.sleeping
+	ss.stopSleepPause()
+	ss.sleepMutex.Unlock()
+	if !sleeping {
+		return nil
+	}
+	return ss.setVolume(volume, 0)
+}
+
+// parseScheduleTime parses times like 15:04 (the next time this time of day is reached) or 2006-01-02T15:04
+func parseScheduleTime(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02T15:04", value, now.Location()); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("15:04", value, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %s, use 15:04 or 2006-01-02T15:04", value)
+	}
+	next := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next, nil
+}
+
+func (ss *serverState) scheduled(e scheduleEntry, err error) (string, bool) {
+	if err != nil {
+		return fmt.Sprintf("failed to schedule actions: %v", err), true
+	}
+	return "scheduled " + e.String(), true
+}
+
+func (ss *serverState) sleepCommand() ssh.Command {
+	return ssh.Command{
+		Name:  "sleep",
+		Usage: "[duration|off]",
+		Info:  "fades out and pauses playback after duration (like 45m)",
+		ExecFunc: func(args []string) (string, bool) {
+			value, ok := parseStringParam(args, 0)
+			sleeps := make([]scheduleEntry, 0)
+			for _, e := range ss.scheduler.list() {
+				if len(e.Actions) == 1 && e.Actions[0] == sleepAction {
+					sleeps = append(sleeps, e)
+				}
+			}
+
+			switch {
+			case !ok && len(sleeps) == 0:
+				return "no sleep timer set", true
+			case !ok:
+				return fmt.Sprintf("sleep timer fades out at %s", sleeps[0].Next.Format(scheduleTimeFormat)), true
+			case value == "off":
+				for _, e := range sleeps {
+					ss.scheduler.cancel(e.ID)
+				}
+				if err := ss.cancelSleep(); err != nil {
+					return fmt.Sprintf("failed to restore the volume: %v", err), true
+				}
+				return fmt.Sprintf("%d sleep timer(s) cancelled", len(sleeps)), true
+			}
+
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return "", false
+			}
+			return ss.scheduled(ss.scheduler.add(ss.scheduler.now().Add(d), nil, []string{sleepAction}))
+		},
+		OptionsFunc: func(prefix string, arg int) []string {
+			if arg != 0 {
+				return []string{}
+			}
+			return []string{"15m", "30m", "45m", "1h", "off"}
+		},
+	}
+}
+
+func (ss *serverState) atCommand() ssh.Command {
+	return ssh.Command{
+		Name:  "at",
+		Usage: "time action...",
+		Info:  "runs actions (pause, resume, volume v [ramp duration], load playlist, jump position) once at time",
+		ExecFunc: func(args []string) (string, bool) {
+			value, ok := parseStringParam(args, 0)
+			if !ok || len(args) < 2 {
+				return "", false
+			}
+			t, err := parseScheduleTime(value, ss.scheduler.now())
+			if err != nil {
+				return err.Error(), true
+			}
+			return ss.scheduled(ss.scheduler.add(t, nil, args[1:]))
+		},
+	}
+}
+
+func (ss *serverState) cronCommand() ssh.Command {
+	return ssh.Command{
+		Name:  "cron",
+		Usage: "minute hour day month weekday action...",
+		Info:  "runs actions every time the cron spec matches",
+		ExecFunc: func(args []string) (string, bool) {
+			if len(args) < 6 {
+				return "", false
+			}
+			cron, err := parseCronSpec(args[:5])
+			if err != nil {
+				return err.Error(), true
+			}
+			return ss.scheduled(ss.scheduler.add(time.Time{}, cron, args[5:]))
+		},
+	}
+}
+
+func (ss *serverState) schedulesCommand() ssh.Command {
+	return ssh.Command{
+		Name:  "schedules",
+		Usage: "",
+		Info:  "lists all scheduled actions",
+		ExecFunc: func([]string) (string, bool) {
+			entries := ss.scheduler.list()
+			if len(entries) == 0 {
+				return "no scheduled actions", true
+			}
+			lines := make([]string, len(entries))
+			for i, e := range entries {
+				lines[i] = e.String()
+			}
+			return strings.Join(lines, "\n"), true
+		},
+	}
+}
+
+func (ss *serverState) cancelCommand() ssh.Command {
+	return ssh.Command{
+		Name:  "cancel",
+		Usage: "id",
+		Info:  "cancels scheduled actions",
+		ExecFunc: func(args []string) (string, bool) {
+			id, ok := parseIntParam(args, 0)
+			if !ok {
+				return "", false
+			}
+			if !ss.scheduler.cancel(id) {
+				return fmt.Sprintf("no scheduled actions with id %d", id), true
+			}
+			return fmt.Sprintf("cancelled scheduled actions %d", id), true
+		},
+	}
+}
@@ -0,0 +1,258 @@
+package schedule
+
+import (
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/testutil"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var schedulerTestNow = time.Date(2026, 10, 16, 22, 30, 0, 0, time.Local)
+
+func newTestScheduler(runs *[][]string) *scheduler {
+	s := newScheduler(func([]string) error { return nil }, func(actions []string) error {
+		*runs = append(*runs, actions)
+		return nil
+	})
+	s.now = func() time.Time { return schedulerTestNow }
+	return s
+}
+
+func TestScheduler(t *testing.T) {
+	runs := make([][]string, 0)
+	s := newTestScheduler(&runs)
+
+	once, _ := s.add(schedulerTestNow.Add(time.Hour), nil, []string{"pause"})
+	cron, _ := parseCronSpec(strings.Fields("0 7 * * *"))
+	daily, err := s.add(time.Time{}, cron, []string{"resume"})
+	assert.Nil(t, err, "scheduler add returned an error")
+	assert.Equal(t, time.Date(2026, 10, 17, 7, 0, 0, 0, time.Local), daily.Next, "scheduler add did not compute the next cron time")
+	assert.Equal(t, []scheduleEntry{once, daily}, s.list(), "scheduler list returned the wrong entries")
+
+	s.tick()
+	assert.Equal(t, 0, len(runs), "scheduler ran actions before they were due")
+
+	s.now = func() time.Time { return schedulerTestNow.Add(9 * time.Hour) }
+	s.tick()
+	assert.Equal(t, [][]string{{"pause"}, {"resume"}}, runs, "scheduler did not run the due actions")
+	entries := s.list()
+	if assert.Equal(t, 1, len(entries), "scheduler did not remove the one-shot entry") {
+		assert.Equal(t, time.Date(2026, 10, 18, 7, 0, 0, 0, time.Local), entries[0].Next, "scheduler did not advance the cron entry")
+	}
+
+	assert.False(t, s.cancel(once.ID), "scheduler cancel removed a missing entry")
+	assert.True(t, s.cancel(daily.ID), "scheduler cancel did not find the entry")
+	assert.Equal(t, []scheduleEntry{}, s.list(), "scheduler cancel did not remove the entry")
+}
+
+func TestScheduler_persistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "music-sync-schedules")
+	if !assert.Nil(t, err, "failed to create temp dir") {
+		return
+	}
+	defer os.RemoveAll(dir)
+	defer func() { ScheduleFile = "" }()
+	ScheduleFile = filepath.Join(dir, "schedules.json")
+
+	runs := make([][]string, 0)
+	s := newTestScheduler(&runs)
+	s.add(schedulerTestNow.Add(time.Minute), nil, []string{"pause"})
+	later, _ := s.add(schedulerTestNow.Add(time.Hour), nil, []string{"volume", "0.2"})
+	cron, _ := parseCronSpec(strings.Fields("0 7 * * *"))
+	daily, _ := s.add(time.Time{}, cron, []string{"resume"})
+
+	restarted := &scheduler{
+		entries:  make([]*scheduleEntry, 0),
+		now:      func() time.Time { return schedulerTestNow.Add(30 * time.Minute) },
+		validate: func([]string) error { return nil },
+	}
+	restarted.load()
+
+	entries := restarted.list()
+	if assert.Equal(t, 2, len(entries), "scheduler did not drop the missed one-shot entry") {
+		assert.Equal(t, later.ID, entries[0].ID, "scheduler did not restore the one-shot entry")
+		assert.True(t, later.Next.Equal(entries[0].Next), "scheduler did not restore the time of the one-shot entry")
+		assert.Equal(t, daily.Cron, entries[1].Cron, "scheduler did not restore the cron entry")
+		assert.True(t, daily.Next.Equal(entries[1].Next), "scheduler did not restore the time of the cron entry")
+	}
+	assert.Equal(t, daily.ID, restarted.lastID, "scheduler did not restore the last id")
+}
+
+func TestServerState_parseScheduledActions(t *testing.T) {
+	ss := newTestServerState([]string{}, false)
+	for _, actions := range []string{"", "explode", "volume", "volume loud", "volume 0.2 ramp", "volume 0.2 ramp soon", "load", "jump"} {
+		assert.NotNil(t, ss.validateScheduledActions(strings.Fields(actions)), "parseScheduledActions accepted %q", actions)
+	}
+	for _, actions := range []string{"pause", "resume volume 0.2 ramp 10m", "load morning jump 2", "fade-out"} {
+		assert.Nil(t, ss.validateScheduledActions(strings.Fields(actions)), "parseScheduledActions rejected %q", actions)
+	}
+
+	fs := &fakeSender{}
+	ss.sender = fs
+	assert.Nil(t, ss.runScheduledActions([]string{"resume", "volume", "0.2", "ramp", "10m"}), "runScheduledActions returned an error")
+	assert.True(t, ss.playlist.Playing(), "runScheduledActions did not resume playback")
+	if msg, ok := fs.lastMessage.(*comm.SetVolumeRequest); assert.True(t, ok, "runScheduledActions did not send the volume") {
+		assert.Equal(t, 0.2, msg.Volume, "runScheduledActions sent the wrong volume")
+		assert.Equal(t, uint64(sampleCount(10*time.Minute)), msg.RampLength, "runScheduledActions sent the wrong ramp length")
+	}
+}
+
+func TestServerState_fadeOutAndPause(t *testing.T) {
+	oldDuration := SleepFadeDuration
+	defer func() { SleepFadeDuration = oldDuration }()
+	SleepFadeDuration = 0
+
+	ss := newTestServerState([]string{}, true)
+	fs := &fakeSender{}
+	ss.sender = fs
+	ss.volume = 0.4
+
+	assert.Nil(t, ss.fadeOutAndPause(), "fadeOutAndPause returned an error")
+	assert.Equal(t, 0.0, ss.volume, "fadeOutAndPause did not fade out the volume")
+	time.Sleep(2 * volumeRampLeadTime)
+	assert.False(t, ss.playlist.Playing(), "fadeOutAndPause did not pause playback")
+
+	ss.setPlaying(true)
+	assert.Equal(t, &comm.SetVolumeRequest{Volume: 0.4}, fs.lastMessage, "resuming did not restore the volume")
+	assert.Equal(t, 0.4, ss.volume, "resuming did not restore the volume")
+	fs.lastMessage = nil
+	ss.setPlaying(true)
+	assert.Nil(t, fs.lastMessage, "resuming restored the volume twice")
+}
+
+func TestServerState_fadeOutAndPauseScheduledResume(t *testing.T) {
+	oldDuration := SleepFadeDuration
+	defer func() { SleepFadeDuration = oldDuration }()
+	SleepFadeDuration = 0
+
+	ss := newTestServerState([]string{}, true)
+	fms := &fakeMessageSender{}
+	ss.sender = fms
+	ss.volume = 0.4
+	assert.Nil(t, ss.fadeOutAndPause(), "fadeOutAndPause returned an error")
+	time.Sleep(2 * volumeRampLeadTime)
+	fms.messages = nil
+
+	// the playlist reports the resume to the pause toggle handler while the scheduled actions run
+	toggled := make(chan struct{})
+	go func() {
+		ss.createPauseToggleHandler()(true, 1000)
+		close(toggled)
+	}()
+	assert.Nil(t, ss.runScheduledActions([]string{"resume", "volume", "0.2", "ramp", "10m"}), "runScheduledActions returned an error")
+	<-toggled
+
+	volumes := make([]float64, 0, 2)
+	for _, m := range fms.Messages() {
+		if v, ok := m.(*comm.SetVolumeRequest); ok {
+			volumes = append(volumes, v.Volume)
+		}
+	}
+	assert.Equal(t, []float64{0.4, 0.2}, volumes, "the volume faded out by the sleep timer was not restored before the scheduled volume")
+	assert.Equal(t, 0.2, ss.volume, "the volume restored after the sleep timer replaced the scheduled volume")
+}
+
+func TestServerState_fadeOutAndPauseCancel(t *testing.T) {
+	oldDuration := SleepFadeDuration
+	defer func() { SleepFadeDuration = oldDuration }()
+	SleepFadeDuration = 0
+
+	cancels := map[string]func(ss *serverState){
+		"resume":     func(ss *serverState) { ss.setPlaying(true) },
+		"volume":     func(ss *serverState) { ss.setVolume(0.2, 0) },
+		"sleep off":  func(ss *serverState) { ss.cancelSleep() },
+		"new volume": func(ss *serverState) { ss.setVolume(0.5, time.Second) },
+	}
+	for name, cancel := range cancels {
+		ss := newTestServerState([]string{}, true)
+		ss.sender = &fakeSender{}
+		ss.volume = 0.4
+
+		assert.Nil(t, ss.fadeOutAndPause(), "fadeOutAndPause returned an error")
+		cancel(&ss)
+		time.Sleep(2 * volumeRampLeadTime)
+		assert.True(t, ss.playlist.Playing(), "fadeOutAndPause paused playback after %s", name)
+	}
+
+	ss := newTestServerState([]string{}, true)
+	fs := &fakeSender{}
+	ss.sender = fs
+	ss.volume = 0.4
+	assert.Nil(t, ss.fadeOutAndPause(), "fadeOutAndPause returned an error")
+	assert.Nil(t, ss.cancelSleep(), "cancelSleep returned an error")
+	assert.Equal(t, 0.4, ss.volume, "cancelSleep did not restore the volume")
+}
+
+func TestParseScheduleTime(t *testing.T) {
+	next, err := parseScheduleTime("07:00", schedulerTestNow)
+	assert.Nil(t, err, "parseScheduleTime returned an error")
+	assert.Equal(t, time.Date(2026, 10, 17, 7, 0, 0, 0, time.Local), next, "parseScheduleTime did not return the next day")
+
+	next, _ = parseScheduleTime("23:15", schedulerTestNow)
+	assert.Equal(t, time.Date(2026, 10, 16, 23, 15, 0, 0, time.Local), next, "parseScheduleTime did not return the same day")
+
+	next, _ = parseScheduleTime("2026-12-24T18:00", schedulerTestNow)
+	assert.Equal(t, time.Date(2026, 12, 24, 18, 0, 0, 0, time.Local), next, "parseScheduleTime did not parse the date")
+
+	_, err = parseScheduleTime("tomorrow", schedulerTestNow)
+	assert.NotNil(t, err, "parseScheduleTime accepted an invalid time")
+}
+
+func TestServerState_scheduleCommands(t *testing.T) {
+	ss := newTestServerState([]string{}, false)
+	runs := make([][]string, 0)
+	ss.scheduler = newTestScheduler(&runs)
+	ss.scheduler.validate = ss.validateScheduledActions
+
+	testutil.CommandTesters{
+		Command: ss.sleepCommand(),
+		Testers: []testutil.CommandTester{
+			testutil.OptionsTestCase{Prefix: "", Arg: 0, Result: []string{"15m", "30m", "45m", "1h", "off"}},
+			testutil.ExecTestCase{Args: []string{}, Result: "no sleep timer set", Success: true},
+			testutil.ExecTestCase{Args: []string{"later"}, Result: "", Success: false},
+			testutil.ExecTestCase{Args: []string{"45m"}, Result: "scheduled [1] at 2026-10-16 23:15:00: fade-out", Success: true},
+			testutil.ExecTestCase{Args: []string{}, Result: "sleep timer fades out at 2026-10-16 23:15:00", Success: true},
+			testutil.ExecTestCase{Args: []string{"off"}, Result: "1 sleep timer(s) cancelled", Success: true},
+		},
+	}.Test(t)
+
+	testutil.CommandTesters{
+		Command: ss.atCommand(),
+		Testers: []testutil.CommandTester{
+			testutil.ExecTestCase{Args: []string{"07:00"}, Result: "", Success: false},
+			testutil.ExecTestCase{Args: []string{"soon", "pause"}, Result: "invalid time soon, use 15:04 or 2006-01-02T15:04", Success: true},
+			testutil.ExecTestCase{Args: []string{"07:00", "explode"}, Result: "failed to schedule actions: unknown action explode", Success: true},
+			testutil.ExecTestCase{Args: []string{"07:00", "resume", "volume", "0.2", "ramp", "10m"}, Result: "scheduled [2] at 2026-10-17 07:00:00: resume volume 0.2 ramp 10m", Success: true},
+		},
+	}.Test(t)
+
+	testutil.CommandTesters{
+		Command: ss.cronCommand(),
+		Testers: []testutil.CommandTester{
+			testutil.ExecTestCase{Args: []string{"0", "7", "*", "*", "1-5"}, Result: "", Success: false},
+			testutil.ExecTestCase{Args: []string{"0", "25", "*", "*", "*", "pause"}, Result: "invalid cron field 25: 25-25 is not within 0-23", Success: true},
+			testutil.ExecTestCase{Args: []string{"0", "7", "*", "*", "1-5", "load", "morning"}, Result: "scheduled [3] cron 0 7 * * 1-5 (next 2026-10-19 07:00:00): load morning", Success: true},
+		},
+	}.Test(t)
+
+	testutil.CommandTesters{
+		Command: ss.schedulesCommand(),
+		Testers: []testutil.CommandTester{
+			testutil.ExecTestCase{Args: []string{}, Result: "[2] at 2026-10-17 07:00:00: resume volume 0.2 ramp 10m\n[3] cron 0 7 * * 1-5 (next 2026-10-19 07:00:00): load morning", Success: true},
+		},
+	}.Test(t)
+
+	testutil.CommandTesters{
+		Command: ss.cancelCommand(),
+		Testers: []testutil.CommandTester{
+			noArgsError,
+			testutil.ExecTestCase{Args: []string{"1"}, Result: "no scheduled actions with id 1", Success: true},
+			testutil.ExecTestCase{Args: []string{"2"}, Result: "cancelled scheduled actions 2", Success: true},
+		},
+	}.Test(t)
+}
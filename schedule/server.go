@@ -2,6 +2,7 @@ package schedule
 
 import (
 	"context"
+	"github.com/LogicalOverflow/music-sync/api"
 	"github.com/LogicalOverflow/music-sync/comm"
 	"github.com/LogicalOverflow/music-sync/metadata"
 	"github.com/LogicalOverflow/music-sync/playback"
@@ -15,26 +16,79 @@ func Server(sender comm.MessageSender) {
 
 	ss.lyricsProvider = metadata.GetLyricsProvider()
 	ss.metadataProvider = metadata.GetProvider()
+	ss.trimProvider = metadata.GetTrimProvider()
+	ss.coverProvider = metadata.GetCoverProvider()
 
 	ss.playlist = playback.NewPlaylist(SampleRate, []string{}, NanBreakSize)
+	ss.playlist.SetSampleRate(SampleRate)
 	ss.volume = 0.1
+	ss.playlist.SetFadeLength(sampleCount(FadeDuration))
+	ss.playlist.SetTrimHandler(ss.createTrimHandler())
+	if SilenceThreshold < 0 {
+		ss.playlist.SetSilenceThreshold(playback.GainToFactor(SilenceThreshold))
+	}
 
 	ss.pauses = make([]*comm.PauseInfo, 0)
+	ss.playlistTracker = &playlistTracker{}
+
+	ss.autoDJ = newAutoDJ(ss.metadataProvider)
+	ss.party = newParty()
+	ss.playlist.SetAutoQueueHandler(ss.createAutoQueueHandler())
+
+	ss.normalizer = newNormalizer(metadata.GetGainProvider())
+	ss.playlist.SetGainHandler(ss.normalizer.gainHandler())
+
+	ss.dsp = newDSPSettings(playback.NewDSP(SampleRate))
+	ss.playlist.SetDSP(ss.dsp.server)
 
 	comm.NewClientHandler = ss.createClientHandler()
+	comm.NamedClientHandler = ss.dsp.playerConnected
+	comm.UploadLyricsHandler = ss.uploadLyrics
+	comm.ControlHandler = ss.control
+
+	api.StatusHandler = ss.apiStatus
+	api.PlaylistHandler = ss.apiPlaylist
+	api.SearchHandler = ss.apiSearch
+	api.LibraryHandler = ss.apiLibrary
+	api.PlayerHandler = apiPlayer
 
 	go ss.playlist.StreamLoop(context.Background())
 
 	ss.playlist.SetNewSongHandler(ss.createNewSongHandler())
 	ss.playlist.SetPauseToggleHandler(ss.createPauseToggleHandler())
+	ss.playlist.SetAnnouncementHandler(ss.createAnnouncementHandler())
+	ss.playlist.SetStreamTitleHandler(ss.createStreamTitleHandler())
+	ss.playlist.SetSpeedHandler(ss.createSpeedHandler())
+	ss.playlist.SetSeekHandler(ss.createSpeedHandler())
 
 	go ss.streamMusic()
+	go ss.playlistInfoLoop(context.Background())
+
+	ss.scheduler = newScheduler(ss.validateScheduledActions, ss.runScheduledActions)
+	go ss.scheduler.loop(context.Background())
 
 	ssh.RegisterCommand(ss.queueCommand())
 	ssh.RegisterCommand(ss.playlistCommand())
 	ssh.RegisterCommand(ss.removeCommand())
+	ssh.RegisterCommand(ss.moveCommand())
 	ssh.RegisterCommand(ss.jumpCommand())
+	ssh.RegisterCommand(ss.seekCommand())
 	ssh.RegisterCommand(ss.volumeCommand())
 	ssh.RegisterCommand(ss.pauseCommand())
 	ssh.RegisterCommand(ss.resumeCommand())
+	ssh.RegisterCommand(ss.autoDJCommand())
+	ssh.RegisterCommand(ss.partyCommand())
+	ssh.RegisterCommand(ss.voteSkipCommand())
+	ssh.RegisterCommand(ss.normalizeCommand())
+	ssh.RegisterCommand(ss.eqCommand())
+	ssh.RegisterCommand(ss.playlistsCommand())
+	ssh.RegisterCommand(ss.sleepCommand())
+	ssh.RegisterCommand(ss.atCommand())
+	ssh.RegisterCommand(ss.cronCommand())
+	ssh.RegisterCommand(ss.schedulesCommand())
+	ssh.RegisterCommand(ss.cancelCommand())
+	ssh.RegisterCommand(ss.announceCommand())
+	ssh.RegisterCommand(ss.speedCommand())
+	ssh.RegisterCommand(ss.karaokeCommand())
+	ssh.RegisterCommand(searchCommand())
 }
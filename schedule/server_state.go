@@ -5,6 +5,7 @@ import (
 	"github.com/LogicalOverflow/music-sync/metadata"
 	"github.com/LogicalOverflow/music-sync/playback"
 	"github.com/LogicalOverflow/music-sync/timing"
+	"strings"
 	"sync"
 	"time"
 )
@@ -14,14 +15,35 @@ type serverState struct {
 
 	lyricsProvider   metadata.LyricsProvider
 	metadataProvider metadata.Provider
+	trimProvider     metadata.TrimProvider
+	coverProvider    metadata.CoverProvider
 
 	playlist *playback.Playlist
 	volume   float64
+	autoDJ   *autoDJ
+	party    *party
 
-	newestSong *comm.NewSongInfo
+	normalizer *normalizer
+	dsp        *dspSettings
+
+	newestSong  *comm.NewSongInfo
+	cover       coverState
+	streamStart int64
+
+	song      playingSong
+	songMutex sync.Mutex
 
 	pauses      []*comm.PauseInfo
 	pausesMutex sync.RWMutex
+
+	playlistTracker *playlistTracker
+
+	scheduler   *scheduler
+	sleepVolume float64
+	sleeping    bool
+	// sleepPause pauses playback after the fade out of the sleep timer
+	sleepPause *time.Timer
+	sleepMutex sync.Mutex
 }
 
 func (ss *serverState) sendVolume(s comm.MessageSender) {
@@ -49,8 +71,11 @@ func (ss *serverState) createClientHandler() func(comm.Channel, comm.MessageSend
 			ss.sendVolume(s)
 		case comm.Channel_META:
 			ss.sendVolume(s)
+			ss.sendCover(s)
 			ss.sendNewestSong(s)
 			ss.sendPauses(s)
+			ss.sendKaraoke(s)
+			ss.sendPlaylistInfo(s)
 		}
 	}
 }
@@ -70,42 +95,118 @@ func toWireLyrics(lyrics []metadata.LyricsLine) []*comm.NewSongInfo_SongLyricsLi
 	return wireLyrics
 }
 
-func (ss *serverState) createNewSongHandler() func(uint64, string, int64) {
-	return func(startSampleIndex uint64, filename string, songLength int64) {
-		lyrics := ss.lyricsProvider.CollectLyrics(filename)
-		wireLyrics := toWireLyrics(lyrics)
+func toWireMetadata(md metadata.SongMetadata) *comm.NewSongInfo_SongMetadata {
+	return &comm.NewSongInfo_SongMetadata{
+		Title:       md.Title,
+		Artist:      md.Artist,
+		Album:       md.Album,
+		Genre:       md.Genre,
+		AlbumArtist: md.AlbumArtist,
+		Composer:    md.Composer,
+		Comment:     md.Comment,
+		Year:        int32(md.Year),
+		Track:       int32(md.Track),
+		TrackTotal:  int32(md.TrackTotal),
+		Disc:        int32(md.Disc),
+		DiscTotal:   int32(md.DiscTotal),
+		Duration:    int64(md.Duration / time.Millisecond),
+	}
+}
+
+func (ss *serverState) createNewSongHandler() func(uint64, string, int64, int64, float64) {
+	return func(startSampleIndex uint64, filename string, songLength int64, trimStart int64, speed float64) {
+		if ss.autoDJ != nil {
+			ss.autoDJ.songPlayed(filename, time.Now())
+		}
+		if ss.party != nil {
+			ss.party.resetVotes()
+		}
 
 		md := ss.metadataProvider.CollectMetadata(filename)
+		coverID := ss.updateCover(filename)
 
+		ss.songMutex.Lock()
+		ss.song = playingSong{
+			info: &comm.NewSongInfo{
+				SongFileName: filename,
+				Metadata:     toWireMetadata(md),
+				Live:         playback.IsLiveSource(filename),
+				CoverId:      coverID,
+			},
+			lyrics:    ss.lyricsProvider.CollectLyrics(filename),
+			trimStart: trimStart,
+			length:    songLength,
+		}
+		ss.newestSong = ss.song.timed(startSampleIndex, 0, 0, speed)
+		ss.song.info = ss.newestSong
+		info := ss.newestSong
+		ss.songMutex.Unlock()
+		ss.sender.SendMessage(info)
+	}
+}
+
+// createTrimHandler returns a handler, which converts the trim points of songs to samples
+func (ss *serverState) createTrimHandler() func(string) (int, int) {
+	return func(song string) (int, int) {
+		tp := ss.trimProvider.CollectTrimPoints(song)
+		return sampleCount(time.Duration(tp.Start) * time.Millisecond), sampleCount(time.Duration(tp.End) * time.Millisecond)
+	}
+}
+
+// createStreamTitleHandler returns a handler, which sends the titles of http streams as new songs
+func (ss *serverState) createStreamTitleHandler() func(uint64, string, string) {
+	return func(startSampleIndex uint64, url string, title string) {
+		md := &comm.NewSongInfo_SongMetadata{Title: title}
+		if parts := strings.SplitN(title, " - ", 2); len(parts) == 2 {
+			md.Artist, md.Title = parts[0], parts[1]
+		}
 		ss.newestSong = &comm.NewSongInfo{
 			FirstSampleOfSongIndex: startSampleIndex,
-			SongFileName:           filename,
-			SongLength:             songLength,
-			Lyrics:                 wireLyrics,
-			Metadata: &comm.NewSongInfo_SongMetadata{
-				Title:  md.Title,
-				Artist: md.Artist,
-				Album:  md.Album,
-			},
+			SongFileName:           url,
+			Metadata:               md,
 		}
 		ss.sender.SendMessage(ss.newestSong)
 	}
 }
 
+func (ss *serverState) createAutoQueueHandler() func(string, int) []string {
+	autoDJHandler := ss.autoDJ.queueHandler()
+	return func(lastSong string, upcoming int) []string {
+		upcoming += ss.party.fill(ss.playlist, lastSong, upcoming)
+		return autoDJHandler(lastSong, upcoming)
+	}
+}
+
 func (ss *serverState) createPauseToggleHandler() func(bool, uint64) {
 	return func(playing bool, sample uint64) {
-		pause := &comm.PauseInfo{
+		ss.addPause(&comm.PauseInfo{
 			Playing:           playing,
 			ToggleSampleIndex: sample,
-		}
-		go func(pause *comm.PauseInfo) {
-			ss.pausesMutex.Lock()
-			defer ss.pausesMutex.Unlock()
-			ss.pauses = append(ss.pauses, pause)
-			go ss.removeOldPauses()
-		}(pause)
-		ss.sender.SendMessage(pause)
+		})
+	}
+}
+
+// addPause stores pause, so it is sent to new infoers, and sends it to all connected clients
+func (ss *serverState) addPause(pause *comm.PauseInfo) {
+	ss.pausesMutex.Lock()
+	ss.pauses = append(ss.pauses, pause)
+	ss.pausesMutex.Unlock()
+	go ss.removeOldPauses()
+	ss.sender.SendMessage(pause)
+}
+
+// restoreSleepVolume restores the volume faded out by the sleep timer. sleepMutex must be locked when calling it,
+// so a volume set concurrently is either set after the restored volume or prevents the restore.
+func (ss *serverState) restoreSleepVolume() {
+	if !ss.sleeping {
+		return
 	}
+	ss.sleeping = false
+	ss.volume = ss.sleepVolume
+	ss.sender.SendMessage(&comm.SetVolumeRequest{
+		Volume:      ss.volume,
+		SampleIndex: ss.sampleIndexAt(timing.GetSyncedTime() + int64(volumeRampLeadTime/time.Nanosecond)),
+	})
 }
 
 func (ss *serverState) removablePauses() int {
@@ -142,9 +243,30 @@ func (ss *serverState) removeOldPauses() {
 	}
 }
 
+// sampleIndexAt returns the index of the sample players play at the synced time t
+func (ss *serverState) sampleIndexAt(t int64) uint64 {
+	if ss.streamStart == 0 || t < ss.streamStart {
+		return 0
+	}
+	elapsed := t - ss.streamStart
+	chunkTime := int64(StreamChunkTime / time.Nanosecond)
+	chunk := elapsed / chunkTime
+	offset := sampleCount(time.Duration(elapsed - chunk*chunkTime))
+	if StreamChunkSize < offset {
+		offset = StreamChunkSize
+	}
+	return uint64(chunk*int64(StreamChunkSize) + int64(offset))
+}
+
+// sampleCount returns the number of samples played in d
+func sampleCount(d time.Duration) int {
+	return int(d.Seconds() * float64(SampleRate))
+}
+
 func (ss *serverState) streamMusic() {
 	time.Sleep(StreamStartDelay)
 	start := timing.GetSyncedTime() + int64(StreamDelay/time.Nanosecond)
+	ss.streamStart = start
 	index := int64(0)
 	for range time.Tick(StreamChunkTime) {
 		low := make([]float64, StreamChunkSize)
@@ -164,6 +286,7 @@ func (ss *serverState) streamMusic() {
 			FirstSampleIndex: firstSampleIndex,
 			ChunkSize:        uint64(StreamChunkSize),
 		})
+		go ss.sendSpectrum(firstSampleIndex, low, high)
 		index++
 	}
 }
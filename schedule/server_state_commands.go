@@ -3,11 +3,14 @@ package schedule
 import (
 	"fmt"
 	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/metadata"
 	"github.com/LogicalOverflow/music-sync/playback"
 	"github.com/LogicalOverflow/music-sync/ssh"
+	"github.com/LogicalOverflow/music-sync/timing"
 	"github.com/LogicalOverflow/music-sync/util"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func parseFloatParam(args []string, index int) (float64, bool) {
@@ -39,19 +42,31 @@ func parseIntParam(args []string, index int) (int, bool) {
 	return v, true
 }
 
-func (ss *serverState) queueCommandExec(args []string) (string, bool) {
+func (ss *serverState) queueCommandExec(user string, args []string) (string, bool) {
 	songPattern, ok := parseStringParam(args, 0)
 	if !ok {
 		return "", false
 	}
 
-	songs, err := util.ListGlobFiles(playback.AudioDir, songPattern)
-	if err != nil {
-		return fmt.Sprintf("glob pattern is invalid: %v", err), true
+	// urls, live sources and cue tracks are not files, so they are added without matching them
+	songs := []string{songPattern}
+	_, _, cueErr := playback.LookupCueTrack(songPattern)
+	if cueErr != nil && !playback.IsURL(songPattern) && !playback.IsLiveSource(songPattern) {
+		var err error
+		songs, err = util.ListGlobFiles(playback.AudioDir, songPattern)
+		if err != nil {
+			return fmt.Sprintf("glob pattern is invalid: %v", err), true
+		}
+		songs = playback.ExpandCueSheets(songs)
+		metadata.SortSongs(songs)
+		if len(songs) == 0 {
+			return fmt.Sprintf("no song matches the glob pattern %s", songPattern), true
+		}
 	}
-	songs = util.FilterSongs(songs)
-	if len(songs) == 0 {
-		return fmt.Sprintf("no song matches the glob pattern %s", songPattern), true
+
+	if ss.party != nil && ss.party.isEnabled() {
+		ss.party.request(user, songs)
+		return fmt.Sprintf("%d song(s) added to the party queue of %s: %s", len(songs), user, strings.Join(songs, ", ")), true
 	}
 
 	var insert func(string, int)
@@ -69,15 +84,15 @@ func (ss *serverState) queueCommandExec(args []string) (string, bool) {
 
 func (ss *serverState) queueCommand() ssh.Command {
 	return ssh.Command{
-		Name:     "queue",
-		Usage:    "filename [position in playlist]",
-		Info:     "adds a song to the playlist",
-		ExecFunc: ss.queueCommandExec,
+		Name:         "queue",
+		Usage:        "filename [position in playlist]",
+		Info:         "adds a song, a http stream url or a live source to the playlist",
+		UserExecFunc: ss.queueCommandExec,
 		OptionsFunc: func(prefix string, arg int) []string {
 			if arg != 0 {
 				return []string{}
 			}
-			songs := util.FilterSongs(util.ListAllFiles(playback.AudioDir, ""))
+			songs := playback.ListSongs("")
 			options := make([]string, 0, len(songs))
 			for _, song := range songs {
 				if strings.HasPrefix(song, prefix) {
@@ -89,12 +104,75 @@ func (ss *serverState) queueCommand() ssh.Command {
 	}
 }
 
+// formatSongDuration formats d as minutes:seconds or hours:minutes:seconds
+func formatSongDuration(d time.Duration) string {
+	if d < time.Hour {
+		return fmt.Sprintf("%d:%02d", d/time.Minute, d/time.Second%60)
+	}
+	return fmt.Sprintf("%d:%02d:%02d", d/time.Hour, d/time.Minute%60, d/time.Second%60)
+}
+
+// describeSong returns a single line describing the song with the metadata md,
+// e.g. "Artist - Title (Album, 2019, disc 1/2, track 3/12, Rock, composed by Composer) 3:45".
+// It is empty if md has no title.
+func describeSong(md metadata.SongMetadata) string {
+	if md.Title == "" {
+		return ""
+	}
+	description := md.Title
+	if md.Artist != "" {
+		description = md.Artist + " - " + description
+	}
+
+	details := make([]string, 0)
+	numbered := func(name string, n, total int) string {
+		if 0 < total {
+			return fmt.Sprintf("%s %d/%d", name, n, total)
+		}
+		return fmt.Sprintf("%s %d", name, n)
+	}
+	if md.Album != "" {
+		details = append(details, md.Album)
+	}
+	if md.AlbumArtist != "" && md.AlbumArtist != md.Artist {
+		details = append(details, "album by "+md.AlbumArtist)
+	}
+	if md.Year != 0 {
+		details = append(details, strconv.Itoa(md.Year))
+	}
+	if md.Disc != 0 {
+		details = append(details, numbered("disc", md.Disc, md.DiscTotal))
+	}
+	if md.Track != 0 {
+		details = append(details, numbered("track", md.Track, md.TrackTotal))
+	}
+	if md.Genre != "" {
+		details = append(details, md.Genre)
+	}
+	if md.Composer != "" {
+		details = append(details, "composed by "+md.Composer)
+	}
+	if 0 < len(details) {
+		description += " (" + strings.Join(details, ", ") + ")"
+	}
+	if 0 < md.Duration {
+		description += " " + formatSongDuration(md.Duration)
+	}
+	return description
+}
+
 func (ss *serverState) playlistCommandExc([]string) (string, bool) {
-	songs := ss.playlist.Songs()
+	songs := ss.playlist.Entries()
 	entries := make([]string, len(songs))
 	format := fmt.Sprintf("  [%%0%dd] %%s", len(strconv.Itoa(len(songs)-1)))
 	for i, s := range songs {
-		entries[i] = fmt.Sprintf(format, i, s)
+		entries[i] = fmt.Sprintf(format, i, s.Song)
+		if description := describeSong(ss.songMetadata(s.Song)); description != "" {
+			entries[i] += ": " + description
+		}
+		if s.AutoQueued {
+			entries[i] += " (auto-dj)"
+		}
 	}
 	var playingStatus string
 	if ss.playlist.Playing() {
@@ -140,6 +218,28 @@ func (ss *serverState) removeCommand() ssh.Command {
 	}
 }
 
+func (ss *serverState) moveCommand() ssh.Command {
+	return ssh.Command{
+		Name:  "move",
+		Usage: "from to",
+		Info:  "moves a song in the playlist",
+		ExecFunc: func(args []string) (string, bool) {
+			from, ok := parseIntParam(args, 0)
+			if !ok {
+				return "", false
+			}
+			to, ok := parseIntParam(args, 1)
+			if !ok {
+				return "", false
+			}
+			if !ss.playlist.MoveSong(from, to) {
+				return fmt.Sprintf("the playlist has no position %d or %d", from, to), true
+			}
+			return fmt.Sprintf("moved song from position %d to %d", from, to), true
+		},
+	}
+}
+
 func (ss *serverState) jumpCommand() ssh.Command {
 	return ssh.Command{
 		Name:  "jump",
@@ -156,18 +256,55 @@ func (ss *serverState) jumpCommand() ssh.Command {
 	}
 }
 
+func (ss *serverState) seekCommand() ssh.Command {
+	return ssh.Command{
+		Name:  "seek",
+		Usage: "[+|-]seconds",
+		Info:  "seeks to a position in the current song, relative to the current position with a sign",
+		ExecFunc: func(args []string) (string, bool) {
+			value, ok := parseStringParam(args, 0)
+			if !ok {
+				return "", false
+			}
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return "", false
+			}
+			relative := strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-")
+			if !relative && seconds < 0 {
+				return "", false
+			}
+			ss.playlist.Seek(int(seconds*float64(SampleRate)), relative)
+			if relative {
+				return fmt.Sprintf("seeking by %+.1f seconds", seconds), true
+			}
+			return fmt.Sprintf("seeking to %.1f seconds", seconds), true
+		},
+	}
+}
+
+// volumeRampLeadTime is the time between sending a volume change and the players starting to ramp the volume
+const volumeRampLeadTime = 250 * time.Millisecond
+
 func (ss *serverState) volumeCommand() ssh.Command {
 	return ssh.Command{
 		Name:  "volume",
-		Usage: "volume",
+		Usage: "volume [ramp duration in seconds]",
 		Info:  "set the playback volume",
 		ExecFunc: func(args []string) (string, bool) {
-			var ok bool
-			ss.volume, ok = parseFloatParam(args, 0)
+			volume, ok := parseFloatParam(args, 0)
 			if !ok {
 				return "", false
 			}
-			if err := ss.sender.SendMessage(&comm.SetVolumeRequest{Volume: ss.volume}); err != nil {
+			rampDuration := VolumeRampDuration
+			if seconds, ok := parseFloatParam(args, 1); ok {
+				if seconds < 0 {
+					return "", false
+				}
+				rampDuration = time.Duration(seconds * float64(time.Second))
+			}
+
+			if err := ss.setVolume(volume, rampDuration); err != nil {
 				return fmt.Sprintf("failed to set volume to %.3f: %v", ss.volume, err), true
 			}
 			return fmt.Sprintf("setting volume to %.3f", ss.volume), true
@@ -175,6 +312,20 @@ func (ss *serverState) volumeCommand() ssh.Command {
 	}
 }
 
+// setVolume changes the volume of all players over rampDuration
+func (ss *serverState) setVolume(volume float64, rampDuration time.Duration) error {
+	ss.sleepMutex.Lock()
+	ss.sleeping = false
+	ss.stopSleepPause()
+	ss.sleepMutex.Unlock()
+	ss.volume = volume
+	return ss.sender.SendMessage(&comm.SetVolumeRequest{
+		Volume:      ss.volume,
+		SampleIndex: ss.sampleIndexAt(timing.GetSyncedTime() + int64(volumeRampLeadTime/time.Nanosecond)),
+		RampLength:  uint64(sampleCount(rampDuration)),
+	})
+}
+
 func (ss *serverState) pauseCommand() ssh.Command {
 	return ss.playbackSetCommand(false)
 }
@@ -195,8 +346,20 @@ func (ss *serverState) playbackSetCommand(targetPlaying bool) ssh.Command {
 		Usage: "",
 		Info:  action + "s playback",
 		ExecFunc: func([]string) (string, bool) {
-			ss.playlist.SetPlaying(targetPlaying)
+			ss.setPlaying(targetPlaying)
 			return "playback " + action + "d", true
 		},
 	}
 }
+
+// setPlaying pauses or resumes playback. Resuming stops the pause after the fade out of the sleep timer and
+// restores the volume it faded out before playback resumes.
+func (ss *serverState) setPlaying(playing bool) {
+	if playing {
+		ss.sleepMutex.Lock()
+		defer ss.sleepMutex.Unlock()
+		ss.stopSleepPause()
+		ss.restoreSleepVolume()
+	}
+	ss.playlist.SetPlaying(playing)
+}
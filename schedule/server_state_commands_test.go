@@ -2,6 +2,7 @@ package schedule
 
 import (
 	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/metadata"
 	"github.com/LogicalOverflow/music-sync/playback"
 	"github.com/LogicalOverflow/music-sync/ssh"
 	"github.com/LogicalOverflow/music-sync/testutil"
@@ -10,6 +11,7 @@ import (
 	"os"
 	"path"
 	"testing"
+	"time"
 )
 
 const pathSeparator = string(os.PathSeparator)
@@ -47,13 +49,15 @@ func TestServerState_queueCommand(t *testing.T) {
 			testutil.ExecTestCase{Args: []string{"dir1/*"}, Result: "3 song(s) added to playlist: dir1" + pathSeparator + "song1.mp3, dir1" + pathSeparator + "song2.mp3, dir1" + pathSeparator + "song3.mp3", Success: true},
 			testutil.ExecTestCase{Args: []string{"song2.mp3", "abc"}, Result: "1 song(s) added to playlist: song2.mp3", Success: true},
 			testutil.ExecTestCase{Args: []string{"song3.mp3", "1"}, Result: "1 song(s) added to playlist: song3.mp3", Success: true},
+			testutil.ExecTestCase{Args: []string{"http://radio.example.com/stream"}, Result: "1 song(s) added to playlist: http://radio.example.com/stream", Success: true},
 		},
 	}
 
 	ct.Test(t)
 	assert.Equal(t,
 		[]string{"song1.mp3", "song3.mp3", "dir1" + pathSeparator + "song1.mp3",
-			"dir1" + pathSeparator + "song2.mp3", "dir1" + pathSeparator + "song3.mp3", "song2.mp3"},
+			"dir1" + pathSeparator + "song2.mp3", "dir1" + pathSeparator + "song3.mp3", "song2.mp3",
+			"http://radio.example.com/stream"},
 		ss.playlist.Songs(), "serverState queueCommand did not add the songs properly")
 
 	// TODO: options cases
@@ -99,6 +103,26 @@ func TestServerState_removeCommand(t *testing.T) {
 	assert.Equal(t, []string{"song-1", "song-2", "song-3"}, ss.playlist.Songs(), "songState removeCommand did not call playlist.RemoveSong properly")
 }
 
+func TestServerState_moveCommand(t *testing.T) {
+	ss := newTestServerState([]string{"song-0", "song-1", "song-2", "song-3"}, false)
+
+	cmd := ss.moveCommand()
+	ct := testutil.CommandTesters{
+		Command: cmd,
+		Testers: []testutil.CommandTester{
+			noArgsError, firstArgNoNumberError,
+			testutil.ExecTestCase{Args: []string{"1"}, Success: false},
+			testutil.ExecTestCase{Args: []string{"1", "a"}, Success: false},
+			testutil.ExecTestCase{Args: []string{"0", "4"}, Result: "the playlist has no position 0 or 4", Success: true},
+			testutil.ExecTestCase{Args: []string{"0", "2"}, Result: "moved song from position 0 to 2", Success: true},
+		},
+	}
+
+	ct.Test(t)
+
+	assert.Equal(t, []string{"song-1", "song-2", "song-0", "song-3"}, ss.playlist.Songs(), "songState moveCommand did not call playlist.MoveSong properly")
+}
+
 func TestServerState_jumpCommand(t *testing.T) {
 	ss := newTestServerState([]string{"song-0", "song-1", "song-2", "song-3", "song-4"}, false)
 
@@ -121,6 +145,25 @@ func TestServerState_jumpCommand(t *testing.T) {
 	assert.Equal(t, 3, ss.playlist.Pos(), "songState jumpCommand did not call playlist.SetPos properly")
 }
 
+func TestServerState_seekCommand(t *testing.T) {
+	oldSampleRate := SampleRate
+	defer func() { SampleRate = oldSampleRate }()
+	SampleRate = 1000
+
+	ss := newTestServerState([]string{"song-0"}, true)
+	cmd := ss.seekCommand()
+	ct := testutil.CommandTesters{
+		Command: cmd,
+		Testers: []testutil.CommandTester{
+			noArgsError, firstArgNoNumberError,
+			testutil.ExecTestCase{Args: []string{"-5"}, Result: "seeking by -5.0 seconds", Success: true},
+			testutil.ExecTestCase{Args: []string{"+2.5"}, Result: "seeking by +2.5 seconds", Success: true},
+			testutil.ExecTestCase{Args: []string{"90"}, Result: "seeking to 90.0 seconds", Success: true},
+		},
+	}
+	ct.Test(t)
+}
+
 func TestServerState_volumeCommand(t *testing.T) {
 	ss := serverState{}
 	ss.volume = 0
@@ -150,6 +193,45 @@ func TestServerState_volumeCommand(t *testing.T) {
 	}
 }
 
+func TestServerState_volumeCommandRamp(t *testing.T) {
+	SampleRate = 1000
+	defer func() { SampleRate = 44100 }()
+	ss := serverState{}
+	fs := &fakeSender{}
+	ss.sender = fs
+
+	testutil.CommandTesters{
+		Command: ss.volumeCommand(),
+		Testers: []testutil.CommandTester{
+			testutil.ExecTestCase{Args: []string{".5", "-1"}, Success: false},
+			testutil.ExecTestCase{Args: []string{".5", "2"}, Result: "setting volume to 0.500", Success: true},
+		},
+	}.Test(t)
+	assert.Equal(t, &comm.SetVolumeRequest{Volume: 0.5, RampLength: 2000}, fs.lastMessage, "serverState volumeCommand did not send the ramp length")
+
+	testutil.CommandTesters{
+		Command: ss.volumeCommand(),
+		Testers: []testutil.CommandTester{
+			testutil.ExecTestCase{Args: []string{".25"}, Result: "setting volume to 0.250", Success: true},
+		},
+	}.Test(t)
+	assert.Equal(t, &comm.SetVolumeRequest{Volume: 0.25, RampLength: uint64(sampleCount(VolumeRampDuration))}, fs.lastMessage, "serverState volumeCommand did not use the default ramp duration")
+}
+
+func TestServerState_sampleIndexAt(t *testing.T) {
+	SampleRate, StreamChunkSize, StreamChunkTime = 1000, 4000, 4*time.Second
+	defer func() { SampleRate, StreamChunkSize, StreamChunkTime = 44100, 44100*4, 4*time.Second }()
+
+	ss := serverState{}
+	assert.Equal(t, uint64(0), ss.sampleIndexAt(5e9), "sampleIndexAt did not return 0 before streaming")
+
+	ss.streamStart = 10e9
+	assert.Equal(t, uint64(0), ss.sampleIndexAt(5e9), "sampleIndexAt did not return 0 before the stream start")
+	assert.Equal(t, uint64(0), ss.sampleIndexAt(10e9), "sampleIndexAt returned the wrong index at the stream start")
+	assert.Equal(t, uint64(2500), ss.sampleIndexAt(12.5e9), "sampleIndexAt returned the wrong index in the first chunk")
+	assert.Equal(t, uint64(9000), ss.sampleIndexAt(19e9), "sampleIndexAt returned the wrong index in the third chunk")
+}
+
 func TestServerState_pauseCommand(t *testing.T) {
 	testServerStatePauseOrResumeCommand(t, false)
 }
@@ -201,3 +283,23 @@ func newTestServerState(songs []string, playing bool) serverState {
 	ss.playlist.SetPlaying(playing)
 	return ss
 }
+
+func TestDescribeSong(t *testing.T) {
+	assert.Equal(t, "", describeSong(metadata.SongMetadata{Artist: "artist"}), "describeSong described a song without title")
+	assert.Equal(t, "title", describeSong(metadata.SongMetadata{Title: "title"}), "describeSong did not describe a song with only a title")
+	assert.Equal(t, "artist - title (album, album by various, 2019, disc 1/2, track 3, Rock, composed by composer) 3:05",
+		describeSong(metadata.SongMetadata{Title: "title", Artist: "artist", Album: "album", AlbumArtist: "various", Year: 2019,
+			Disc: 1, DiscTotal: 2, Track: 3, Genre: "Rock", Composer: "composer", Comment: "comment", Duration: 185 * time.Second}),
+		"describeSong did not describe all metadata")
+	assert.Equal(t, "title (track 3/12) 1:00:00", describeSong(metadata.SongMetadata{Title: "title", Track: 3, TrackTotal: 12,
+		Duration: time.Hour}), "describeSong did not describe the track total and a long duration")
+}
+
+func TestServerState_playlistCommandMetadata(t *testing.T) {
+	ss := newTestServerState([]string{"a.mp3", "b.mp3"}, true)
+	ss.metadataProvider = fakeMetadataProvider{"a.mp3": metadata.SongMetadata{Title: "A", Artist: "Artist", Track: 1, Duration: time.Minute}}
+	result, ok := ss.playlistCommandExc([]string{})
+	assert.True(t, ok, "playlist command failed")
+	assert.Equal(t, "Current Playlist (Playing): \n  [0] a.mp3: Artist - A (track 1) 1:00\n  [1] b.mp3\nCurrent Song: None", result,
+		"playlist command did not describe the songs")
+}
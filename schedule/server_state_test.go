@@ -2,6 +2,7 @@ package schedule
 
 import (
 	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/metadata"
 	"github.com/LogicalOverflow/music-sync/testutil"
 	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
@@ -98,3 +99,82 @@ func TestToWireLyrics(t *testing.T) {
 		assert.Equal(t, c.result, actual, "toWireLyrics returned the wrong wire lyrics")
 	}
 }
+
+func TestServerState_createStreamTitleHandler(t *testing.T) {
+	fms := &fakeMessageSender{}
+	ss := &serverState{sender: fms}
+	handler := ss.createStreamTitleHandler()
+
+	handler(100, "http://radio.example.com/stream", "Artist - Some - Title")
+	handler(200, "http://radio.example.com/stream", "Station Jingle")
+
+	assertFakeMessageSenderMessages(t, fms, []proto.Message{
+		&comm.NewSongInfo{
+			FirstSampleOfSongIndex: 100,
+			SongFileName:           "http://radio.example.com/stream",
+			Metadata:               &comm.NewSongInfo_SongMetadata{Title: "Some - Title", Artist: "Artist"},
+		},
+		&comm.NewSongInfo{
+			FirstSampleOfSongIndex: 200,
+			SongFileName:           "http://radio.example.com/stream",
+			Metadata:               &comm.NewSongInfo_SongMetadata{Title: "Station Jingle"},
+		},
+	}, "stream title handler")
+	assert.Equal(t, uint64(200), ss.newestSong.FirstSampleOfSongIndex, "stream title handler did not update the newest song")
+}
+
+type fakeLyricsProvider map[string][]metadata.LyricsLine
+
+func (flp fakeLyricsProvider) CollectLyrics(song string) []metadata.LyricsLine {
+	return flp[song]
+}
+
+type fakeTrimProvider map[string]metadata.TrimPoints
+
+func (ftp fakeTrimProvider) CollectTrimPoints(song string) metadata.TrimPoints {
+	return ftp[song]
+}
+
+func TestServerState_createNewSongHandlerTrimmed(t *testing.T) {
+	oldSampleRate := SampleRate
+	defer func() { SampleRate = oldSampleRate }()
+	SampleRate = 1000
+
+	fms := &fakeMessageSender{}
+	ss := &serverState{
+		sender:           fms,
+		lyricsProvider:   fakeLyricsProvider{"song": {{{Timestamp: 3000, Caption: "a"}, {Timestamp: 4500, Caption: "b"}}}},
+		metadataProvider: fakeMetadataProvider{},
+	}
+	ss.createNewSongHandler()(100, "song", 5000, 2500, 1)
+
+	assertFakeMessageSenderMessages(t, fms, []proto.Message{
+		&comm.NewSongInfo{
+			FirstSampleOfSongIndex: 100,
+			SongFileName:           "song",
+			SongLength:             5000,
+			Lyrics: []*comm.NewSongInfo_SongLyricsLine{{Atoms: []*comm.NewSongInfo_SongLyricsAtom{
+				{Timestamp: 500, Caption: "a"}, {Timestamp: 2000, Caption: "b"},
+			}}},
+			Metadata:     &comm.NewSongInfo_SongMetadata{},
+			Speed:        1,
+			FilePosition: 2500,
+		},
+	}, "new song handler")
+}
+
+func TestServerState_createTrimHandler(t *testing.T) {
+	oldSampleRate := SampleRate
+	defer func() { SampleRate = oldSampleRate }()
+	SampleRate = 1000
+
+	ss := &serverState{trimProvider: fakeTrimProvider{"song": {Start: 1500, End: 60000}}}
+	handler := ss.createTrimHandler()
+
+	start, end := handler("song")
+	assert.Equal(t, 1500, start, "trim handler returned the wrong start")
+	assert.Equal(t, 60000, end, "trim handler returned the wrong end")
+	start, end = handler("other-song")
+	assert.Zero(t, start, "trim handler returned a start for a song without trim points")
+	assert.Zero(t, end, "trim handler returned an end for a song without trim points")
+}
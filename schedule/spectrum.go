@@ -0,0 +1,45 @@
+package schedule
+
+import (
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/playback"
+	"time"
+)
+
+// SpectrumBands is the number of bands of the spectra sent to the infoers (0 to disable spectra)
+var SpectrumBands = 32
+
+// SpectrumSliceTime is the duration of the time slices a spectrum is sent for
+var SpectrumSliceTime = 50 * time.Millisecond
+
+// spectrumInfo returns the spectra of the slices of a chunk starting at firstSampleIndex or nil, if spectra
+// are disabled
+func spectrumInfo(firstSampleIndex uint64, low, high []float64) *comm.SpectrumInfo {
+	sliceSize := sampleCount(SpectrumSliceTime)
+	if SpectrumBands <= 0 || sliceSize <= 0 {
+		return nil
+	}
+	levels := make([]byte, 0, (len(low)/sliceSize+1)*SpectrumBands)
+	for start := 0; start < len(low); start += sliceSize {
+		end := start + sliceSize
+		if len(low) < end {
+			end = len(low)
+		}
+		for _, l := range playback.Spectrum(low[start:end], high[start:end], SampleRate, SpectrumBands) {
+			levels = append(levels, byte(l*255+0.5))
+		}
+	}
+	return &comm.SpectrumInfo{
+		FirstSampleIndex: firstSampleIndex,
+		SliceSize:        uint64(sliceSize),
+		Bands:            uint32(SpectrumBands),
+		Levels:           levels,
+	}
+}
+
+// sendSpectrum sends the spectra of a chunk to all infoers
+func (ss *serverState) sendSpectrum(firstSampleIndex uint64, low, high []float64) {
+	if info := spectrumInfo(firstSampleIndex, low, high); info != nil {
+		ss.sender.SendMessage(info)
+	}
+}
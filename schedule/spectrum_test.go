@@ -0,0 +1,43 @@
+package schedule
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSpectrumInfo(t *testing.T) {
+	oldSampleRate, oldBands, oldSliceTime := SampleRate, SpectrumBands, SpectrumSliceTime
+	defer func() { SampleRate, SpectrumBands, SpectrumSliceTime = oldSampleRate, oldBands, oldSliceTime }()
+	SampleRate, SpectrumBands, SpectrumSliceTime = 44100, 4, 50*time.Millisecond
+
+	low, high := make([]float64, 5000), make([]float64, 5000)
+	for i := 2205; i < 4410; i++ {
+		low[i] = math.Sin(2 * math.Pi * 1000 * float64(i) / 44100)
+		high[i] = low[i]
+	}
+
+	info := spectrumInfo(100, low, high)
+	if assert.NotNil(t, info, "spectrumInfo returned nil") {
+		assert.Equal(t, uint64(100), info.FirstSampleIndex, "spectrumInfo returned the wrong first sample index")
+		assert.Equal(t, uint64(2205), info.SliceSize, "spectrumInfo returned the wrong slice size")
+		assert.Equal(t, uint32(4), info.Bands, "spectrumInfo returned the wrong number of bands")
+		if assert.Len(t, info.Levels, 3*4, "spectrumInfo did not return the levels of all slices") {
+			assert.Equal(t, []byte{0, 0, 0, 0}, info.Levels[:4], "spectrumInfo returned levels for silence")
+			peak := byte(0)
+			for _, l := range info.Levels[4:8] {
+				if peak < l {
+					peak = l
+				}
+			}
+			assert.True(t, 230 < peak, "spectrumInfo did not return the level of the sine")
+		}
+	}
+
+	SpectrumBands = 0
+	assert.Nil(t, spectrumInfo(100, low, high), "spectrumInfo returned spectra while they are disabled")
+	fms := &fakeMessageSender{}
+	(&serverState{sender: fms}).sendSpectrum(100, low, high)
+	assert.Empty(t, fms.Messages(), "sendSpectrum sent spectra while they are disabled")
+}
@@ -0,0 +1,84 @@
+package schedule
+
+import (
+	"fmt"
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/metadata"
+	"github.com/LogicalOverflow/music-sync/ssh"
+)
+
+const (
+	minSpeed = 0.5
+	maxSpeed = 2.0
+)
+
+// playingSong is the song currently played, which is announced again when its speed changes
+type playingSong struct {
+	info      *comm.NewSongInfo
+	lyrics    []metadata.LyricsLine
+	trimStart int64
+	length    int64
+}
+
+// samplesToMillis converts a number of samples to milliseconds
+func samplesToMillis(samples int64) int64 {
+	return samples * 1000 / int64(SampleRate)
+}
+
+// timed returns the info of the song, starting at startSampleIndex with the song at position. streamed samples of
+// the song were streamed before startSampleIndex. The song length and lyrics are stretched to the speed.
+func (ps playingSong) timed(startSampleIndex uint64, position int64, streamed int64, speed float64) *comm.NewSongInfo {
+	lyrics := metadata.ShiftLyrics(ps.lyrics, -samplesToMillis(ps.trimStart+position))
+	lyrics = metadata.ShiftLyrics(metadata.StretchLyrics(lyrics, speed), samplesToMillis(streamed))
+
+	return &comm.NewSongInfo{
+		FirstSampleOfSongIndex: startSampleIndex,
+		SongFileName:           ps.info.SongFileName,
+		SongLength:             streamed + int64(float64(ps.length-position)/speed),
+		Lyrics:                 toWireLyrics(lyrics),
+		Metadata:               ps.info.Metadata,
+		Live:                   ps.info.Live,
+		SongOffset:             streamed,
+		Speed:                  speed,
+		FilePosition:           ps.trimStart + position,
+		CoverId:                ps.info.CoverId,
+	}
+}
+
+// createSpeedHandler returns a handler, which announces the current song again with its new timing.
+// It is used for speed changes and seeks.
+func (ss *serverState) createSpeedHandler() func(uint64, float64, int64, int64) {
+	return func(sampleIndex uint64, speed float64, songPosition int64, streamed int64) {
+		ss.songMutex.Lock()
+		if ss.song.info == nil || sampleIndex < ss.song.info.FirstSampleOfSongIndex {
+			ss.songMutex.Unlock()
+			return
+		}
+		ss.newestSong = ss.song.timed(sampleIndex, songPosition, streamed, speed)
+		info := ss.newestSong
+		ss.songMutex.Unlock()
+		ss.sender.SendMessage(info)
+	}
+}
+
+func (ss *serverState) speedCommand() ssh.Command {
+	return ssh.Command{
+		Name:  "speed",
+		Usage: "[factor]",
+		Info:  "shows or sets the playback speed without changing the pitch",
+		ExecFunc: func(args []string) (string, bool) {
+			if len(args) == 0 {
+				return fmt.Sprintf("playback speed is %.2f", ss.playlist.Speed()), true
+			}
+			speed, ok := parseFloatParam(args, 0)
+			if !ok {
+				return "", false
+			}
+			if speed < minSpeed || maxSpeed < speed {
+				return fmt.Sprintf("speed must be between %.2f and %.2f", minSpeed, maxSpeed), true
+			}
+			ss.playlist.SetSpeed(speed)
+			return fmt.Sprintf("setting playback speed to %.2f", speed), true
+		},
+	}
+}
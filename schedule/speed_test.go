@@ -0,0 +1,78 @@
+package schedule
+
+import (
+	"github.com/LogicalOverflow/music-sync/comm"
+	"github.com/LogicalOverflow/music-sync/testutil"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestServerState_speedCommand(t *testing.T) {
+	ss := newTestServerState([]string{}, false)
+
+	cmd := ss.speedCommand()
+	assert.NotNil(t, cmd, "serverState speedCommand is nil")
+
+	ct := testutil.CommandTesters{
+		Command: cmd,
+		Testers: []testutil.CommandTester{
+			testutil.ExecTestCase{Args: []string{}, Result: "playback speed is 1.00", Success: true},
+			firstArgNoNumberError,
+			testutil.ExecTestCase{Args: []string{"0.1"}, Result: "speed must be between 0.50 and 2.00", Success: true},
+			testutil.ExecTestCase{Args: []string{"3"}, Result: "speed must be between 0.50 and 2.00", Success: true},
+			testutil.ExecTestCase{Args: []string{"0.8"}, Result: "setting playback speed to 0.80", Success: true},
+			testutil.ExecTestCase{Args: []string{}, Result: "playback speed is 0.80", Success: true},
+		},
+	}
+	ct.Test(t)
+	assert.Equal(t, 0.8, ss.playlist.Speed(), "speed command did not set the playlist speed")
+}
+
+func TestServerState_speedHandlers(t *testing.T) {
+	oldSampleRate := SampleRate
+	defer func() { SampleRate = oldSampleRate }()
+	SampleRate = 1000
+
+	fms := &fakeMessageSender{}
+	ss := &serverState{
+		sender:           fms,
+		lyricsProvider:   fakeLyricsProvider{"song": {{{Timestamp: 2000, Caption: "a"}}, {{Timestamp: 8000, Caption: "b"}}}},
+		metadataProvider: fakeMetadataProvider{"song": {Title: "Title"}},
+	}
+	md := &comm.NewSongInfo_SongMetadata{Title: "Title"}
+
+	ss.createNewSongHandler()(100, "song", 10000, 1000, 0.5)
+	speedHandler := ss.createSpeedHandler()
+	speedHandler(8100, 2, 4000, 8000)
+	speedHandler(50, 1, 0, 0)
+
+	assertFakeMessageSenderMessages(t, fms, []proto.Message{
+		&comm.NewSongInfo{
+			FirstSampleOfSongIndex: 100,
+			SongFileName:           "song",
+			SongLength:             20000,
+			Lyrics: []*comm.NewSongInfo_SongLyricsLine{
+				{Atoms: []*comm.NewSongInfo_SongLyricsAtom{{Timestamp: 2000, Caption: "a"}}},
+				{Atoms: []*comm.NewSongInfo_SongLyricsAtom{{Timestamp: 14000, Caption: "b"}}},
+			},
+			Metadata:     md,
+			Speed:        0.5,
+			FilePosition: 1000,
+		},
+		&comm.NewSongInfo{
+			FirstSampleOfSongIndex: 8100,
+			SongFileName:           "song",
+			SongLength:             11000,
+			Lyrics: []*comm.NewSongInfo_SongLyricsLine{
+				{Atoms: []*comm.NewSongInfo_SongLyricsAtom{{Timestamp: 6500, Caption: "a"}}},
+				{Atoms: []*comm.NewSongInfo_SongLyricsAtom{{Timestamp: 9500, Caption: "b"}}},
+			},
+			Metadata:     md,
+			SongOffset:   8000,
+			Speed:        2,
+			FilePosition: 5000,
+		},
+	}, "speed handlers")
+	assert.Equal(t, uint64(8100), ss.newestSong.FirstSampleOfSongIndex, "speed handler did not update the newest song")
+}
@@ -2,6 +2,7 @@ package ssh
 
 import (
 	"fmt"
+	"github.com/LogicalOverflow/music-sync/metadata"
 	"github.com/LogicalOverflow/music-sync/playback"
 	"github.com/LogicalOverflow/music-sync/util"
 	"os"
@@ -32,13 +33,24 @@ type Command struct {
 	// Exec runs the command. It is passed the arguments as a string slice.
 	// If it returns false, a usage message is printed, otherwise the returned string is printed
 	ExecFunc func(args []string) (string, bool)
+	// UserExecFunc (optional) is used instead of ExecFunc for commands which depend on the user executing them.
+	// It is passed the name of the user and the arguments.
+	UserExecFunc func(user string, args []string) (string, bool)
 	// OptionsFunc (optional) is used for auto completion. It is passed a prefix and the number of the argument and should
 	// return all possible completion options
 	OptionsFunc func(prefix string, arg int) []string
 }
 
-// Exec executes ExecFunc
+// Exec executes ExecFunc (or UserExecFunc without a user)
 func (command Command) Exec(args []string) (string, bool) {
+	return command.ExecAs("", args)
+}
+
+// ExecAs executes UserExecFunc (if provided) as user or ExecFunc
+func (command Command) ExecAs(user string, args []string) (string, bool) {
+	if command.UserExecFunc != nil {
+		return command.UserExecFunc(user, args)
+	}
 	return command.ExecFunc(args)
 }
 
@@ -71,6 +83,20 @@ func commandByName(name string) *Command {
 	return nil
 }
 
+// RunCommand executes the registered command name as user, the same way it is executed from the terminal.
+// It returns an error, if the command does not exist or the arguments are invalid.
+func RunCommand(user, name string, args []string) (string, error) {
+	c := commandByName(name)
+	if c == nil {
+		return "", fmt.Errorf("command '%s' does not exist", name)
+	}
+	msg, ok := c.ExecAs(user, args)
+	if !ok {
+		return "", fmt.Errorf("%s", c.usage())
+	}
+	return strings.TrimSuffix(msg, "\n"), nil
+}
+
 var helpCommand = Command{
 	Name:  "help",
 	Usage: "[command name]",
@@ -131,7 +157,8 @@ var lsCommand = Command{
 		if 0 < len(args) {
 			subDir = args[0]
 		}
-		songs := util.FilterSongs(util.ListAllFiles(playback.AudioDir, subDir))
+		songs := playback.ListSongs(subDir)
+		metadata.SortSongs(songs)
 		return strings.Join(songs, "\n"), true
 	},
 	OptionsFunc: func(prefix string, arg int) []string {
@@ -131,3 +131,41 @@ func songFilesInDir(p ...string) []string {
 	}
 	return ss
 }
+
+func TestCommand_ExecAs(t *testing.T) {
+	cmd := Command{ExecFunc: func(args []string) (string, bool) { return "exec " + strings.Join(args, " "), true }}
+	r, _ := cmd.ExecAs("test-user", []string{"a", "b"})
+	assert.Equal(t, "exec a b", r, "command ExecAs did not call ExecFunc without UserExecFunc")
+
+	cmd.UserExecFunc = func(user string, args []string) (string, bool) { return user + " " + strings.Join(args, " "), true }
+	r, _ = cmd.ExecAs("test-user", []string{"a", "b"})
+	assert.Equal(t, "test-user a b", r, "command ExecAs did not call UserExecFunc")
+	r, _ = cmd.Exec([]string{"a"})
+	assert.Equal(t, " a", r, "command Exec did not call UserExecFunc without a user")
+}
+
+func TestRunCommand(t *testing.T) {
+	oldCommands := make([]Command, len(commands))
+	copy(oldCommands, commands)
+	defer func() { commands = oldCommands }()
+
+	RegisterCommand(Command{
+		Name:  "test-run",
+		Usage: "arg",
+		UserExecFunc: func(user string, args []string) (string, bool) {
+			if len(args) == 0 {
+				return "", false
+			}
+			return user + " " + args[0] + "\n", true
+		},
+	})
+
+	r, err := RunCommand("test-user", "test-run", []string{"a"})
+	if assert.NoError(t, err, "RunCommand returned an error") {
+		assert.Equal(t, "test-user a", r, "RunCommand returned the wrong result")
+	}
+	_, err = RunCommand("test-user", "test-run", []string{})
+	assert.EqualError(t, err, "Usage: test-run arg", "RunCommand did not return the usage for invalid arguments")
+	_, err = RunCommand("test-user", "missing", []string{})
+	assert.Error(t, err, "RunCommand did not return an error for a missing command")
+}
@@ -1,6 +1,7 @@
 package ssh
 
 import (
+	"crypto/subtle"
 	"github.com/LogicalOverflow/music-sync/util"
 	"github.com/gliderlabs/ssh"
 )
@@ -26,8 +27,7 @@ func getHostKeyOption() ssh.Option {
 
 func createPasswordAuthOption(users map[string]UserAuth) ssh.Option {
 	return ssh.PasswordAuth(func(ctx ssh.Context, password string) bool {
-		auth, ok := users[ctx.User()]
-		if ok && auth.Password != "" && auth.Password == password {
+		if CheckPassword(users, ctx.User(), password) {
 			return true
 		}
 		logger.Warnf("failed ssh login attempt from %s as %s using a password", ctx.RemoteAddr(), ctx.User())
@@ -35,6 +35,25 @@ func createPasswordAuthOption(users map[string]UserAuth) ssh.Option {
 	})
 }
 
+// CheckPassword returns true, if user is in users and has password as its (non-empty) password
+func CheckPassword(users map[string]UserAuth, user, password string) bool {
+	auth, ok := users[user]
+	return ok && auth.Password != "" && subtle.ConstantTimeCompare([]byte(auth.Password), []byte(password)) == 1
+}
+
+// UserByToken returns the user in users with token as its (non-empty) api token
+func UserByToken(users map[string]UserAuth, token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+	for user, auth := range users {
+		if subtle.ConstantTimeCompare([]byte(auth.Token), []byte(token)) == 1 {
+			return user, true
+		}
+	}
+	return "", false
+}
+
 func createPublicKeyAuthOption(users map[string]UserAuth) ssh.Option {
 	return ssh.PublicKeyAuth(func(ctx ssh.Context, key ssh.PublicKey) bool {
 		auth, ok := users[ctx.User()]
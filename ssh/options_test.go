@@ -86,6 +86,23 @@ func TestCreatePasswordAuthOption(t *testing.T) {
 	}
 }
 
+func TestCheckPassword(t *testing.T) {
+	for _, c := range passwordCases {
+		assert.Equal(t, c.success, CheckPassword(testUsers, c.username, c.password), "CheckPassword did not return correctly for case %v", c)
+	}
+}
+
+func TestUserByToken(t *testing.T) {
+	users := map[string]UserAuth{"token-user": {Token: "secret-token"}, "tokenless-user": {Password: "password"}}
+	user, ok := UserByToken(users, "secret-token")
+	assert.True(t, ok, "UserByToken did not find the user of a token")
+	assert.Equal(t, "token-user", user, "UserByToken returned the wrong user")
+	_, ok = UserByToken(users, "wrong-token")
+	assert.False(t, ok, "UserByToken accepted a wrong token")
+	_, ok = UserByToken(users, "")
+	assert.False(t, ok, "UserByToken accepted an empty token")
+}
+
 func TestCreatePublicKeyAuthOption(t *testing.T) {
 	log.DefaultCutoffLevel = log.LevelOff
 
@@ -6,9 +6,41 @@ import (
 	"github.com/chzyer/readline"
 	"github.com/gliderlabs/ssh"
 	"io"
+	"sort"
 	"strings"
+	"sync"
 )
 
+var connectedUsers = make(map[string]int)
+var connectedUsersMutex sync.RWMutex
+
+// ConnectedUsers returns the names of all users currently connected to the ssh control interface
+func ConnectedUsers() []string {
+	connectedUsersMutex.RLock()
+	defer connectedUsersMutex.RUnlock()
+	users := make([]string, 0, len(connectedUsers))
+	for u := range connectedUsers {
+		users = append(users, u)
+	}
+	sort.Strings(users)
+	return users
+}
+
+func addConnectedUser(user string) {
+	connectedUsersMutex.Lock()
+	defer connectedUsersMutex.Unlock()
+	connectedUsers[user]++
+}
+
+func removeConnectedUser(user string) {
+	connectedUsersMutex.Lock()
+	defer connectedUsersMutex.Unlock()
+	connectedUsers[user]--
+	if connectedUsers[user] <= 0 {
+		delete(connectedUsers, user)
+	}
+}
+
 type session struct {
 	ssh.Session
 	cfg *readline.Config
@@ -97,7 +129,7 @@ func (s *session) handleLine(line string) bool {
 }
 
 func (s *session) execCommand(c Command, args []string) {
-	msg, ok := c.Exec(args)
+	msg, ok := c.ExecAs(s.User(), args)
 	if ok {
 		if strings.HasSuffix(msg, "\n") {
 			msg = msg[:len(msg)-1]
@@ -136,6 +168,9 @@ func sessionHandler(sshSession ssh.Session) {
 		return
 	}
 
+	addConnectedUser(s.User())
+	defer removeConnectedUser(s.User())
+
 	defer s.ex.Close()
 	s.ex.Clean()
 	s.readLoop()
@@ -0,0 +1,21 @@
+package ssh
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConnectedUsers(t *testing.T) {
+	assert.Empty(t, ConnectedUsers(), "ConnectedUsers returned users before any connected")
+
+	addConnectedUser("user-b")
+	addConnectedUser("user-a")
+	addConnectedUser("user-b")
+	assert.Equal(t, []string{"user-a", "user-b"}, ConnectedUsers(), "ConnectedUsers returned the wrong users")
+
+	removeConnectedUser("user-b")
+	assert.Equal(t, []string{"user-a", "user-b"}, ConnectedUsers(), "ConnectedUsers removed a user with a session left")
+	removeConnectedUser("user-b")
+	removeConnectedUser("user-a")
+	assert.Empty(t, ConnectedUsers(), "ConnectedUsers returned users after all disconnected")
+}
@@ -16,6 +16,8 @@ var HostKeyFile string
 type UserAuth struct {
 	Password string `json:"password"`
 	PubKey   []byte `json:"pubKey"`
+	// Token (optional) authenticates the user to the http api
+	Token string `json:"token"`
 }
 
 var logger = log.GetLogger("ssh")
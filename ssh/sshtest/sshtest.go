@@ -0,0 +1,57 @@
+// Package sshtest provides fake ssh commands for the tests of packages running commands through ssh.RunCommand
+package sshtest
+
+import (
+	"github.com/LogicalOverflow/music-sync/ssh"
+	"strings"
+	"sync"
+)
+
+// ExecFunc executes a fake command name with args as user
+type ExecFunc func(user, name string, args []string) (string, bool)
+
+// CommandRecorder records the successful calls of the fake commands it registered
+type CommandRecorder struct {
+	mutex sync.Mutex
+	calls []string
+}
+
+// RegisterCommands registers fake commands called names, which are executed by exec. Their successful calls are
+// recorded as "<user> <name> <args...>". If exec is nil, the commands succeed and return their call.
+func RegisterCommands(exec ExecFunc, names ...string) *CommandRecorder {
+	cr := &CommandRecorder{}
+	for _, name := range names {
+		name := name
+		ssh.RegisterCommand(ssh.Command{
+			Name: name,
+			UserExecFunc: func(user string, args []string) (string, bool) {
+				call := strings.Join(append([]string{user, name}, args...), " ")
+				msg, ok := call, true
+				if exec != nil {
+					msg, ok = exec(user, name, args)
+				}
+				if ok {
+					cr.mutex.Lock()
+					cr.calls = append(cr.calls, call)
+					cr.mutex.Unlock()
+				}
+				return msg, ok
+			},
+		})
+	}
+	return cr
+}
+
+// Calls returns the calls recorded since the last reset
+func (cr *CommandRecorder) Calls() []string {
+	cr.mutex.Lock()
+	defer cr.mutex.Unlock()
+	return append([]string(nil), cr.calls...)
+}
+
+// Reset forgets the recorded calls
+func (cr *CommandRecorder) Reset() {
+	cr.mutex.Lock()
+	defer cr.mutex.Unlock()
+	cr.calls = nil
+}
@@ -8,6 +8,7 @@ import (
 type commandInterface interface {
 	GetName() string
 	Exec([]string) (string, bool)
+	ExecAs(string, []string) (string, bool)
 	Options(string, int) []string
 }
 
@@ -31,6 +32,7 @@ func (otc OptionsTestCase) Test(t *testing.T, command commandInterface) {
 
 // ExecTestCase tests the result of calling the exec func on a command
 type ExecTestCase struct {
+	User    string
 	Args    []string
 	Result  string
 	Success bool
@@ -42,7 +44,7 @@ func (etc ExecTestCase) Test(t *testing.T, command commandInterface) {
 	if etc.Before != nil {
 		etc.Before()
 	}
-	r, s := command.Exec(etc.Args)
+	r, s := command.ExecAs(etc.User, etc.Args)
 	if assert.Equal(t, etc.Success, s, "command %s returned wrong success flag for args %v", command.GetName(), etc.Args) && etc.Success {
 		assert.Equal(t, etc.Result, r, "command %s returned wrong result for args %v", command.GetName(), etc.Args)
 	}